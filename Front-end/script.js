@@ -6,6 +6,7 @@ document.addEventListener('DOMContentLoaded', function () {
     setupOrderFormListener();
     setupMapControls();
     carregarPedidos();
+    acompanharStatusPedidos();
 });
 
 function setupAddressListener() {
@@ -217,11 +218,16 @@ function carregarPedidos() {
             console.error('Erro ao buscar pedidos:', error);
             document.getElementById('pedidos').innerHTML = '<p>Erro ao carregar pedidos. Tente novamente mais tarde.</p>';
         });
-}function exibirPedidos(pedidos) {
+}
+// Versão de cada pedido exibido, enviada no If-Match ao excluir: a API recusa a exclusão sem ela (428)
+// ou se o pedido tiver sido alterado por outra pessoa depois de carregado (412).
+const versoesPedidos = {};
+function exibirPedidos(pedidos) {
     const sectionPedidos = document.getElementById('pedidos');
     sectionPedidos.innerHTML = ''; // Limpa o conteúdo anterior
 
     pedidos.forEach(pedido => {
+        versoesPedidos[pedido.id] = pedido.version;
         // Monta o endereço com base nos novos campos
         const address = `${pedido.logradouro}, ${pedido.numero}, ${pedido.bairro}, ${pedido.complemento}, ${pedido.cidade} - ${pedido.estado}, ${pedido.pais}`;
 
@@ -235,7 +241,7 @@ function carregarPedidos() {
                 <p><strong>Teste Técnico:</strong> ${pedido.test_name}</p>
                 <p><strong>Peso:</strong> ${pedido.weight} kg</p>
                 <p><strong>Endereço:</strong> ${address}</p>
-                <p><strong>Status:</strong> ${pedido.order_status}</p>
+                <p><strong>Status:</strong> <span id="status-${pedido.id}">${pedido.order_status}</span></p>
             </div>
             <div class="pedido-acoes">
                 <button onclick="excluirPedido(${pedido.id})">Excluir</button>
@@ -247,6 +253,22 @@ function carregarPedidos() {
         sectionPedidos.appendChild(pedidoCard);
     });
 }
+// Atualiza o status dos pedidos exibidos em tempo real, sem recarregar a página.
+// O EventSource reconecta sozinho e envia o Last-Event-ID, então nenhuma mudança é perdida.
+function acompanharStatusPedidos() {
+    if (!document.getElementById('pedidos') || !window.EventSource) {
+        return;
+    }
+    const stream = new EventSource('http://localhost:8080/api/v1/deliveries/stream');
+    stream.addEventListener('status_changed', function (event) {
+        const mudanca = JSON.parse(event.data);
+        const status = document.getElementById(`status-${mudanca.delivery.id}`);
+        if (status) {
+            status.textContent = mudanca.delivery.order_status;
+            versoesPedidos[mudanca.delivery.id] = mudanca.delivery.version;
+        }
+    });
+}
 function verMapa(id, latitude, longitude) {
     // Seleciona o contêiner do mapa
     const mapaContainer = document.getElementById(`mapa-${id}`);
@@ -275,11 +297,15 @@ function excluirPedido(id) {
     if (confirm('Tem certeza que deseja excluir este pedido?')) {
         fetch(`http://localhost:8080/api/v1/deliveries/${id}`, {
             method: 'DELETE',
+            headers: { 'If-Match': `"${versoesPedidos[id]}"` },
         })
             .then(response => {
                 if (response.ok) {
                     alert('Pedido excluído com sucesso!');
                     carregarPedidos(); // Recarrega a lista de pedidos
+                } else if (response.status === 412) {
+                    alert('O pedido foi alterado por outra pessoa. A lista será recarregada; confira antes de excluir.');
+                    carregarPedidos();
                 } else {
                     alert('Erro ao excluir pedido.');
                 }
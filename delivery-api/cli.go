@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/gorm"
+
+	"delivery-api/config"
+	"delivery-api/internal/blob"
+	"delivery-api/internal/migrations"
+)
+
+// newFlagSet cria o conjunto de flags de um comando, com a ajuda no mesmo formato em todos eles.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: delivery-api %s\n\nflags:\n", usage)
+		flags.PrintDefaults()
+		fmt.Fprintln(flags.Output(), "\nThe database and the other settings come from the same environment variables as the API (see .env.example).")
+	}
+	return flags
+}
+
+// signalContext retorna um contexto cancelado ao receber SIGINT (Ctrl+C) ou SIGTERM.
+// As consultas em andamento são canceladas junto com ele.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// openDatabase conecta ao banco configurado e verifica se o schema está na versão esperada pelo código.
+// Se DB_AUTO_MIGRATE=true, as migrações pendentes são aplicadas antes da verificação.
+// É usado por todos os comandos que leem ou gravam dados; o comando migrate conecta sem a verificação.
+func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	db, err := config.OpenDatabase(cfg.Database, cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up()
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range applied {
+			log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		}
+	}
+	if err := migrator.Check(); err != nil {
+		return nil, fmt.Errorf("%w (run \"delivery-api migrate up\" or set DB_AUTO_MIGRATE=true)", err)
+	}
+	return db, nil
+}
+
+// openBlobStore cria o armazenamento dos arquivos enviados à API, conforme BLOB_STORE (já validado em config.Load).
+func openBlobStore(cfg *config.Config) (blob.Store, error) {
+	switch cfg.Blob.Store {
+	case config.BlobStoreLocal:
+		return blob.NewLocalStore(cfg.Blob.Dir)
+	}
+	return nil, fmt.Errorf("unsupported blob store %q", cfg.Blob.Store)
+}
+
+// openInput abre o arquivo de entrada de um comando; "-" é a entrada padrão.
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// createOutput cria o arquivo de saída de um comando; "-" é a saída padrão.
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+// nopWriteCloser adapta a saída padrão para io.WriteCloser sem fechá-la.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close não faz nada: a saída padrão continua aberta.
+func (nopWriteCloser) Close() error {
+	return nil
+}
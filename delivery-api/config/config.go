@@ -4,17 +4,155 @@ import (
 	"fmt"
 	"log"
 	"os"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Tipos de banco de dados suportados (DB_TYPE).
+const (
+	DatabaseSQLite   = "sqlite"
+	DatabasePostgres = "postgres"
+	DatabaseMySQL    = "mysql"
+)
+
+// Níveis de log aceitos em LOG_LEVEL.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Formatos de log aceitos em LOG_FORMAT.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Config reúne toda a configuração da aplicação, lida das variáveis de ambiente (e do arquivo .env).
+type Config struct {
+	Database           DatabaseConfig
+	HTTP               HTTPConfig
+	LogLevel           string        // LOG_LEVEL: debug, info, warn ou error (padrão: info)
+	LogFormat          string        // LOG_FORMAT: json ou text (padrão: json)
+	IdempotencyTTL     time.Duration // IDEMPOTENCY_TTL: tempo em que as respostas idempotentes ficam guardadas (padrão: 24h)
+	OutboxPollInterval time.Duration // OUTBOX_POLL_INTERVAL: intervalo de leitura da outbox (padrão: 1s)
+	OutboxMaxAttempts  int           // OUTBOX_MAX_ATTEMPTS: tentativas antes de um evento da outbox ser marcado como morto (padrão: 20)
+	// CUBIC_WEIGHT_DIVISOR: divisor do peso cubado dos volumes, em centímetros cúbicos por quilo (padrão: 6000); ao mudar, rode "delivery-api recompute weights"
+	CubicWeightDivisor float64
+	Webhooks           WebhookConfig
+	Tracing            TracingConfig
+	Scheduler          SchedulerConfig
+	Blob               BlobConfig
+}
+
+// DatabaseConfig reúne a configuração da conexão com o banco de dados.
+// Se DSN for informado, ele é usado como está; caso contrário, é montado a partir dos demais campos.
+type DatabaseConfig struct {
+	Type            string        // DB_TYPE: sqlite, postgres ou mysql (padrão: mysql)
+	DSN             string        // DB_DSN: string de conexão completa (opcional)
+	Path            string        // DB_PATH: arquivo do SQLite (padrão: gorm.db)
+	Host            string        // DB_HOST (padrão: localhost)
+	Port            int           // DB_PORT (padrão: 3306 no MySQL, 5432 no PostgreSQL)
+	User            string        // DB_USER (padrão: root no MySQL, postgres no PostgreSQL)
+	Password        string        // DB_PASSWORD
+	Name            string        // DB_NAME (padrão: desafio)
+	SSLMode         string        // DB_SSLMODE, apenas PostgreSQL (padrão: disable)
+	MaxOpenConns    int           // DB_MAX_OPEN_CONNS: conexões abertas no máximo (padrão: 25)
+	MaxIdleConns    int           // DB_MAX_IDLE_CONNS: conexões ociosas mantidas no pool (padrão: 10)
+	ConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME: tempo máximo de vida de uma conexão (padrão: 30m)
+	AutoMigrate     bool          // DB_AUTO_MIGRATE: aplica as migrações pendentes ao iniciar (padrão: false)
+}
+
+// HTTPConfig reúne a configuração do servidor HTTP.
+type HTTPConfig struct {
+	Addr        string   // HTTP_ADDR: endereço em que o servidor escuta (padrão: :8080)
+	CORSOrigins []string // CORS_ALLOWED_ORIGINS: origens permitidas, separadas por vírgula (padrão: *)
+	// HTTP_SHUTDOWN_TIMEOUT: tempo máximo para as requisições em andamento terminarem no desligamento (padrão: 15s)
+	ShutdownTimeout time.Duration
+	// QUERY_TIMEOUT: tempo máximo de cada requisição; ao estourar, as consultas ao banco são canceladas (padrão: 10s)
+	QueryTimeout time.Duration
+	// QUERY_TIMEOUT_ROUTES: tempos específicos por rota, no formato "MÉTODO /rota=duração", separados por vírgula.
+	// Os valores informados se somam aos padrões de DefaultRouteTimeouts; 0 desativa o limite na rota.
+	RouteTimeouts map[string]time.Duration
+}
+
+// DefaultRouteTimeouts são os tempos por rota usados quando QUERY_TIMEOUT_ROUTES não os redefine:
+// exportação e importação percorrem muitas linhas, e o stream de status fica aberto indefinidamente.
+var DefaultRouteTimeouts = map[string]time.Duration{
+	"GET /api/v1/clients/export":     5 * time.Minute,
+	"GET /api/v1/deliveries/export":  5 * time.Minute,
+	"POST /api/v1/deliveries/import": 2 * time.Minute,
+	"GET /api/v1/deliveries/stream":  0,
+}
+
+// WebhookConfig reúne a configuração de envio dos webhooks.
+type WebhookConfig struct {
+	MaxAttempts         int           // WEBHOOK_MAX_ATTEMPTS: tentativas antes da lista de mensagens mortas (padrão: 8)
+	RetryBase           time.Duration // WEBHOOK_RETRY_BASE: espera após a primeira falha (padrão: 30s)
+	Timeout             time.Duration // WEBHOOK_TIMEOUT: tempo máximo de cada envio (padrão: 10s)
+	AllowPrivateTargets bool          // WEBHOOK_ALLOW_PRIVATE_TARGETS: aceita destinos em endereços internos, só para desenvolvimento (padrão: false)
+}
+
+// Exportadores de spans aceitos em TRACING_EXPORTER.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingConfig reúne a configuração do tracing com OpenTelemetry.
+type TracingConfig struct {
+	Exporter     string  // TRACING_EXPORTER: none, stdout, file ou otlp (padrão: none)
+	File         string  // TRACING_FILE: arquivo em que o exportador file acrescenta os spans (padrão: traces.json)
+	OTLPEndpoint string  // TRACING_OTLP_ENDPOINT: host:porta do coletor OTLP/HTTP (padrão: OTEL_EXPORTER_OTLP_ENDPOINT ou localhost:4318)
+	OTLPInsecure bool    // TRACING_OTLP_INSECURE: envia ao coletor sem TLS (padrão: false)
+	SampleRatio  float64 // TRACING_SAMPLE_RATIO: fração dos traces gravados, de 0 a 1 (padrão: 1)
+	ServiceName  string  // TRACING_SERVICE_NAME: nome do serviço nos spans (padrão: delivery-api)
+}
+
+// SchedulerConfig reúne a configuração das rotinas em segundo plano.
+// As agendas são expressões cron de cinco campos (minuto hora dia mês dia-da-semana), no fuso horário do servidor;
+// uma agenda vazia deixa a rotina apenas com a execução manual (POST /api/v1/admin/jobs/:name/run).
+type SchedulerConfig struct {
+	Enabled         bool          // SCHEDULER_ENABLED: executa as rotinas nos horários agendados (padrão: true)
+	LeaseTTL        time.Duration // SCHEDULER_LEASE_TTL: tempo máximo de cada execução, reservada para uma instância (padrão: 5m)
+	OverdueSchedule string        // SCHEDULER_OVERDUE_SCHEDULE: agenda da sinalização das entregas atrasadas (padrão: */15 * * * *)
+	StaleSchedule   string        // SCHEDULER_STALE_SCHEDULE: agenda do cancelamento das entregas pendentes esquecidas (padrão: 0 * * * *)
+	StalePendingAge time.Duration // SCHEDULER_STALE_PENDING_AGE: tempo como pendente até o cancelamento automático (padrão: 720h)
+	SummarySchedule string        // SCHEDULER_SUMMARY_SCHEDULE: agenda do resumo diário do dia anterior (padrão: 5 0 * * *)
+	ZonesSchedule   string        // SCHEDULER_ZONES_SCHEDULE: agenda do recálculo das zonas das entregas (padrão: 30 3 * * *)
+}
+
+// Armazenamentos de arquivos aceitos em BLOB_STORE.
+const (
+	BlobStoreLocal = "local"
+)
+
+// BlobConfig reúne a configuração do armazenamento dos arquivos enviados à API (como os comprovantes de entrega).
+type BlobConfig struct {
+	Store string // BLOB_STORE: armazenamento dos arquivos; por enquanto apenas local (padrão: local)
+	Dir   string // BLOB_DIR: diretório dos arquivos no armazenamento local (padrão: data/blobs)
+}
+
+// Error é retornado por Load e Validate com todos os problemas encontrados na configuração,
+// para que todos possam ser corrigidos de uma vez.
+type Error struct {
+	Problems []string
+}
+
+// Error implementa a interface error, listando um problema por linha.
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
 // LoadEnv carrega as variáveis de ambiente de um arquivo .env.
 // Se o arquivo .env não for encontrado, o sistema usará as variáveis de ambiente do sistema operacional.
+// Variáveis já definidas no ambiente têm prioridade sobre as do arquivo.
 func LoadEnv() {
 	err := godotenv.Load()
 	if err != nil {
@@ -22,62 +160,347 @@ func LoadEnv() {
 	}
 }
 
-// GetDatabaseConnection estabelece uma conexão com o banco de dados com base no tipo de banco de dados configurado.
-// Ele suporta SQLite, PostgreSQL e MySQL.
-// Retorna uma instância do GORM (*gorm.DB) ou um erro, caso a conexão falhe.
-func GetDatabaseConnection() (*gorm.DB, error) {
-	// Carrega as variáveis de ambiente do arquivo .env ou do sistema.
+// Load lê a configuração das variáveis de ambiente (e do arquivo .env), aplica os valores padrão e a valida.
+// Valores que não podem ser convertidos (por exemplo, DB_PORT=abc) também são reportados, junto com os
+// problemas de validação, em um único *Error.
+func Load() (*Config, error) {
 	LoadEnv()
 
-	// Define o tipo de banco de dados a ser utilizado.
-	// Aqui, foi definido diretamente como "mysql" para facilitar a execução pelos recrutadores.
-	// Em um ambiente real, você pode usar: dbType := os.Getenv("DATABASE_TYPE")
-	dbType := "mysql"
-
-	var db *gorm.DB
-	var err error
-
-	// Escolhe o driver de banco de dados com base no tipo configurado.
-	switch dbType {
-	case "sqlite":
-		// Configuração para SQLite (banco de dados embutido).
-		// O banco de dados será criado no arquivo "gorm.db".
-		db, err = gorm.Open(sqlite.Open("gorm.db"), &gorm.Config{})
-	case "postgres":
-		// Configuração para PostgreSQL.
-		// Monta a string de conexão (DSN) usando as variáveis de ambiente.
-		dsn := fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			os.Getenv("DB_HOST"),     // Endereço do servidor PostgreSQL
-			os.Getenv("DB_USER"),     // Usuário do banco de dados
-			os.Getenv("DB_PASSWORD"), // Senha do banco de dados
-			os.Getenv("DB_NAME"),     // Nome do banco de dados
-			os.Getenv("DB_PORT"),     // Porta do servidor PostgreSQL
-		)
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	case "mysql":
-		// Configuração para MySQL.
-		// Monta a string de conexão (DSN) usando as variáveis de ambiente.
-		dsn := fmt.Sprintf(
-			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			os.Getenv("DB_USER"),     // Usuário do banco de dados
-			os.Getenv("DB_PASSWORD"), // Senha do banco de dados
-			os.Getenv("DB_HOST"),     // Endereço do servidor MySQL
-			os.Getenv("DB_PORT"),     // Porta do servidor MySQL
-			os.Getenv("DB_NAME"),     // Nome do banco de dados
-		)
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	env := &envReader{}
+	dbType := strings.ToLower(env.String("DB_TYPE", DatabaseMySQL))
+	cfg := &Config{
+		Database: DatabaseConfig{
+			Type:            dbType,
+			DSN:             env.String("DB_DSN", ""),
+			Path:            env.String("DB_PATH", "gorm.db"),
+			Host:            env.String("DB_HOST", "localhost"),
+			Port:            env.Int("DB_PORT", defaultPort(dbType)),
+			User:            env.String("DB_USER", defaultUser(dbType)),
+			Password:        env.String("DB_PASSWORD", ""),
+			Name:            env.String("DB_NAME", "desafio"),
+			SSLMode:         env.String("DB_SSLMODE", "disable"),
+			MaxOpenConns:    env.Int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    env.Int("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: env.Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			AutoMigrate:     env.Bool("DB_AUTO_MIGRATE", false),
+		},
+		HTTP: HTTPConfig{
+			Addr:        env.String("HTTP_ADDR", ":8080"),
+			CORSOrigins: env.List("CORS_ALLOWED_ORIGINS", []string{"*"}),
+			ShutdownTimeout: env.Duration("HTTP_SHUTDOWN_TIMEOUT", 15*time.Second),
+			QueryTimeout:    env.Duration("QUERY_TIMEOUT", 10*time.Second),
+			RouteTimeouts:   env.DurationMap("QUERY_TIMEOUT_ROUTES", DefaultRouteTimeouts),
+		},
+		LogLevel:           strings.ToLower(env.String("LOG_LEVEL", LogLevelInfo)),
+		LogFormat:          strings.ToLower(env.String("LOG_FORMAT", LogFormatJSON)),
+		IdempotencyTTL:     env.Duration("IDEMPOTENCY_TTL", 24*time.Hour),
+		OutboxPollInterval: env.Duration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxMaxAttempts:  env.Int("OUTBOX_MAX_ATTEMPTS", 20),
+		CubicWeightDivisor: env.Float("CUBIC_WEIGHT_DIVISOR", 6000),
+		Webhooks: WebhookConfig{
+			MaxAttempts:         env.Int("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBase:           env.Duration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			Timeout:             env.Duration("WEBHOOK_TIMEOUT", 10*time.Second),
+			AllowPrivateTargets: env.Bool("WEBHOOK_ALLOW_PRIVATE_TARGETS", false),
+		},
+		Tracing: TracingConfig{
+			Exporter:     strings.ToLower(env.String("TRACING_EXPORTER", TracingExporterNone)),
+			File:         env.String("TRACING_FILE", "traces.json"),
+			OTLPEndpoint: env.String("TRACING_OTLP_ENDPOINT", ""),
+			OTLPInsecure: env.Bool("TRACING_OTLP_INSECURE", false),
+			SampleRatio:  env.Float("TRACING_SAMPLE_RATIO", 1),
+			ServiceName:  env.String("TRACING_SERVICE_NAME", "delivery-api"),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:         env.Bool("SCHEDULER_ENABLED", true),
+			LeaseTTL:        env.Duration("SCHEDULER_LEASE_TTL", 5*time.Minute),
+			OverdueSchedule: env.String("SCHEDULER_OVERDUE_SCHEDULE", "*/15 * * * *"),
+			StaleSchedule:   env.String("SCHEDULER_STALE_SCHEDULE", "0 * * * *"),
+			StalePendingAge: env.Duration("SCHEDULER_STALE_PENDING_AGE", 720*time.Hour),
+			SummarySchedule: env.String("SCHEDULER_SUMMARY_SCHEDULE", "5 0 * * *"),
+			ZonesSchedule:   env.String("SCHEDULER_ZONES_SCHEDULE", "30 3 * * *"),
+		},
+		Blob: BlobConfig{
+			Store: strings.ToLower(env.String("BLOB_STORE", BlobStoreLocal)),
+			Dir:   env.String("BLOB_DIR", "data/blobs"),
+		},
+	}
+
+	problems := append(env.problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Validate verifica se a configuração é coerente e retorna um *Error com todos os problemas encontrados.
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+// problems lista os problemas de validação da configuração.
+func (c *Config) problems() []string {
+	var problems []string
+
+	db := c.Database
+	switch db.Type {
+	case DatabaseSQLite:
+		if db.DSN == "" && db.Path == "" {
+			problems = append(problems, "DB_PATH (or DB_DSN) is required when DB_TYPE=sqlite")
+		}
+	case DatabasePostgres, DatabaseMySQL:
+		if db.DSN == "" {
+			if db.Host == "" {
+				problems = append(problems, fmt.Sprintf("DB_HOST (or DB_DSN) is required when DB_TYPE=%s", db.Type))
+			}
+			if db.User == "" {
+				problems = append(problems, fmt.Sprintf("DB_USER (or DB_DSN) is required when DB_TYPE=%s", db.Type))
+			}
+			if db.Name == "" {
+				problems = append(problems, fmt.Sprintf("DB_NAME (or DB_DSN) is required when DB_TYPE=%s", db.Type))
+			}
+			if db.Port <= 0 || db.Port > 65535 {
+				problems = append(problems, fmt.Sprintf("DB_PORT must be between 1 and 65535, got %d", db.Port))
+			}
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("DB_TYPE %q is not supported (use sqlite, postgres or mysql)", db.Type))
+	}
+	if db.MaxOpenConns <= 0 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS must be greater than zero")
+	}
+	if db.MaxIdleConns < 0 {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must not be negative")
+	} else if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		problems = append(problems, fmt.Sprintf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", db.MaxIdleConns, db.MaxOpenConns))
+	}
+	if db.ConnMaxLifetime < 0 {
+		problems = append(problems, "DB_CONN_MAX_LIFETIME must not be negative")
+	}
+
+	if c.HTTP.Addr == "" {
+		problems = append(problems, "HTTP_ADDR must not be empty")
+	}
+	if len(c.HTTP.CORSOrigins) == 0 {
+		problems = append(problems, "CORS_ALLOWED_ORIGINS must list at least one origin")
+	}
+	for _, origin := range c.HTTP.CORSOrigins {
+		if origin == "*" && len(c.HTTP.CORSOrigins) > 1 {
+			problems = append(problems, "CORS_ALLOWED_ORIGINS must be either * or a list of origins, not both")
+		} else if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			problems = append(problems, fmt.Sprintf("CORS_ALLOWED_ORIGINS entry %q must start with http:// or https://", origin))
+		}
+	}
+
+	if c.HTTP.ShutdownTimeout <= 0 {
+		problems = append(problems, "HTTP_SHUTDOWN_TIMEOUT must be greater than zero")
+	}
+	if c.HTTP.QueryTimeout <= 0 {
+		problems = append(problems, "QUERY_TIMEOUT must be greater than zero")
+	}
+	for route, timeout := range c.HTTP.RouteTimeouts {
+		if method, path, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(path, "/") {
+			problems = append(problems, fmt.Sprintf("QUERY_TIMEOUT_ROUTES entry %q must be in the form \"METHOD /route=duration\"", route))
+		}
+		if timeout < 0 {
+			problems = append(problems, fmt.Sprintf("QUERY_TIMEOUT_ROUTES entry %q must not be negative", route))
+		}
+	}
+
+	switch c.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL %q is not supported (use debug, info, warn or error)", c.LogLevel))
+	}
+	if c.LogFormat != LogFormatJSON && c.LogFormat != LogFormatText {
+		problems = append(problems, fmt.Sprintf("LOG_FORMAT %q is not supported (use json or text)", c.LogFormat))
+	}
+
+	if c.IdempotencyTTL <= 0 {
+		problems = append(problems, "IDEMPOTENCY_TTL must be greater than zero")
+	}
+	if c.OutboxPollInterval <= 0 {
+		problems = append(problems, "OUTBOX_POLL_INTERVAL must be greater than zero")
+	}
+	if c.OutboxMaxAttempts <= 0 {
+		problems = append(problems, "OUTBOX_MAX_ATTEMPTS must be greater than zero")
+	}
+	if c.CubicWeightDivisor <= 0 {
+		problems = append(problems, "CUBIC_WEIGHT_DIVISOR must be greater than zero")
+	}
+	if c.Webhooks.MaxAttempts <= 0 {
+		problems = append(problems, "WEBHOOK_MAX_ATTEMPTS must be greater than zero")
+	}
+	if c.Webhooks.RetryBase <= 0 {
+		problems = append(problems, "WEBHOOK_RETRY_BASE must be greater than zero")
+	}
+	if c.Webhooks.Timeout <= 0 {
+		problems = append(problems, "WEBHOOK_TIMEOUT must be greater than zero")
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	case TracingExporterFile:
+		if c.Tracing.File == "" {
+			problems = append(problems, "TRACING_FILE is required when TRACING_EXPORTER=file")
+		}
 	default:
-		// Retorna um erro se o tipo de banco de dados não for suportado.
-		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+		problems = append(problems, fmt.Sprintf("TRACING_EXPORTER %q is not supported (use none, stdout, file or otlp)", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+	if c.Tracing.ServiceName == "" {
+		problems = append(problems, "TRACING_SERVICE_NAME must not be empty")
+	}
+
+	// As expressões cron são validadas ao registrar as rotinas, no pacote scheduler.
+	if c.Scheduler.LeaseTTL <= 0 {
+		problems = append(problems, "SCHEDULER_LEASE_TTL must be greater than zero")
+	}
+	if c.Scheduler.StalePendingAge <= 0 {
+		problems = append(problems, "SCHEDULER_STALE_PENDING_AGE must be greater than zero")
+	}
+
+	switch c.Blob.Store {
+	case BlobStoreLocal:
+		if c.Blob.Dir == "" {
+			problems = append(problems, "BLOB_DIR must not be empty when BLOB_STORE is local")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("BLOB_STORE %q is not supported (use local)", c.Blob.Store))
+	}
+	return problems
+}
+
+// defaultPort retorna a porta padrão do banco de dados informado.
+func defaultPort(dbType string) int {
+	if dbType == DatabasePostgres {
+		return 5432
+	}
+	return 3306
+}
+
+// defaultUser retorna o usuário padrão do banco de dados informado.
+func defaultUser(dbType string) string {
+	if dbType == DatabasePostgres {
+		return "postgres"
+	}
+	return "root"
+}
+
+// envReader lê variáveis de ambiente tipadas e acumula os valores que não puderam ser convertidos.
+type envReader struct {
+	problems []string
+}
+
+// String lê uma variável de ambiente, retornando o valor padrão se ela não estiver definida.
+func (r *envReader) String(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return strings.TrimSpace(value)
+	}
+	return fallback
+}
+
+// Int lê uma variável de ambiente numérica inteira.
+func (r *envReader) Int(key string, fallback int) int {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s must be an integer, got %q", key, value))
+		return fallback
+	}
+	return number
+}
+
+// Bool lê uma variável de ambiente booleana (true/false, 1/0).
+func (r *envReader) Bool(key string, fallback bool) bool {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
 	}
 
-	// Verifica se houve erro ao conectar ao banco de dados.
+	b, err := strconv.ParseBool(value)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		r.problems = append(r.problems, fmt.Sprintf("%s must be true or false, got %q", key, value))
+		return fallback
 	}
+	return b
+}
 
-	// Loga uma mensagem de sucesso ao estabelecer a conexão.
-	log.Println("Database connection established successfully")
-	return db, nil
-}
\ No newline at end of file
+// Float lê uma variável de ambiente numérica decimal (por exemplo, "0.25").
+func (r *envReader) Float(key string, fallback float64) float64 {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s must be a number, got %q", key, value))
+		return fallback
+	}
+	return number
+}
+
+// Duration lê uma variável de ambiente no formato aceito por time.ParseDuration (por exemplo, "24h").
+func (r *envReader) Duration(key string, fallback time.Duration) time.Duration {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s must be a duration such as 30s or 24h, got %q", key, value))
+		return fallback
+	}
+	return duration
+}
+
+// List lê uma variável de ambiente com valores separados por vírgula, ignorando os vazios.
+func (r *envReader) List(key string, fallback []string) []string {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// DurationMap lê uma variável de ambiente no formato "chave=duração,chave=duração" e a aplica sobre uma cópia
+// do mapa padrão.
+func (r *envReader) DurationMap(key string, fallback map[string]time.Duration) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(fallback))
+	for k, v := range fallback {
+		result[k] = v
+	}
+
+	for _, item := range r.List(key, nil) {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			r.problems = append(r.problems, fmt.Sprintf("%s entry %q must be in the form key=duration", key, item))
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			r.problems = append(r.problems, fmt.Sprintf("%s entry %q must have a duration such as 30s or 5m", key, item))
+			continue
+		}
+		result[strings.Join(strings.Fields(name), " ")] = duration
+	}
+	return result
+}
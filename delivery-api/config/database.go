@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DSNString retorna a string de conexão do banco de dados: o DB_DSN, se informado, ou a montada a partir
+// do host, porta, usuário, senha e nome do banco.
+func (d DatabaseConfig) DSNString() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	switch d.Type {
+	case DatabaseSQLite:
+		return d.Path
+	case DatabasePostgres:
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(d.User, d.Password),
+			Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+			Path:     d.Name,
+			RawQuery: url.Values{"sslmode": []string{d.SSLMode}}.Encode(),
+		}
+		return dsn.String()
+	default:
+		// Formato: "usuário:senha@tcp(endereço:porta)/nome_do_banco?parâmetros_adicionais"
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			d.User, d.Password, net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), d.Name)
+	}
+}
+
+// OpenDatabase estabelece a conexão com o banco de dados configurado (SQLite, PostgreSQL ou MySQL)
+// e aplica os limites do pool de conexões.
+// O logLevel (LOG_LEVEL) define quais consultas o GORM registra: em "debug" todas as consultas são registradas.
+// Retorna uma instância do GORM (*gorm.DB) ou um erro, caso a conexão falhe.
+func OpenDatabase(cfg DatabaseConfig, logLevel string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Type {
+	case DatabaseSQLite:
+		dialector = sqlite.Open(cfg.DSNString())
+	case DatabasePostgres:
+		dialector = postgres.Open(cfg.DSNString())
+	case DatabaseMySQL:
+		dialector = mysql.Open(cfg.DSNString())
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newGormLogger(logLevel)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Type, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure connection pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// Verifica a conexão agora, para que um banco inacessível impeça a aplicação de subir.
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Type, err)
+	}
+
+	log.Printf("Database connection established successfully (%s)", cfg.Type)
+	return db, nil
+}
+
+// newGormLogger cria o logger do GORM correspondente ao nível de log da aplicação.
+// As mensagens vão para o logger padrão do pacote log, que o servidor direciona para os logs estruturados
+// (com os valores das consultas mascarados).
+func newGormLogger(logLevel string) logger.Interface {
+	level := logger.Warn
+	switch logLevel {
+	case LogLevelDebug:
+		level = logger.Info
+	case LogLevelError:
+		level = logger.Error
+	}
+	return logger.New(log.Default(), logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: true,
+		Colorful:                  false,
+	})
+}
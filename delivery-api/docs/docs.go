@@ -23,9 +23,186 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna as rotinas em segundo plano, com a agenda (cron), a próxima execução nesta instância e a\nsituação da última execução (compartilhada entre as instâncias). Apenas administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lista as rotinas agendadas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/scheduler.JobInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Inicia a rotina em segundo plano e responde sem esperar o fim; o resultado aparece em GET /admin/jobs.\nSe a rotina já estiver em execução (nesta ou em outra instância), responde 409. Apenas administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Executa uma rotina agendada",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome da rotina",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Execução iniciada"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "404": {
+                        "description": "Rotina não encontrada"
+                    },
+                    "409": {
+                        "description": "Rotina já em execução"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/analytics/daily/{day}": {
+            "get": {
+                "description": "Retorna o resumo das entregas criadas no dia, gravado pela rotina agendada daily-summary\n(ou pela execução manual dela em /admin/jobs/daily-summary/run).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Resumo diário das entregas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dia do resumo (AAAA-MM-DD)",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.DailySummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Data inválida"
+                    },
+                    "404": {
+                        "description": "Resumo não encontrado"
+                    },
+                    "500": {
+                        "description": "Erro ao buscar o resumo"
+                    }
+                }
+            }
+        },
+        "/analytics/deliveries": {
+            "get": {
+                "description": "Retorna a quantidade de entregas e o peso total por status, estado, cidade e dia ou semana,\na taxa de cancelamento, o tempo médio até a entrega e os clientes com mais entregas.\nO período considera a data de criação da entrega, no fuso horário do servidor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Indicadores operacionais das entregas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Data inicial, inclusive (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final, inclusive (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "description": "Agrupamento por período: day (padrão) ou week",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade de clientes no ranking (1 a 100; padrão: 10)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.DeliveryReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Filtros inválidos"
+                    },
+                    "500": {
+                        "description": "Erro ao calcular os indicadores"
+                    }
+                }
+            }
+        },
         "/clients": {
             "get": {
-                "description": "Retorna todos os clientes cadastrados na base de dados",
+                "description": "Retorna todos os clientes cadastrados na base de dados, opcionalmente filtrados",
                 "consumes": [
                     "application/json"
                 ],
@@ -36,6 +213,26 @@ const docTemplate = `{
                     "Clients"
                 ],
                 "summary": "Obtém a lista de todos os clientes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CNPJ do cliente",
+                        "name": "cnpj",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -46,6 +243,9 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -64,6 +264,12 @@ const docTemplate = `{
                 ],
                 "summary": "Cria um novo cliente",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chave que torna a criação idempotente em novas tentativas",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Cliente a ser criado",
                         "name": "Client",
@@ -84,6 +290,12 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
+                    "409": {
+                        "description": "Requisição com a mesma chave ainda em processamento"
+                    },
+                    "422": {
+                        "description": "Chave reutilizada com outro conteúdo"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -151,6 +363,60 @@ const docTemplate = `{
                 }
             }
         },
+        "/clients/export": {
+            "get": {
+                "description": "Exporta os clientes que atendem aos mesmos filtros da listagem (sem as entregas).\nO CSV usa BOM UTF-8 e \";\" como separador, para abrir diretamente no Excel em pt-BR.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Clients"
+                ],
+                "summary": "Exporta clientes em CSV ou NDJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Formato do arquivo (csv ou ndjson)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CNPJ do cliente",
+                        "name": "cnpj",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/clients/name/{name}": {
             "get": {
                 "description": "Retorna os dados dos clientes com base no nome informado.",
@@ -219,6 +485,12 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/clients.Client"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versão atual do cliente"
+                            }
                         }
                     },
                     "400": {
@@ -246,6 +518,13 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão que está sendo editada",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Cliente com dados atualizados",
                         "name": "Client",
@@ -261,11 +540,26 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/clients.Client"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nova versão do cliente"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Client not found"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -290,6 +584,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão que está sendo removida",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -299,42 +600,72 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Client not found"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/deliveries": {
+        "/couriers": {
             "get": {
-                "description": "Retorna todas as entregas cadastradas na base de dados",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retorna os entregadores em ordem de nome, opcionalmente filtrados pela cidade e pelo tipo de veículo.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
+                ],
+                "summary": "Lista os entregadores",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cidade de origem",
+                        "name": "home_city",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "bicycle",
+                            "motorcycle",
+                            "car",
+                            "van",
+                            "truck"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de veículo",
+                        "name": "vehicle_type",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Obtém a lista de todas as entregas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/deliveries.Delivery"
+                                "$ref": "#/definitions/couriers.Courier"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Filtro inválido"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             },
             "post": {
-                "description": "Cria uma nova entrega com validações de peso e status de pedido",
+                "description": "Cadastra um entregador com o tipo de veículo (bicycle, motorcycle, car, van ou truck), a capacidade de\ncarga (na mesma unidade do peso das entregas) e a cidade de origem.",
                 "consumes": [
                     "application/json"
                 ],
@@ -342,17 +673,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Cria uma nova entrega",
+                "summary": "Cadastra um entregador",
                 "parameters": [
                     {
-                        "description": "Entrega a ser criada",
-                        "name": "Delivery",
+                        "description": "Entregador a ser cadastrado",
+                        "name": "Courier",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/deliveries.Delivery"
+                            "$ref": "#/definitions/couriers.Courier"
                         }
                     }
                 ],
@@ -360,11 +691,11 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/deliveries.Delivery"
+                            "$ref": "#/definitions/couriers.Courier"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Dados inválidos"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -372,24 +703,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/deliveries/city/{city}": {
+        "/couriers/{id}": {
             "get": {
-                "description": "Retorna todas as entregas associadas a uma cidade.",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Buscar entregas por cidade",
+                "summary": "Consulta um entregador",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Nome da Cidade",
-                        "name": "city",
+                        "type": "integer",
+                        "description": "ID do entregador",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -398,24 +725,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/deliveries.Delivery"
-                            }
+                            "$ref": "#/definitions/couriers.Courier"
                         }
                     },
                     "400": {
-                        "description": "Cidade inválida"
+                        "description": "ID inválido"
                     },
                     "404": {
-                        "description": "Nenhuma entrega encontrada"
+                        "description": "Entregador não encontrado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/deliveries/client/cpf/{cpf}": {
-            "get": {
-                "description": "Retorna todas as entregas associadas a um CPF.",
+            },
+            "put": {
+                "description": "Substitui os dados do entregador. A nova capacidade não pode ser menor que o peso das entregas que ele\njá carrega.",
                 "consumes": [
                     "application/json"
                 ],
@@ -423,55 +748,97 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Buscar entregas por CPF",
+                "summary": "Atualiza um entregador",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "CPF do Cliente",
-                        "name": "cpf",
+                        "type": "integer",
+                        "description": "ID do entregador",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
+                    },
+                    {
+                        "description": "Novos dados do entregador",
+                        "name": "Courier",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/couriers.Courier"
+                        }
+                    }
+                ],
+                "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/deliveries.Delivery"
-                            }
+                            "$ref": "#/definitions/couriers.Courier"
                         }
                     },
                     "400": {
-                        "description": "CPF inválido"
+                        "description": "Dados inválidos"
                     },
                     "404": {
-                        "description": "Nenhuma entrega encontrada"
+                        "description": "Entregador não encontrado"
+                    },
+                    "409": {
+                        "description": "Capacidade menor que a carga atribuída"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove o entregador, desde que ele não tenha entregas em andamento.",
+                "tags": [
+                    "Couriers"
+                ],
+                "summary": "Remove um entregador",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do entregador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Entregador removido"
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Entregador não encontrado"
+                    },
+                    "409": {
+                        "description": "Entregador com entregas em andamento"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/deliveries/client/name/{name}": {
+        "/couriers/{id}/deliveries": {
             "get": {
-                "description": "Retorna todas as entregas associadas ao nome de um cliente.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retorna as entregas em andamento do entregador, em ordem de prazo (as sem prazo por último), seguidas\ndas que ele concluiu hoje, com o peso carregado e a capacidade ainda livre.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Buscar entregas por nome do cliente",
+                "summary": "Lista as entregas do dia de um entregador",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Nome do Cliente",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "ID do entregador",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -480,24 +847,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/deliveries.Delivery"
-                            }
+                            "$ref": "#/definitions/couriers.Workload"
                         }
                     },
                     "400": {
-                        "description": "Nome inválido"
+                        "description": "ID inválido"
                     },
                     "404": {
-                        "description": "Nenhuma entrega encontrada"
+                        "description": "Entregador não encontrado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/deliveries/{id}": {
-            "get": {
-                "description": "Retorna uma entrega específica através do seu ID",
+            },
+            "post": {
+                "description": "Atribui a entrega (Pendente ou Enviado) ao entregador. O peso dela, somado ao das entregas em andamento\ndo entregador, não pode passar da capacidade. Uma entrega que estava com outro entregador passa para\neste. A atribuição fica no histórico da entrega (evento delivery.assigned).",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,16 +870,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Obtém uma entrega pelo ID",
+                "summary": "Atribui uma entrega a um entregador",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID da entrega",
+                        "description": "ID do entregador",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Entrega a ser atribuída",
+                        "name": "Assignment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/couriers.assignmentRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -525,38 +899,44 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Dados inválidos"
+                    },
+                    "404": {
+                        "description": "Entregador ou entrega não encontrados"
+                    },
+                    "409": {
+                        "description": "Capacidade excedida ou entrega concluída ou cancelada"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza os dados de uma entrega existente através do seu ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/couriers/{id}/deliveries/{delivery_id}": {
+            "delete": {
+                "description": "A retirada fica no histórico da entrega (evento delivery.unassigned).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Deliveries"
+                    "Couriers"
                 ],
-                "summary": "Atualiza as informações de uma entrega",
+                "summary": "Retira uma entrega de um entregador",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID da entrega",
+                        "description": "ID do entregador",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Entrega com dados atualizados",
-                        "name": "Delivery",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/deliveries.Delivery"
-                        }
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -567,15 +947,23 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Entregador ou entrega não encontrados"
+                    },
+                    "409": {
+                        "description": "Entrega não está com o entregador"
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove uma entrega da base de dados pelo seu ID",
+            }
+        },
+        "/deliveries": {
+            "get": {
+                "description": "Retorna todas as entregas cadastradas na base de dados, opcionalmente filtradas",
                 "consumes": [
                     "application/json"
                 ],
@@ -585,19 +973,54 @@ const docTemplate = `{
                 "tags": [
                     "Deliveries"
                 ],
-                "summary": "Deleta uma entrega pelo ID",
+                "summary": "Obtém a lista de todas as entregas",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "client_cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "client_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome da cidade",
+                        "name": "cidade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (UF)",
+                        "name": "estado",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status do pedido",
+                        "name": "order_status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "ID da entrega",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ID da zona",
+                        "name": "zone_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Delivery"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -606,11 +1029,9 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/deliveries/{id}/status": {
-            "patch": {
-                "description": "Atualiza o status de uma entrega com base no ID da entrega.",
+            },
+            "post": {
+                "description": "Cria uma nova entrega com validações de peso e status de pedido",
                 "consumes": [
                     "application/json"
                 ],
@@ -620,135 +1041,2881 @@ const docTemplate = `{
                 "tags": [
                     "Deliveries"
                 ],
-                "summary": "Atualizar status do pedido",
+                "summary": "Cria uma nova entrega",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID da Entrega",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Chave que torna a criação idempotente em novas tentativas",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "description": "Novo Status",
-                        "name": "status",
+                        "description": "Entrega a ser criada",
+                        "name": "Delivery",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/deliveries.Delivery"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/deliveries.Delivery"
                         }
                     },
                     "400": {
-                        "description": "Requisição inválida"
+                        "description": "Bad Request"
                     },
-                    "404": {
-                        "description": "Entrega não encontrada"
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "clients.Client": {
-            "description": "Dados da entrega",
-            "type": "object",
-            "required": [
-                "birth_date",
-                "cnpj",
-                "cpf",
-                "email",
-                "name",
-                "phone"
-            ],
-            "properties": {
-                "birth_date": {
-                    "type": "string"
-                },
-                "cnpj": {
-                    "type": "string"
-                },
-                "cpf": {
-                    "type": "string"
-                },
-                "deliveries": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/deliveries.Delivery"
+                    "409": {
+                        "description": "Requisição com a mesma chave ainda em processamento"
+                    },
+                    "422": {
+                        "description": "Chave reutilizada com outro conteúdo"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
-                },
-                "email": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
                 }
             }
         },
-        "deliveries.Delivery": {
-            "description": "Dados da entrega",
-            "type": "object",
-            "properties": {
-                "bairro": {
-                    "type": "string"
-                },
-                "cidade": {
-                    "type": "string"
-                },
-                "client_cpf": {
-                    "type": "string"
-                },
-                "client_name": {
-                    "type": "string"
-                },
-                "complemento": {
-                    "type": "string"
-                },
-                "estado": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "latitude": {
-                    "type": "number"
-                },
+        "/deliveries.geojson": {
+            "get": {
+                "description": "Retorna as entregas com coordenadas que atendem aos filtros da listagem como um FeatureCollection\n(RFC 7946): um Point por entrega, com o status, o prazo, a zona e o entregador nas propriedades.\nOs dados pessoais do cliente não são incluídos. Entregas sem coordenadas (0, 0) ficam de fora.",
+                "produces": [
+                    "application/geo+json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Entregas em GeoJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Retângulo do mapa: oeste,sul,leste,norte (ex.: -46.8,-23.7,-46.4,-23.4)",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "client_cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "client_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome da cidade",
+                        "name": "cidade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (UF)",
+                        "name": "estado",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status do pedido",
+                        "name": "order_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da zona",
+                        "name": "zone_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "FeatureCollection com as entregas em features",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Feature"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Filtro ou bbox inválidos"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/city/{city}": {
+            "get": {
+                "description": "Retorna todas as entregas associadas a uma cidade.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Buscar entregas por cidade",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome da Cidade",
+                        "name": "city",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Cidade inválida"
+                    },
+                    "404": {
+                        "description": "Nenhuma entrega encontrada"
+                    }
+                }
+            }
+        },
+        "/deliveries/client/cpf/{cpf}": {
+            "get": {
+                "description": "Retorna todas as entregas associadas a um CPF.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Buscar entregas por CPF",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CPF do Cliente",
+                        "name": "cpf",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "CPF inválido"
+                    },
+                    "404": {
+                        "description": "Nenhuma entrega encontrada"
+                    }
+                }
+            }
+        },
+        "/deliveries/client/name/{name}": {
+            "get": {
+                "description": "Retorna todas as entregas associadas ao nome de um cliente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Buscar entregas por nome do cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome do Cliente",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Nome inválido"
+                    },
+                    "404": {
+                        "description": "Nenhuma entrega encontrada"
+                    }
+                }
+            }
+        },
+        "/deliveries/clusters": {
+            "get": {
+                "description": "Agrupa as entregas com coordenadas dentro do bbox pela célula do geohash, com a precisão definida\npelo zoom do mapa (de 1 caractere no zoom 0 a 8 a partir do zoom 18). Cada grupo é um Point no centro\ndas suas entregas, com o total, a quantidade de cada status e, se tiver uma única entrega, o ID dela.\nAceita os mesmos filtros da listagem.",
+                "produces": [
+                    "application/geo+json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Entregas agrupadas para o mapa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Zoom do mapa (0 a 22)",
+                        "name": "zoom",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retângulo do mapa: oeste,sul,leste,norte (ex.: -46.8,-23.7,-46.4,-23.4)",
+                        "name": "bbox",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "client_cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "client_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome da cidade",
+                        "name": "cidade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (UF)",
+                        "name": "estado",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status do pedido",
+                        "name": "order_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da zona",
+                        "name": "zone_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.ClusterCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Zoom, filtro ou bbox inválidos"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/export": {
+            "get": {
+                "description": "Exporta as entregas que atendem aos mesmos filtros da listagem.\nO CSV usa BOM UTF-8, \";\" como separador e vírgula decimal, para abrir diretamente no Excel em pt-BR.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Exporta entregas em CSV ou NDJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Formato do arquivo (csv ou ndjson)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "client_cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome do cliente",
+                        "name": "client_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do nome da cidade",
+                        "name": "cidade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (UF)",
+                        "name": "estado",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status do pedido",
+                        "name": "order_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da zona",
+                        "name": "zone_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/import": {
+            "post": {
+                "description": "Valida cada linha do CSV e grava as entregas válidas em lotes dentro de uma transação.\nPor padrão, as colunas usam os mesmos nomes dos campos JSON (client_cpf, weight, ...).\nO parâmetro mapping permite associar outros nomes de coluna a esses campos, por exemplo {\"CPF\":\"client_cpf\"}.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Importa entregas a partir de um CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Arquivo CSV",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas valida, sem gravar",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mapeamento de colunas em JSON (coluna -\u003e campo)",
+                        "name": "mapping",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/late": {
+            "get": {
+                "description": "Retorna as entregas em andamento (Pendente ou Enviado) com o prazo (sla_due_at) vencido,\nda mais atrasada para a menos atrasada. Com within, inclui também as que vencem nesse intervalo.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Buscar entregas atrasadas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inclui as entregas que vencem nesse intervalo a partir de agora (ex.: 24h, 90m)",
+                        "name": "within",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Intervalo inválido"
+                    },
+                    "500": {
+                        "description": "Erro ao buscar as entregas atrasadas"
+                    }
+                }
+            }
+        },
+        "/deliveries/stream": {
+            "get": {
+                "description": "Envia as mudanças de status das entregas via Server-Sent Events, com heartbeats periódicos.\nOs filtros são opcionais. Para retomar o stream, use o cabeçalho Last-Event-ID (ou o parâmetro last_event_id).\nSe o reenvio das mudanças perdidas atingir o limite, o stream envia o id da última mudança lida e é encerrado;\nreconecte com esse id para receber as demais.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Stream de status das entregas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPF do cliente",
+                        "name": "client_cpf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cidade da entrega",
+                        "name": "cidade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do último evento recebido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do último evento recebido (alternativa ao cabeçalho)",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream de eventos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "503": {
+                        "description": "Stream indisponível"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}": {
+            "get": {
+                "description": "Retorna uma entrega específica através do seu ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Obtém uma entrega pelo ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Delivery"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versão atual da entrega"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            },
+            "put": {
+                "description": "Atualiza os dados de uma entrega existente através do seu ID.\nOs campos packages, weight e declared_value são ignorados: os volumes são alterados em /deliveries/{id}/packages,\nque recalcula o peso e o valor declarado da entrega.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Atualiza as informações de uma entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão que está sendo editada",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Entrega com dados atualizados",
+                        "name": "Delivery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Delivery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Delivery"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nova versão da entrega"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Delivery not found"
+                    },
+                    "409": {
+                        "description": "Proof of delivery required to mark as delivered"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove uma entrega da base de dados pelo seu ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Deleta uma entrega pelo ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão que está sendo removida",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Delivery not found"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/history": {
+            "get": {
+                "description": "Retorna, em ordem, os eventos da entrega gravados na outbox: criação, alterações, mudanças de status,\nsinalização de atraso, atribuições a entregadores e remoção. O campo data traz o conteúdo de cada evento.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Histórico da entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.HistoryEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Entrega não encontrada"
+                    },
+                    "500": {
+                        "description": "Erro ao buscar o histórico"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/packages": {
+            "get": {
+                "description": "Retorna os volumes da entrega, na ordem em que foram incluídos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Lista os volumes de uma entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/deliveries.Package"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Delivery not found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "O peso e o valor declarado da entrega passam a incluir o volume (quantity × weight e quantity × declared_value).\nOs volumes só podem ser alterados enquanto a entrega está pendente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Inclui um volume na entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão da entrega que está sendo editada",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Volume a ser incluído",
+                        "name": "Package",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Package"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Package"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nova versão da entrega"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Delivery not found"
+                    },
+                    "409": {
+                        "description": "A entrega não está pendente ou o entregador dela não tem capacidade para o novo peso"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/packages/{package_id}": {
+            "put": {
+                "description": "Substitui os dados do volume e recalcula o peso e o valor declarado da entrega.\nOs volumes só podem ser alterados enquanto a entrega está pendente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Altera um volume da entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do volume",
+                        "name": "package_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão da entrega que está sendo editada",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Volume com os dados atualizados",
+                        "name": "Package",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Package"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Package"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nova versão da entrega"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Entrega ou volume não encontrados"
+                    },
+                    "409": {
+                        "description": "A entrega não está pendente ou o entregador dela não tem capacidade para o novo peso"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Recalcula o peso e o valor declarado da entrega. O único volume de uma entrega não pode ser removido.\nOs volumes só podem ser alterados enquanto a entrega está pendente.",
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Remove um volume da entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do volume",
+                        "name": "package_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão da entrega que está sendo editada",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nova versão da entrega"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Entrega ou volume não encontrados"
+                    },
+                    "409": {
+                        "description": "A entrega não está pendente ou o volume é o único da entrega"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/proof": {
+            "get": {
+                "description": "Retorna os dados do comprovante e as rotas de download da assinatura e da foto.\nO comprovante continua disponível mesmo que a entrega seja removida.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proofs"
+                ],
+                "summary": "Consulta o comprovante de entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/proofs.Proof"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Comprovante não encontrado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Recebe, em um formulário multipart, quem recebeu, a assinatura, uma foto opcional, a posição do\nentregador e o horário da entrega. A assinatura e a foto devem ser imagens PNG ou JPEG de até 5 MB.\nO comprovante é obrigatório para mudar a entrega para Entregue e não pode ser substituído depois.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proofs"
+                ],
+                "summary": "Registra o comprovante de entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nome de quem recebeu",
+                        "name": "recipient_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Documento de quem recebeu (CPF, RG, ...)",
+                        "name": "recipient_document",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Imagem da assinatura (PNG ou JPEG)",
+                        "name": "signature",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Foto da entrega (PNG ou JPEG)",
+                        "name": "photo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude do entregador",
+                        "name": "latitude",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude do entregador",
+                        "name": "longitude",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Horário da entrega (RFC 3339, por exemplo 2024-05-10T14:30:00-03:00)",
+                        "name": "delivered_at",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/proofs.Proof"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos"
+                    },
+                    "404": {
+                        "description": "Entrega não encontrada"
+                    },
+                    "409": {
+                        "description": "Entrega cancelada ou com comprovante já registrado"
+                    },
+                    "413": {
+                        "description": "Formulário maior que o permitido"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/proof/{file}": {
+            "get": {
+                "description": "Retorna a imagem da assinatura (signature) ou da foto (photo), com o tipo original.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "Proofs"
+                ],
+                "summary": "Baixa um arquivo do comprovante de entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "signature",
+                            "photo"
+                        ],
+                        "type": "string",
+                        "description": "Arquivo",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Comprovante ou arquivo não encontrado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/deliveries/{id}/status": {
+            "patch": {
+                "description": "Atualiza o status de uma entrega com base no ID da entrega.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deliveries"
+                ],
+                "summary": "Atualizar status do pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da Entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag da versão que está sendo alterada",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo Status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.Delivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Requisição inválida"
+                    },
+                    "404": {
+                        "description": "Entrega não encontrada"
+                    },
+                    "409": {
+                        "description": "Comprovante de entrega obrigatório para o status Entregue"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "428": {
+                        "description": "Precondition Required"
+                    }
+                }
+            }
+        },
+        "/load-plans": {
+            "get": {
+                "description": "Retorna os 100 planos gravados mais recentes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Load plans"
+                ],
+                "summary": "Lista os planos de carga",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/planning.Plan"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Distribui as entregas do filtro (cidade e/ou estado; status Pendente, o padrão, ou Enviado) que ainda\nnão têm entregador entre os veículos informados, sem passar da capacidade de cada um e mantendo juntas\nas entregas próximas. Veículos com courier_id usam a capacidade livre do entregador. As entregas que\nnão couberem são listadas em unassigned. Com dry_run=true, o plano não é gravado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Load plans"
+                ],
+                "summary": "Calcula um plano de carga",
+                "parameters": [
+                    {
+                        "description": "Filtro das entregas e veículos disponíveis",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/planning.Request"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas calcula, sem gravar",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Plano calculado (dry_run)",
+                        "schema": {
+                            "$ref": "#/definitions/planning.Plan"
+                        }
+                    },
+                    "201": {
+                        "description": "Plano gravado",
+                        "schema": {
+                            "$ref": "#/definitions/planning.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/load-plans/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Load plans"
+                ],
+                "summary": "Consulta um plano de carga",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do plano",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/planning.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Plano não encontrado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/load-plans/{id}/apply": {
+            "post": {
+                "description": "Atribui cada entrega do plano ao entregador do seu veículo, todas na mesma transação: se uma delas\nfalhar (entrega atribuída a outro entregador, concluída ou cancelada, ou que não cabe mais no veículo),\nnada é aplicado. Todos os veículos com entregas precisam ter courier_id. Um plano só é aplicado uma vez.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Load plans"
+                ],
+                "summary": "Aplica um plano de carga",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do plano",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/planning.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido ou veículo sem entregador"
+                    },
+                    "404": {
+                        "description": "Plano ou entrega não encontrados"
+                    },
+                    "409": {
+                        "description": "Plano já aplicado ou desatualizado"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna todas as assinaturas (sem os segredos)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Lista as assinaturas de webhook",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Subscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria uma assinatura para os eventos informados (delivery.created, delivery.status_changed, delivery.deleted, delivery.overdue,\ndelivery.assigned, delivery.unassigned).\nSe nenhum segredo for informado, um segredo é gerado e retornado apenas nesta resposta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Cria uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "description": "Assinatura a ser criada",
+                        "name": "Subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna as mensagens de webhook que não foram entregues após todas as tentativas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Lista as mensagens mortas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Message"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/messages/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Coloca a mensagem de volta na fila com as tentativas zeradas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Reenvia uma mensagem de webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da mensagem",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Obtém uma assinatura de webhook pelo ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Substitui a URL, os eventos, a descrição e o estado da assinatura. O segredo só é trocado se informado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Atualiza uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Assinatura com dados atualizados",
+                        "name": "Subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Remove uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido"
+                    },
+                    "403": {
+                        "description": "Usuário sem permissão"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/zones": {
+            "get": {
+                "description": "Retorna as zonas em ordem de cidade e nome, com a geometria em GeoJSON (MultiPolygon).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zones"
+                ],
+                "summary": "Lista as zonas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/zones.Zone"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Recebe um Feature ou um FeatureCollection do GeoJSON (RFC 7946, coordenadas [longitude, latitude]) com\ngeometrias Polygon ou MultiPolygon. Cada Feature precisa de properties.name, que identifica a zona:\numa zona com o mesmo nome tem a geometria substituída. properties.cidade é opcional. Depois do envio,\nas entregas em andamento são localizadas de novo em segundo plano (rotina recompute-delivery-zones).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zones"
+                ],
+                "summary": "Envia zonas em GeoJSON",
+                "parameters": [
+                    {
+                        "description": "Feature ou FeatureCollection do GeoJSON",
+                        "name": "FeatureCollection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/zones.Feature"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Zonas gravadas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/zones.Zone"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "GeoJSON inválido"
+                    },
+                    "413": {
+                        "description": "Arquivo maior que o permitido"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/zones/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zones"
+                ],
+                "summary": "Consulta uma zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/zones.Zone"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Zona não encontrada"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a zona. As entregas em andamento que estavam nela são localizadas de novo em segundo plano;\nas concluídas e canceladas mantêm o zone_id que tinham.",
+                "tags": [
+                    "Zones"
+                ],
+                "summary": "Remove uma zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Zona removida"
+                    },
+                    "400": {
+                        "description": "ID inválido"
+                    },
+                    "404": {
+                        "description": "Zona não encontrada"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "analytics.CityGroup": {
+            "description": "Quantidade de entregas e peso total de uma cidade",
+            "type": "object",
+            "properties": {
+                "cidade": {
+                    "type": "string"
+                },
+                "deliveries": {
+                    "type": "integer"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "total_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.ClientVolume": {
+            "description": "Cliente no ranking por volume de entregas",
+            "type": "object",
+            "properties": {
+                "client_cpf": {
+                    "type": "string"
+                },
+                "client_name": {
+                    "type": "string"
+                },
+                "deliveries": {
+                    "type": "integer"
+                },
+                "total_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.DailySummary": {
+            "description": "Resumo gravado das entregas criadas em um dia",
+            "type": "object",
+            "properties": {
+                "day": {
+                    "description": "Dia do resumo (AAAA-MM-DD), no fuso horário do servidor",
+                    "type": "string"
+                },
+                "generated_at": {
+                    "description": "Horário da última geração; gerar de novo substitui o resumo",
+                    "type": "string"
+                },
+                "report": {
+                    "$ref": "#/definitions/analytics.DeliveryReport"
+                }
+            }
+        },
+        "analytics.DeliveryReport": {
+            "description": "Indicadores operacionais das entregas criadas no período",
+            "type": "object",
+            "properties": {
+                "avg_delivery_hours": {
+                    "description": "Tempo médio da criação até a entrega; null se nenhuma foi entregue",
+                    "type": "number"
+                },
+                "by_cidade": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.CityGroup"
+                    }
+                },
+                "by_estado": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.Group"
+                    }
+                },
+                "by_period": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.PeriodGroup"
+                    }
+                },
+                "by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.Group"
+                    }
+                },
+                "cancellation_rate": {
+                    "description": "Fração das entregas do período que foram canceladas (0 a 1)",
+                    "type": "number"
+                },
+                "deliveries": {
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "Início do período (AAAA-MM-DD), se informado",
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Fim do período (AAAA-MM-DD, inclusive), se informado",
+                    "type": "string"
+                },
+                "top_clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.ClientVolume"
+                    }
+                },
+                "total_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.Group": {
+            "description": "Quantidade de entregas e peso total de um grupo",
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Valor do agrupamento (status ou estado)",
+                    "type": "string"
+                },
+                "total_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.PeriodGroup": {
+            "description": "Quantidade de entregas e peso total de um dia ou semana",
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "integer"
+                },
+                "period": {
+                    "description": "Dia, ou segunda-feira da semana, no formato AAAA-MM-DD",
+                    "type": "string"
+                },
+                "total_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "clients.Client": {
+            "description": "Dados da entrega",
+            "type": "object",
+            "required": [
+                "birth_date",
+                "cnpj",
+                "cpf",
+                "email",
+                "name",
+                "phone"
+            ],
+            "properties": {
+                "birth_date": {
+                    "type": "string"
+                },
+                "cnpj": {
+                    "type": "string"
+                },
+                "cpf": {
+                    "type": "string"
+                },
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.Delivery"
+                    }
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incrementada a cada alteração (usada no ETag)",
+                    "type": "integer"
+                }
+            }
+        },
+        "couriers.Courier": {
+            "description": "Entregador",
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Peso máximo carregado, comparado ao peso tarifado (chargeable_weight) das entregas",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "home_city": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "vehicle_type": {
+                    "description": "bicycle, motorcycle, car, van ou truck",
+                    "type": "string"
+                }
+            }
+        },
+        "couriers.Workload": {
+            "description": "Entregas do dia de um entregador",
+            "type": "object",
+            "properties": {
+                "assigned_weight": {
+                    "description": "Peso tarifado das entregas em andamento (Pendente ou Enviado)",
+                    "type": "number"
+                },
+                "available": {
+                    "description": "Capacidade ainda livre",
+                    "type": "number"
+                },
+                "courier": {
+                    "$ref": "#/definitions/couriers.Courier"
+                },
+                "deliveries": {
+                    "description": "Em andamento, pelo prazo, seguidas das concluídas no dia",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.Delivery"
+                    }
+                }
+            }
+        },
+        "couriers.assignmentRequest": {
+            "type": "object",
+            "required": [
+                "delivery_id"
+            ],
+            "properties": {
+                "delivery_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.Cluster": {
+            "type": "object",
+            "properties": {
+                "by_status": {
+                    "description": "Quantidade de entregas de cada status",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "delivery_id": {
+                    "description": "Preenchido quando o grupo tem uma única entrega",
+                    "type": "integer"
+                },
+                "geohash": {
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.ClusterCollection": {
+            "description": "Entregas agrupadas por geohash (FeatureCollection do GeoJSON)",
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.ClusterFeature"
+                    }
+                },
+                "precision": {
+                    "description": "Número de caracteres do geohash usado no agrupamento",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total de entregas agrupadas",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Sempre \"FeatureCollection\"",
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.ClusterFeature": {
+            "description": "Grupo de entregas no mapa (Feature do GeoJSON no centro das entregas do grupo)",
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/geo.Point"
+                },
+                "properties": {
+                    "$ref": "#/definitions/deliveries.Cluster"
+                },
+                "type": {
+                    "description": "Sempre \"Feature\"",
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.Delivery": {
+            "description": "Dados da entrega",
+            "type": "object",
+            "properties": {
+                "actual_weight": {
+                    "description": "Pesos calculados a partir dos volumes; os valores enviados pelo cliente são ignorados. O peso cubado de cada\nvolume é o volume em centímetros cúbicos dividido por CUBIC_WEIGHT_DIVISOR, e o peso tarifado soma, volume a\nvolume, o maior entre o peso real e o cubado. É o peso tarifado que conta na capacidade dos entregadores, nos\nplanos de carga e nos indicadores.",
+                    "type": "number"
+                },
+                "assigned_at": {
+                    "description": "Quando a entrega foi atribuída ao entregador",
+                    "type": "string"
+                },
+                "bairro": {
+                    "type": "string"
+                },
+                "cancel_reason": {
+                    "description": "Motivo do cancelamento automático",
+                    "type": "string"
+                },
+                "chargeable_weight": {
+                    "description": "Peso tarifado total",
+                    "type": "number"
+                },
+                "cidade": {
+                    "type": "string"
+                },
+                "client_cpf": {
+                    "type": "string"
+                },
+                "client_name": {
+                    "type": "string"
+                },
+                "complemento": {
+                    "type": "string"
+                },
+                "courier_id": {
+                    "description": "Preenchidos pela atribuição a um entregador (veja o pacote couriers); os valores enviados pelo cliente são ignorados.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "Horários do ciclo de vida e prazo (SLA), preenchidos pela aplicação; os valores enviados pelo cliente são ignorados.",
+                    "type": "string"
+                },
+                "cubed_weight": {
+                    "description": "Peso cubado total",
+                    "type": "number"
+                },
+                "declared_value": {
+                    "description": "Volumes da entrega. Na criação, sem volumes, a entrega tem um volume só, com test_name, weight e declared_value;\ncom volumes, weight e declared_value são os totais deles. Depois da criação, os volumes são alterados pelas rotas\n/deliveries/{id}/packages, e os enviados na atualização da entrega são ignorados. Só vêm na consulta por ID.",
+                    "type": "number"
+                },
+                "delivered_at": {
+                    "description": "Mudança para Entregue",
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
                 "logradouro": {
                     "type": "string"
                 },
-                "longitude": {
+                "longitude": {
+                    "type": "number"
+                },
+                "numero": {
+                    "type": "string"
+                },
+                "order_status": {
+                    "type": "string"
+                },
+                "overdue_at": {
+                    "description": "Preenchidos pelas rotinas agendadas (veja o pacote jobs); os valores enviados pelo cliente são ignorados.",
+                    "type": "string"
+                },
+                "packages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.Package"
+                    }
+                },
+                "pais": {
+                    "type": "string"
+                },
+                "service_level": {
+                    "description": "standard (padrão) ou express; define o prazo junto com o estado",
+                    "type": "string"
+                },
+                "shipped_at": {
+                    "description": "Primeira mudança para Enviado",
+                    "type": "string"
+                },
+                "sla_due_at": {
+                    "description": "Prazo de entrega: criação + prazo do estado e do nível de serviço",
+                    "type": "string"
+                },
+                "sla_status": {
+                    "description": "on_time, at_risk ou breached, calculado na resposta",
+                    "type": "string"
+                },
+                "test_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incrementada a cada alteração (usada no ETag)",
+                    "type": "integer"
+                },
+                "weight": {
+                    "description": "Peso total, somado dos volumes (veja Packages)",
+                    "type": "number"
+                },
+                "zone_id": {
+                    "description": "Zona operacional que contém as coordenadas (veja o pacote zones), recalculada a cada criação e alteração;\no valor enviado pelo cliente é ignorado. Nula sem coordenadas ou fora de todas as zonas.",
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.Feature": {
+            "description": "Entrega no mapa (Feature do GeoJSON)",
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/geo.Point"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "properties": {
+                    "$ref": "#/definitions/deliveries.FeatureProperties"
+                },
+                "type": {
+                    "description": "Sempre \"Feature\"",
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.FeatureProperties": {
+            "type": "object",
+            "properties": {
+                "cidade": {
+                    "type": "string"
+                },
+                "courier_id": {
+                    "type": "integer"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "order_status": {
+                    "type": "string"
+                },
+                "sla_due_at": {
+                    "type": "string"
+                },
+                "sla_status": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                },
+                "zone_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.HistoryEntry": {
+            "description": "Evento do histórico de uma entrega, lido da outbox",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Tipo do evento de domínio (DeliveryCreated, DeliveryStatusChanged, DeliveryAssigned, ...)",
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.ImportAccepted": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.ImportRejected": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.ImportReport": {
+            "description": "Relatório da importação de entregas",
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.ImportAccepted"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deliveries.ImportRejected"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "deliveries.Package": {
+            "description": "Volume (caixa) de uma entrega",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cubed_weight": {
+                    "description": "Peso cubado de cada volume, calculado pela aplicação",
+                    "type": "number"
+                },
+                "declared_value": {
+                    "description": "Valor declarado de cada volume, em reais",
+                    "type": "number"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "dimension_unit": {
+                    "description": "cm (padrão) ou in",
+                    "type": "string"
+                },
+                "height": {
+                    "description": "Altura em dimension_unit (0 se não informada)",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "length": {
+                    "description": "Comprimento em dimension_unit (0 se não informado)",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Quantidade de volumes iguais",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Peso de cada volume, na mesma unidade do weight da entrega",
+                    "type": "number"
+                },
+                "width": {
+                    "description": "Largura em dimension_unit (0 se não informada)",
+                    "type": "number"
+                }
+            }
+        },
+        "geo.Point": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "planning.Leftover": {
+            "description": "Entrega que ficou fora do plano",
+            "type": "object",
+            "properties": {
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "too_heavy ou no_capacity",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Peso tarifado da entrega",
                     "type": "number"
+                }
+            }
+        },
+        "planning.Plan": {
+            "description": "Plano de carga",
+            "type": "object",
+            "properties": {
+                "applied_at": {
+                    "type": "string"
                 },
-                "numero": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "filter": {
+                    "$ref": "#/definitions/planning.PlanFilter"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "planned_weight": {
+                    "description": "Peso distribuído entre os veículos",
+                    "type": "number"
+                },
+                "status": {
+                    "description": "draft ou applied; vazio quando o plano não foi gravado",
+                    "type": "string"
+                },
+                "total_deliveries": {
+                    "description": "Entregas que atendiam ao filtro",
+                    "type": "integer"
+                },
+                "total_weight": {
+                    "description": "Soma do peso tarifado das entregas que atendiam ao filtro",
+                    "type": "number"
+                },
+                "unassigned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/planning.Leftover"
+                    }
+                },
+                "vehicles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/planning.VehicleLoad"
+                    }
+                }
+            }
+        },
+        "planning.PlanFilter": {
+            "description": "Filtro das entregas a serem distribuídas",
+            "type": "object",
+            "properties": {
+                "cidade": {
+                    "description": "Cidade de destino (sem diferenciar maiúsculas)",
+                    "type": "string"
+                },
+                "estado": {
+                    "description": "UF de destino",
                     "type": "string"
                 },
                 "order_status": {
+                    "description": "Pendente (padrão) ou Enviado",
                     "type": "string"
+                }
+            }
+        },
+        "planning.Request": {
+            "description": "Pedido de um plano de carga",
+            "type": "object",
+            "required": [
+                "vehicles"
+            ],
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/planning.PlanFilter"
                 },
-                "pais": {
+                "vehicles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/planning.Vehicle"
+                    }
+                }
+            }
+        },
+        "planning.Vehicle": {
+            "description": "Veículo disponível para o plano",
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Peso máximo; com courier_id, limitado à capacidade livre do entregador",
+                    "type": "number"
+                },
+                "courier_id": {
+                    "description": "Entregador do veículo, necessário para aplicar o plano",
+                    "type": "integer"
+                },
+                "label": {
+                    "description": "Identificação do veículo; padrão: o nome do entregador ou \"veículo N\"",
                     "type": "string"
+                }
+            }
+        },
+        "planning.VehicleLoad": {
+            "description": "Carga planejada para um veículo",
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
                 },
-                "test_name": {
+                "courier_id": {
+                    "type": "integer"
+                },
+                "delivery_ids": {
+                    "description": "Entregas do veículo, na ordem da varredura (as próximas ficam juntas)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "label": {
                     "type": "string"
                 },
                 "weight": {
+                    "description": "Soma do peso tarifado (chargeable_weight) das entregas do veículo",
+                    "type": "number"
+                }
+            }
+        },
+        "proofs.Proof": {
+            "description": "Comprovante de entrega",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "Horário da entrega informado pelo entregador",
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "description": "Uma entrega tem no máximo um comprovante",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "description": "Posição do entregador (GPS) no momento da entrega",
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "photo_sha256": {
+                    "type": "string"
+                },
+                "photo_type": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "recipient_document": {
+                    "description": "Documento de quem recebeu (CPF, RG, ...)",
+                    "type": "string"
+                },
+                "recipient_name": {
+                    "type": "string"
+                },
+                "recorded_by": {
+                    "description": "E-mail do usuário que registrou o comprovante",
+                    "type": "string"
+                },
+                "signature_sha256": {
+                    "description": "Hash do arquivo, para comprovar que ele não foi alterado",
+                    "type": "string"
+                },
+                "signature_type": {
+                    "type": "string"
+                },
+                "signature_url": {
+                    "description": "Rota de download da assinatura",
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.JobInfo": {
+            "description": "Rotina agendada, com a próxima execução e a situação da última",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "description": "Próxima execução agendada nesta instância; null se não houver",
+                    "type": "string"
+                },
+                "schedule": {
+                    "description": "Vazio: a rotina só roda manualmente",
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/scheduler.JobState"
+                }
+            }
+        },
+        "scheduler.JobState": {
+            "description": "Situação de uma rotina agendada, compartilhada entre as instâncias da aplicação",
+            "type": "object",
+            "properties": {
+                "last_error": {
+                    "type": "string"
+                },
+                "last_finished_at": {
+                    "type": "string"
+                },
+                "last_result": {
+                    "type": "string"
+                },
+                "last_scheduled_at": {
+                    "description": "Horário agendado da última execução automática",
+                    "type": "string"
+                },
+                "last_started_at": {
+                    "type": "string"
+                },
+                "last_status": {
+                    "description": "running, succeeded ou failed",
+                    "type": "string"
+                },
+                "locked_by": {
+                    "description": "Instância que está executando a rotina",
+                    "type": "string"
+                },
+                "locked_until": {
+                    "description": "Fim da reserva; depois dele, outra instância pode executar",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Message": {
+            "description": "Mensagem de webhook enviada (ou a enviar) para uma assinatura",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhooks.Subscription": {
+            "description": "Assinatura de webhook",
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Usado para assinar os envios (HMAC-SHA256)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "zones.Feature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "description": "Polygon ou MultiPolygon",
+                    "type": "object"
+                },
+                "properties": {
+                    "$ref": "#/definitions/zones.FeatureProperties"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "zones.FeatureProperties": {
+            "type": "object",
+            "properties": {
+                "cidade": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "zones.Zone": {
+            "description": "Zona operacional",
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "Em graus ao quadrado; entre zonas sobrepostas, vale a de menor área",
+                    "type": "number"
+                },
+                "cidade": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "geometry": {
+                    "description": "GeoJSON MultiPolygon",
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_lat": {
+                    "type": "number"
+                },
+                "max_lon": {
+                    "type": "number"
+                },
+                "min_lat": {
+                    "description": "Retângulo envolvente, calculado a partir da geometria",
+                    "type": "number"
+                },
+                "min_lon": {
                     "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Token de API no formato \"Bearer \u003ctoken\u003e\" (crie com \"delivery-api user create\")",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
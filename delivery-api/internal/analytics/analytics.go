@@ -0,0 +1,107 @@
+// Package analytics calcula os indicadores operacionais das entregas para o dashboard: quantidades e peso por
+// status, estado, cidade e período, taxa de cancelamento, tempo médio até a entrega e os clientes com mais entregas.
+// O peso dos indicadores é o peso tarifado das entregas (chargeable_weight), o maior entre o real e o cubado.
+// O resumo de cada dia também pode ser gravado (DailySummary), pela rotina agendada daily-summary.
+// Todas as agregações são feitas no banco, em SQL compatível com SQLite, MySQL e PostgreSQL.
+package analytics
+
+import (
+	"errors"
+	"time"
+)
+
+// Agrupamentos por período aceitos no parâmetro interval.
+const (
+	IntervalDay  = "day"  // Um grupo por dia (AAAA-MM-DD)
+	IntervalWeek = "week" // Um grupo por semana, identificada pela segunda-feira (AAAA-MM-DD)
+)
+
+// Limites do ranking de clientes (parâmetro top).
+const (
+	DefaultTop = 10
+	MaxTop     = 100
+)
+
+// Filter reúne os filtros do relatório. Datas zero deixam o período aberto naquela ponta.
+type Filter struct {
+	From     time.Time // Início do período (inclusive), pela data de criação da entrega
+	To       time.Time // Fim do período (exclusive)
+	Interval string    // day ou week
+	Top      int       // Quantidade de clientes no ranking
+}
+
+// @description Quantidade de entregas e peso total de um grupo
+// @type object
+type Group struct {
+	Key         string  `json:"key" gorm:"column:group_key"` // Valor do agrupamento (status ou estado)
+	Deliveries  int64   `json:"deliveries"`
+	TotalWeight float64 `json:"total_weight"`
+}
+
+// @description Quantidade de entregas e peso total de uma cidade
+// @type object
+type CityGroup struct {
+	Cidade      string  `json:"cidade"`
+	Estado      string  `json:"estado"`
+	Deliveries  int64   `json:"deliveries"`
+	TotalWeight float64 `json:"total_weight"`
+}
+
+// @description Quantidade de entregas e peso total de um dia ou semana
+// @type object
+type PeriodGroup struct {
+	Period      string  `json:"period"` // Dia, ou segunda-feira da semana, no formato AAAA-MM-DD
+	Deliveries  int64   `json:"deliveries"`
+	TotalWeight float64 `json:"total_weight"`
+}
+
+// @description Cliente no ranking por volume de entregas
+// @type object
+type ClientVolume struct {
+	ClientCPF   string  `json:"client_cpf"`
+	ClientName  string  `json:"client_name"`
+	Deliveries  int64   `json:"deliveries"`
+	TotalWeight float64 `json:"total_weight"`
+}
+
+// DeliveryTime é o resultado do cálculo do tempo até a entrega.
+type DeliveryTime struct {
+	Deliveries     int64   // Entregas concluídas com os horários de criação e de entrega conhecidos
+	AverageSeconds float64 // Tempo médio entre a criação e a entrega
+}
+
+// @description Indicadores operacionais das entregas criadas no período
+// @type object
+type DeliveryReport struct {
+	From             string         `json:"from,omitempty"` // Início do período (AAAA-MM-DD), se informado
+	To               string         `json:"to,omitempty"`   // Fim do período (AAAA-MM-DD, inclusive), se informado
+	Interval         string         `json:"interval"`
+	Deliveries       int64          `json:"deliveries"`
+	TotalWeight      float64        `json:"total_weight"`
+	CancellationRate float64        `json:"cancellation_rate"`  // Fração das entregas do período que foram canceladas (0 a 1)
+	AvgDeliveryHours *float64       `json:"avg_delivery_hours"` // Tempo médio da criação até a entrega; null se nenhuma foi entregue
+	ByStatus         []Group        `json:"by_status"`
+	ByEstado         []Group        `json:"by_estado"`
+	ByCidade         []CityGroup    `json:"by_cidade"`
+	ByPeriod         []PeriodGroup  `json:"by_period"`
+	TopClients       []ClientVolume `json:"top_clients"`
+}
+
+// DateLayout é o formato das datas dos filtros de período e dos resumos diários.
+const DateLayout = "2006-01-02"
+
+// ErrSummaryNotFound é retornado quando não há resumo gravado para o dia solicitado.
+var ErrSummaryNotFound = errors.New("daily summary not found")
+
+// @description Resumo gravado das entregas criadas em um dia
+// @type object
+type DailySummary struct {
+	Day         string          `json:"day" gorm:"primaryKey;size:10"` // Dia do resumo (AAAA-MM-DD), no fuso horário do servidor
+	Report      *DeliveryReport `json:"report" gorm:"serializer:json;type:text;not null"`
+	GeneratedAt time.Time       `json:"generated_at" gorm:"not null"` // Horário da última geração; gerar de novo substitui o resumo
+}
+
+// TableName define o nome da tabela dos resumos diários.
+func (DailySummary) TableName() string {
+	return "daily_summaries"
+}
@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler expõe os indicadores operacionais pela API.
+type Handler struct {
+	Service Service
+}
+
+// GetDeliveryAnalytics é um handler HTTP que retorna os indicadores das entregas criadas no período.
+// @Summary Indicadores operacionais das entregas
+// @Description Retorna a quantidade de entregas e o peso total por status, estado, cidade e dia ou semana,
+// @Description a taxa de cancelamento, o tempo médio até a entrega e os clientes com mais entregas.
+// @Description O período considera a data de criação da entrega, no fuso horário do servidor.
+// @Tags Analytics
+// @Produce json
+// @Param from query string false "Data inicial, inclusive (AAAA-MM-DD)"
+// @Param to query string false "Data final, inclusive (AAAA-MM-DD)"
+// @Param interval query string false "Agrupamento por período: day (padrão) ou week" Enums(day, week)
+// @Param top query int false "Quantidade de clientes no ranking (1 a 100; padrão: 10)"
+// @Success 200 {object} DeliveryReport
+// @Failure 400 "Filtros inválidos"
+// @Failure 500 "Erro ao calcular os indicadores"
+// @Router /analytics/deliveries [get]
+func (h *Handler) GetDeliveryAnalytics(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.Service.DeliveryReport(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute delivery analytics"})
+		return
+	}
+
+	// O fim do período é devolvido como foi informado (inclusive), e não como o limite exclusivo usado na consulta.
+	report.From = c.Query("from")
+	report.To = c.Query("to")
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDailySummary é um handler HTTP que retorna o resumo gravado de um dia.
+// @Summary Resumo diário das entregas
+// @Description Retorna o resumo das entregas criadas no dia, gravado pela rotina agendada daily-summary
+// @Description (ou pela execução manual dela em /admin/jobs/daily-summary/run).
+// @Tags Analytics
+// @Produce json
+// @Param day path string true "Dia do resumo (AAAA-MM-DD)"
+// @Success 200 {object} DailySummary
+// @Failure 400 "Data inválida"
+// @Failure 404 "Resumo não encontrado"
+// @Failure 500 "Erro ao buscar o resumo"
+// @Router /analytics/daily/{day} [get]
+func (h *Handler) GetDailySummary(c *gin.Context) {
+	day := c.Param("day")
+	if _, err := time.Parse(DateLayout, day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid day %q: expected YYYY-MM-DD", day)})
+		return
+	}
+
+	summary, err := h.Service.GetDailySummary(c.Request.Context(), day)
+	if err != nil {
+		if errors.Is(err, ErrSummaryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get daily summary"})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// parseFilter lê e valida os filtros da query string.
+// As datas são interpretadas no fuso horário do servidor; "to" inclui o dia inteiro.
+func parseFilter(c *gin.Context) (Filter, error) {
+	filter := Filter{Interval: c.DefaultQuery("interval", IntervalDay), Top: DefaultTop}
+
+	if from := c.Query("from"); from != "" {
+		date, err := time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date %q: expected YYYY-MM-DD", from)
+		}
+		filter.From = date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date %q: expected YYYY-MM-DD", to)
+		}
+		filter.To = date.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must not be after to")
+	}
+
+	if filter.Interval != IntervalDay && filter.Interval != IntervalWeek {
+		return filter, fmt.Errorf("invalid interval %q: expected %s or %s", filter.Interval, IntervalDay, IntervalWeek)
+	}
+
+	if top := c.Query("top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 || n > MaxTop {
+			return filter, fmt.Errorf("invalid top %q: expected a number between 1 and %d", top, MaxTop)
+		}
+		filter.Top = n
+	}
+	return filter, nil
+}
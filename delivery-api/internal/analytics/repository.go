@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"delivery-api/internal/deliveries"
+)
+
+// Repository define as consultas de agregação do relatório de entregas e a gravação dos resumos diários.
+// Cada consulta recebe o filtro de período e devolve apenas os totais calculados pelo banco.
+type Repository interface {
+	CountByStatus(ctx context.Context, filter Filter) ([]Group, error)       // Entregas e peso por status
+	CountByEstado(ctx context.Context, filter Filter) ([]Group, error)       // Entregas e peso por estado
+	CountByCidade(ctx context.Context, filter Filter) ([]CityGroup, error)   // Entregas e peso por cidade
+	CountByPeriod(ctx context.Context, filter Filter) ([]PeriodGroup, error) // Entregas e peso por dia ou semana
+	TopClients(ctx context.Context, filter Filter) ([]ClientVolume, error)   // Clientes com mais entregas
+	DeliveryTime(ctx context.Context, filter Filter) (*DeliveryTime, error)  // Tempo médio da criação até a entrega
+	SaveDailySummary(ctx context.Context, summary *DailySummary) error      // Grava (ou substitui) o resumo de um dia
+	GetDailySummary(ctx context.Context, day string) (*DailySummary, error) // Retorna o resumo gravado de um dia
+}
+
+// dialect reúne as expressões SQL que mudam entre os bancos suportados.
+type dialect struct {
+	day     string // Formata a data como AAAA-MM-DD (%[1]s é a coluna)
+	week    string // Formata a segunda-feira da semana como AAAA-MM-DD (%[1]s é a coluna)
+	seconds string // Diferença em segundos entre duas datas (%[1]s é o início e %[2]s o fim)
+}
+
+// dialects são as expressões de cada banco, pelo nome do dialeto do GORM.
+// As datas são agrupadas no fuso horário do servidor, o mesmo usado nos filtros de período.
+var dialects = map[string]dialect{
+	"sqlite": {
+		day:     "strftime('%%Y-%%m-%%d', %[1]s, 'localtime')",
+		week:    "strftime('%%Y-%%m-%%d', %[1]s, 'localtime', 'weekday 0', '-6 days')",
+		seconds: "(julianday(%[2]s) - julianday(%[1]s)) * 86400",
+	},
+	"mysql": {
+		day:     "DATE_FORMAT(%[1]s, '%%Y-%%m-%%d')",
+		week:    "DATE_FORMAT(DATE_SUB(DATE(%[1]s), INTERVAL WEEKDAY(%[1]s) DAY), '%%Y-%%m-%%d')",
+		seconds: "TIMESTAMPDIFF(SECOND, %[1]s, %[2]s)",
+	},
+	"postgres": {
+		day:     "to_char(%[1]s, 'YYYY-MM-DD')",
+		week:    "to_char(date_trunc('week', %[1]s), 'YYYY-MM-DD')",
+		seconds: "EXTRACT(EPOCH FROM (%[2]s - %[1]s))",
+	},
+}
+
+// repository implementa Repository com o GORM.
+type repository struct {
+	db      *gorm.DB
+	dialect dialect
+}
+
+// NewRepository cria uma nova instância do repositório de indicadores, com as expressões do banco conectado.
+// A configuração só aceita sqlite, mysql e postgres; outro dialeto usa as expressões do SQLite.
+func NewRepository(db *gorm.DB) Repository {
+	d, ok := dialects[db.Dialector.Name()]
+	if !ok {
+		d = dialects["sqlite"]
+	}
+	return &repository{db: db, dialect: d}
+}
+
+// baseQuery monta a consulta base: as entregas (d) criadas no período do filtro.
+func (r *repository) baseQuery(ctx context.Context, filter Filter) *gorm.DB {
+	query := r.db.WithContext(ctx).Table("deliveries AS d")
+	if !filter.From.IsZero() {
+		query = query.Where("d.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("d.created_at < ?", filter.To)
+	}
+	return query
+}
+
+// CountByStatus conta as entregas e soma o peso por status.
+func (r *repository) CountByStatus(ctx context.Context, filter Filter) ([]Group, error) {
+	var groups []Group
+	err := r.baseQuery(ctx, filter).
+		Select("d.order_status AS group_key, COUNT(*) AS deliveries, COALESCE(SUM(d.chargeable_weight), 0) AS total_weight").
+		Group("d.order_status").Order("deliveries DESC, group_key").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// CountByEstado conta as entregas e soma o peso por estado.
+func (r *repository) CountByEstado(ctx context.Context, filter Filter) ([]Group, error) {
+	var groups []Group
+	err := r.baseQuery(ctx, filter).
+		Select("d.estado AS group_key, COUNT(*) AS deliveries, COALESCE(SUM(d.chargeable_weight), 0) AS total_weight").
+		Group("d.estado").Order("deliveries DESC, group_key").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// CountByCidade conta as entregas e soma o peso por cidade (cidade e estado, já que há cidades homônimas).
+func (r *repository) CountByCidade(ctx context.Context, filter Filter) ([]CityGroup, error) {
+	var groups []CityGroup
+	err := r.baseQuery(ctx, filter).
+		Select("d.cidade AS cidade, d.estado AS estado, COUNT(*) AS deliveries, COALESCE(SUM(d.chargeable_weight), 0) AS total_weight").
+		Group("d.cidade, d.estado").Order("deliveries DESC, cidade, estado").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// CountByPeriod conta as entregas e soma o peso por dia ou semana de criação.
+func (r *repository) CountByPeriod(ctx context.Context, filter Filter) ([]PeriodGroup, error) {
+	format := r.dialect.day
+	if filter.Interval == IntervalWeek {
+		format = r.dialect.week
+	}
+	period := fmt.Sprintf(format, "d.created_at")
+
+	var groups []PeriodGroup
+	err := r.baseQuery(ctx, filter).
+		Select(period + " AS period, COUNT(*) AS deliveries, COALESCE(SUM(d.chargeable_weight), 0) AS total_weight").
+		Group(period).Order("period").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// TopClients retorna os clientes com mais entregas no período, limitados a filter.Top.
+func (r *repository) TopClients(ctx context.Context, filter Filter) ([]ClientVolume, error) {
+	var clients []ClientVolume
+	err := r.baseQuery(ctx, filter).
+		Select("d.client_cpf AS client_cpf, MAX(d.client_name) AS client_name, COUNT(*) AS deliveries, COALESCE(SUM(d.chargeable_weight), 0) AS total_weight").
+		Group("d.client_cpf").Order("deliveries DESC, client_cpf").Limit(filter.Top).
+		Scan(&clients).Error
+	return clients, err
+}
+
+// DeliveryTime calcula o tempo médio entre a criação e a entrega (delivered_at) das entregas concluídas.
+func (r *repository) DeliveryTime(ctx context.Context, filter Filter) (*DeliveryTime, error) {
+	var result DeliveryTime
+	err := r.baseQuery(ctx, filter).
+		Select("COUNT(*) AS deliveries, COALESCE(AVG("+fmt.Sprintf(r.dialect.seconds, "d.created_at", "d.delivered_at")+"), 0) AS average_seconds").
+		Where("d.order_status = ? AND d.delivered_at IS NOT NULL", deliveries.OrderStatusDelivered).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SaveDailySummary grava o resumo do dia. Se o dia já tiver um resumo, ele é substituído (upsert pela chave day),
+// para que a rotina possa ser executada de novo sem erro.
+func (r *repository) SaveDailySummary(ctx context.Context, summary *DailySummary) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(summary).Error
+}
+
+// GetDailySummary retorna o resumo gravado do dia (AAAA-MM-DD).
+func (r *repository) GetDailySummary(ctx context.Context, day string) (*DailySummary, error) {
+	var summary DailySummary
+	if err := r.db.WithContext(ctx).Where("day = ?", day).First(&summary).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSummaryNotFound
+		}
+		return nil, err
+	}
+	return &summary, nil
+}
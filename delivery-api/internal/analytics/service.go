@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"delivery-api/internal/deliveries"
+)
+
+// Service define a camada de serviço dos indicadores operacionais.
+type Service interface {
+	DeliveryReport(ctx context.Context, filter Filter) (*DeliveryReport, error) // Monta o relatório de entregas do período
+	WriteDailySummary(ctx context.Context, day time.Time) (*DailySummary, error) // Calcula e grava o resumo de um dia
+	GetDailySummary(ctx context.Context, day string) (*DailySummary, error)      // Retorna o resumo gravado de um dia
+}
+
+// service implementa Service, combinando as agregações do repositório.
+type service struct {
+	repo Repository
+}
+
+// NewService cria uma nova instância do serviço de indicadores.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository) Service {
+	return &tracedService{next: &service{repo: repo}}
+}
+
+// DeliveryReport monta o relatório das entregas criadas no período do filtro.
+// Os totais e a taxa de cancelamento são derivados da contagem por status, para que sejam coerentes com ela.
+func (s *service) DeliveryReport(ctx context.Context, filter Filter) (*DeliveryReport, error) {
+	if filter.Interval == "" {
+		filter.Interval = IntervalDay
+	}
+	if filter.Top <= 0 {
+		filter.Top = DefaultTop
+	}
+
+	report := &DeliveryReport{Interval: filter.Interval}
+	var err error
+	if report.ByStatus, err = s.repo.CountByStatus(ctx, filter); err != nil {
+		return nil, err
+	}
+	if report.ByEstado, err = s.repo.CountByEstado(ctx, filter); err != nil {
+		return nil, err
+	}
+	if report.ByCidade, err = s.repo.CountByCidade(ctx, filter); err != nil {
+		return nil, err
+	}
+	if report.ByPeriod, err = s.repo.CountByPeriod(ctx, filter); err != nil {
+		return nil, err
+	}
+	if report.TopClients, err = s.repo.TopClients(ctx, filter); err != nil {
+		return nil, err
+	}
+	deliveryTime, err := s.repo.DeliveryTime(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var canceled int64
+	for _, group := range report.ByStatus {
+		report.Deliveries += group.Deliveries
+		report.TotalWeight += group.TotalWeight
+		if group.Key == deliveries.OrderStatusCanceled {
+			canceled = group.Deliveries
+		}
+	}
+	if report.Deliveries > 0 {
+		report.CancellationRate = float64(canceled) / float64(report.Deliveries)
+	}
+	if deliveryTime.Deliveries > 0 {
+		hours := deliveryTime.AverageSeconds / 3600
+		report.AvgDeliveryHours = &hours
+	}
+	return report, nil
+}
+
+// WriteDailySummary calcula o relatório das entregas criadas no dia de day (no fuso horário do servidor)
+// e o grava como o resumo desse dia, substituindo um resumo anterior.
+func (s *service) WriteDailySummary(ctx context.Context, day time.Time) (*DailySummary, error) {
+	day = day.In(time.Local)
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	report, err := s.DeliveryReport(ctx, Filter{From: from, To: from.AddDate(0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	report.From = from.Format(DateLayout)
+	report.To = report.From
+
+	summary := &DailySummary{Day: report.From, Report: report, GeneratedAt: time.Now()}
+	if err := s.repo.SaveDailySummary(ctx, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetDailySummary retorna o resumo gravado do dia (AAAA-MM-DD).
+func (s *service) GetDailySummary(ctx context.Context, day string) (*DailySummary, error) {
+	return s.repo.GetDailySummary(ctx, day)
+}
@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// Os spans levam apenas o período e as quantidades; nomes e CPFs do ranking não são gravados.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) DeliveryReport(ctx context.Context, filter Filter) (*DeliveryReport, error) {
+	ctx, span := tracing.Start(ctx, "analytics.DeliveryReport", attribute.String("analytics.interval", filter.Interval))
+	report, err := s.next.DeliveryReport(ctx, filter)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("deliveries.count", report.Deliveries))
+	}
+	tracing.End(span, err)
+	return report, err
+}
+
+func (s *tracedService) WriteDailySummary(ctx context.Context, day time.Time) (*DailySummary, error) {
+	ctx, span := tracing.Start(ctx, "analytics.WriteDailySummary", attribute.String("analytics.day", day.Format(DateLayout)))
+	summary, err := s.next.WriteDailySummary(ctx, day)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("deliveries.count", summary.Report.Deliveries))
+	}
+	tracing.End(span, err)
+	return summary, err
+}
+
+func (s *tracedService) GetDailySummary(ctx context.Context, day string) (*DailySummary, error) {
+	ctx, span := tracing.Start(ctx, "analytics.GetDailySummary", attribute.String("analytics.day", day))
+	summary, err := s.next.GetDailySummary(ctx, day)
+	tracing.End(span, err, ErrSummaryNotFound)
+	return summary, err
+}
@@ -0,0 +1,51 @@
+// Package blob guarda os arquivos enviados à API (como as assinaturas e fotos dos comprovantes de entrega) fora do
+// banco de dados. O armazenamento é escolhido pela interface Store; a implementação disponível grava os arquivos
+// em um diretório local (NewLocalStore), e outras (como um bucket S3) podem ser acrescentadas sem mudar quem a usa.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound é retornado quando não existe arquivo com a chave informada.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey é retornado quando a chave não segue o formato aceito (veja ValidateKey).
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store é uma interface que define as operações de um armazenamento de arquivos.
+// Os arquivos são identificados por chaves no formato de caminho relativo, como "proofs/1/signature-ab12.png".
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error      // Grava o arquivo, substituindo um existente com a mesma chave
+	Open(ctx context.Context, key string) (io.ReadCloser, error) // Abre o arquivo para leitura (ErrNotFound se não existir)
+	Delete(ctx context.Context, key string) error                // Remove o arquivo; não é erro se ele não existir
+}
+
+// ValidateKey verifica se a chave é um caminho relativo formado apenas por letras, números, ".", "-" e "_",
+// com os segmentos separados por "/". Segmentos vazios, "." e ".." não são aceitos, o que impede que uma chave
+// aponte para fora do armazenamento.
+func ValidateKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: key is empty", ErrInvalidKey)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+		for _, r := range segment {
+			if !isKeyRune(r) {
+				return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+			}
+		}
+	}
+	return nil
+}
+
+// isKeyRune informa se o caractere é aceito em um segmento da chave.
+func isKeyRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_'
+}
@@ -0,0 +1,91 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localStore é uma struct que implementa a interface Store gravando os arquivos em um diretório local.
+// Cada chave vira um caminho dentro do diretório; os subdiretórios são criados conforme necessário.
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore cria um armazenamento de arquivos no diretório informado, criando-o se ele não existir.
+// Com várias instâncias da aplicação, o diretório precisa ser compartilhado entre elas (por exemplo, um volume de rede).
+func NewLocalStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("blob: failed to create directory %s: %w", dir, err)
+	}
+	return &localStore{dir: dir}, nil
+}
+
+// path retorna o caminho do arquivo correspondente à chave, depois de validá-la.
+func (s *localStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put grava o arquivo em um arquivo temporário no mesmo diretório e o renomeia ao final, para que uma leitura
+// simultânea nunca encontre o arquivo pela metade.
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Não faz nada depois do Rename
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open abre o arquivo para leitura. Retorna ErrNotFound se ele não existir.
+func (s *localStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete remove o arquivo. Não é erro se ele não existir.
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
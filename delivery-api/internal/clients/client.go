@@ -17,9 +17,18 @@ type Client struct {
 	Email      string                `json:"email" validate:"required,email"`
 	Phone      string                `json:"phone" validate:"required"`
 	Deliveries []deliveries.Delivery `json:"deliveries" gorm:"foreignKey:ClientCPF;references:CPF"`
+	Version    uint                  `json:"version" gorm:"not null;default:1"` // Incrementada a cada alteração (usada no ETag)
 }
 
 func (c *Client) Validate() error {
 	validate := validator.New()
 	return validate.Struct(c)
 }
+
+// Filter reúne os filtros aceitos pela listagem e pela exportação de clientes.
+// Campos vazios são ignorados; o nome aceita o início do nome, sem diferenciar maiúsculas.
+type Filter struct {
+	Name string `form:"name"`
+	CPF  string `form:"cpf"`
+	CNPJ string `form:"cnpj"`
+}
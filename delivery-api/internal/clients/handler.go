@@ -1,12 +1,16 @@
 package clients
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
 	"time"
 
+	"delivery-api/internal/etag"
+	"delivery-api/internal/export"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
@@ -24,9 +28,12 @@ type Handler struct {
 // @Tags Clients
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Chave que torna a criação idempotente em novas tentativas"
 // @Param Client body Client true "Cliente a ser criado"
 // @Success 201 {object} Client
 // @Failure 400 "Bad Request"
+// @Failure 409 "Requisição com a mesma chave ainda em processamento"
+// @Failure 422 "Chave reutilizada com outro conteúdo"
 // @Failure 500 "Internal Server Error"
 // @Router /clients [post]
 func (h *Handler) CreateClient(c *gin.Context) {
@@ -48,7 +55,7 @@ func (h *Handler) CreateClient(c *gin.Context) {
 
 	// Chama o método CreateClient do serviço para criar o cliente no banco de dados.
 	// Se houver erro na criação, retorna um erro 500 (Internal Server Error).
-	createdClient, err := h.Service.CreateClient(&client)
+	createdClient, err := h.Service.CreateClient(c.Request.Context(), &client)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create client"})
 		return
@@ -172,16 +179,27 @@ func isValidCNPJ(cnpj string) bool {
 
 // GetClients é um handler HTTP para retornar todos os clientes cadastrados.
 // @Summary Obtém a lista de todos os clientes
-// @Description Retorna todos os clientes cadastrados na base de dados
+// @Description Retorna todos os clientes cadastrados na base de dados, opcionalmente filtrados
 // @Tags Clients
 // @Accept json
 // @Produce json
+// @Param name query string false "Início do nome do cliente"
+// @Param cpf query string false "CPF do cliente"
+// @Param cnpj query string false "CNPJ do cliente"
 // @Success 200 {array} Client
+// @Failure 400 "Bad Request"
 // @Failure 500 "Internal Server Error"
 // @Router /clients [get]
 func (h *Handler) GetClients(c *gin.Context) {
+	// Lê os filtros da query string.
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
 	// Chama o método GetClients do serviço para obter a lista de clientes.
-	clients, err := h.Service.GetClients()
+	clients, err := h.Service.GetClients(c.Request.Context(), filter)
 	if err != nil {
 		// Se houver erro ao buscar os clientes, retorna um erro 500 (Internal Server Error).
 		c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch clients"})
@@ -200,6 +218,7 @@ func (h *Handler) GetClients(c *gin.Context) {
 // @Produce json
 // @Param id path int true "ID do cliente"
 // @Success 200 {object} Client
+// @Header 200 {string} ETag "Versão atual do cliente"
 // @Failure 400 "Bad Request"
 // @Router /clients/{id} [get]
 func (h *Handler) GetClientByID(c *gin.Context) {
@@ -213,14 +232,15 @@ func (h *Handler) GetClientByID(c *gin.Context) {
 	}
 
 	// Chama o método GetClientByID do serviço para buscar o cliente pelo ID.
-	client, err := h.Service.GetClientByID(uint(id))
+	client, err := h.Service.GetClientByID(c.Request.Context(), uint(id))
 	if err != nil {
 		// Se o cliente não for encontrado, retorna um erro 404 (Not Found).
 		c.JSON(http.StatusNotFound, map[string]string{"error": "Client not found"})
 		return
 	}
 
-	// Retorna o cliente encontrado com status 200 (OK).
+	// Retorna o cliente encontrado com status 200 (OK) e sua versão no cabeçalho ETag.
+	etag.Set(c, client.Version)
 	c.JSON(http.StatusOK, client)
 }
 
@@ -231,9 +251,14 @@ func (h *Handler) GetClientByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID do cliente"
+// @Param If-Match header string true "ETag da versão que está sendo editada"
 // @Param Client body Client true "Cliente com dados atualizados"
 // @Success 200 {object} Client
+// @Header 200 {string} ETag "Nova versão do cliente"
 // @Failure 400 "Bad Request"
+// @Failure 404 "Client not found"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
 // @Failure 500 "Internal Server Error"
 // @Router /clients/{id} [put]
 func (h *Handler) UpdateClient(c *gin.Context) {
@@ -259,15 +284,22 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 		return
 	}
 
+	// A versão esperada vem exclusivamente do cabeçalho If-Match, obrigatório (veja etag.Require).
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+	client.Version = version
+
 	// Chama o método UpdateClient do serviço para atualizar o cliente no banco de dados.
-	updatedClient, err := h.Service.UpdateClient(uint(id), &client)
+	updatedClient, err := h.Service.UpdateClient(c.Request.Context(), uint(id), &client)
 	if err != nil {
-		// Se houver erro na atualização, retorna um erro 500 (Internal Server Error).
-		c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update client"})
+		respondWriteError(c, err, "Failed to update client")
 		return
 	}
 
-	// Retorna o cliente atualizado com status 200 (OK).
+	// Retorna o cliente atualizado com status 200 (OK) e sua nova versão no cabeçalho ETag.
+	etag.Set(c, updatedClient.Version)
 	c.JSON(http.StatusOK, updatedClient)
 }
 
@@ -278,8 +310,12 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID do cliente"
+// @Param If-Match header string true "ETag da versão que está sendo removida"
 // @Success 204 {object} nil
 // @Failure 400 "Bad Request"
+// @Failure 404 "Client not found"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
 // @Failure 500 "Internal Server Error"
 // @Router /clients/{id} [delete]
 func (h *Handler) DeleteClient(c *gin.Context) {
@@ -292,11 +328,16 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 		return
 	}
 
+	// Obtém a versão esperada do cabeçalho If-Match, obrigatório (veja etag.Require).
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+
 	// Chama o método DeleteClient do serviço para deletar o cliente do banco de dados.
-	err = h.Service.DeleteClient(uint(id))
+	err = h.Service.DeleteClient(c.Request.Context(), uint(id), version)
 	if err != nil {
-		// Se houver erro na exclusão, retorna um erro 500 (Internal Server Error).
-		c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete client"})
+		respondWriteError(c, err, "Failed to delete client")
 		return
 	}
 
@@ -320,7 +361,7 @@ func (h *Handler) GetClientByCPF(c *gin.Context) {
 	cpf := c.Param("cpf")
 
 	// Chama o método GetClientByCPF do serviço para buscar o cliente pelo CPF.
-	client, err := h.Service.GetClientByCPF(cpf)
+	client, err := h.Service.GetClientByCPF(c.Request.Context(), cpf)
 	if err != nil {
 		// Se o cliente não for encontrado, retorna um erro 404 (Not Found).
 		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente não encontrado"})
@@ -347,7 +388,7 @@ func (h *Handler) GetClientsByName(c *gin.Context) {
 	name := c.Param("name")
 
 	// Chama o método GetClientsByName do serviço para buscar os clientes pelo nome.
-	clients, err := h.Service.GetClientByName(name)
+	clients, err := h.Service.GetClientByName(c.Request.Context(), name)
 	if err != nil {
 		// Se nenhum cliente for encontrado, retorna um erro 404 (Not Found).
 		c.JSON(http.StatusNotFound, gin.H{"error": "Nenhum cliente encontrado"})
@@ -370,7 +411,7 @@ func (h *Handler) GetClientsByName(c *gin.Context) {
 // @Router /clients/count [get]
 func (h *Handler) GetTotalClients(c *gin.Context) {
 	// Chama o método GetTotalClients do serviço para obter a contagem de clientes.
-	count, err := h.Service.GetTotalClients()
+	count, err := h.Service.GetTotalClients(c.Request.Context())
 	if err != nil {
 		// Se houver erro ao obter a contagem, retorna um erro 500 (Internal Server Error).
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter a contagem de clientes"})
@@ -379,4 +420,67 @@ func (h *Handler) GetTotalClients(c *gin.Context) {
 
 	// Retorna o total de clientes com status 200 (OK).
 	c.JSON(http.StatusOK, gin.H{"total_clients": count})
-}
\ No newline at end of file
+}
+
+// respondWriteError traduz os erros das operações de escrita em respostas HTTP.
+// Conflitos de versão viram 412 (Precondition Failed), clientes inexistentes viram 404 (Not Found)
+// e qualquer outro erro vira 500 (Internal Server Error) com a mensagem informada.
+func respondWriteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrClientNotFound):
+		c.JSON(http.StatusNotFound, map[string]string{"error": "Client not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+	}
+}
+
+// ExportColumns são as colunas do CSV de exportação de clientes.
+var ExportColumns = []string{"id", "name", "cpf", "cnpj", "birth_date", "email", "phone"}
+
+// ExportRecord converte um cliente em uma linha do CSV de exportação.
+func ExportRecord(client *Client) []string {
+	return []string{
+		strconv.FormatUint(uint64(client.ID), 10), client.Name, client.CPF, client.CNPJ,
+		client.BirthDate, client.Email, client.Phone,
+	}
+}
+
+// ExportClients é um handler HTTP para exportar os clientes em CSV ou NDJSON.
+// As linhas são lidas do banco com um cursor e enviadas ao cliente em blocos, sem carregar tudo em memória.
+// @Summary Exporta clientes em CSV ou NDJSON
+// @Description Exporta os clientes que atendem aos mesmos filtros da listagem (sem as entregas).
+// @Description O CSV usa BOM UTF-8 e ";" como separador, para abrir diretamente no Excel em pt-BR.
+// @Tags Clients
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "Formato do arquivo (csv ou ndjson)" default(csv)
+// @Param name query string false "Início do nome do cliente"
+// @Param cpf query string false "CPF do cliente"
+// @Param cnpj query string false "CNPJ do cliente"
+// @Success 200 {file} file
+// @Failure 400 "Bad Request"
+// @Failure 500 "Internal Server Error"
+// @Router /clients/export [get]
+func (h *Handler) ExportClients(c *gin.Context) {
+	// Valida o formato solicitado.
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Lê os filtros da query string.
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Percorre os clientes com o serviço, gravando cada um no arquivo de saída.
+	export.Stream(c, format, "clients", ExportColumns, func(emit export.EmitFunc) error {
+		return h.Service.ExportClients(c.Request.Context(), filter, func(client *Client) error {
+			return emit(ExportRecord(client), client)
+		})
+	})
+}
@@ -1,24 +1,36 @@
 package clients
 
 import (
+	"context"
+	"errors"
 	"strings"
 
 	"gorm.io/gorm"
+
+	"delivery-api/internal/events"
 )
 
 // Repository é uma interface que define os métodos necessários para operações de banco de dados relacionadas a clientes.
 // Essa interface permite que diferentes implementações de repositório sejam usadas, facilitando testes e manutenção.
 type Repository interface {
-	CreateClient(client *Client) (*Client, error)      // Cria um novo cliente
-	GetClients() ([]Client, error)                    // Retorna todos os clientes
-	GetClientByID(id uint) (*Client, error)           // Retorna um cliente pelo ID
-	UpdateClient(id uint, client *Client) (*Client, error) // Atualiza os dados de um cliente
-	DeleteClient(id uint) error                       // Deleta um cliente pelo ID
-	FindByCPF(cpf string) (*Client, error)            // Busca um cliente pelo CPF
-	CountClients() (int64, error)                     // Retorna o total de clientes cadastrados
-	FindByName(name string) ([]Client, error)
+	CreateClient(ctx context.Context, client *Client) (*Client, error)      // Cria um novo cliente
+	GetClients(ctx context.Context, filter Filter) ([]Client, error)       // Retorna os clientes que atendem ao filtro
+	StreamClients(ctx context.Context, filter Filter, fn func(*Client) error) error // Percorre os clientes com um cursor
+	GetClientByID(ctx context.Context, id uint) (*Client, error)           // Retorna um cliente pelo ID
+	UpdateClient(ctx context.Context, id uint, client *Client) (*Client, error) // Atualiza os dados de um cliente
+	DeleteClient(ctx context.Context, id uint, version uint) error         // Deleta um cliente pelo ID
+	FindByCPF(ctx context.Context, cpf string) (*Client, error)            // Busca um cliente pelo CPF
+	CountClients(ctx context.Context) (int64, error)                     // Retorna o total de clientes cadastrados
+	FindByName(ctx context.Context, name string) ([]Client, error)
 }
 
+// ErrClientNotFound é retornado quando o cliente solicitado não existe.
+var ErrClientNotFound = errors.New("client not found")
+
+// ErrVersionConflict é retornado quando a versão esperada pelo cliente (If-Match)
+// não corresponde mais à versão armazenada, ou seja, o cliente foi alterado por outra requisição.
+var ErrVersionConflict = errors.New("client was modified by another request")
+
 // repository é uma struct que implementa a interface Repository.
 // Ela contém uma instância do GORM (*gorm.DB) para interagir com o banco de dados.
 type repository struct {
@@ -33,37 +45,79 @@ func NewRepository(db *gorm.DB) Repository {
 
 // CreateClient cria um novo cliente no banco de dados.
 // Recebe um ponteiro para um objeto Client e o persiste no banco de dados usando o GORM.
+// O evento ClientCreated é gravado na outbox na mesma transação.
 // Retorna o cliente criado ou um erro, caso ocorra algum problema.
-func (r *repository) CreateClient(client *Client) (*Client, error) {
-	if err := r.db.Create(client).Error; err != nil {
+func (r *repository) CreateClient(ctx context.Context, client *Client) (*Client, error) {
+	// Todo cliente nasce na versão 1.
+	client.Version = 1
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(client).Error; err != nil {
+			return err
+		}
+		return events.Record(tx, events.AggregateClient, client.ID, events.ClientCreated, client)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return client, nil
 }
 
-// GetClients retorna uma lista de todos os clientes cadastrados no banco de dados.
-// Usa o método Find do GORM para buscar todos os registros da tabela de clientes.
+// GetClients retorna uma lista dos clientes cadastrados no banco de dados que atendem ao filtro.
+// Usa o método Find do GORM para buscar os registros da tabela de clientes.
 // Retorna a lista de clientes ou um erro, caso ocorra algum problema.
-func (r *repository) GetClients() ([]Client, error) {
+func (r *repository) GetClients(ctx context.Context, filter Filter) ([]Client, error) {
 	var clients []Client
-	if err := r.db.Find(&clients).Error; err != nil {
+	if err := applyFilter(r.db.WithContext(ctx), filter).Preload("Deliveries").Find(&clients).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Preload("Deliveries").Find(&clients).Error; err != nil {
-        return nil, err
-		}
 	return clients, nil
 }
 
+// StreamClients percorre os clientes que atendem ao filtro usando um cursor do banco de dados,
+// chamando fn para cada um, sem carregar o resultado inteiro em memória. As entregas não são carregadas.
+// A leitura é interrompida no primeiro erro retornado por fn.
+func (r *repository) StreamClients(ctx context.Context, filter Filter, fn func(*Client) error) error {
+	rows, err := applyFilter(r.db.WithContext(ctx).Model(&Client{}), filter).Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var client Client
+		if err := r.db.WithContext(ctx).ScanRows(rows, &client); err != nil {
+			return err
+		}
+		if err := fn(&client); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// applyFilter adiciona à consulta as condições correspondentes aos campos preenchidos do filtro.
+func applyFilter(db *gorm.DB, filter Filter) *gorm.DB {
+	if filter.Name != "" {
+		db = db.Where("LOWER(name) LIKE ?", strings.ToLower(filter.Name)+"%")
+	}
+	if filter.CPF != "" {
+		db = db.Where("cpf = ?", filter.CPF)
+	}
+	if filter.CNPJ != "" {
+		db = db.Where("cnpj = ?", filter.CNPJ)
+	}
+	return db
+}
+
 // GetClientByID retorna um cliente específico com base no ID fornecido.
 // Usa o método First do GORM para buscar o cliente pelo ID.
 // Retorna o cliente encontrado ou um erro, caso o cliente não exista ou ocorra algum problema.
-func (r *repository) GetClientByID(id uint) (*Client, error) {
+func (r *repository) GetClientByID(ctx context.Context, id uint) (*Client, error) {
 	var client Client
-	if err := r.db.First(&client, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&client, id).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Preload("Deliveries").Find(&client).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Deliveries").Find(&client).Error; err != nil {
         return nil, err
 		}
 	return &client, nil
@@ -71,41 +125,91 @@ func (r *repository) GetClientByID(id uint) (*Client, error) {
 
 // UpdateClient atualiza os dados de um cliente existente no banco de dados.
 // Primeiro, busca o cliente pelo ID para garantir que ele existe.
-// Em seguida, usa o método Updates do GORM para aplicar as alterações.
+// Se client.Version for diferente de zero, ela é tratada como a versão esperada (If-Match)
+// e a atualização só acontece se a versão armazenada for a mesma.
+// Em seguida, usa o método Updates do GORM para aplicar as alterações, incrementando a versão,
+// e grava o evento ClientUpdated na outbox na mesma transação.
 // Retorna o cliente atualizado ou um erro, caso ocorra algum problema.
-func (r *repository) UpdateClient(id uint, client *Client) (*Client, error) {
-	var existingClient Client
-	if err := r.db.First(&existingClient, id).Error; err != nil {
-		return nil, err
-	}
+func (r *repository) UpdateClient(ctx context.Context, id uint, client *Client) (*Client, error) {
+	var updatedClient Client
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingClient Client
+		if err := tx.First(&existingClient, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrClientNotFound
+			}
+			return err
+		}
+
+		// Verifica se o cliente da API está editando a versão mais recente.
+		if client.Version != 0 && client.Version != existingClient.Version {
+			return ErrVersionConflict
+		}
 
-	// Atualiza os campos do cliente existente com os dados fornecidos.
-	if err := r.db.Model(&existingClient).Updates(client).Error; err != nil {
+		// Atualiza os campos do cliente existente com os dados fornecidos.
+		// A condição sobre a versão impede que duas atualizações concorrentes se sobrescrevam.
+		currentVersion := existingClient.Version
+		client.Version = currentVersion + 1
+		result := tx.Model(&existingClient).Where("version = ?", currentVersion).Updates(client)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		// Relê o cliente para que a resposta e o evento tenham os dados gravados.
+		if err := tx.First(&updatedClient, id).Error; err != nil {
+			return err
+		}
+		return events.Record(tx, events.AggregateClient, id, events.ClientUpdated, &updatedClient)
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	return &existingClient, nil
+	return &updatedClient, nil
 }
 
 // DeleteClient remove um cliente do banco de dados com base no ID fornecido.
-// Usa o método Delete do GORM para excluir o registro.
+// Se uma versão for informada, a exclusão só acontece se ela ainda for a versão atual.
+// Usa o método Delete do GORM para excluir o registro e grava o evento ClientDeleted na mesma transação.
 // Retorna um erro, caso ocorra algum problema durante a exclusão.
-func (r *repository) DeleteClient(id uint) error {
-	if err := r.db.Delete(&Client{}, id).Error; err != nil {
-		return err
-	}
-	return nil
+func (r *repository) DeleteClient(ctx context.Context, id uint, version uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var client Client
+		if err := tx.First(&client, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrClientNotFound
+			}
+			return err
+		}
+
+		query := tx
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Delete(&client)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Nenhuma linha afetada: o cliente existe, então a versão não confere.
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return events.Record(tx, events.AggregateClient, id, events.ClientDeleted, &client)
+	})
 }
 
 // FindByCPF busca um cliente no banco de dados com base no CPF fornecido.
 // Usa o método Where do GORM para filtrar os registros pelo CPF.
 // Retorna o cliente encontrado ou um erro, caso o cliente não exista ou ocorra algum problema.
-func (r *repository) FindByCPF(cpf string) (*Client, error) {
+func (r *repository) FindByCPF(ctx context.Context, cpf string) (*Client, error) {
 	var client Client
-	if err := r.db.Where("cpf = ?", cpf).First(&client).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("cpf = ?", cpf).First(&client).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Preload("Deliveries").Find(&client).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Deliveries").Find(&client).Error; err != nil {
         return nil, err
 		}
 	return &client, nil
@@ -114,14 +218,14 @@ func (r *repository) FindByCPF(cpf string) (*Client, error) {
 // FindByName busca múltiplos clientes no banco de dados com base em uma correspondência parcial do nome.
 // Usa o método Where do GORM com LIKE para filtrar os registros pelo nome, garantindo que o nome comece com o termo fornecido.
 // A busca não será sensível a maiúsculas/minúsculas.
-func (r *repository) FindByName(name string) ([]Client, error) {
+func (r *repository) FindByName(ctx context.Context, name string) ([]Client, error) {
 	var clients []Client
 
 	// Converte o nome para minúsculas e adiciona o operador LIKE
 	searchTerm := strings.ToLower(name) + "%"
 
 	// Realiza a busca com a cláusula WHERE e o Preload em uma única consulta
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Where("LOWER(name) LIKE ?", searchTerm). // Filtra pelo nome
 		Preload("Deliveries").                  // Carrega as entregas associadas
 		Find(&clients).                         // Executa a consulta
@@ -136,9 +240,9 @@ func (r *repository) FindByName(name string) ([]Client, error) {
 // CountClients retorna o número total de clientes cadastrados no banco de dados.
 // Usa o método Count do GORM para contar os registros na tabela de clientes.
 // Retorna o total de clientes ou um erro, caso ocorra algum problema.
-func (r *repository) CountClients() (int64, error) {
+func (r *repository) CountClients(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.Model(&Client{}).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&Client{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -1,16 +1,19 @@
 package clients
 
+import "context"
+
 // Service é uma interface que define os métodos necessários para a camada de serviço de clientes.
 // Ela atua como um contrato para a lógica de negócio relacionada a clientes.
 type Service interface {
-	CreateClient(client *Client) (*Client, error)      // Cria um novo cliente
-	GetClients() ([]Client, error)                    // Retorna todos os clientes
-	GetClientByID(id uint) (*Client, error)           // Retorna um cliente pelo ID
-	UpdateClient(id uint, client *Client) (*Client, error) // Atualiza os dados de um cliente
-	DeleteClient(id uint) error                       // Deleta um cliente pelo ID
-	GetClientByCPF(cpf string) (*Client, error)       // Busca um cliente pelo CPF
-	GetTotalClients() (int64, error)                  // Retorna o número total de clientes
-	GetClientByName(name string) ([]Client, error)
+	CreateClient(ctx context.Context, client *Client) (*Client, error)      // Cria um novo cliente
+	GetClients(ctx context.Context, filter Filter) ([]Client, error)       // Retorna os clientes que atendem ao filtro
+	ExportClients(ctx context.Context, filter Filter, fn func(*Client) error) error // Percorre os clientes para exportação
+	GetClientByID(ctx context.Context, id uint) (*Client, error)           // Retorna um cliente pelo ID
+	UpdateClient(ctx context.Context, id uint, client *Client) (*Client, error) // Atualiza os dados de um cliente
+	DeleteClient(ctx context.Context, id uint, version uint) error         // Deleta um cliente pelo ID
+	GetClientByCPF(ctx context.Context, cpf string) (*Client, error)       // Busca um cliente pelo CPF
+	GetTotalClients(ctx context.Context) (int64, error)                  // Retorna o número total de clientes
+	GetClientByName(ctx context.Context, name string) ([]Client, error)
 }
 
 // service é uma struct que implementa a interface Service.
@@ -21,53 +24,61 @@ type service struct {
 
 // NewService é uma função que cria e retorna uma nova instância do serviço de clientes.
 // Recebe um repositório (Repository) como dependência e retorna um objeto que implementa a interface Service.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	return &tracedService{next: &service{repo: repo}}
 }
 
 // CreateClient implementa a lógica para criar um novo cliente.
 // Ele delega a operação para o repositório (Repository) e retorna o cliente criado ou um erro.
-func (s *service) CreateClient(client *Client) (*Client, error) {
-	return s.repo.CreateClient(client)
+func (s *service) CreateClient(ctx context.Context, client *Client) (*Client, error) {
+	return s.repo.CreateClient(ctx, client)
 }
 
-// GetClients implementa a lógica para retornar todos os clientes cadastrados.
+// GetClients implementa a lógica para retornar os clientes cadastrados que atendem ao filtro.
 // Ele delega a operação para o repositório (Repository) e retorna a lista de clientes ou um erro.
-func (s *service) GetClients() ([]Client, error) {
-	return s.repo.GetClients()
+func (s *service) GetClients(ctx context.Context, filter Filter) ([]Client, error) {
+	return s.repo.GetClients(ctx, filter)
+}
+
+// ExportClients implementa a lógica para percorrer os clientes que atendem ao filtro, um a um.
+// Ele delega a operação para o repositório (Repository), que lê os registros com um cursor.
+func (s *service) ExportClients(ctx context.Context, filter Filter, fn func(*Client) error) error {
+	return s.repo.StreamClients(ctx, filter, fn)
 }
 
 // GetClientByID implementa a lógica para buscar um cliente pelo ID.
 // Ele delega a operação para o repositório (Repository) e retorna o cliente encontrado ou um erro.
-func (s *service) GetClientByID(id uint) (*Client, error) {
-	return s.repo.GetClientByID(id)
+func (s *service) GetClientByID(ctx context.Context, id uint) (*Client, error) {
+	return s.repo.GetClientByID(ctx, id)
 }
 
 // UpdateClient implementa a lógica para atualizar os dados de um cliente existente.
 // Ele delega a operação para o repositório (Repository) e retorna o cliente atualizado ou um erro.
-func (s *service) UpdateClient(id uint, client *Client) (*Client, error) {
-	return s.repo.UpdateClient(id, client)
+func (s *service) UpdateClient(ctx context.Context, id uint, client *Client) (*Client, error) {
+	return s.repo.UpdateClient(ctx, id, client)
 }
 
 // DeleteClient implementa a lógica para deletar um cliente pelo ID.
+// A versão esperada (0 para não verificar) é repassada ao repositório.
 // Ele delega a operação para o repositório (Repository) e retorna um erro, caso ocorra algum problema.
-func (s *service) DeleteClient(id uint) error {
-	return s.repo.DeleteClient(id)
+func (s *service) DeleteClient(ctx context.Context, id uint, version uint) error {
+	return s.repo.DeleteClient(ctx, id, version)
 }
 
 // GetClientByCPF implementa a lógica para buscar um cliente pelo CPF.
 // Ele delega a operação para o repositório (Repository) e retorna o cliente encontrado ou um erro.
-func (s *service) GetClientByCPF(cpf string) (*Client, error) {
-	return s.repo.FindByCPF(cpf)
+func (s *service) GetClientByCPF(ctx context.Context, cpf string) (*Client, error) {
+	return s.repo.FindByCPF(ctx, cpf)
 }
 // GetClientByName implementa a lógica para buscar um cliente pelo Nome.
 // Ele delega a operação para o repositório (Repository) e retorna o cliente encontrado ou um erro.
-func (s *service) GetClientByName(name string) ([]Client, error) {
-	return s.repo.FindByName(name)
+func (s *service) GetClientByName(ctx context.Context, name string) ([]Client, error) {
+	return s.repo.FindByName(ctx, name)
 }
 
 // GetTotalClients implementa a lógica para retornar o número total de clientes cadastrados.
 // Ele delega a operação para o repositório (Repository) e retorna o total de clientes ou um erro.
-func (s *service) GetTotalClients() (int64, error) {
-	return s.repo.CountClients()
+func (s *service) GetTotalClients(ctx context.Context) (int64, error) {
+	return s.repo.CountClients(ctx)
 }
\ No newline at end of file
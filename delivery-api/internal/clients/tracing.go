@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{ErrClientNotFound, ErrVersionConflict}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// Os spans levam apenas IDs e quantidades; CPFs, CNPJs e nomes não são gravados.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) CreateClient(ctx context.Context, client *Client) (*Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.CreateClient")
+	created, err := s.next.CreateClient(ctx, client)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("client.id", int64(created.ID)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return created, err
+}
+
+func (s *tracedService) GetClients(ctx context.Context, filter Filter) ([]Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.GetClients")
+	list, err := s.next.GetClients(ctx, filter)
+	span.SetAttributes(attribute.Int("clients.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) ExportClients(ctx context.Context, filter Filter, fn func(*Client) error) error {
+	ctx, span := tracing.Start(ctx, "clients.ExportClients")
+	err := s.next.ExportClients(ctx, filter, fn)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) GetClientByID(ctx context.Context, id uint) (*Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.GetClientByID", attribute.Int64("client.id", int64(id)))
+	client, err := s.next.GetClientByID(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return client, err
+}
+
+func (s *tracedService) UpdateClient(ctx context.Context, id uint, client *Client) (*Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.UpdateClient", attribute.Int64("client.id", int64(id)))
+	updated, err := s.next.UpdateClient(ctx, id, client)
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
+
+func (s *tracedService) DeleteClient(ctx context.Context, id uint, version uint) error {
+	ctx, span := tracing.Start(ctx, "clients.DeleteClient", attribute.Int64("client.id", int64(id)))
+	err := s.next.DeleteClient(ctx, id, version)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) GetClientByCPF(ctx context.Context, cpf string) (*Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.GetClientByCPF")
+	client, err := s.next.GetClientByCPF(ctx, cpf)
+	tracing.End(span, err, expectedErrors...)
+	return client, err
+}
+
+func (s *tracedService) GetTotalClients(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "clients.GetTotalClients")
+	total, err := s.next.GetTotalClients(ctx)
+	tracing.End(span, err, expectedErrors...)
+	return total, err
+}
+
+func (s *tracedService) GetClientByName(ctx context.Context, name string) ([]Client, error) {
+	ctx, span := tracing.Start(ctx, "clients.GetClientByName")
+	list, err := s.next.GetClientByName(ctx, name)
+	span.SetAttributes(attribute.Int("clients.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
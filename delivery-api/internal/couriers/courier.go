@@ -0,0 +1,51 @@
+// Package couriers cadastra os entregadores e controla quais entregas cada um carrega. Uma entrega é atribuída a no
+// máximo um entregador (deliveries.Delivery.CourierID), e a soma do peso das entregas em andamento de um entregador
+// não pode passar da capacidade do veículo dele. Cada atribuição e retirada fica registrada no histórico da entrega
+// (eventos DeliveryAssigned e DeliveryUnassigned na outbox).
+package couriers
+
+import (
+	"time"
+
+	"delivery-api/internal/deliveries"
+)
+
+// Tipos de veículo aceitos em vehicle_type.
+const (
+	VehicleBicycle    = "bicycle"
+	VehicleMotorcycle = "motorcycle"
+	VehicleCar        = "car"
+	VehicleVan        = "van"
+	VehicleTruck      = "truck"
+)
+
+// VehicleTypes são os tipos de veículo que podem ser informados para um entregador.
+var VehicleTypes = []string{VehicleBicycle, VehicleMotorcycle, VehicleCar, VehicleVan, VehicleTruck}
+
+// @description Entregador
+// @type object
+type Courier struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:255;not null"`
+	Phone       string    `json:"phone" gorm:"size:30"`
+	VehicleType string    `json:"vehicle_type" gorm:"size:20;not null"` // bicycle, motorcycle, car, van ou truck
+	Capacity    float64   `json:"capacity" gorm:"not null"`             // Peso máximo carregado, comparado ao peso tarifado (chargeable_weight) das entregas
+	HomeCity    string    `json:"home_city" gorm:"size:255;not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Filter reúne os filtros aceitos pela listagem de entregadores. Campos vazios são ignorados.
+type Filter struct {
+	HomeCity    string `form:"home_city"`
+	VehicleType string `form:"vehicle_type"`
+}
+
+// @description Entregas do dia de um entregador
+// @type object
+type Workload struct {
+	Courier        *Courier              `json:"courier"`
+	AssignedWeight float64               `json:"assigned_weight"` // Peso tarifado das entregas em andamento (Pendente ou Enviado)
+	Available      float64               `json:"available"`       // Capacidade ainda livre
+	Deliveries     []deliveries.Delivery `json:"deliveries"`      // Em andamento, pelo prazo, seguidas das concluídas no dia
+}
@@ -0,0 +1,275 @@
+package couriers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"delivery-api/internal/deliveries"
+)
+
+// Handler expõe o cadastro dos entregadores e a atribuição de entregas pela API.
+type Handler struct {
+	Service Service
+}
+
+// assignmentRequest é o corpo aceito na atribuição de uma entrega.
+type assignmentRequest struct {
+	DeliveryID uint `json:"delivery_id" binding:"required"`
+}
+
+// CreateCourier é um handler HTTP para cadastrar um entregador.
+// @Summary Cadastra um entregador
+// @Description Cadastra um entregador com o tipo de veículo (bicycle, motorcycle, car, van ou truck), a capacidade de
+// @Description carga (na mesma unidade do peso das entregas) e a cidade de origem.
+// @Tags Couriers
+// @Accept json
+// @Produce json
+// @Param Courier body Courier true "Entregador a ser cadastrado"
+// @Success 201 {object} Courier
+// @Failure 400 "Dados inválidos"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers [post]
+func (h *Handler) CreateCourier(c *gin.Context) {
+	var courier Courier
+	if err := c.ShouldBindJSON(&courier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.Service.CreateCourier(c.Request.Context(), &courier)
+	if err != nil {
+		respondError(c, err, "Failed to create courier")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetCouriers é um handler HTTP para listar os entregadores.
+// @Summary Lista os entregadores
+// @Description Retorna os entregadores em ordem de nome, opcionalmente filtrados pela cidade e pelo tipo de veículo.
+// @Tags Couriers
+// @Produce json
+// @Param home_city query string false "Cidade de origem"
+// @Param vehicle_type query string false "Tipo de veículo" Enums(bicycle, motorcycle, car, van, truck)
+// @Success 200 {array} Courier
+// @Failure 400 "Filtro inválido"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers [get]
+func (h *Handler) GetCouriers(c *gin.Context) {
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	couriers, err := h.Service.GetCouriers(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err, "Failed to fetch couriers")
+		return
+	}
+	c.JSON(http.StatusOK, couriers)
+}
+
+// GetCourier é um handler HTTP para consultar um entregador.
+// @Summary Consulta um entregador
+// @Tags Couriers
+// @Produce json
+// @Param id path int true "ID do entregador"
+// @Success 200 {object} Courier
+// @Failure 400 "ID inválido"
+// @Failure 404 "Entregador não encontrado"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id} [get]
+func (h *Handler) GetCourier(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	courier, err := h.Service.GetCourierByID(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Failed to get courier")
+		return
+	}
+	c.JSON(http.StatusOK, courier)
+}
+
+// UpdateCourier é um handler HTTP para atualizar um entregador.
+// @Summary Atualiza um entregador
+// @Description Substitui os dados do entregador. A nova capacidade não pode ser menor que o peso das entregas que ele
+// @Description já carrega.
+// @Tags Couriers
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do entregador"
+// @Param Courier body Courier true "Novos dados do entregador"
+// @Success 200 {object} Courier
+// @Failure 400 "Dados inválidos"
+// @Failure 404 "Entregador não encontrado"
+// @Failure 409 "Capacidade menor que a carga atribuída"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id} [put]
+func (h *Handler) UpdateCourier(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var courier Courier
+	if err := c.ShouldBindJSON(&courier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.Service.UpdateCourier(c.Request.Context(), id, &courier)
+	if err != nil {
+		respondError(c, err, "Failed to update courier")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteCourier é um handler HTTP para remover um entregador.
+// @Summary Remove um entregador
+// @Description Remove o entregador, desde que ele não tenha entregas em andamento.
+// @Tags Couriers
+// @Param id path int true "ID do entregador"
+// @Success 204 "Entregador removido"
+// @Failure 400 "ID inválido"
+// @Failure 404 "Entregador não encontrado"
+// @Failure 409 "Entregador com entregas em andamento"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id} [delete]
+func (h *Handler) DeleteCourier(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteCourier(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Failed to delete courier")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetWorkload é um handler HTTP para consultar as entregas do dia de um entregador.
+// @Summary Lista as entregas do dia de um entregador
+// @Description Retorna as entregas em andamento do entregador, em ordem de prazo (as sem prazo por último), seguidas
+// @Description das que ele concluiu hoje, com o peso carregado e a capacidade ainda livre.
+// @Tags Couriers
+// @Produce json
+// @Param id path int true "ID do entregador"
+// @Success 200 {object} Workload
+// @Failure 400 "ID inválido"
+// @Failure 404 "Entregador não encontrado"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id}/deliveries [get]
+func (h *Handler) GetWorkload(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	workload, err := h.Service.GetWorkload(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Failed to fetch courier deliveries")
+		return
+	}
+	c.JSON(http.StatusOK, workload)
+}
+
+// AssignDelivery é um handler HTTP para atribuir uma entrega a um entregador.
+// @Summary Atribui uma entrega a um entregador
+// @Description Atribui a entrega (Pendente ou Enviado) ao entregador. O peso dela, somado ao das entregas em andamento
+// @Description do entregador, não pode passar da capacidade. Uma entrega que estava com outro entregador passa para
+// @Description este. A atribuição fica no histórico da entrega (evento delivery.assigned).
+// @Tags Couriers
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do entregador"
+// @Param Assignment body assignmentRequest true "Entrega a ser atribuída"
+// @Success 200 {object} deliveries.Delivery
+// @Failure 400 "Dados inválidos"
+// @Failure 404 "Entregador ou entrega não encontrados"
+// @Failure 409 "Capacidade excedida ou entrega concluída ou cancelada"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id}/deliveries [post]
+func (h *Handler) AssignDelivery(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var request assignmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	delivery, err := h.Service.AssignDelivery(c.Request.Context(), id, request.DeliveryID)
+	if err != nil {
+		respondError(c, err, "Failed to assign delivery")
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// UnassignDelivery é um handler HTTP para retirar uma entrega de um entregador.
+// @Summary Retira uma entrega de um entregador
+// @Description A retirada fica no histórico da entrega (evento delivery.unassigned).
+// @Tags Couriers
+// @Produce json
+// @Param id path int true "ID do entregador"
+// @Param delivery_id path int true "ID da entrega"
+// @Success 200 {object} deliveries.Delivery
+// @Failure 400 "ID inválido"
+// @Failure 404 "Entregador ou entrega não encontrados"
+// @Failure 409 "Entrega não está com o entregador"
+// @Failure 500 "Internal Server Error"
+// @Router /couriers/{id}/deliveries/{delivery_id} [delete]
+func (h *Handler) UnassignDelivery(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseID(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.Service.UnassignDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		respondError(c, err, "Failed to unassign delivery")
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// parseID lê um ID da rota, respondendo 400 se ele for inválido.
+func parseID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondError traduz os erros do serviço em respostas HTTP.
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidCourier):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrCourierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Courier not found"})
+	case errors.Is(err, deliveries.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+	case errors.Is(err, ErrCapacityExceeded), errors.Is(err, ErrNotAssignable),
+		errors.Is(err, ErrNotAssigned), errors.Is(err, ErrCourierBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
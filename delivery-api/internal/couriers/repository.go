@@ -0,0 +1,285 @@
+package couriers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+)
+
+// ErrCourierNotFound é retornado quando o entregador solicitado não existe.
+var ErrCourierNotFound = errors.New("courier not found")
+
+// ErrCapacityExceeded é retornado quando o peso das entregas em andamento passaria da capacidade do entregador.
+// É o mesmo erro retornado pelo pacote deliveries ao alterar os volumes de uma entrega atribuída.
+var ErrCapacityExceeded = deliveries.ErrCapacityExceeded
+
+// ErrNotAssignable é retornado ao atribuir uma entrega que já foi concluída ou cancelada.
+var ErrNotAssignable = errors.New("only pending or shipped deliveries can be assigned")
+
+// ErrNotAssigned é retornado ao retirar do entregador uma entrega que não está com ele.
+var ErrNotAssigned = errors.New("delivery is not assigned to this courier")
+
+// ErrCourierBusy é retornado ao remover um entregador que ainda tem entregas em andamento.
+var ErrCourierBusy = errors.New("courier still has deliveries in progress")
+
+// Repository é uma interface que define o acesso aos entregadores e às atribuições no banco de dados.
+type Repository interface {
+	CreateCourier(ctx context.Context, courier *Courier) (*Courier, error)                              // Cria um entregador
+	GetCouriers(ctx context.Context, filter Filter) ([]Courier, error)                                  // Lista os entregadores que atendem ao filtro
+	GetCourierByID(ctx context.Context, id uint) (*Courier, error)                                      // Retorna um entregador pelo ID
+	UpdateCourier(ctx context.Context, id uint, courier *Courier) (*Courier, error)                     // Atualiza um entregador
+	DeleteCourier(ctx context.Context, id uint) error                                                   // Remove um entregador sem entregas em andamento
+	Assign(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error)               // Atribui uma entrega ao entregador
+	Unassign(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error)             // Retira uma entrega do entregador
+	FindDeliveries(ctx context.Context, courierID uint, since time.Time) ([]deliveries.Delivery, error) // Entregas do entregador
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório dos entregadores.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreateCourier grava um novo entregador.
+func (r *repository) CreateCourier(ctx context.Context, courier *Courier) (*Courier, error) {
+	courier.ID = 0
+	if err := r.db.WithContext(ctx).Create(courier).Error; err != nil {
+		return nil, err
+	}
+	return courier, nil
+}
+
+// GetCouriers retorna os entregadores que atendem ao filtro, em ordem de nome.
+func (r *repository) GetCouriers(ctx context.Context, filter Filter) ([]Courier, error) {
+	query := r.db.WithContext(ctx)
+	if filter.HomeCity != "" {
+		query = query.Where("LOWER(home_city) = LOWER(?)", filter.HomeCity)
+	}
+	if filter.VehicleType != "" {
+		query = query.Where("vehicle_type = ?", filter.VehicleType)
+	}
+	var couriers []Courier
+	if err := query.Order("name").Order("id").Find(&couriers).Error; err != nil {
+		return nil, err
+	}
+	return couriers, nil
+}
+
+// GetCourierByID retorna o entregador pelo ID, ou ErrCourierNotFound.
+func (r *repository) GetCourierByID(ctx context.Context, id uint) (*Courier, error) {
+	var courier Courier
+	if err := r.db.WithContext(ctx).First(&courier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourierNotFound
+		}
+		return nil, err
+	}
+	return &courier, nil
+}
+
+// UpdateCourier atualiza os dados do entregador. A nova capacidade não pode ser menor que o peso das entregas
+// que ele já carrega (ErrCapacityExceeded).
+func (r *repository) UpdateCourier(ctx context.Context, id uint, courier *Courier) (*Courier, error) {
+	var updated Courier
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockCourier(tx, id, &updated); err != nil {
+			return err
+		}
+		load, err := deliveries.AssignedWeight(tx, id, 0)
+		if err != nil {
+			return err
+		}
+		if courier.Capacity < load {
+			return fmt.Errorf("%w: capacity %g is below the %g already assigned", ErrCapacityExceeded, courier.Capacity, load)
+		}
+
+		changes := map[string]interface{}{
+			"name":         courier.Name,
+			"phone":        courier.Phone,
+			"vehicle_type": courier.VehicleType,
+			"capacity":     courier.Capacity,
+			"home_city":    courier.HomeCity,
+		}
+		if err := tx.Model(&Courier{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+		return tx.First(&updated, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteCourier remove o entregador, se ele não tiver entregas em andamento (ErrCourierBusy).
+// As entregas concluídas ou canceladas continuam com o ID do entregador, como registro de quem as carregou.
+func (r *repository) DeleteCourier(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var courier Courier
+		if err := lockCourier(tx, id, &courier); err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&deliveries.Delivery{}).Where("courier_id = ? AND order_status IN ?", id, deliveries.OpenStatuses).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrCourierBusy
+		}
+		return tx.Delete(&Courier{}, id).Error
+	})
+}
+
+// Assign atribui a entrega ao entregador dentro de uma transação (veja AssignTx).
+func (r *repository) Assign(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	var updated *deliveries.Delivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		updated, err = AssignTx(tx, courierID, deliveryID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// AssignTx atribui a entrega ao entregador na transação tx. É exportada para que outras operações (como a aplicação
+// de um plano de carga) façam várias atribuições de uma vez, desfeitas juntas se uma delas falhar.
+// A entrega precisa estar em andamento (ErrNotAssignable), e o peso dela, somado ao das entregas em andamento do
+// entregador, não pode passar da capacidade (ErrCapacityExceeded). Uma entrega que estava com outro entregador passa
+// para este. A versão da entrega é incrementada, e o evento DeliveryAssigned é gravado na outbox na mesma transação.
+// Atribuir a entrega ao entregador que já a carrega não altera nada.
+func AssignTx(tx *gorm.DB, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	var courier Courier
+	if err := lockCourier(tx, courierID, &courier); err != nil {
+		return nil, err
+	}
+	var existing deliveries.Delivery
+	if err := findDelivery(tx, deliveryID, &existing); err != nil {
+		return nil, err
+	}
+	if existing.OrderStatus != deliveries.OrderStatusPending && existing.OrderStatus != deliveries.OrderStatusShipped {
+		return nil, ErrNotAssignable
+	}
+	if existing.CourierID != nil && *existing.CourierID == courierID {
+		return &existing, nil
+	}
+
+	load, err := deliveries.AssignedWeight(tx, courierID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if load+existing.ChargeableWeight > courier.Capacity {
+		return nil, fmt.Errorf("%w: %g already assigned plus %g exceeds the capacity of %g",
+			ErrCapacityExceeded, load, existing.ChargeableWeight, courier.Capacity)
+	}
+
+	changes := map[string]interface{}{
+		"courier_id":  courierID,
+		"assigned_at": time.Now(),
+		"version":     gorm.Expr("version + 1"),
+	}
+	if err := tx.Model(&deliveries.Delivery{}).Where("id = ?", deliveryID).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	var updated deliveries.Delivery
+	if err := tx.First(&updated, deliveryID).Error; err != nil {
+		return nil, err
+	}
+	assignment := deliveries.Assignment{Delivery: &updated, CourierID: &courierID, PreviousCourierID: existing.CourierID}
+	if err := events.Record(tx, events.AggregateDelivery, deliveryID, events.DeliveryAssigned, assignment); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Unassign retira a entrega do entregador dentro de uma transação, se ela estiver com ele (ErrNotAssigned).
+// A versão da entrega é incrementada, e o evento DeliveryUnassigned é gravado na outbox na mesma transação.
+func (r *repository) Unassign(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	var updated deliveries.Delivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var courier Courier
+		if err := lockCourier(tx, courierID, &courier); err != nil {
+			return err
+		}
+		var existing deliveries.Delivery
+		if err := findDelivery(tx, deliveryID, &existing); err != nil {
+			return err
+		}
+		if existing.CourierID == nil || *existing.CourierID != courierID {
+			return ErrNotAssigned
+		}
+
+		changes := map[string]interface{}{
+			"courier_id":  nil,
+			"assigned_at": nil,
+			"version":     gorm.Expr("version + 1"),
+		}
+		if err := tx.Model(&deliveries.Delivery{}).Where("id = ?", deliveryID).Updates(changes).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&updated, deliveryID).Error; err != nil {
+			return err
+		}
+		assignment := deliveries.Assignment{Delivery: &updated, PreviousCourierID: &courierID}
+		return events.Record(tx, events.AggregateDelivery, deliveryID, events.DeliveryUnassigned, assignment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// FindDeliveries retorna as entregas do entregador em andamento, ordenadas pelo prazo (as sem prazo por último),
+// seguidas das que ele concluiu a partir de since, na ordem em que foram entregues.
+func (r *repository) FindDeliveries(ctx context.Context, courierID uint, since time.Time) ([]deliveries.Delivery, error) {
+	var found []deliveries.Delivery
+	err := r.db.WithContext(ctx).
+		Where("courier_id = ?", courierID).
+		Where("order_status IN ? OR (order_status = ? AND delivered_at >= ?)",
+			deliveries.OpenStatuses, deliveries.OrderStatusDelivered, since).
+		Order("delivered_at IS NOT NULL").Order("delivered_at").
+		Order("sla_due_at IS NULL").Order("sla_due_at").Order("id").
+		Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// lockCourier carrega o entregador depois de alterar a linha dele (updated_at), o que a bloqueia até o fim da
+// transação. Assim, duas atribuições simultâneas ao mesmo entregador são feitas uma depois da outra, e a soma dos
+// pesos de uma já inclui a entrega da outra. Retorna ErrCourierNotFound se o entregador não existir.
+func lockCourier(tx *gorm.DB, id uint, courier *Courier) error {
+	result := tx.Model(&Courier{}).Where("id = ?", id).Update("updated_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCourierNotFound
+	}
+	return tx.First(courier, id).Error
+}
+
+// findDelivery carrega a entrega, ou retorna deliveries.ErrDeliveryNotFound.
+func findDelivery(tx *gorm.DB, id uint, delivery *deliveries.Delivery) error {
+	if err := tx.First(delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return deliveries.ErrDeliveryNotFound
+		}
+		return err
+	}
+	return nil
+}
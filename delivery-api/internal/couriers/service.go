@@ -0,0 +1,121 @@
+package couriers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"delivery-api/internal/deliveries"
+)
+
+// ErrInvalidCourier é retornado quando os dados de um entregador são inválidos.
+var ErrInvalidCourier = errors.New("invalid courier")
+
+// Service é uma interface que define as operações de negócio dos entregadores.
+type Service interface {
+	CreateCourier(ctx context.Context, courier *Courier) (*Courier, error)                          // Cadastra um entregador
+	GetCouriers(ctx context.Context, filter Filter) ([]Courier, error)                              // Lista os entregadores
+	GetCourierByID(ctx context.Context, id uint) (*Courier, error)                                  // Retorna um entregador pelo ID
+	UpdateCourier(ctx context.Context, id uint, courier *Courier) (*Courier, error)                 // Atualiza um entregador
+	DeleteCourier(ctx context.Context, id uint) error                                               // Remove um entregador
+	AssignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error)   // Atribui uma entrega ao entregador
+	UnassignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) // Retira uma entrega do entregador
+	GetWorkload(ctx context.Context, courierID uint) (*Workload, error)                             // Entregas do dia do entregador
+}
+
+// service é uma struct que implementa a interface Service.
+type service struct {
+	repo Repository
+}
+
+// NewService cria uma nova instância do serviço dos entregadores.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository) Service {
+	return &tracedService{next: &service{repo: repo}}
+}
+
+// CreateCourier valida e cadastra o entregador.
+func (s *service) CreateCourier(ctx context.Context, courier *Courier) (*Courier, error) {
+	if err := validateCourier(courier); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateCourier(ctx, courier)
+}
+
+// GetCouriers lista os entregadores que atendem ao filtro.
+func (s *service) GetCouriers(ctx context.Context, filter Filter) ([]Courier, error) {
+	return s.repo.GetCouriers(ctx, filter)
+}
+
+// GetCourierByID retorna o entregador pelo ID.
+func (s *service) GetCourierByID(ctx context.Context, id uint) (*Courier, error) {
+	return s.repo.GetCourierByID(ctx, id)
+}
+
+// UpdateCourier valida e atualiza o entregador.
+func (s *service) UpdateCourier(ctx context.Context, id uint, courier *Courier) (*Courier, error) {
+	if err := validateCourier(courier); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateCourier(ctx, id, courier)
+}
+
+// DeleteCourier remove o entregador.
+func (s *service) DeleteCourier(ctx context.Context, id uint) error {
+	return s.repo.DeleteCourier(ctx, id)
+}
+
+// AssignDelivery atribui a entrega ao entregador, respeitando a capacidade dele.
+func (s *service) AssignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	return s.repo.Assign(ctx, courierID, deliveryID)
+}
+
+// UnassignDelivery retira a entrega do entregador.
+func (s *service) UnassignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	return s.repo.Unassign(ctx, courierID, deliveryID)
+}
+
+// GetWorkload monta a lista do dia do entregador: as entregas em andamento, na ordem do prazo, seguidas das que
+// ele concluiu desde o início do dia (no fuso do servidor), com o peso carregado e a capacidade livre.
+func (s *service) GetWorkload(ctx context.Context, courierID uint) (*Workload, error) {
+	courier, err := s.repo.GetCourierByID(ctx, courierID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	found, err := s.repo.FindDeliveries(ctx, courierID, startOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	workload := &Workload{Courier: courier, Deliveries: found}
+	for _, delivery := range found {
+		if slices.Contains(deliveries.OpenStatuses, delivery.OrderStatus) {
+			workload.AssignedWeight += delivery.ChargeableWeight
+		}
+	}
+	workload.Available = max(courier.Capacity-workload.AssignedWeight, 0)
+	return workload, nil
+}
+
+// validateCourier verifica os campos obrigatórios, o tipo de veículo e a capacidade do entregador.
+func validateCourier(courier *Courier) error {
+	courier.Name = strings.TrimSpace(courier.Name)
+	courier.HomeCity = strings.TrimSpace(courier.HomeCity)
+	courier.VehicleType = strings.ToLower(strings.TrimSpace(courier.VehicleType))
+	switch {
+	case courier.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidCourier)
+	case courier.HomeCity == "":
+		return fmt.Errorf("%w: home_city is required", ErrInvalidCourier)
+	case !slices.Contains(VehicleTypes, courier.VehicleType):
+		return fmt.Errorf("%w: vehicle_type must be one of %s", ErrInvalidCourier, strings.Join(VehicleTypes, ", "))
+	case courier.Capacity <= 0:
+		return fmt.Errorf("%w: capacity must be greater than zero", ErrInvalidCourier)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package couriers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{
+	ErrInvalidCourier, ErrCourierNotFound, ErrCapacityExceeded, ErrNotAssignable, ErrNotAssigned, ErrCourierBusy,
+	deliveries.ErrDeliveryNotFound,
+}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// Os spans levam apenas os IDs; o nome e o telefone do entregador não são gravados.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) CreateCourier(ctx context.Context, courier *Courier) (*Courier, error) {
+	ctx, span := tracing.Start(ctx, "couriers.CreateCourier")
+	created, err := s.next.CreateCourier(ctx, courier)
+	tracing.End(span, err, expectedErrors...)
+	return created, err
+}
+
+func (s *tracedService) GetCouriers(ctx context.Context, filter Filter) ([]Courier, error) {
+	ctx, span := tracing.Start(ctx, "couriers.GetCouriers")
+	couriers, err := s.next.GetCouriers(ctx, filter)
+	span.SetAttributes(attribute.Int("couriers.count", len(couriers)))
+	tracing.End(span, err, expectedErrors...)
+	return couriers, err
+}
+
+func (s *tracedService) GetCourierByID(ctx context.Context, id uint) (*Courier, error) {
+	ctx, span := tracing.Start(ctx, "couriers.GetCourierByID", attribute.Int64("courier.id", int64(id)))
+	courier, err := s.next.GetCourierByID(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return courier, err
+}
+
+func (s *tracedService) UpdateCourier(ctx context.Context, id uint, courier *Courier) (*Courier, error) {
+	ctx, span := tracing.Start(ctx, "couriers.UpdateCourier", attribute.Int64("courier.id", int64(id)))
+	updated, err := s.next.UpdateCourier(ctx, id, courier)
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
+
+func (s *tracedService) DeleteCourier(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "couriers.DeleteCourier", attribute.Int64("courier.id", int64(id)))
+	err := s.next.DeleteCourier(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) AssignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	ctx, span := tracing.Start(ctx, "couriers.AssignDelivery",
+		attribute.Int64("courier.id", int64(courierID)),
+		attribute.Int64("delivery.id", int64(deliveryID)),
+	)
+	delivery, err := s.next.AssignDelivery(ctx, courierID, deliveryID)
+	tracing.End(span, err, expectedErrors...)
+	return delivery, err
+}
+
+func (s *tracedService) UnassignDelivery(ctx context.Context, courierID, deliveryID uint) (*deliveries.Delivery, error) {
+	ctx, span := tracing.Start(ctx, "couriers.UnassignDelivery",
+		attribute.Int64("courier.id", int64(courierID)),
+		attribute.Int64("delivery.id", int64(deliveryID)),
+	)
+	delivery, err := s.next.UnassignDelivery(ctx, courierID, deliveryID)
+	tracing.End(span, err, expectedErrors...)
+	return delivery, err
+}
+
+func (s *tracedService) GetWorkload(ctx context.Context, courierID uint) (*Workload, error) {
+	ctx, span := tracing.Start(ctx, "couriers.GetWorkload", attribute.Int64("courier.id", int64(courierID)))
+	workload, err := s.next.GetWorkload(ctx, courierID)
+	if workload != nil {
+		span.SetAttributes(attribute.Int("deliveries.count", len(workload.Deliveries)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return workload, err
+}
@@ -1,5 +1,12 @@
 package deliveries
 
+import (
+	"encoding/json"
+	"time"
+
+	"delivery-api/internal/geo"
+)
+
 // @description Dados da entrega
 // @type object
 type Delivery struct {
@@ -7,7 +14,7 @@ type Delivery struct {
     ClientCPF    string  `json:"client_cpf" gorm:"not null;index"`
     ClientName   string  `json:"client_name" gorm:"not null"`
     TestName     string  `json:"test_name" gorm:"not null"`
-    Weight       float64 `json:"weight" gorm:"not null"`
+    Weight       float64 `json:"weight" gorm:"not null"` // Peso total, somado dos volumes (veja Packages)
     Logradouro   string  `json:"logradouro" gorm:"not null"`
     Numero       string  `json:"numero" gorm:"not null"`
     Bairro       string  `json:"bairro" gorm:"not null"`
@@ -18,6 +25,42 @@ type Delivery struct {
     Latitude     float64 `json:"latitude" gorm:"not null"`
     Longitude    float64 `json:"longitude" gorm:"not null"`
     OrderStatus  string  `json:"order_status" gorm:"not null"`
+    Version      uint    `json:"version" gorm:"not null;default:1"` // Incrementada a cada alteração (usada no ETag)
+    ServiceLevel string  `json:"service_level" gorm:"size:20;not null;default:standard"` // standard (padrão) ou express; define o prazo junto com o estado
+
+    // Horários do ciclo de vida e prazo (SLA), preenchidos pela aplicação; os valores enviados pelo cliente são ignorados.
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+    ShippedAt   *time.Time `json:"shipped_at"`                  // Primeira mudança para Enviado
+    DeliveredAt *time.Time `json:"delivered_at"`                // Mudança para Entregue
+    SLADueAt    *time.Time `json:"sla_due_at" gorm:"index"`     // Prazo de entrega: criação + prazo do estado e do nível de serviço
+    SLAStatus   string     `json:"sla_status,omitempty" gorm:"-"` // on_time, at_risk ou breached, calculado na resposta
+
+    // Preenchidos pelas rotinas agendadas (veja o pacote jobs); os valores enviados pelo cliente são ignorados.
+    OverdueAt    *time.Time `json:"overdue_at"`                           // Quando o atraso foi sinalizado (evento delivery.overdue)
+    CancelReason string     `json:"cancel_reason,omitempty" gorm:"size:255"` // Motivo do cancelamento automático
+
+    // Preenchidos pela atribuição a um entregador (veja o pacote couriers); os valores enviados pelo cliente são ignorados.
+    CourierID  *uint      `json:"courier_id" gorm:"index"` // Entregador responsável pela entrega
+    AssignedAt *time.Time `json:"assigned_at"`             // Quando a entrega foi atribuída ao entregador
+
+    // Zona operacional que contém as coordenadas (veja o pacote zones), recalculada a cada criação e alteração;
+    // o valor enviado pelo cliente é ignorado. Nula sem coordenadas ou fora de todas as zonas.
+    ZoneID *uint `json:"zone_id" gorm:"index"`
+
+    // Volumes da entrega. Na criação, sem volumes, a entrega tem um volume só, com test_name, weight e declared_value;
+    // com volumes, weight e declared_value são os totais deles. Depois da criação, os volumes são alterados pelas rotas
+    // /deliveries/{id}/packages, e os enviados na atualização da entrega são ignorados. Só vêm na consulta por ID.
+    DeclaredValue float64   `json:"declared_value" gorm:"not null;default:0"` // Valor declarado total, em reais
+    Packages      []Package `json:"packages,omitempty" gorm:"foreignKey:DeliveryID"`
+
+    // Pesos calculados a partir dos volumes; os valores enviados pelo cliente são ignorados. O peso cubado de cada
+    // volume é o volume em centímetros cúbicos dividido por CUBIC_WEIGHT_DIVISOR, e o peso tarifado soma, volume a
+    // volume, o maior entre o peso real e o cubado. É o peso tarifado que conta na capacidade dos entregadores, nos
+    // planos de carga e nos indicadores.
+    ActualWeight     float64 `json:"actual_weight" gorm:"-"`                    // Peso real total, o mesmo que weight
+    CubedWeight      float64 `json:"cubed_weight" gorm:"not null;default:0"`      // Peso cubado total
+    ChargeableWeight float64 `json:"chargeable_weight" gorm:"not null;default:0"` // Peso tarifado total
 }
 
 const (
@@ -26,3 +69,62 @@ const (
 	OrderStatusDelivered = "Entregue"
 	OrderStatusCanceled  = "Cancelado"
 )
+
+// OpenStatuses são os status das entregas em andamento, que também ocupam a capacidade do entregador.
+var OpenStatuses = []string{OrderStatusPending, OrderStatusShipped}
+
+// Eventos de webhook publicados quando uma entrega é alterada.
+const (
+	EventDeliveryCreated       = "delivery.created"
+	EventDeliveryStatusChanged = "delivery.status_changed"
+	EventDeliveryDeleted       = "delivery.deleted"
+	EventDeliveryOverdue       = "delivery.overdue"
+	EventDeliveryAssigned      = "delivery.assigned"
+	EventDeliveryUnassigned    = "delivery.unassigned"
+)
+
+// StatusChange é o conteúdo do evento de mudança de status (DeliveryStatusChanged na outbox
+// e delivery.status_changed nos webhooks).
+type StatusChange struct {
+	Delivery       *Delivery `json:"delivery"`
+	PreviousStatus string    `json:"previous_status"`
+}
+
+// Assignment é o conteúdo dos eventos de atribuição da entrega a um entregador (DeliveryAssigned e DeliveryUnassigned
+// na outbox; delivery.assigned e delivery.unassigned nos webhooks).
+type Assignment struct {
+	Delivery          *Delivery `json:"delivery"`
+	CourierID         *uint     `json:"courier_id"`          // Entregador atual; null quando a entrega deixa de ter entregador
+	PreviousCourierID *uint     `json:"previous_courier_id"` // Entregador anterior; null se a entrega não tinha entregador
+}
+
+// ZoneChange é o conteúdo do evento DeliveryZoneChanged, gravado quando o recálculo das zonas muda a zona da entrega.
+type ZoneChange struct {
+	Delivery       *Delivery `json:"delivery"`
+	ZoneID         *uint     `json:"zone_id"`          // Zona atual; null se a entrega ficou fora de todas as zonas
+	PreviousZoneID *uint     `json:"previous_zone_id"` // Zona anterior; null se a entrega não tinha zona
+}
+
+// @description Evento do histórico de uma entrega, lido da outbox
+// @type object
+type HistoryEntry struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"` // Tipo do evento de domínio (DeliveryCreated, DeliveryStatusChanged, DeliveryAssigned, ...)
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Filter reúne os filtros aceitos pela listagem e pela exportação de entregas.
+// Campos vazios são ignorados; cidade e nome do cliente aceitam o início do nome, sem diferenciar maiúsculas.
+type Filter struct {
+	ClientCPF   string `form:"client_cpf"`
+	ClientName  string `form:"client_name"`
+	Cidade      string `form:"cidade"`
+	Estado      string `form:"estado"`
+	OrderStatus string `form:"order_status"`
+	ZoneID      uint   `form:"zone_id"`
+
+	// Retângulo do mapa, lido do parâmetro bbox apenas pelas rotas do mapa (veja geo.ParseBBox).
+	// Com ele, as entregas sem coordenadas ficam de fora.
+	BBox *geo.Bounds `form:"-"`
+}
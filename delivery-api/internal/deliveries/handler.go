@@ -1,20 +1,36 @@
 package deliveries
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"delivery-api/internal/etag"
+	"delivery-api/internal/export"
+	"delivery-api/internal/geo"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 // Handler é uma struct que manipula as requisições HTTP relacionadas a entregas.
-// Ele contém uma instância de um serviço (Service) para realizar as operações de negócio.
+// Ele contém uma instância de um serviço (Service) para realizar as operações de negócio
+// e o Broker que distribui as mudanças de status para o stream (sem ele, o stream fica indisponível).
 type Handler struct {
-	Service Service
+	Service         Service
+	Broker          *Broker
+	StreamHeartbeat time.Duration // Intervalo entre heartbeats do stream (padrão: defaultStreamHeartbeat)
 }
 
+// defaultStreamHeartbeat é o intervalo padrão entre heartbeats do stream, que mantém a conexão aberta em proxies.
+const defaultStreamHeartbeat = 15 * time.Second
+
 // CreateDelivery é um handler HTTP para criar uma nova entrega.
 // Ele valida os dados recebidos, cria a entrega no banco de dados e retorna uma resposta apropriada.
 // @Summary Cria uma nova entrega
@@ -22,9 +38,12 @@ type Handler struct {
 // @Tags Deliveries
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Chave que torna a criação idempotente em novas tentativas"
 // @Param Delivery body Delivery true "Entrega a ser criada"
 // @Success 201 {object} Delivery
 // @Failure 400 "Bad Request"
+// @Failure 409 "Requisição com a mesma chave ainda em processamento"
+// @Failure 422 "Chave reutilizada com outro conteúdo"
 // @Failure 500 "Internal Server Error"
 // @Router /deliveries [post]
 func (h *Handler) CreateDelivery(c *gin.Context) {
@@ -46,7 +65,7 @@ func (h *Handler) CreateDelivery(c *gin.Context) {
 
 	// Chama o método CreateDelivery do serviço para criar a entrega no banco de dados.
 	// Se houver erro na criação, retorna um erro 500 (Internal Server Error).
-	createdDelivery, err := h.Service.CreateDelivery(&delivery)
+	createdDelivery, err := h.Service.CreateDelivery(c.Request.Context(), &delivery)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create delivery"})
 		return
@@ -58,16 +77,30 @@ func (h *Handler) CreateDelivery(c *gin.Context) {
 
 // GetDeliveries é um handler HTTP para retornar todas as entregas cadastradas.
 // @Summary Obtém a lista de todas as entregas
-// @Description Retorna todas as entregas cadastradas na base de dados
+// @Description Retorna todas as entregas cadastradas na base de dados, opcionalmente filtradas
 // @Tags Deliveries
 // @Accept json
 // @Produce json
+// @Param client_cpf query string false "CPF do cliente"
+// @Param client_name query string false "Início do nome do cliente"
+// @Param cidade query string false "Início do nome da cidade"
+// @Param estado query string false "Estado (UF)"
+// @Param order_status query string false "Status do pedido"
+// @Param zone_id query int false "ID da zona"
 // @Success 200 {array} Delivery
+// @Failure 400 "Bad Request"
 // @Failure 500 "Internal Server Error"
 // @Router /deliveries [get]
 func (h *Handler) GetDeliveries(c *gin.Context) {
+	// Lê os filtros da query string.
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Chama o método GetDeliveries do serviço para obter a lista de entregas.
-	deliveries, err := h.Service.GetDeliveries()
+	deliveries, err := h.Service.GetDeliveries(c.Request.Context(), filter)
 	if err != nil {
 		// Se houver erro ao buscar as entregas, retorna um erro 500 (Internal Server Error).
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
@@ -86,6 +119,7 @@ func (h *Handler) GetDeliveries(c *gin.Context) {
 // @Produce json
 // @Param id path int true "ID da entrega"
 // @Success 200 {object} Delivery
+// @Header 200 {string} ETag "Versão atual da entrega"
 // @Failure 400 "Bad Request"
 // @Router /deliveries/{id} [get]
 func (h *Handler) GetDeliveryByID(c *gin.Context) {
@@ -98,27 +132,36 @@ func (h *Handler) GetDeliveryByID(c *gin.Context) {
 	}
 
 	// Chama o método GetDeliveryByID do serviço para buscar a entrega pelo ID.
-	delivery, err := h.Service.GetDeliveryByID(uint(id))
+	delivery, err := h.Service.GetDeliveryByID(c.Request.Context(), uint(id))
 	if err != nil {
 		// Se a entrega não for encontrada, retorna um erro 404 (Not Found).
 		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
 		return
 	}
 
-	// Retorna a entrega encontrada com status 200 (OK).
+	// Retorna a entrega encontrada com status 200 (OK) e sua versão no cabeçalho ETag.
+	etag.Set(c, delivery.Version)
 	c.JSON(http.StatusOK, delivery)
 }
 
 // UpdateDelivery é um handler HTTP para atualizar os dados de uma entrega existente.
 // @Summary Atualiza as informações de uma entrega
-// @Description Atualiza os dados de uma entrega existente através do seu ID
+// @Description Atualiza os dados de uma entrega existente através do seu ID.
+// @Description Os campos packages, weight e declared_value são ignorados: os volumes são alterados em /deliveries/{id}/packages,
+// @Description que recalcula o peso e o valor declarado da entrega.
 // @Tags Deliveries
 // @Accept json
 // @Produce json
 // @Param id path int true "ID da entrega"
+// @Param If-Match header string true "ETag da versão que está sendo editada"
 // @Param Delivery body Delivery true "Entrega com dados atualizados"
 // @Success 200 {object} Delivery
+// @Header 200 {string} ETag "Nova versão da entrega"
 // @Failure 400 "Bad Request"
+// @Failure 404 "Delivery not found"
+// @Failure 409 "Proof of delivery required to mark as delivered"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
 // @Failure 500 "Internal Server Error"
 // @Router /deliveries/{id} [put]
 func (h *Handler) UpdateDelivery(c *gin.Context) {
@@ -137,21 +180,28 @@ func (h *Handler) UpdateDelivery(c *gin.Context) {
 		return
 	}
 
-	// Valida os dados da entrega.
-	if err := validateDelivery(&delivery); err != nil {
+	// Valida os dados da entrega; os volumes, o peso e o valor declarado são ignorados na atualização.
+	if err := validateDeliveryFields(&delivery); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// A versão esperada vem exclusivamente do cabeçalho If-Match, obrigatório (veja etag.Require).
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+	delivery.Version = version
+
 	// Chama o método UpdateDelivery do serviço para atualizar a entrega no banco de dados.
-	updatedDelivery, err := h.Service.UpdateDelivery(uint(id), &delivery)
+	updatedDelivery, err := h.Service.UpdateDelivery(c.Request.Context(), uint(id), &delivery)
 	if err != nil {
-		// Se houver erro na atualização, retorna um erro 500 (Internal Server Error).
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update delivery"})
+		respondWriteError(c, err, "Failed to update delivery")
 		return
 	}
 
-	// Retorna a entrega atualizada com status 200 (OK).
+	// Retorna a entrega atualizada com status 200 (OK) e sua nova versão no cabeçalho ETag.
+	etag.Set(c, updatedDelivery.Version)
 	c.JSON(http.StatusOK, updatedDelivery)
 }
 
@@ -162,8 +212,12 @@ func (h *Handler) UpdateDelivery(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID da entrega"
+// @Param If-Match header string true "ETag da versão que está sendo removida"
 // @Success 204 {object} nil
 // @Failure 400 "Bad Request"
+// @Failure 404 "Delivery not found"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
 // @Failure 500 "Internal Server Error"
 // @Router /deliveries/{id} [delete]
 func (h *Handler) DeleteDelivery(c *gin.Context) {
@@ -175,11 +229,16 @@ func (h *Handler) DeleteDelivery(c *gin.Context) {
 		return
 	}
 
+	// Obtém a versão esperada do cabeçalho If-Match, obrigatório (veja etag.Require).
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+
 	// Chama o método DeleteDelivery do serviço para deletar a entrega do banco de dados.
-	err = h.Service.DeleteDelivery(uint(id))
+	err = h.Service.DeleteDelivery(c.Request.Context(), uint(id), version)
 	if err != nil {
-		// Se houver erro na exclusão, retorna um erro 500 (Internal Server Error).
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete delivery"})
+		respondWriteError(c, err, "Failed to delete delivery")
 		return
 	}
 
@@ -187,9 +246,31 @@ func (h *Handler) DeleteDelivery(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
-// validateDelivery realiza a validação dos campos da entrega.
-// Ele usa o pacote validator para validar campos obrigatórios e regras personalizadas.
+// validateDelivery realiza a validação dos campos de uma entrega nova, incluindo os volumes, o peso e o valor declarado.
 func validateDelivery(delivery *Delivery) error {
+	if err := validateDeliveryFields(delivery); err != nil {
+		return err
+	}
+
+	// Valida cada volume; sem volumes, a entrega é um volume só e o peso dela não pode ser negativo ou zero.
+	for i := range delivery.Packages {
+		if err := validatePackage(&delivery.Packages[i]); err != nil {
+			return fmt.Errorf("package %d: %w", i+1, err)
+		}
+	}
+	if len(delivery.Packages) == 0 && delivery.Weight <= 0 {
+		return fmt.Errorf("weight must be greater than zero")
+	}
+	if delivery.DeclaredValue < 0 {
+		return fmt.Errorf("declared value must not be negative")
+	}
+	return nil
+}
+
+// validateDeliveryFields realiza a validação dos campos da entrega que não dependem dos volumes; é usada também na
+// atualização, que ignora os volumes, o peso e o valor declarado (veja UpdateDelivery).
+// Ele usa o pacote validator para validar campos obrigatórios e regras personalizadas.
+func validateDeliveryFields(delivery *Delivery) error {
 	validate := validator.New()
 
 	// Valida os campos obrigatórios da struct Delivery.
@@ -198,16 +279,16 @@ func validateDelivery(delivery *Delivery) error {
 		return fmt.Errorf("validation failed: %s", err.Error())
 	}
 
-	// Valida o peso da entrega (não pode ser negativo ou zero).
-	if delivery.Weight <= 0 {
-		return fmt.Errorf("weight must be greater than zero")
-	}
-
 	// Verifica se o status da entrega é válido.
 	if !isValidOrderStatus(delivery.OrderStatus) {
 		return fmt.Errorf("invalid order status, must be one of: 'Pendente', 'Enviado', 'Entregue', 'Cancelado'")
 	}
 
+	// Verifica o nível de serviço, se informado (vazio usa o padrão, standard).
+	if delivery.ServiceLevel != "" && !isValidServiceLevel(delivery.ServiceLevel) {
+		return fmt.Errorf("invalid service level, must be one of: 'standard', 'express'")
+	}
+
 	// Se todas as validações passarem, retorna nil (sem erros).
 	return nil
 }
@@ -228,7 +309,7 @@ func (h *Handler) GetDeliveriesByCPF(c *gin.Context) {
 	cpf := c.Param("cpf")
 
 	// Chama o método GetDeliveriesByCPF do serviço para buscar as entregas pelo CPF.
-	deliveries, err := h.Service.GetDeliveriesByCPF(cpf)
+	deliveries, err := h.Service.GetDeliveriesByCPF(c.Request.Context(), cpf)
 	if err != nil {
 		// Se nenhuma entrega for encontrada, retorna um erro 404 (Not Found).
 		c.JSON(http.StatusNotFound, gin.H{"error": "Nenhuma entrega encontrada"})
@@ -255,7 +336,7 @@ func (h *Handler) GetDeliveriesByCity(c *gin.Context) {
     city := c.Param("city")
 
     // Chama o método GetDeliveriesByCity do serviço para buscar as entregas pela cidade.
-    deliveries, err := h.Service.GetDeliveriesByCity(city)
+    deliveries, err := h.Service.GetDeliveriesByCity(c.Request.Context(), city)
     if err != nil {
         // Se nenhuma entrega for encontrada, retorna um erro 404 (Not Found).
         c.JSON(http.StatusNotFound, gin.H{"error": "Nenhuma entrega encontrada"})
@@ -281,7 +362,7 @@ func (h *Handler) GetDeliveriesByClientName(c *gin.Context) {
 	name := c.Param("name")
 
 	// Chama o método FindByClientName do serviço para buscar as entregas associadas ao nome do cliente
-	deliveries, err := h.Service.GetDeliveriesByClientName(name)
+	deliveries, err := h.Service.GetDeliveriesByClientName(c.Request.Context(), name)
 	if err != nil {
 		// Se nenhuma entrega for encontrada, retorna um erro 404 (Not Found)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Nenhuma entrega encontrada"})
@@ -300,10 +381,14 @@ func (h *Handler) GetDeliveriesByClientName(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "ID da Entrega"
+// @Param If-Match header string true "ETag da versão que está sendo alterada"
 // @Param status body string true "Novo Status"
 // @Success 200 {object} Delivery
 // @Failure 400 "Requisição inválida"
 // @Failure 404 "Entrega não encontrada"
+// @Failure 409 "Comprovante de entrega obrigatório para o status Entregue"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
 // @Router /deliveries/{id}/status [patch]
 func (h *Handler) UpdateOrderStatus(c *gin.Context) {
 	// Obtém o ID da entrega da URL e converte para uint.
@@ -322,13 +407,693 @@ func (h *Handler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
+	// Obtém a versão esperada do cabeçalho If-Match, obrigatório (veja etag.Require).
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+
 	// Chama o método UpdateOrderStatus do serviço para atualizar o status da entrega.
-	err = h.Service.UpdateOrderStatus(uint(id), request.Status)
+	err = h.Service.UpdateOrderStatus(c.Request.Context(), uint(id), request.Status, version)
 	if err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrProofRequired) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Entrega não encontrada"})
 		return
 	}
 
 	// Retorna uma mensagem de sucesso com status 200 (OK).
 	c.JSON(http.StatusOK, gin.H{"message": "Status atualizado com sucesso"})
-}
\ No newline at end of file
+}
+
+// respondWriteError traduz os erros das operações de escrita em respostas HTTP.
+// Conflitos de versão viram 412 (Precondition Failed), entregas inexistentes viram 404 (Not Found),
+// a falta do comprovante de entrega vira 409 (Conflict) e qualquer outro erro vira 500 (Internal Server Error)
+// com a mensagem informada.
+func respondWriteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrProofRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+	case errors.Is(err, ErrPackageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+	case errors.Is(err, ErrPackagesLocked), errors.Is(err, ErrLastPackage), errors.Is(err, ErrCapacityExceeded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// maxImportSize é o tamanho máximo aceito para o arquivo CSV de importação (20 MB).
+const maxImportSize = 20 << 20
+
+// ImportDeliveries é um handler HTTP para importar entregas em lote a partir de um arquivo CSV.
+// O arquivo pode ser enviado no campo "file" de um formulário multipart ou diretamente no corpo (text/csv).
+// @Summary Importa entregas a partir de um CSV
+// @Description Valida cada linha do CSV e grava as entregas válidas em lotes dentro de uma transação.
+// @Description Por padrão, as colunas usam os mesmos nomes dos campos JSON (client_cpf, weight, ...).
+// @Description O parâmetro mapping permite associar outros nomes de coluna a esses campos, por exemplo {"CPF":"client_cpf"}.
+// @Tags Deliveries
+// @Accept text/csv,multipart/form-data
+// @Produce json
+// @Param file formData file false "Arquivo CSV"
+// @Param dry_run query bool false "Apenas valida, sem gravar"
+// @Param mapping query string false "Mapeamento de colunas em JSON (coluna -> campo)"
+// @Success 200 {object} ImportReport
+// @Failure 400 "Bad Request"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/import [post]
+func (h *Handler) ImportDeliveries(c *gin.Context) {
+	// Lê o modo dry-run da query string.
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+		return
+	}
+
+	// Lê o mapeamento de colunas, se informado.
+	var mapping map[string]string
+	if raw := c.Query("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping, expected a JSON object"})
+			return
+		}
+	}
+
+	// Obtém o arquivo do formulário multipart ou, na falta dele, usa o corpo da requisição.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var file io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV file in field 'file'"})
+			return
+		}
+		upload, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file"})
+			return
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	// Converte o CSV em linhas de entrega.
+	rows, err := ParseCSV(file, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Chama o método ImportDeliveries do serviço para validar e gravar as entregas.
+	report, err := h.Service.ImportDeliveries(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import deliveries"})
+		return
+	}
+
+	// Retorna o relatório da importação com status 200 (OK).
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportColumns são as colunas do CSV de exportação, com os mesmos nomes aceitos pela importação.
+var ExportColumns = []string{
+	"id", "client_cpf", "client_name", "test_name", "weight", "chargeable_weight", "logradouro", "numero", "bairro",
+	"complemento", "cidade", "estado", "pais", "latitude", "longitude", "order_status",
+}
+
+// ExportRecord converte uma entrega em uma linha do CSV de exportação.
+func ExportRecord(d *Delivery) []string {
+	return []string{
+		strconv.FormatUint(uint64(d.ID), 10), d.ClientCPF, d.ClientName, d.TestName, export.Decimal(d.Weight),
+		export.Decimal(d.ChargeableWeight), d.Logradouro, d.Numero, d.Bairro, d.Complemento, d.Cidade, d.Estado, d.Pais,
+		export.Decimal(d.Latitude), export.Decimal(d.Longitude), d.OrderStatus,
+	}
+}
+
+// ExportDeliveries é um handler HTTP para exportar as entregas em CSV ou NDJSON.
+// As linhas são lidas do banco com um cursor e enviadas ao cliente em blocos, sem carregar tudo em memória.
+// @Summary Exporta entregas em CSV ou NDJSON
+// @Description Exporta as entregas que atendem aos mesmos filtros da listagem.
+// @Description O CSV usa BOM UTF-8, ";" como separador e vírgula decimal, para abrir diretamente no Excel em pt-BR.
+// @Tags Deliveries
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "Formato do arquivo (csv ou ndjson)" default(csv)
+// @Param client_cpf query string false "CPF do cliente"
+// @Param client_name query string false "Início do nome do cliente"
+// @Param cidade query string false "Início do nome da cidade"
+// @Param estado query string false "Estado (UF)"
+// @Param order_status query string false "Status do pedido"
+// @Param zone_id query int false "ID da zona"
+// @Success 200 {file} file
+// @Failure 400 "Bad Request"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/export [get]
+func (h *Handler) ExportDeliveries(c *gin.Context) {
+	// Valida o formato solicitado.
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Lê os filtros da query string.
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Percorre as entregas com o serviço, gravando cada uma no arquivo de saída.
+	export.Stream(c, format, "deliveries", ExportColumns, func(emit export.EmitFunc) error {
+		return h.Service.ExportDeliveries(c.Request.Context(), filter, func(d *Delivery) error {
+			return emit(ExportRecord(d), d)
+		})
+	})
+}
+
+// StreamDeliveries é um handler HTTP que envia as mudanças de status das entregas em tempo real (Server-Sent Events).
+// Cada mudança é enviada como um evento "status_changed" cujo id é o ID do evento na outbox.
+// Ao reconectar, o navegador envia o último id recebido no cabeçalho Last-Event-ID e as mudanças perdidas são reenviadas.
+// Se houver mais mudanças perdidas que o limite reenviado de uma vez, o stream envia o id da última lida e é encerrado,
+// para que o navegador reconecte a partir dele.
+// @Summary Stream de status das entregas
+// @Description Envia as mudanças de status das entregas via Server-Sent Events, com heartbeats periódicos.
+// @Description Os filtros são opcionais. Para retomar o stream, use o cabeçalho Last-Event-ID (ou o parâmetro last_event_id).
+// @Description Se o reenvio das mudanças perdidas atingir o limite, o stream envia o id da última mudança lida e é encerrado;
+// @Description reconecte com esse id para receber as demais.
+// @Tags Deliveries
+// @Produce text/event-stream
+// @Param id query int false "ID da entrega"
+// @Param client_cpf query string false "CPF do cliente"
+// @Param cidade query string false "Cidade da entrega"
+// @Param Last-Event-ID header int false "ID do último evento recebido"
+// @Param last_event_id query int false "ID do último evento recebido (alternativa ao cabeçalho)"
+// @Success 200 {string} string "Stream de eventos"
+// @Failure 400 "Bad Request"
+// @Failure 503 "Stream indisponível"
+// @Router /deliveries/stream [get]
+func (h *Handler) StreamDeliveries(c *gin.Context) {
+	if h.Broker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Delivery stream is not available"})
+		return
+	}
+
+	// Lê os filtros e o ponto de retomada.
+	var filter StreamFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var afterID uint64
+	if lastEventID != "" {
+		var err error
+		if afterID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Inscreve o cliente antes de buscar as mudanças perdidas, para não perder as que acontecerem no meio tempo.
+	subscription := h.Broker.Subscribe(filter)
+	defer h.Broker.Unsubscribe(subscription)
+
+	var replayed map[uint]bool
+	var missed []StatusEvent
+	var replay *StatusReplay
+	if afterID > 0 {
+		var err error
+		replay, err = h.Service.GetStatusChangesAfter(c.Request.Context(), uint(afterID), filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status changes"})
+			return
+		}
+		// As mudanças confirmadas fora de ordem (ID menor que o Last-Event-ID) vêm antes, para que o último id
+		// enviado continue sendo o maior.
+		missed = append(h.Broker.LateAfter(uint(afterID), filter), replay.Events...)
+		replayed = make(map[uint]bool, len(missed))
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Desativa o buffer do nginx
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+
+	for _, statusEvent := range missed {
+		if err := writeStatusEvent(c.Writer, statusEvent); err != nil {
+			return
+		}
+		replayed[statusEvent.ID] = true
+	}
+	// Limite de reenvio atingido: envia o id da última mudança lida (sem dados, o navegador só guarda o id)
+	// e encerra o stream, para que o navegador reconecte a partir dele.
+	if replay != nil && replay.Truncated {
+		fmt.Fprintf(c.Writer, "id: %d\n\n", replay.LastID)
+		c.Writer.Flush()
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := h.StreamHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case statusEvent, ok := <-subscription.Events:
+			// Canal fechado: o cliente ficou para trás (ou o servidor está desligando) e deve reconectar com o Last-Event-ID.
+			if !ok {
+				return
+			}
+			if replayed[statusEvent.ID] {
+				continue
+			}
+			if err := writeStatusEvent(c.Writer, statusEvent); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStatusEvent escreve uma mudança de status no formato de Server-Sent Events.
+func writeStatusEvent(w io.Writer, statusEvent StatusEvent) error {
+	data, err := json.Marshal(statusEvent.Change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: status_changed\ndata: %s\n\n", statusEvent.ID, data)
+	return err
+}
+
+// GetLateDeliveries é um handler HTTP para buscar as entregas atrasadas.
+// @Summary Buscar entregas atrasadas
+// @Description Retorna as entregas em andamento (Pendente ou Enviado) com o prazo (sla_due_at) vencido,
+// @Description da mais atrasada para a menos atrasada. Com within, inclui também as que vencem nesse intervalo.
+// @Tags Deliveries
+// @Produce  json
+// @Param within query string false "Inclui as entregas que vencem nesse intervalo a partir de agora (ex.: 24h, 90m)"
+// @Success 200 {array} Delivery
+// @Failure 400 "Intervalo inválido"
+// @Failure 500 "Erro ao buscar as entregas atrasadas"
+// @Router /deliveries/late [get]
+func (h *Handler) GetLateDeliveries(c *gin.Context) {
+	// Lê o intervalo opcional (formato do Go, como 24h ou 90m).
+	var within time.Duration
+	if value := c.Query("within"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid within, expected a positive duration such as 24h"})
+			return
+		}
+		within = parsed
+	}
+
+	deliveries, err := h.Service.GetLateDeliveries(c.Request.Context(), within)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch late deliveries"})
+		return
+	}
+
+	// Retorna a lista de entregas com status 200 (OK).
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDeliveryHistory é um handler HTTP para buscar o histórico de uma entrega.
+// @Summary Histórico da entrega
+// @Description Retorna, em ordem, os eventos da entrega gravados na outbox: criação, alterações, mudanças de status,
+// @Description sinalização de atraso, atribuições a entregadores e remoção. O campo data traz o conteúdo de cada evento.
+// @Tags Deliveries
+// @Produce  json
+// @Param id path int true "ID da entrega"
+// @Success 200 {array} HistoryEntry
+// @Failure 400 "ID inválido"
+// @Failure 404 "Entrega não encontrada"
+// @Failure 500 "Erro ao buscar o histórico"
+// @Router /deliveries/{id}/history [get]
+func (h *Handler) GetDeliveryHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	history, err := h.Service.GetDeliveryHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, ErrDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch delivery history"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// bindMapFilter lê os filtros da listagem e o retângulo do mapa (bbox, "oeste,sul,leste,norte"), usados pelas
+// rotas do mapa. Em caso de erro, responde 400 e retorna false.
+func bindMapFilter(c *gin.Context, requireBBox bool) (Filter, bool) {
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	value := c.Query("bbox")
+	if value == "" {
+		if requireBBox {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bbox is required"})
+			return filter, false
+		}
+		return filter, true
+	}
+	bounds, err := geo.ParseBBox(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	filter.BBox = &bounds
+	return filter, true
+}
+
+// GetDeliveriesGeoJSON é um handler HTTP para exportar as entregas como um FeatureCollection do GeoJSON.
+// As entregas são lidas com um cursor e enviadas ao cliente em blocos, sem carregar tudo em memória.
+// @Summary Entregas em GeoJSON
+// @Description Retorna as entregas com coordenadas que atendem aos filtros da listagem como um FeatureCollection
+// @Description (RFC 7946): um Point por entrega, com o status, o prazo, a zona e o entregador nas propriedades.
+// @Description Os dados pessoais do cliente não são incluídos. Entregas sem coordenadas (0, 0) ficam de fora.
+// @Tags Deliveries
+// @Produce application/geo+json
+// @Param bbox query string false "Retângulo do mapa: oeste,sul,leste,norte (ex.: -46.8,-23.7,-46.4,-23.4)"
+// @Param client_cpf query string false "CPF do cliente"
+// @Param client_name query string false "Início do nome do cliente"
+// @Param cidade query string false "Início do nome da cidade"
+// @Param estado query string false "Estado (UF)"
+// @Param order_status query string false "Status do pedido"
+// @Param zone_id query int false "ID da zona"
+// @Success 200 {array} Feature "FeatureCollection com as entregas em features"
+// @Failure 400 "Filtro ou bbox inválidos"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries.geojson [get]
+func (h *Handler) GetDeliveriesGeoJSON(c *gin.Context) {
+	filter, ok := bindMapFilter(c, false)
+	if !ok {
+		return
+	}
+
+	// A resposta só é iniciada na primeira entrega, então um erro antes disso ainda vira um 500.
+	ctx := c.Request.Context()
+	now := time.Now()
+	started, count := false, 0
+	start := func() {
+		c.Header("Content-Type", GeoJSONContentType)
+		c.Status(http.StatusOK)
+		io.WriteString(c.Writer, `{"type":"FeatureCollection","features":[`)
+		started = true
+	}
+	err := h.Service.ExportDeliveries(ctx, filter, func(d *Delivery) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Latitude == 0 && d.Longitude == 0 {
+			return nil
+		}
+		data, err := json.Marshal(NewFeature(d, now))
+		if err != nil {
+			return err
+		}
+		if !started {
+			start()
+		} else if _, err := io.WriteString(c.Writer, ","); err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write(data); err != nil {
+			return err
+		}
+		if count++; count%export.ChunkSize == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export deliveries"})
+			return
+		}
+		// A resposta já começou: resta registrar o erro e encerrar o documento como está.
+		slog.ErrorContext(c.Request.Context(), "GeoJSON export interrupted", "deliveries", count, "err", err)
+	}
+	if !started {
+		start()
+	}
+	io.WriteString(c.Writer, "]}")
+	c.Writer.Flush()
+}
+
+// GetDeliveryClusters é um handler HTTP para agrupar as entregas do mapa por geohash.
+// @Summary Entregas agrupadas para o mapa
+// @Description Agrupa as entregas com coordenadas dentro do bbox pela célula do geohash, com a precisão definida
+// @Description pelo zoom do mapa (de 1 caractere no zoom 0 a 8 a partir do zoom 18). Cada grupo é um Point no centro
+// @Description das suas entregas, com o total, a quantidade de cada status e, se tiver uma única entrega, o ID dela.
+// @Description Aceita os mesmos filtros da listagem.
+// @Tags Deliveries
+// @Produce application/geo+json
+// @Param zoom query int true "Zoom do mapa (0 a 22)"
+// @Param bbox query string true "Retângulo do mapa: oeste,sul,leste,norte (ex.: -46.8,-23.7,-46.4,-23.4)"
+// @Param client_cpf query string false "CPF do cliente"
+// @Param client_name query string false "Início do nome do cliente"
+// @Param cidade query string false "Início do nome da cidade"
+// @Param estado query string false "Estado (UF)"
+// @Param order_status query string false "Status do pedido"
+// @Param zone_id query int false "ID da zona"
+// @Success 200 {object} ClusterCollection
+// @Failure 400 "Zoom, filtro ou bbox inválidos"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/clusters [get]
+func (h *Handler) GetDeliveryClusters(c *gin.Context) {
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil || zoom < 0 || zoom > geo.MaxZoom {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("zoom must be an integer between 0 and %d", geo.MaxZoom)})
+		return
+	}
+	filter, ok := bindMapFilter(c, true)
+	if !ok {
+		return
+	}
+
+	clusters, err := h.Service.ClusterDeliveries(c.Request.Context(), filter, geo.PrecisionForZoom(zoom))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cluster deliveries"})
+		return
+	}
+	c.Header("Content-Type", GeoJSONContentType)
+	c.JSON(http.StatusOK, clusters)
+}
+
+// GetPackages é um handler HTTP para listar os volumes de uma entrega.
+// @Summary Lista os volumes de uma entrega
+// @Description Retorna os volumes da entrega, na ordem em que foram incluídos.
+// @Tags Deliveries
+// @Produce json
+// @Param id path int true "ID da entrega"
+// @Success 200 {array} Package
+// @Failure 400 "Bad Request"
+// @Failure 404 "Delivery not found"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/packages [get]
+func (h *Handler) GetPackages(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	packages, err := h.Service.GetPackages(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err, "Failed to fetch packages")
+		return
+	}
+	c.JSON(http.StatusOK, packages)
+}
+
+// AddPackage é um handler HTTP para incluir um volume em uma entrega.
+// @Summary Inclui um volume na entrega
+// @Description O peso e o valor declarado da entrega passam a incluir o volume (quantity × weight e quantity × declared_value).
+// @Description Os volumes só podem ser alterados enquanto a entrega está pendente.
+// @Tags Deliveries
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da entrega"
+// @Param If-Match header string false "ETag da versão da entrega que está sendo editada"
+// @Param Package body Package true "Volume a ser incluído"
+// @Success 201 {object} Package
+// @Header 201 {string} ETag "Nova versão da entrega"
+// @Failure 400 "Bad Request"
+// @Failure 404 "Delivery not found"
+// @Failure 409 "A entrega não está pendente ou o entregador dela não tem capacidade para o novo peso"
+// @Failure 412 "Precondition Failed"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/packages [post]
+func (h *Handler) AddPackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	pkg, version, ok := bindPackage(c, false)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.Service.AddPackage(c.Request.Context(), id, &pkg, version)
+	if err != nil {
+		respondWriteError(c, err, "Failed to add package")
+		return
+	}
+	etag.Set(c, delivery.Version)
+	c.JSON(http.StatusCreated, pkg)
+}
+
+// UpdatePackage é um handler HTTP para alterar um volume de uma entrega.
+// @Summary Altera um volume da entrega
+// @Description Substitui os dados do volume e recalcula o peso e o valor declarado da entrega.
+// @Description Os volumes só podem ser alterados enquanto a entrega está pendente.
+// @Tags Deliveries
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da entrega"
+// @Param package_id path int true "ID do volume"
+// @Param If-Match header string true "ETag da versão da entrega que está sendo editada"
+// @Param Package body Package true "Volume com os dados atualizados"
+// @Success 200 {object} Package
+// @Header 200 {string} ETag "Nova versão da entrega"
+// @Failure 400 "Bad Request"
+// @Failure 404 "Entrega ou volume não encontrados"
+// @Failure 409 "A entrega não está pendente ou o entregador dela não tem capacidade para o novo peso"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/packages/{package_id} [put]
+func (h *Handler) UpdatePackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	packageID, ok := parseID(c, "package_id")
+	if !ok {
+		return
+	}
+	pkg, version, ok := bindPackage(c, true)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.Service.UpdatePackage(c.Request.Context(), id, packageID, &pkg, version)
+	if err != nil {
+		respondWriteError(c, err, "Failed to update package")
+		return
+	}
+	etag.Set(c, delivery.Version)
+	c.JSON(http.StatusOK, pkg)
+}
+
+// DeletePackage é um handler HTTP para remover um volume de uma entrega.
+// @Summary Remove um volume da entrega
+// @Description Recalcula o peso e o valor declarado da entrega. O único volume de uma entrega não pode ser removido.
+// @Description Os volumes só podem ser alterados enquanto a entrega está pendente.
+// @Tags Deliveries
+// @Param id path int true "ID da entrega"
+// @Param package_id path int true "ID do volume"
+// @Param If-Match header string true "ETag da versão da entrega que está sendo editada"
+// @Success 204 "No Content"
+// @Header 204 {string} ETag "Nova versão da entrega"
+// @Failure 400 "Bad Request"
+// @Failure 404 "Entrega ou volume não encontrados"
+// @Failure 409 "A entrega não está pendente ou o volume é o único da entrega"
+// @Failure 412 "Precondition Failed"
+// @Failure 428 "Precondition Required"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/packages/{package_id} [delete]
+func (h *Handler) DeletePackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	packageID, ok := parseID(c, "package_id")
+	if !ok {
+		return
+	}
+	version, ok := etag.Require(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.Service.DeletePackage(c.Request.Context(), id, packageID, version)
+	if err != nil {
+		respondWriteError(c, err, "Failed to delete package")
+		return
+	}
+	etag.Set(c, delivery.Version)
+	c.Status(http.StatusNoContent)
+}
+
+// bindPackage lê e valida o volume enviado no corpo e a versão esperada da entrega (If-Match),
+// respondendo 400 se algum deles for inválido. Com required, o If-Match é obrigatório (veja etag.Require);
+// sem ele, como na inclusão de um volume, o cabeçalho é opcional.
+func bindPackage(c *gin.Context, required bool) (Package, uint, bool) {
+	var pkg Package
+	if err := c.ShouldBindJSON(&pkg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Package{}, 0, false
+	}
+	if err := validatePackage(&pkg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Package{}, 0, false
+	}
+	if required {
+		version, ok := etag.Require(c)
+		return pkg, version, ok
+	}
+	version, err := etag.IfMatch(c)
+	if errors.Is(err, etag.ErrWeak) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return Package{}, 0, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+		return Package{}, 0, false
+	}
+	return pkg, version, true
+}
+
+// parseID lê um ID da rota, respondendo 400 se ele for inválido.
+func parseID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
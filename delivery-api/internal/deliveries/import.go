@@ -0,0 +1,165 @@
+package deliveries
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportRow representa uma linha do arquivo CSV convertida em uma entrega.
+// Row é o número da linha no arquivo (o cabeçalho é a linha 1).
+// Quando a linha não pôde ser convertida, ParseError contém o motivo e Delivery deve ser ignorada.
+type ImportRow struct {
+	Row        int
+	Delivery   Delivery
+	ParseError string
+}
+
+// ImportAccepted identifica uma linha aceita na importação.
+// ID só é preenchido quando a entrega foi de fato gravada (fora do modo dry-run).
+type ImportAccepted struct {
+	Row int  `json:"row"`
+	ID  uint `json:"id,omitempty"`
+}
+
+// ImportRejected identifica uma linha rejeitada na importação e o motivo.
+type ImportRejected struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// @description Relatório da importação de entregas
+// @type object
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Accepted []ImportAccepted `json:"accepted"`
+	Rejected []ImportRejected `json:"rejected"`
+}
+
+// importFields associa o nome de cada campo (o mesmo usado no JSON) à função que o preenche a partir do texto do CSV.
+// As colunas "id" e "chargeable_weight" de um arquivo exportado são aceitas e ignoradas: a importação sempre cria
+// novas entregas, e o peso tarifado é calculado a partir dos volumes.
+var importFields = map[string]func(d *Delivery, value string) error{
+	"id":                func(d *Delivery, v string) error { return nil },
+	"chargeable_weight": func(d *Delivery, v string) error { return nil },
+	"client_cpf":        func(d *Delivery, v string) error { d.ClientCPF = v; return nil },
+	"client_name":       func(d *Delivery, v string) error { d.ClientName = v; return nil },
+	"test_name":         func(d *Delivery, v string) error { d.TestName = v; return nil },
+	"weight":            func(d *Delivery, v string) error { return parseDecimal(v, &d.Weight) },
+	"logradouro":        func(d *Delivery, v string) error { d.Logradouro = v; return nil },
+	"numero":            func(d *Delivery, v string) error { d.Numero = v; return nil },
+	"bairro":            func(d *Delivery, v string) error { d.Bairro = v; return nil },
+	"complemento":       func(d *Delivery, v string) error { d.Complemento = v; return nil },
+	"cidade":            func(d *Delivery, v string) error { d.Cidade = v; return nil },
+	"estado":            func(d *Delivery, v string) error { d.Estado = v; return nil },
+	"pais":              func(d *Delivery, v string) error { d.Pais = v; return nil },
+	"latitude":          func(d *Delivery, v string) error { return parseDecimal(v, &d.Latitude) },
+	"longitude":         func(d *Delivery, v string) error { return parseDecimal(v, &d.Longitude) },
+	"order_status":      func(d *Delivery, v string) error { d.OrderStatus = v; return nil },
+}
+
+// ParseCSV lê um arquivo CSV de entregas e converte cada linha em um ImportRow.
+// A primeira linha deve ser o cabeçalho. Por padrão, cada coluna é associada ao campo de mesmo nome do JSON
+// (por exemplo, "client_cpf"); mapping permite associar outros nomes de coluna a esses campos (coluna -> campo).
+// O separador (vírgula ou ponto e vírgula) é detectado a partir do cabeçalho, e números aceitam vírgula decimal.
+// Retorna erro apenas quando o arquivo inteiro é inválido (por exemplo, cabeçalho ausente ou coluna desconhecida).
+func ParseCSV(r io.Reader, mapping map[string]string) ([]ImportRow, error) {
+	buffered := bufio.NewReader(r)
+
+	// Ignora o BOM do UTF-8, comum em arquivos gerados pelo Excel.
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		buffered.Discard(3)
+	}
+
+	// Detecta o separador olhando apenas a linha do cabeçalho.
+	headerLine, err := buffered.Peek(buffered.Size())
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if i := strings.IndexByte(string(headerLine), '\n'); i >= 0 {
+		headerLine = headerLine[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = detectSeparator(string(headerLine))
+	reader.FieldsPerRecord = -1 // O número de colunas é verificado linha a linha
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv file is empty")
+		}
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	// Resolve, para cada coluna, qual campo da entrega ela preenche.
+	setters := make([]func(*Delivery, string) error, len(header))
+	for i, column := range header {
+		name := strings.TrimSpace(column)
+		field := strings.ToLower(name)
+		if mapped, ok := mapping[name]; ok {
+			field = strings.ToLower(mapped)
+		}
+		setter, ok := importFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		setters[i] = setter
+	}
+
+	var rows []ImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		row := ImportRow{Row: line}
+		switch {
+		case err != nil:
+			row.ParseError = fmt.Sprintf("invalid csv line: %s", err.Error())
+		case len(record) != len(header):
+			row.ParseError = fmt.Sprintf("expected %d columns, got %d", len(header), len(record))
+		default:
+			for i, value := range record {
+				if err := setters[i](&row.Delivery, strings.TrimSpace(value)); err != nil {
+					row.ParseError = fmt.Sprintf("column %q: %s", header[i], err.Error())
+					break
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// detectSeparator escolhe entre vírgula e ponto e vírgula de acordo com o que aparece mais no cabeçalho.
+func detectSeparator(header string) rune {
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		return ';'
+	}
+	return ','
+}
+
+// parseDecimal converte um número que pode usar vírgula como separador decimal (por exemplo, "10,5").
+func parseDecimal(value string, target *float64) error {
+	if value == "" {
+		return fmt.Errorf("value is required")
+	}
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*target = number
+	return nil
+}
@@ -0,0 +1,141 @@
+package deliveries
+
+import (
+	"sort"
+	"time"
+
+	"delivery-api/internal/geo"
+)
+
+// GeoJSONContentType é o tipo de conteúdo das respostas em GeoJSON (RFC 7946).
+const GeoJSONContentType = "application/geo+json"
+
+// Location é a posição e o status de uma entrega, lidos para o agrupamento do mapa.
+type Location struct {
+	ID          uint
+	Latitude    float64
+	Longitude   float64
+	OrderStatus string
+}
+
+// @description Entrega no mapa (Feature do GeoJSON)
+// @type object
+type Feature struct {
+	Type       string            `json:"type"` // Sempre "Feature"
+	ID         uint              `json:"id"`
+	Geometry   geo.Point         `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// FeatureProperties são os dados da entrega levados ao mapa. Os dados pessoais do cliente não são incluídos.
+type FeatureProperties struct {
+	OrderStatus string     `json:"order_status"`
+	SLAStatus   string     `json:"sla_status,omitempty"`
+	SLADueAt    *time.Time `json:"sla_due_at"`
+	Weight      float64    `json:"weight"`
+	Cidade      string     `json:"cidade"`
+	Estado      string     `json:"estado"`
+	ZoneID      *uint      `json:"zone_id"`
+	CourierID   *uint      `json:"courier_id"`
+}
+
+// NewFeature converte a entrega em um Feature do GeoJSON, com o SLA calculado em now.
+func NewFeature(d *Delivery, now time.Time) Feature {
+	return Feature{
+		Type:     "Feature",
+		ID:       d.ID,
+		Geometry: geo.NewPoint(d.Latitude, d.Longitude),
+		Properties: FeatureProperties{
+			OrderStatus: d.OrderStatus,
+			SLAStatus:   d.SLAStatusAt(now),
+			SLADueAt:    d.SLADueAt,
+			Weight:      d.Weight,
+			Cidade:      d.Cidade,
+			Estado:      d.Estado,
+			ZoneID:      d.ZoneID,
+			CourierID:   d.CourierID,
+		},
+	}
+}
+
+// @description Grupo de entregas no mapa (Feature do GeoJSON no centro das entregas do grupo)
+// @type object
+type ClusterFeature struct {
+	Type       string    `json:"type"` // Sempre "Feature"
+	Geometry   geo.Point `json:"geometry"`
+	Properties Cluster   `json:"properties"`
+}
+
+// Cluster são os dados de um grupo de entregas que caem na mesma célula do geohash.
+type Cluster struct {
+	Geohash    string         `json:"geohash"`
+	Count      int            `json:"count"`
+	ByStatus   map[string]int `json:"by_status"`             // Quantidade de entregas de cada status
+	DeliveryID *uint          `json:"delivery_id,omitempty"` // Preenchido quando o grupo tem uma única entrega
+}
+
+// @description Entregas agrupadas por geohash (FeatureCollection do GeoJSON)
+// @type object
+type ClusterCollection struct {
+	Type      string           `json:"type"`      // Sempre "FeatureCollection"
+	Precision int              `json:"precision"` // Número de caracteres do geohash usado no agrupamento
+	Total     int              `json:"total"`     // Total de entregas agrupadas
+	Features  []ClusterFeature `json:"features"`
+}
+
+// clusterer acumula as entregas por geohash, somando as coordenadas para calcular o centro de cada grupo.
+type clusterer struct {
+	precision int
+	total     int
+	groups    map[string]*clusterSum
+}
+
+// clusterSum é a soma parcial de um grupo.
+type clusterSum struct {
+	cluster Cluster
+	sumLat  float64
+	sumLon  float64
+	firstID uint
+}
+
+// newClusterer cria um agrupador com a precisão de geohash informada.
+func newClusterer(precision int) *clusterer {
+	return &clusterer{precision: precision, groups: make(map[string]*clusterSum)}
+}
+
+// add inclui a entrega no grupo da célula dela.
+func (c *clusterer) add(location *Location) {
+	hash := geo.Geohash(location.Latitude, location.Longitude, c.precision)
+	group, ok := c.groups[hash]
+	if !ok {
+		group = &clusterSum{cluster: Cluster{Geohash: hash, ByStatus: map[string]int{}}, firstID: location.ID}
+		c.groups[hash] = group
+	}
+	group.cluster.Count++
+	group.cluster.ByStatus[location.OrderStatus]++
+	group.sumLat += location.Latitude
+	group.sumLon += location.Longitude
+	c.total++
+}
+
+// collection retorna os grupos em ordem de geohash, cada um no centro das suas entregas.
+func (c *clusterer) collection() *ClusterCollection {
+	features := make([]ClusterFeature, 0, len(c.groups))
+	for _, group := range c.groups {
+		cluster := group.cluster
+		if cluster.Count == 1 {
+			id := group.firstID
+			cluster.DeliveryID = &id
+		}
+		count := float64(cluster.Count)
+		features = append(features, ClusterFeature{
+			Type:       "Feature",
+			Geometry:   geo.NewPoint(group.sumLat/count, group.sumLon/count),
+			Properties: cluster,
+		})
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Properties.Geohash < features[j].Properties.Geohash
+	})
+	return &ClusterCollection{Type: "FeatureCollection", Precision: c.precision, Total: c.total, Features: features}
+}
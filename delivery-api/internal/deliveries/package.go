@@ -0,0 +1,126 @@
+package deliveries
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Unidades aceitas nas dimensões dos volumes (dimension_unit).
+const (
+	DimensionUnitCentimeter = "cm" // Padrão
+	DimensionUnitInch       = "in"
+)
+
+// cubicCentimetersPerCubicInch converte as dimensões em polegadas para o volume em centímetros cúbicos.
+const cubicCentimetersPerCubicInch = 2.54 * 2.54 * 2.54
+
+// DefaultCubicDivisor é o divisor padrão do peso cubado (CUBIC_WEIGHT_DIVISOR): o volume em centímetros cúbicos
+// dividido por 6000 dá o peso em quilos, o fator mais usado pelas transportadoras no transporte rodoviário.
+const DefaultCubicDivisor = 6000
+
+// ErrPackageNotFound é retornado quando o volume não existe ou pertence a outra entrega.
+var ErrPackageNotFound = errors.New("package not found")
+
+// ErrPackagesLocked é retornado ao alterar os volumes de uma entrega que já saiu do status Pendente.
+var ErrPackagesLocked = errors.New("packages can only be changed while the delivery is pending")
+
+// ErrLastPackage é retornado ao remover o único volume de uma entrega: toda entrega tem ao menos um volume.
+var ErrLastPackage = errors.New("a delivery must have at least one package")
+
+// ErrCapacityExceeded é retornado quando o peso tarifado das entregas em andamento do entregador passaria da
+// capacidade dele, ao alterar os volumes de uma entrega atribuída ou ao atribuir uma entrega (couriers.ErrCapacityExceeded).
+var ErrCapacityExceeded = errors.New("courier capacity exceeded")
+
+// @description Volume (caixa) de uma entrega
+// @type object
+type Package struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID    uint      `json:"delivery_id" gorm:"not null;index"`
+	Description   string    `json:"description" gorm:"size:255;not null"`
+	Quantity      int       `json:"quantity" gorm:"not null"`                         // Quantidade de volumes iguais
+	Weight        float64   `json:"weight" gorm:"not null"`                           // Peso de cada volume, na mesma unidade do weight da entrega
+	Length        float64   `json:"length" gorm:"not null;default:0"`                 // Comprimento em dimension_unit (0 se não informado)
+	Width         float64   `json:"width" gorm:"not null;default:0"`                  // Largura em dimension_unit (0 se não informada)
+	Height        float64   `json:"height" gorm:"not null;default:0"`                 // Altura em dimension_unit (0 se não informada)
+	DimensionUnit string    `json:"dimension_unit" gorm:"size:2;not null;default:cm"` // cm (padrão) ou in
+	CubedWeight   float64   `json:"cubed_weight" gorm:"not null;default:0"`           // Peso cubado de cada volume, calculado pela aplicação
+	DeclaredValue float64   `json:"declared_value" gorm:"not null;default:0"`         // Valor declarado de cada volume, em reais
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName define o nome da tabela dos volumes das entregas.
+func (Package) TableName() string {
+	return "delivery_packages"
+}
+
+// validatePackage valida os dados de um volume: descrição, quantidade e peso são obrigatórios,
+// as dimensões e o valor declarado não podem ser negativos e a unidade das dimensões, se informada, é cm ou in.
+func validatePackage(pkg *Package) error {
+	if strings.TrimSpace(pkg.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+	if pkg.Quantity < 1 {
+		return fmt.Errorf("quantity must be at least 1")
+	}
+	if pkg.Weight <= 0 {
+		return fmt.Errorf("weight must be greater than zero")
+	}
+	if pkg.Length < 0 || pkg.Width < 0 || pkg.Height < 0 {
+		return fmt.Errorf("dimensions must not be negative")
+	}
+	if pkg.DimensionUnit != "" && pkg.DimensionUnit != DimensionUnitCentimeter && pkg.DimensionUnit != DimensionUnitInch {
+		return fmt.Errorf("invalid dimension unit, must be one of: 'cm', 'in'")
+	}
+	if pkg.DeclaredValue < 0 {
+		return fmt.Errorf("declared value must not be negative")
+	}
+	return nil
+}
+
+// prepare completa um volume antes de gravá-lo: a unidade das dimensões (cm, se não informada) e o peso cubado,
+// calculado com o divisor informado. O peso cubado enviado pelo cliente é ignorado.
+func (p *Package) prepare(cubicDivisor float64) {
+	if p.DimensionUnit == "" {
+		p.DimensionUnit = DimensionUnitCentimeter
+	}
+	volume := p.Length * p.Width * p.Height // Em centímetros cúbicos
+	if p.DimensionUnit == DimensionUnitInch {
+		volume *= cubicCentimetersPerCubicInch
+	}
+	p.CubedWeight = math.Round(volume/cubicDivisor*1000) / 1000
+}
+
+// applyPackages prepara os volumes de uma entrega nova e calcula os totais a partir deles (veja setTotals).
+// Sem volumes, a entrega é tratada como um volume só, com test_name, weight e declared_value informados;
+// com volumes, o weight e o declared_value enviados são substituídos pela soma dos volumes e, se o test_name
+// não for informado, ele recebe a descrição do primeiro volume. Os IDs enviados pelo cliente são ignorados.
+func (d *Delivery) applyPackages(cubicDivisor float64) {
+	if len(d.Packages) == 0 {
+		d.Packages = []Package{{Description: d.TestName, Quantity: 1, Weight: d.Weight, DeclaredValue: d.DeclaredValue}}
+	}
+	for i := range d.Packages {
+		d.Packages[i].ID, d.Packages[i].DeliveryID = 0, 0
+		d.Packages[i].prepare(cubicDivisor)
+	}
+	if d.TestName == "" {
+		d.TestName = d.Packages[0].Description
+	}
+	d.setTotals(d.Packages)
+}
+
+// setTotals calcula os totais da entrega a partir dos volumes, multiplicando cada um pela quantidade: o peso real,
+// o peso cubado, o valor declarado e o peso tarifado, que soma o maior entre o peso real e o cubado de cada volume.
+func (d *Delivery) setTotals(packages []Package) {
+	d.Weight, d.CubedWeight, d.ChargeableWeight, d.DeclaredValue = 0, 0, 0, 0
+	for _, pkg := range packages {
+		quantity := float64(pkg.Quantity)
+		d.Weight += quantity * pkg.Weight
+		d.CubedWeight += quantity * pkg.CubedWeight
+		d.ChargeableWeight += quantity * math.Max(pkg.Weight, pkg.CubedWeight)
+		d.DeclaredValue += quantity * pkg.DeclaredValue
+	}
+}
@@ -1,67 +1,190 @@
 package deliveries
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+
+	"delivery-api/internal/events"
+	"delivery-api/internal/zones"
 )
 
 // Repository é uma interface que define os métodos que o repositório deve implementar.
 // Ela serve como um contrato para a camada de acesso a dados relacionada a entregas.
 type Repository interface {
-	CreateDelivery(delivery *Delivery) (*Delivery, error) // Cria uma nova entrega
-	GetDeliveries() ([]Delivery, error)                  // Retorna todas as entregas
-	GetDeliveryByID(id uint) (*Delivery, error)          // Retorna uma entrega pelo ID
-	UpdateDelivery(id uint, delivery *Delivery) (*Delivery, error) // Atualiza uma entrega
-	DeleteDelivery(id uint) error                        // Deleta uma entrega pelo ID
-	FindByCPF(cpf string) ([]Delivery, error)            // Busca entregas pelo CPF do cliente
-	FindByClientName(name string) ([]Delivery, error) // Busca entregas pelo Nome do cliente
-	FindByCity(city string) ([]Delivery, error) // Busca entregas pelo Nome da cidade
-	UpdateOrderStatus(id uint, status string) error      // Atualiza o status de uma entrega
+	CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) // Cria uma nova entrega
+	GetDeliveries(ctx context.Context, filter Filter) ([]Delivery, error)     // Retorna as entregas que atendem ao filtro
+	StreamDeliveries(ctx context.Context, filter Filter, fn func(*Delivery) error) error // Percorre as entregas com um cursor
+	StreamLocations(ctx context.Context, filter Filter, fn func(*Location) error) error // Percorre a posição e o status das entregas
+	GetDeliveryByID(ctx context.Context, id uint) (*Delivery, error)          // Retorna uma entrega pelo ID
+	UpdateDelivery(ctx context.Context, id uint, delivery *Delivery) (*Delivery, error) // Atualiza uma entrega
+	DeleteDelivery(ctx context.Context, id uint, version uint) error          // Deleta uma entrega pelo ID
+	FindByCPF(ctx context.Context, cpf string) ([]Delivery, error)            // Busca entregas pelo CPF do cliente
+	FindByClientName(ctx context.Context, name string) ([]Delivery, error) // Busca entregas pelo Nome do cliente
+	FindByCity(ctx context.Context, city string) ([]Delivery, error) // Busca entregas pelo Nome da cidade
+	UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error // Atualiza o status de uma entrega
+	CreateDeliveries(ctx context.Context, deliveries []Delivery, batchSize int) error  // Cria várias entregas em uma única transação
+	FindClientNamesByCPF(ctx context.Context, cpfs []string) (map[string]string, error) // Retorna o nome dos clientes cadastrados por CPF
+	FindStatusChangesAfter(ctx context.Context, afterID uint, deliveryID uint, limit int) ([]StatusEvent, error) // Lê as mudanças de status gravadas na outbox
+	LastOutboxEventID(ctx context.Context) (uint, error) // Retorna o ID do último evento gravado na outbox
+	CountByStatus(ctx context.Context) (map[string]int64, error) // Conta as entregas de cada status
+	FindLate(ctx context.Context, dueBefore time.Time) ([]Delivery, error) // Busca as entregas em andamento com prazo anterior a dueBefore
+	FlagOverdue(ctx context.Context, now time.Time, limit int) (int, error) // Sinaliza as entregas em andamento com prazo vencido
+	CancelStale(ctx context.Context, createdBefore time.Time, reason string, limit int) (int, error) // Cancela as entregas pendentes criadas antes de createdBefore
+	FindHistory(ctx context.Context, id uint) ([]HistoryEntry, error) // Lê os eventos da entrega gravados na outbox
+	LoadZoneIndex(ctx context.Context) (*zones.Index, error) // Carrega as zonas para localizar as entregas
+	RelocateZones(ctx context.Context, index *zones.Index, afterID uint, limit int) (uint, int, error) // Localiza de novo as entregas em andamento
+	RecomputeWeights(ctx context.Context, afterID uint, limit int) (uint, int, error) // Recalcula os pesos cubado e tarifado das entregas
+	FindPackages(ctx context.Context, deliveryID uint) ([]Package, error) // Lista os volumes de uma entrega
+	SavePackage(ctx context.Context, deliveryID uint, pkg *Package, version uint) (*Delivery, error) // Cria ou altera um volume da entrega
+	DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*Delivery, error) // Remove um volume da entrega
 }
 
+// ErrDeliveryNotFound é retornado quando a entrega solicitada não existe.
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// ErrVersionConflict é retornado quando a versão esperada pelo cliente (If-Match)
+// não corresponde mais à versão armazenada, ou seja, a entrega foi alterada por outra requisição.
+var ErrVersionConflict = errors.New("delivery was modified by another request")
+
 // repository é uma struct que implementa a interface Repository.
-// Ela contém uma instância do GORM (*gorm.DB) para interagir com o banco de dados.
+// Ela contém uma instância do GORM (*gorm.DB) para interagir com o banco de dados
+// e o divisor usado no cálculo do peso cubado dos volumes.
 type repository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	cubicDivisor float64
 }
 
 // NewRepository cria uma nova instância do repositório.
-// Recebe uma conexão com o banco de dados (*gorm.DB) e retorna um objeto que implementa a interface Repository.
-func NewRepository(db *gorm.DB) Repository {
-	return &repository{db: db}
+// Recebe uma conexão com o banco de dados (*gorm.DB) e o divisor do peso cubado (CUBIC_WEIGHT_DIVISOR, normalmente
+// DefaultCubicDivisor) e retorna um objeto que implementa a interface Repository.
+func NewRepository(db *gorm.DB, cubicDivisor float64) Repository {
+	return &repository{db: db, cubicDivisor: cubicDivisor}
 }
 
 // CreateDelivery cria uma nova entrega no banco de dados.
 // Recebe um ponteiro para um objeto Delivery e o persiste no banco de dados usando o GORM.
+// Os volumes são gravados junto com a entrega, que recebe os totais deles (veja applyPackages).
+// A zona é localizada pelas coordenadas, e o evento DeliveryCreated é gravado na outbox na mesma transação.
 // Retorna a entrega criada ou um erro, caso ocorra algum problema.
-func (r *repository) CreateDelivery(delivery *Delivery) (*Delivery, error) {
-	if err := r.db.Create(&delivery).Error; err != nil {
+func (r *repository) CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) {
+	// Toda entrega nasce na versão 1, com o horário de criação e o prazo calculados pela aplicação.
+	delivery.Version = 1
+	delivery.start(time.Now())
+	delivery.applyPackages(r.cubicDivisor)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		zoneID, err := zones.Locate(tx, delivery.Latitude, delivery.Longitude)
+		if err != nil {
+			return err
+		}
+		delivery.ZoneID = zoneID
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		return events.Record(tx, events.AggregateDelivery, delivery.ID, events.DeliveryCreated, delivery)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return delivery, nil
 }
 
-// GetDeliveries retorna todas as entregas cadastradas no banco de dados.
-// Usa o método Find do GORM para buscar todos os registros da tabela de entregas.
+// GetDeliveries retorna as entregas cadastradas no banco de dados que atendem ao filtro.
+// Usa o método Find do GORM para buscar os registros da tabela de entregas.
 // Retorna a lista de entregas ou um erro, caso ocorra algum problema.
-func (r *repository) GetDeliveries() ([]Delivery, error) {
+func (r *repository) GetDeliveries(ctx context.Context, filter Filter) ([]Delivery, error) {
 	var deliveries []Delivery
-	if err := r.db.Find(&deliveries).Error; err != nil {
+	if err := applyFilter(r.db.WithContext(ctx), filter).Find(&deliveries).Error; err != nil {
 		return nil, err
 	}
 	return deliveries, nil
 }
 
-// GetDeliveryByID retorna uma entrega específica com base no ID fornecido.
+// StreamDeliveries percorre as entregas que atendem ao filtro usando um cursor do banco de dados,
+// chamando fn para cada uma, sem carregar o resultado inteiro em memória.
+// A leitura é interrompida no primeiro erro retornado por fn.
+func (r *repository) StreamDeliveries(ctx context.Context, filter Filter, fn func(*Delivery) error) error {
+	rows, err := applyFilter(r.db.WithContext(ctx).Model(&Delivery{}), filter).Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var delivery Delivery
+		if err := r.db.WithContext(ctx).ScanRows(rows, &delivery); err != nil {
+			return err
+		}
+		if err := fn(&delivery); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamLocations percorre apenas o ID, as coordenadas e o status das entregas que atendem ao filtro, com um cursor,
+// chamando fn para cada uma. É a leitura usada no agrupamento do mapa, que pode passar por muitas entregas.
+func (r *repository) StreamLocations(ctx context.Context, filter Filter, fn func(*Location) error) error {
+	rows, err := applyFilter(r.db.WithContext(ctx).Model(&Delivery{}), filter).
+		Select("id, latitude, longitude, order_status").Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var location Location
+		if err := rows.Scan(&location.ID, &location.Latitude, &location.Longitude, &location.OrderStatus); err != nil {
+			return err
+		}
+		if err := fn(&location); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// applyFilter adiciona à consulta as condições correspondentes aos campos preenchidos do filtro.
+func applyFilter(db *gorm.DB, filter Filter) *gorm.DB {
+	if filter.ClientCPF != "" {
+		db = db.Where("client_cpf = ?", filter.ClientCPF)
+	}
+	if filter.ClientName != "" {
+		db = db.Where("LOWER(client_name) LIKE ?", strings.ToLower(filter.ClientName)+"%")
+	}
+	if filter.Cidade != "" {
+		db = db.Where("LOWER(cidade) LIKE ?", strings.ToLower(filter.Cidade)+"%")
+	}
+	if filter.Estado != "" {
+		db = db.Where("estado = ?", filter.Estado)
+	}
+	if filter.OrderStatus != "" {
+		db = db.Where("order_status = ?", filter.OrderStatus)
+	}
+	if filter.ZoneID != 0 {
+		db = db.Where("zone_id = ?", filter.ZoneID)
+	}
+	if filter.BBox != nil {
+		db = db.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+			filter.BBox.MinLat, filter.BBox.MaxLat, filter.BBox.MinLon, filter.BBox.MaxLon).
+			Where("NOT (latitude = 0 AND longitude = 0)")
+	}
+	return db
+}
+
+// GetDeliveryByID retorna uma entrega específica com base no ID fornecido, com os seus volumes.
 // Usa o método First do GORM para buscar a entrega pelo ID.
 // Retorna a entrega encontrada ou um erro, caso a entrega não exista ou ocorra algum problema.
-func (r *repository) GetDeliveryByID(id uint) (*Delivery, error) {
+func (r *repository) GetDeliveryByID(ctx context.Context, id uint) (*Delivery, error) {
 	var delivery Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Packages", orderPackages).First(&delivery, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("delivery not found")
+			return nil, ErrDeliveryNotFound
 		}
 		return nil, err
 	}
@@ -70,49 +193,144 @@ func (r *repository) GetDeliveryByID(id uint) (*Delivery, error) {
 
 // UpdateDelivery atualiza os dados de uma entrega existente no banco de dados.
 // Primeiro, busca a entrega pelo ID para garantir que ela existe.
-// Em seguida, usa o método Updates do GORM para aplicar as alterações.
+// Se delivery.Version for diferente de zero, ela é tratada como a versão esperada (If-Match)
+// e a atualização só acontece se a versão armazenada for a mesma.
+// Em seguida, usa o método Updates do GORM para aplicar as alterações, incrementando a versão.
+// Os horários do ciclo de vida seguem a mudança de status e o prazo é recalculado pelo estado e nível de serviço.
+// A zona é localizada de novo pelas coordenadas resultantes da alteração.
+// Os volumes e os totais calculados a partir deles não mudam (veja SavePackage e DeletePackage).
+// A passagem para Entregue exige o comprovante de entrega (veja requireProof).
+// Na mesma transação, grava na outbox o evento DeliveryUpdated e, se o status mudou, o DeliveryStatusChanged.
 // Retorna a entrega atualizada ou um erro, caso ocorra algum problema.
-func (r *repository) UpdateDelivery(id uint, delivery *Delivery) (*Delivery, error) {
-	var existingDelivery Delivery
-	if err := r.db.First(&existingDelivery, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("delivery not found")
+func (r *repository) UpdateDelivery(ctx context.Context, id uint, delivery *Delivery) (*Delivery, error) {
+	var updatedDelivery Delivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingDelivery Delivery
+		if err := tx.First(&existingDelivery, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeliveryNotFound
+			}
+			return err
+		}
+
+		// Verifica se o cliente está editando a versão mais recente da entrega.
+		if delivery.Version != 0 && delivery.Version != existingDelivery.Version {
+			return ErrVersionConflict
+		}
+		if err := requireProof(tx, &existingDelivery, delivery.OrderStatus); err != nil {
+			return err
 		}
-		return nil, err
-	}
 
-	// Atualiza os campos da entrega existente com os dados fornecidos.
-	if err := r.db.Model(&existingDelivery).Updates(delivery).Error; err != nil {
+		// Os horários e o prazo enviados pelo cliente são ignorados; eles são calculados abaixo.
+		delivery.CreatedAt, delivery.ShippedAt, delivery.DeliveredAt, delivery.SLADueAt = time.Time{}, nil, nil, nil
+		delivery.OverdueAt, delivery.CancelReason = nil, ""
+		// O entregador é alterado apenas pelas rotas de atribuição (pacote couriers).
+		delivery.CourierID, delivery.AssignedAt = nil, nil
+		delivery.ZoneID = nil
+		// Os volumes são alterados apenas pelas rotas dos volumes, que recalculam os pesos e o valor declarado.
+		delivery.Packages, delivery.Weight, delivery.DeclaredValue = nil, 0, 0
+		delivery.CubedWeight, delivery.ChargeableWeight = 0, 0
+		now := time.Now()
+		lifecycle := lifecycleChanges(&existingDelivery, delivery.OrderStatus, now)
+		if lifecycle == nil {
+			lifecycle = map[string]interface{}{}
+		}
+		estado, serviceLevel := delivery.Estado, delivery.ServiceLevel
+		if estado == "" {
+			estado = existingDelivery.Estado
+		}
+		if serviceLevel == "" {
+			serviceLevel = existingDelivery.ServiceLevel
+		}
+		due := existingDelivery.CreatedAt.Add(SLATarget(estado, serviceLevel))
+		lifecycle["sla_due_at"] = due
+		// Se o novo prazo ainda não venceu, o atraso sinalizado antes deixa de valer.
+		if existingDelivery.OverdueAt != nil && due.After(now) {
+			lifecycle["overdue_at"] = nil
+		}
+		// Coordenadas zeradas não são alteradas pelo Updates abaixo, então valem as que já estavam gravadas.
+		latitude, longitude := delivery.Latitude, delivery.Longitude
+		if latitude == 0 {
+			latitude = existingDelivery.Latitude
+		}
+		if longitude == 0 {
+			longitude = existingDelivery.Longitude
+		}
+		zoneID, err := zones.Locate(tx, latitude, longitude)
+		if err != nil {
+			return err
+		}
+		lifecycle["zone_id"] = zoneID
+
+		// Atualiza os campos da entrega existente com os dados fornecidos.
+		// A condição sobre a versão impede que duas atualizações concorrentes se sobrescrevam.
+		currentVersion := existingDelivery.Version
+		delivery.Version = currentVersion + 1
+		result := tx.Model(&existingDelivery).Where("version = ?", currentVersion).Updates(delivery)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if err := tx.Model(&Delivery{}).Where("id = ?", id).UpdateColumns(lifecycle).Error; err != nil {
+			return err
+		}
+
+		// Relê a entrega para que a resposta e o evento tenham os dados gravados.
+		if err := tx.Preload("Packages", orderPackages).First(&updatedDelivery, id).Error; err != nil {
+			return err
+		}
+		if err := events.Record(tx, events.AggregateDelivery, id, events.DeliveryUpdated, &updatedDelivery); err != nil {
+			return err
+		}
+		return recordStatusChange(tx, &updatedDelivery, existingDelivery.OrderStatus)
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &existingDelivery, nil
+	return &updatedDelivery, nil
 }
 
 // DeleteDelivery remove uma entrega do banco de dados com base no ID fornecido.
-// Primeiro, verifica se a entrega existe.
-// Em seguida, usa o método Delete do GORM para excluir o registro.
+// Primeiro, verifica se a entrega existe e, se uma versão for informada, se ela ainda é a atual.
+// Em seguida, usa o método Delete do GORM para excluir o registro e os seus volumes e grava o evento DeliveryDeleted
+// na mesma transação.
 // Retorna um erro, caso ocorra algum problema durante a exclusão.
-func (r *repository) DeleteDelivery(id uint) error {
-	var delivery Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("delivery not found")
+func (r *repository) DeleteDelivery(ctx context.Context, id uint, version uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var delivery Delivery
+		if err := tx.First(&delivery, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeliveryNotFound
+			}
+			return err
 		}
-		return err
-	}
 
-	if err := r.db.Delete(&delivery).Error; err != nil {
-		return err
-	}
-	return nil
+		query := tx
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Delete(&delivery)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if err := tx.Where("delivery_id = ?", id).Delete(&Package{}).Error; err != nil {
+			return err
+		}
+		return events.Record(tx, events.AggregateDelivery, id, events.DeliveryDeleted, &delivery)
+	})
 }
 
 // FindByCPF busca todas as entregas associadas a um CPF específico.
 // Usa o método Where do GORM para filtrar os registros pelo CPF.
 // Retorna a lista de entregas ou um erro, caso ocorra algum problema.
-func (r *repository) FindByCPF(cpf string) ([]Delivery, error) {
+func (r *repository) FindByCPF(ctx context.Context, cpf string) ([]Delivery, error) {
 	var deliveries []Delivery
-	if err := r.db.Where("client_cpf = ?", cpf).Find(&deliveries).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("client_cpf = ?", cpf).Find(&deliveries).Error; err != nil {
 		return nil, err
 	}
 	return deliveries, nil
@@ -121,14 +339,14 @@ func (r *repository) FindByCPF(cpf string) ([]Delivery, error) {
 // FindByClientName busca múltiplas entregas associadas a um cliente no banco de dados com base em uma correspondência parcial do nome do cliente.
 // Usa o método Where do GORM com LIKE para filtrar os registros pelo nome do cliente.
 // A busca não será sensível a maiúsculas/minúsculas.
-func (r *repository) FindByClientName(name string) ([]Delivery, error) {
+func (r *repository) FindByClientName(ctx context.Context, name string) ([]Delivery, error) {
 	var deliveries []Delivery
 
 	// Converte o nome para minúsculas e adiciona o operador LIKE
 	searchTerm := strings.ToLower(name) + "%"
 
 	// Realiza a busca com a cláusula WHERE e o Preload em uma única consulta
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		// Realiza a busca no nome do cliente associado à entrega
 		Joins("JOIN clients c ON c.name = deliveries.client_name").
 		Where("LOWER(c.name) LIKE ?", searchTerm). // Filtra pelo nome do cliente
@@ -143,14 +361,14 @@ func (r *repository) FindByClientName(name string) ([]Delivery, error) {
 // FindByCity busca múltiplas entregas associadas a uma cidade no banco de dados com base em uma correspondência parcial do nome da cidade.
 // Usa o método Where do GORM com LIKE para filtrar os registros pelo nome da cidade.
 // A busca não será sensível a maiúsculas/minúsculas.
-func (r *repository) FindByCity(city string) ([]Delivery, error) {
+func (r *repository) FindByCity(ctx context.Context, city string) ([]Delivery, error) {
 	var deliveries []Delivery
 
 	// Converte o nome da cidade para minúsculas e adiciona o operador LIKE
 	searchTerm := strings.ToLower(city) + "%"
 
 	// Realiza a busca com a cláusula WHERE
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Where("LOWER(cidade) LIKE ?", searchTerm). // Filtra pelo nome da cidade
 		Find(&deliveries).                         // Executa a consulta
 		Error; err != nil {
@@ -162,8 +380,604 @@ func (r *repository) FindByCity(city string) ([]Delivery, error) {
 
 
 // UpdateOrderStatus atualiza o status de uma entrega no banco de dados.
-// Usa o método Update do GORM para alterar o campo "order_status" da entrega com o ID fornecido.
+// Usa o método Updates do GORM para alterar o campo "order_status" e incrementar a versão da entrega.
+// Se uma versão for informada, a atualização só acontece se ela ainda for a versão atual.
+// Os horários do ciclo de vida (envio e entrega) são atualizados junto com o status.
+// A passagem para Entregue exige o comprovante de entrega (veja requireProof).
+// Se o status mudou, o evento DeliveryStatusChanged é gravado na outbox na mesma transação.
 // Retorna um erro, caso ocorra algum problema durante a atualização.
-func (r *repository) UpdateOrderStatus(id uint, status string) error {
-	return r.db.Model(&Delivery{}).Where("id = ?", id).Update("order_status", status).Error
-}
\ No newline at end of file
+func (r *repository) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingDelivery Delivery
+		if err := tx.First(&existingDelivery, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeliveryNotFound
+			}
+			return err
+		}
+		if version != 0 && version != existingDelivery.Version {
+			return ErrVersionConflict
+		}
+		if err := requireProof(tx, &existingDelivery, status); err != nil {
+			return err
+		}
+
+		query := tx.Model(&Delivery{}).Where("id = ?", id)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		changes := map[string]interface{}{
+			"order_status": status,
+			"version":      gorm.Expr("version + 1"),
+		}
+		for column, value := range lifecycleChanges(&existingDelivery, status, time.Now()) {
+			changes[column] = value
+		}
+		result := query.Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Nenhuma linha afetada: a entrega existe, então a versão não confere.
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		var updatedDelivery Delivery
+		if err := tx.First(&updatedDelivery, id).Error; err != nil {
+			return err
+		}
+		return recordStatusChange(tx, &updatedDelivery, existingDelivery.OrderStatus)
+	})
+}
+
+// recordStatusChange grava o evento DeliveryStatusChanged se o status da entrega for diferente do anterior.
+func recordStatusChange(tx *gorm.DB, delivery *Delivery, previousStatus string) error {
+	if delivery.OrderStatus == previousStatus {
+		return nil
+	}
+	change := StatusChange{Delivery: delivery, PreviousStatus: previousStatus}
+	return events.Record(tx, events.AggregateDelivery, delivery.ID, events.DeliveryStatusChanged, change)
+}
+
+// FindHistory lê da outbox todos os eventos da entrega, na ordem em que foram gravados.
+// Os eventos continuam na outbox depois de entregues aos assinantes, então formam o histórico da entrega.
+func (r *repository) FindHistory(ctx context.Context, id uint) ([]HistoryEntry, error) {
+	var outbox []events.Event
+	err := r.db.WithContext(ctx).Where("aggregate_type = ? AND aggregate_id = ?", events.AggregateDelivery, id).
+		Order("id").Find(&outbox).Error
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]HistoryEntry, len(outbox))
+	for i, event := range outbox {
+		history[i] = HistoryEntry{ID: event.ID, Type: event.Type, Data: json.RawMessage(event.Payload), CreatedAt: event.CreatedAt}
+	}
+	return history, nil
+}
+
+// CreateDeliveries cria várias entregas em lotes dentro de uma única transação.
+// Se qualquer lote falhar, nenhuma entrega é gravada.
+// A zona de cada entrega é localizada com as zonas carregadas uma única vez (zones.Index), e os volumes são
+// gravados junto com as entregas (veja applyPackages).
+// Um evento DeliveryCreated por entrega é gravado na outbox na mesma transação.
+// Os IDs gerados são preenchidos nas próprias entregas do slice.
+func (r *repository) CreateDeliveries(ctx context.Context, deliveries []Delivery, batchSize int) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	// Toda entrega nasce na versão 1, com o horário de criação e o prazo calculados pela aplicação.
+	now := time.Now()
+	for i := range deliveries {
+		deliveries[i].Version = 1
+		deliveries[i].start(now)
+		deliveries[i].applyPackages(r.cubicDivisor)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		index, err := zones.LoadIndex(tx)
+		if err != nil {
+			return err
+		}
+		for i := range deliveries {
+			deliveries[i].ZoneID = index.Locate(deliveries[i].Latitude, deliveries[i].Longitude)
+		}
+		if err := tx.CreateInBatches(deliveries, batchSize).Error; err != nil {
+			return err
+		}
+
+		created := make([]events.Event, 0, len(deliveries))
+		for i := range deliveries {
+			event, err := events.New(events.AggregateDelivery, deliveries[i].ID, events.DeliveryCreated, &deliveries[i])
+			if err != nil {
+				return err
+			}
+			created = append(created, *event)
+		}
+		return tx.CreateInBatches(created, batchSize).Error
+	})
+}
+
+// FindStatusChangesAfter lê da outbox as mudanças de status com ID maior que afterID, em ordem.
+// Se deliveryID for diferente de zero, retorna apenas as mudanças dessa entrega.
+func (r *repository) FindStatusChangesAfter(ctx context.Context, afterID uint, deliveryID uint, limit int) ([]StatusEvent, error) {
+	query := r.db.WithContext(ctx).Where("id > ? AND type = ?", afterID, events.DeliveryStatusChanged)
+	if deliveryID != 0 {
+		query = query.Where("aggregate_type = ? AND aggregate_id = ?", events.AggregateDelivery, deliveryID)
+	}
+
+	var outbox []events.Event
+	if err := query.Order("id").Limit(limit).Find(&outbox).Error; err != nil {
+		return nil, err
+	}
+
+	statusEvents := make([]StatusEvent, 0, len(outbox))
+	for i := range outbox {
+		var change StatusChange
+		if err := outbox[i].Decode(&change); err != nil {
+			return nil, err
+		}
+		statusEvents = append(statusEvents, StatusEvent{ID: outbox[i].ID, Change: change})
+	}
+	return statusEvents, nil
+}
+
+// LastOutboxEventID retorna o ID do último evento gravado na outbox (0 se ela estiver vazia).
+func (r *repository) LastOutboxEventID(ctx context.Context) (uint, error) {
+	var id uint
+	if err := r.db.WithContext(ctx).Model(&events.Event{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// FindClientNamesByCPF busca na tabela de clientes os CPFs informados.
+// Retorna um mapa CPF -> nome contendo apenas os clientes que existem.
+// A consulta é feita em blocos para não exceder o limite de parâmetros do banco de dados.
+func (r *repository) FindClientNamesByCPF(ctx context.Context, cpfs []string) (map[string]string, error) {
+	const chunkSize = 500
+
+	names := make(map[string]string, len(cpfs))
+	for start := 0; start < len(cpfs); start += chunkSize {
+		end := start + chunkSize
+		if end > len(cpfs) {
+			end = len(cpfs)
+		}
+
+		var clients []struct {
+			CPF  string
+			Name string
+		}
+		if err := r.db.WithContext(ctx).Table("clients").Select("cpf, name").Where("cpf IN ?", cpfs[start:end]).Scan(&clients).Error; err != nil {
+			return nil, err
+		}
+		for _, client := range clients {
+			names[client.CPF] = client.Name
+		}
+	}
+	return names, nil
+}
+
+// CountByStatus conta as entregas agrupadas pelo status do pedido.
+// Retorna um mapa status -> quantidade, apenas com os status que têm entregas.
+func (r *repository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		OrderStatus string
+		Total       int64
+	}
+	if err := r.db.WithContext(ctx).Model(&Delivery{}).Select("order_status, COUNT(*) AS total").Group("order_status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.OrderStatus] = row.Total
+	}
+	return counts, nil
+}
+
+// FindLate busca as entregas ainda em andamento (Pendente ou Enviado) com prazo anterior a dueBefore,
+// da mais atrasada para a menos atrasada. Entregas sem prazo não entram.
+func (r *repository) FindLate(ctx context.Context, dueBefore time.Time) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.WithContext(ctx).
+		Where("order_status IN ? AND sla_due_at < ?", OpenStatuses, dueBefore).
+		Order("sla_due_at, id").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FlagOverdue sinaliza até limit entregas em andamento com o prazo vencido em now que ainda não foram sinalizadas.
+// Cada entrega é alterada na sua própria transação, que grava overdue_at, incrementa a versão e registra o evento
+// DeliveryOverdue na outbox. A condição da atualização é repetida para que uma entrega alterada (ou sinalizada
+// por outra instância) entre a busca e a atualização seja ignorada.
+// Retorna quantas entregas foram sinalizadas.
+func (r *repository) FlagOverdue(ctx context.Context, now time.Time, limit int) (int, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Delivery{}).
+		Where("order_status IN ? AND sla_due_at < ? AND overdue_at IS NULL", OpenStatuses, now).
+		Order("sla_due_at, id").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	for _, id := range ids {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Delivery{}).
+				Where("id = ? AND order_status IN ? AND sla_due_at < ? AND overdue_at IS NULL", id, OpenStatuses, now).
+				Updates(map[string]interface{}{"overdue_at": now, "version": gorm.Expr("version + 1")})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var delivery Delivery
+			if err := tx.First(&delivery, id).Error; err != nil {
+				return err
+			}
+			flagged++
+			return events.Record(tx, events.AggregateDelivery, id, events.DeliveryOverdue, &delivery)
+		})
+		if err != nil {
+			return flagged, err
+		}
+	}
+	return flagged, nil
+}
+
+// CancelStale cancela até limit entregas que continuam pendentes desde antes de createdBefore, gravando o motivo
+// em cancel_reason. Cada entrega é alterada na sua própria transação, com o evento DeliveryStatusChanged,
+// e só é cancelada se ainda estiver pendente no momento da atualização.
+// Retorna quantas entregas foram canceladas.
+func (r *repository) CancelStale(ctx context.Context, createdBefore time.Time, reason string, limit int) (int, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Delivery{}).
+		Where("order_status = ? AND created_at < ?", OrderStatusPending, createdBefore).
+		Order("created_at, id").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	canceled := 0
+	for _, id := range ids {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Delivery{}).
+				Where("id = ? AND order_status = ?", id, OrderStatusPending).
+				Updates(map[string]interface{}{
+					"order_status":  OrderStatusCanceled,
+					"cancel_reason": reason,
+					"version":       gorm.Expr("version + 1"),
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var delivery Delivery
+			if err := tx.First(&delivery, id).Error; err != nil {
+				return err
+			}
+			canceled++
+			return recordStatusChange(tx, &delivery, OrderStatusPending)
+		})
+		if err != nil {
+			return canceled, err
+		}
+	}
+	return canceled, nil
+}
+
+// requireProof retorna ErrProofRequired se a entrega estiver passando a Entregue sem um comprovante registrado.
+// É chamado dentro da transação da alteração, depois da verificação da versão, para que uma versão desatualizada
+// resulte em ErrVersionConflict. Entregas que já estavam entregues não são verificadas de novo, e a criação e a
+// importação de entregas já entregues continuam permitidas (dados históricos, anteriores aos comprovantes).
+// Os comprovantes são gravados pelo pacote proofs na tabela delivery_proofs; aqui ela é consultada pelo nome,
+// como a tabela de clientes em FindClientNamesByCPF, para que este pacote não dependa daquele.
+func requireProof(tx *gorm.DB, existing *Delivery, status string) error {
+	if status != OrderStatusDelivered || existing.OrderStatus == OrderStatusDelivered {
+		return nil
+	}
+	var count int64
+	if err := tx.Table("delivery_proofs").Where("delivery_id = ?", existing.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProofRequired
+	}
+	return nil
+}
+
+// LoadZoneIndex carrega todas as zonas em memória (veja zones.LoadIndex).
+func (r *repository) LoadZoneIndex(ctx context.Context) (*zones.Index, error) {
+	return zones.LoadIndex(r.db.WithContext(ctx))
+}
+
+// RelocateZones localiza de novo as entregas em andamento com ID maior que afterID, até limit entregas, em ordem de ID.
+// Cada entrega cuja zona mudou é alterada na sua própria transação, que grava zone_id, incrementa a versão e registra
+// o evento DeliveryZoneChanged na outbox. A atualização exige a mesma versão lida, então uma entrega alterada nesse
+// meio tempo é ignorada (a alteração já localizou a zona dela).
+// Retorna o maior ID lido (zero quando não há mais entregas) e quantas entregas mudaram de zona.
+func (r *repository) RelocateZones(ctx context.Context, index *zones.Index, afterID uint, limit int) (uint, int, error) {
+	var rows []struct {
+		ID        uint
+		Latitude  float64
+		Longitude float64
+		Version   uint
+		ZoneID    *uint
+	}
+	err := r.db.WithContext(ctx).Model(&Delivery{}).
+		Select("id, latitude, longitude, version, zone_id").
+		Where("id > ? AND order_status IN ?", afterID, OpenStatuses).
+		Order("id").Limit(limit).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	changed := 0
+	for _, row := range rows {
+		zoneID := index.Locate(row.Latitude, row.Longitude)
+		if sameZone(zoneID, row.ZoneID) {
+			continue
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Delivery{}).
+				Where("id = ? AND version = ?", row.ID, row.Version).
+				Updates(map[string]interface{}{"zone_id": zoneID, "version": gorm.Expr("version + 1")})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var delivery Delivery
+			if err := tx.First(&delivery, row.ID).Error; err != nil {
+				return err
+			}
+			changed++
+			change := &ZoneChange{Delivery: &delivery, ZoneID: zoneID, PreviousZoneID: row.ZoneID}
+			return events.Record(tx, events.AggregateDelivery, row.ID, events.DeliveryZoneChanged, change)
+		})
+		if err != nil {
+			return 0, changed, err
+		}
+	}
+	return rows[len(rows)-1].ID, changed, nil
+}
+
+// RecomputeWeights recalcula, com o divisor do repositório, o peso cubado dos volumes e os pesos cubado e tarifado
+// das entregas com ID maior que afterID, até limit entregas, em ordem de ID. Cada entrega cujo peso mudou é alterada
+// na sua própria transação, que grava os pesos, incrementa a versão e registra o evento DeliveryUpdated na outbox.
+// A atualização exige a mesma versão lida, então uma entrega alterada nesse meio tempo é ignorada. A capacidade dos
+// entregadores não é verificada: as atribuições existentes são mantidas.
+// Retorna o maior ID lido (zero quando não há mais entregas) e quantas entregas mudaram de peso.
+func (r *repository) RecomputeWeights(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	var found []Delivery
+	err := r.db.WithContext(ctx).Preload("Packages", orderPackages).
+		Where("id > ?", afterID).Order("id").Limit(limit).Find(&found).Error
+	if err != nil || len(found) == 0 {
+		return 0, 0, err
+	}
+
+	changed := 0
+	for i := range found {
+		delivery := &found[i]
+		if len(delivery.Packages) == 0 {
+			continue // Sem volumes, os pesos são os informados na entrega (veja a migração 0011_cubed_weight)
+		}
+		var stale []Package
+		for j := range delivery.Packages {
+			pkg := &delivery.Packages[j]
+			cubed := pkg.CubedWeight
+			pkg.prepare(r.cubicDivisor)
+			if pkg.CubedWeight != cubed {
+				stale = append(stale, *pkg)
+			}
+		}
+		var totals Delivery
+		totals.setTotals(delivery.Packages)
+		if len(stale) == 0 && totals.CubedWeight == delivery.CubedWeight && totals.ChargeableWeight == delivery.ChargeableWeight {
+			continue
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Delivery{}).Where("id = ? AND version = ?", delivery.ID, delivery.Version).
+				Updates(map[string]interface{}{
+					"cubed_weight":      totals.CubedWeight,
+					"chargeable_weight": totals.ChargeableWeight,
+					"version":           gorm.Expr("version + 1"),
+					"updated_at":        time.Now(),
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			for _, pkg := range stale {
+				if err := tx.Model(&Package{}).Where("id = ?", pkg.ID).Update("cubed_weight", pkg.CubedWeight).Error; err != nil {
+					return err
+				}
+			}
+
+			var updatedDelivery Delivery
+			if err := tx.Preload("Packages", orderPackages).First(&updatedDelivery, delivery.ID).Error; err != nil {
+				return err
+			}
+			changed++
+			return events.Record(tx, events.AggregateDelivery, delivery.ID, events.DeliveryUpdated, &updatedDelivery)
+		})
+		if err != nil {
+			return 0, changed, err
+		}
+	}
+	return found[len(found)-1].ID, changed, nil
+}
+
+// sameZone indica se as duas zonas são iguais (inclusive ambas nulas).
+func sameZone(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// orderPackages ordena os volumes carregados com a entrega pelo ID, na ordem em que foram incluídos.
+func orderPackages(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// FindPackages lista os volumes de uma entrega, na ordem em que foram incluídos.
+// Retorna ErrDeliveryNotFound se a entrega não existir.
+func (r *repository) FindPackages(ctx context.Context, deliveryID uint) ([]Package, error) {
+	delivery, err := r.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.Packages, nil
+}
+
+// SavePackage cria o volume na entrega (pkg.ID igual a zero) ou altera um volume existente dela.
+// O ID, a entrega, o peso cubado e os horários preenchidos em pkg são os gravados. Veja changePackages.
+func (r *repository) SavePackage(ctx context.Context, deliveryID uint, pkg *Package, version uint) (*Delivery, error) {
+	pkg.prepare(r.cubicDivisor)
+	return r.changePackages(ctx, deliveryID, version, func(tx *gorm.DB) error {
+		if pkg.ID == 0 {
+			pkg.DeliveryID = deliveryID
+			return tx.Create(pkg).Error
+		}
+
+		var existing Package
+		if err := tx.Where("id = ? AND delivery_id = ?", pkg.ID, deliveryID).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPackageNotFound
+			}
+			return err
+		}
+		pkg.DeliveryID, pkg.CreatedAt = deliveryID, existing.CreatedAt
+		return tx.Save(pkg).Error
+	})
+}
+
+// DeletePackage remove um volume da entrega. O único volume de uma entrega não pode ser removido (ErrLastPackage).
+// Veja changePackages.
+func (r *repository) DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*Delivery, error) {
+	return r.changePackages(ctx, deliveryID, version, func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Package{}).Where("delivery_id = ?", deliveryID).Count(&count).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND delivery_id = ?", packageID, deliveryID).Delete(&Package{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPackageNotFound
+		}
+		if count <= 1 {
+			return ErrLastPackage
+		}
+		return nil
+	})
+}
+
+// changePackages aplica change aos volumes da entrega dentro de uma transação e recalcula os pesos e o valor
+// declarado da entrega a partir dos volumes gravados (veja setTotals). Os volumes só podem ser alterados enquanto a entrega está pendente
+// (ErrPackagesLocked). Como em UpdateDelivery, uma versão diferente de zero é a versão esperada (If-Match); a versão
+// da entrega é incrementada e o evento DeliveryUpdated é gravado na outbox na mesma transação.
+// Se a entrega estiver atribuída e o peso tarifado aumentar, ele precisa caber na capacidade do entregador
+// (veja checkCourierCapacity).
+// Retorna a entrega alterada, com os volumes.
+func (r *repository) changePackages(ctx context.Context, deliveryID uint, version uint, change func(tx *gorm.DB) error) (*Delivery, error) {
+	var updatedDelivery Delivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingDelivery Delivery
+		if err := tx.First(&existingDelivery, deliveryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeliveryNotFound
+			}
+			return err
+		}
+		if version != 0 && version != existingDelivery.Version {
+			return ErrVersionConflict
+		}
+		if existingDelivery.OrderStatus != OrderStatusPending {
+			return ErrPackagesLocked
+		}
+		if err := change(tx); err != nil {
+			return err
+		}
+
+		var packages []Package
+		if err := tx.Where("delivery_id = ?", deliveryID).Find(&packages).Error; err != nil {
+			return err
+		}
+		var totals Delivery
+		totals.setTotals(packages)
+		if existingDelivery.CourierID != nil && totals.ChargeableWeight > existingDelivery.ChargeableWeight {
+			if err := checkCourierCapacity(tx, *existingDelivery.CourierID, deliveryID, totals.ChargeableWeight); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&Delivery{}).Where("id = ? AND version = ?", deliveryID, existingDelivery.Version).
+			Updates(map[string]interface{}{
+				"weight":            totals.Weight,
+				"cubed_weight":      totals.CubedWeight,
+				"chargeable_weight": totals.ChargeableWeight,
+				"declared_value":    totals.DeclaredValue,
+				"version":           gorm.Expr("version + 1"),
+				"updated_at":        time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		if err := tx.Preload("Packages", orderPackages).First(&updatedDelivery, deliveryID).Error; err != nil {
+			return err
+		}
+		return events.Record(tx, events.AggregateDelivery, deliveryID, events.DeliveryUpdated, &updatedDelivery)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updatedDelivery, nil
+}
+
+// checkCourierCapacity verifica se o peso tarifado weight da entrega, somado ao das outras entregas em andamento do
+// entregador, cabe na capacidade dele (ErrCapacityExceeded). Como em couriers.AssignTx, a linha do entregador é
+// alterada (updated_at) antes da soma, o que a bloqueia até o fim da transação; assim, uma atribuição simultânea ao
+// mesmo entregador espera esta alteração. A tabela couriers é consultada pelo nome, como delivery_proofs em
+// requireProof, porque o pacote couriers depende deste.
+func checkCourierCapacity(tx *gorm.DB, courierID, deliveryID uint, weight float64) error {
+	result := tx.Table("couriers").Where("id = ?", courierID).Update("updated_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil // Entregador removido: não há capacidade a respeitar
+	}
+	var capacity float64
+	if err := tx.Table("couriers").Select("capacity").Where("id = ?", courierID).Scan(&capacity).Error; err != nil {
+		return err
+	}
+
+	load, err := AssignedWeight(tx, courierID, deliveryID)
+	if err != nil {
+		return err
+	}
+	if load+weight > capacity {
+		return fmt.Errorf("%w: %g already assigned plus %g exceeds the capacity of %g", ErrCapacityExceeded, load, weight, capacity)
+	}
+	return nil
+}
+
+// AssignedWeight soma o peso tarifado (chargeable_weight) das entregas em andamento (OpenStatuses) do entregador,
+// sem contar a entrega except. É usada aqui e no pacote couriers, dentro da transação que já bloqueou o entregador.
+func AssignedWeight(tx *gorm.DB, courierID, except uint) (float64, error) {
+	var total float64
+	err := tx.Model(&Delivery{}).
+		Select("COALESCE(SUM(chargeable_weight), 0)").
+		Where("courier_id = ? AND order_status IN ? AND id <> ?", courierID, OpenStatuses, except).
+		Scan(&total).Error
+	return total, err
+}
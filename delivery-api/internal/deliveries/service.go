@@ -1,104 +1,262 @@
 package deliveries
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 )
 
+// ErrProofRequired é retornado quando uma entrega passaria a Entregue sem um comprovante de entrega registrado
+// (veja POST /deliveries/{id}/proof).
+var ErrProofRequired = errors.New("proof of delivery is required before marking the delivery as delivered")
+
 // Service é uma interface que define os métodos do serviço relacionado a entregas.
 // Ela serve como um contrato para a camada de lógica de negócio.
 type Service interface {
-	CreateDelivery(delivery *Delivery) (*Delivery, error)      // Cria uma nova entrega
-	GetDeliveries() ([]Delivery, error)                      // Retorna todas as entregas
-	GetDeliveryByID(id uint) (*Delivery, error)              // Retorna uma entrega pelo ID
-	UpdateDelivery(id uint, delivery *Delivery) (*Delivery, error) // Atualiza uma entrega
-	DeleteDelivery(id uint) error                            // Deleta uma entrega pelo ID
-	GetDeliveriesByCPF(cpf string) ([]Delivery, error)       // Busca entregas por CPF
-	GetDeliveriesByCity(city string) ([]Delivery, error)       // Busca entregas por cidade
-	GetDeliveriesByClientName(clientName string) ([]Delivery, error) // Busca entregas por Nome do cliente
-	UpdateOrderStatus(id uint, status string) error          // Atualiza o status de uma entrega
+	CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error)      // Cria uma nova entrega
+	GetDeliveries(ctx context.Context, filter Filter) ([]Delivery, error)         // Retorna as entregas que atendem ao filtro
+	ExportDeliveries(ctx context.Context, filter Filter, fn func(*Delivery) error) error // Percorre as entregas para exportação
+	ClusterDeliveries(ctx context.Context, filter Filter, precision int) (*ClusterCollection, error) // Agrupa as entregas por geohash
+	GetDeliveryByID(ctx context.Context, id uint) (*Delivery, error)              // Retorna uma entrega pelo ID
+	UpdateDelivery(ctx context.Context, id uint, delivery *Delivery) (*Delivery, error) // Atualiza uma entrega
+	DeleteDelivery(ctx context.Context, id uint, version uint) error              // Deleta uma entrega pelo ID
+	GetDeliveriesByCPF(ctx context.Context, cpf string) ([]Delivery, error)       // Busca entregas por CPF
+	GetDeliveriesByCity(ctx context.Context, city string) ([]Delivery, error)       // Busca entregas por cidade
+	GetDeliveriesByClientName(ctx context.Context, clientName string) ([]Delivery, error) // Busca entregas por Nome do cliente
+	UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error // Atualiza o status de uma entrega
+	ImportDeliveries(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) // Importa entregas em lote
+	GetStatusChangesAfter(ctx context.Context, afterID uint, filter StreamFilter) (*StatusReplay, error) // Mudanças de status para retomar o stream
+	CountDeliveriesByStatus(ctx context.Context) (map[string]int64, error) // Quantidade de entregas em cada status
+	GetLateDeliveries(ctx context.Context, within time.Duration) ([]Delivery, error) // Entregas em andamento com prazo vencido (ou vencendo em within)
+	FlagOverdueDeliveries(ctx context.Context) (int, error) // Sinaliza as entregas em andamento com prazo vencido
+	CancelStalePending(ctx context.Context, olderThan time.Duration, reason string) (int, error) // Cancela as entregas pendentes há mais de olderThan
+	RecomputeZones(ctx context.Context) (int, error) // Localiza de novo a zona das entregas em andamento
+	RecomputeWeights(ctx context.Context) (int, error) // Recalcula os pesos cubado e tarifado com o CUBIC_WEIGHT_DIVISOR atual
+	GetDeliveryHistory(ctx context.Context, id uint) ([]HistoryEntry, error) // Histórico de eventos de uma entrega
+	GetPackages(ctx context.Context, deliveryID uint) ([]Package, error) // Lista os volumes de uma entrega
+	AddPackage(ctx context.Context, deliveryID uint, pkg *Package, version uint) (*Delivery, error) // Inclui um volume na entrega
+	UpdatePackage(ctx context.Context, deliveryID, packageID uint, pkg *Package, version uint) (*Delivery, error) // Altera um volume da entrega
+	DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*Delivery, error) // Remove um volume da entrega
 }
 
+// importBatchSize é a quantidade de entregas inseridas por comando INSERT durante a importação.
+const importBatchSize = 200
+
+// maintenanceBatchSize é a quantidade de entregas lidas por vez pelas rotinas de sinalização e cancelamento.
+const maintenanceBatchSize = 100
+
+// streamReplayLimit é a quantidade máxima de mudanças de status reenviadas ao retomar o stream com Last-Event-ID.
+const streamReplayLimit = 1000
+
 // service é uma struct que implementa a interface Service.
 // Ela contém uma instância de um repositório (Repository) para interagir com a camada de dados.
+// Os eventos de domínio são gravados pelo próprio repositório, na outbox, dentro da transação de cada alteração.
 type service struct {
 	repo Repository
 }
 
 // NewService cria uma nova instância de service.
 // Recebe um repositório (Repository) como dependência e retorna um objeto que implementa a interface Service.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	return &tracedService{next: &service{repo: repo}}
 }
 
 // CreateDelivery implementa a lógica para criar uma nova entrega.
 // Ele valida o status da entrega antes de delegar a operação para o repositório.
-func (s *service) CreateDelivery(delivery *Delivery) (*Delivery, error) {
+func (s *service) CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) {
 	// Verifica se o status da entrega é válido.
 	if !isValidOrderStatus(delivery.OrderStatus) {
 		return nil, fmt.Errorf("invalid order status")
 	}
 
 	// Delega a criação da entrega para o repositório.
-	return s.repo.CreateDelivery(delivery)
+	return s.repo.CreateDelivery(ctx, delivery)
 }
 
-// GetDeliveries implementa a lógica para retornar todas as entregas cadastradas.
+// GetDeliveries implementa a lógica para retornar as entregas cadastradas que atendem ao filtro.
 // Ele delega a operação para o repositório.
-func (s *service) GetDeliveries() ([]Delivery, error) {
-	return s.repo.GetDeliveries()
+func (s *service) GetDeliveries(ctx context.Context, filter Filter) ([]Delivery, error) {
+	return s.repo.GetDeliveries(ctx, filter)
+}
+
+// ExportDeliveries implementa a lógica para percorrer as entregas que atendem ao filtro, uma a uma.
+// Ele delega a operação para o repositório, que lê os registros com um cursor.
+func (s *service) ExportDeliveries(ctx context.Context, filter Filter, fn func(*Delivery) error) error {
+	return s.repo.StreamDeliveries(ctx, filter, fn)
+}
+
+// ClusterDeliveries implementa a lógica para agrupar as entregas do mapa: as entregas com coordenadas que atendem ao
+// filtro são percorridas com um cursor e somadas na célula do geohash com a precisão informada. Só os grupos ficam
+// em memória, então a resposta tem o tamanho do mapa, não o da quantidade de entregas.
+func (s *service) ClusterDeliveries(ctx context.Context, filter Filter, precision int) (*ClusterCollection, error) {
+	clusters := newClusterer(precision)
+	err := s.repo.StreamLocations(ctx, filter, func(location *Location) error {
+		if location.Latitude != 0 || location.Longitude != 0 {
+			clusters.add(location)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clusters.collection(), nil
 }
 
 // GetDeliveryByID implementa a lógica para buscar uma entrega pelo ID.
 // Ele delega a operação para o repositório.
-func (s *service) GetDeliveryByID(id uint) (*Delivery, error) {
-	return s.repo.GetDeliveryByID(id)
+func (s *service) GetDeliveryByID(ctx context.Context, id uint) (*Delivery, error) {
+	return s.repo.GetDeliveryByID(ctx, id)
 }
 
 // UpdateDelivery implementa a lógica para atualizar os dados de uma entrega existente.
 // Ele valida o status da entrega antes de delegar a operação para o repositório.
-func (s *service) UpdateDelivery(id uint, delivery *Delivery) (*Delivery, error) {
+// A passagem para Entregue exige o comprovante de entrega, verificado pelo repositório.
+func (s *service) UpdateDelivery(ctx context.Context, id uint, delivery *Delivery) (*Delivery, error) {
 	// Verifica se o status da entrega é válido.
 	if !isValidOrderStatus(delivery.OrderStatus) {
 		return nil, fmt.Errorf("invalid order status")
 	}
 
 	// Delega a atualização da entrega para o repositório.
-	return s.repo.UpdateDelivery(id, delivery)
+	return s.repo.UpdateDelivery(ctx, id, delivery)
 }
 
 // DeleteDelivery implementa a lógica para deletar uma entrega pelo ID.
+// A versão esperada (0 para não verificar) é repassada ao repositório.
 // Ele delega a operação para o repositório.
-func (s *service) DeleteDelivery(id uint) error {
-	return s.repo.DeleteDelivery(id)
+func (s *service) DeleteDelivery(ctx context.Context, id uint, version uint) error {
+	return s.repo.DeleteDelivery(ctx, id, version)
 }
 
 // GetDeliveriesByCPF implementa a lógica para buscar entregas associadas a um CPF específico.
 // Ele delega a operação para o repositório.
-func (s *service) GetDeliveriesByCPF(cpf string) ([]Delivery, error) {
-	return s.repo.FindByCPF(cpf)
+func (s *service) GetDeliveriesByCPF(ctx context.Context, cpf string) ([]Delivery, error) {
+	return s.repo.FindByCPF(ctx, cpf)
 }
 // GetDeliveriesByCity implementa a lógica para buscar entregas associadas a um CPF específico.
 // Ele delega a operação para o repositório.
-func (s *service) GetDeliveriesByCity(city string) ([]Delivery, error) {
-	return s.repo.FindByCity(city)
+func (s *service) GetDeliveriesByCity(ctx context.Context, city string) ([]Delivery, error) {
+	return s.repo.FindByCity(ctx, city)
 }
 
 // GetDeliveriesByName implementa a lógica para buscar entregas associadas a um Name específico.
 // Ele delega a operação para o repositório.
-func (s *service) GetDeliveriesByClientName(clientName string) ([]Delivery, error) {
-	return s.repo.FindByClientName(clientName)
+func (s *service) GetDeliveriesByClientName(ctx context.Context, clientName string) ([]Delivery, error) {
+	return s.repo.FindByClientName(ctx, clientName)
 }
 
 // UpdateOrderStatus implementa a lógica para atualizar o status de uma entrega.
 // Ele valida o novo status antes de delegar a operação para o repositório.
-func (s *service) UpdateOrderStatus(id uint, status string) error {
+// A versão esperada (0 para não verificar) é repassada ao repositório.
+// A passagem para Entregue exige o comprovante de entrega, verificado pelo repositório.
+func (s *service) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error {
 	// Verifica se o novo status é válido.
 	if !isValidOrderStatus(status) {
 		return fmt.Errorf("invalid order status")
 	}
 
 	// Delega a atualização do status para o repositório.
-	return s.repo.UpdateOrderStatus(id, status)
+	return s.repo.UpdateOrderStatus(ctx, id, status, version)
+}
+
+// ImportDeliveries implementa a lógica de importação em lote de entregas.
+// Cada linha passa pelas mesmas validações da criação (validateDelivery) e o CPF deve pertencer a um cliente cadastrado.
+// Se o nome do cliente não for informado, ele é preenchido com o nome cadastrado; se for informado, deve ser o mesmo.
+// As linhas válidas são gravadas em lotes dentro de uma transação, a menos que dryRun seja verdadeiro.
+func (s *service) ImportDeliveries(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{
+		DryRun:   dryRun,
+		Total:    len(rows),
+		Accepted: []ImportAccepted{},
+		Rejected: []ImportRejected{},
+	}
+
+	// Busca de uma só vez todos os clientes referenciados no arquivo.
+	cpfs := make([]string, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.ParseError == "" && !seen[row.Delivery.ClientCPF] {
+			seen[row.Delivery.ClientCPF] = true
+			cpfs = append(cpfs, row.Delivery.ClientCPF)
+		}
+	}
+	clientNames, err := s.repo.FindClientNamesByCPF(ctx, cpfs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Valida cada linha, separando as aceitas das rejeitadas.
+	var valid []Delivery
+	var validRows []int
+	for _, row := range rows {
+		reason := row.ParseError
+		if reason == "" {
+			reason = checkImportRow(&row.Delivery, clientNames)
+		}
+		if reason != "" {
+			report.Rejected = append(report.Rejected, ImportRejected{Row: row.Row, Reason: reason})
+			continue
+		}
+		valid = append(valid, row.Delivery)
+		validRows = append(validRows, row.Row)
+	}
+
+	// Grava as entregas válidas, a menos que seja apenas uma simulação.
+	if !dryRun {
+		if err := s.repo.CreateDeliveries(ctx, valid, importBatchSize); err != nil {
+			return nil, err
+		}
+	}
+	for i, delivery := range valid {
+		report.Accepted = append(report.Accepted, ImportAccepted{Row: validRows[i], ID: delivery.ID})
+	}
+
+	return report, nil
+}
+
+// GetStatusChangesAfter implementa a lógica para retomar o stream de status a partir do Last-Event-ID.
+// Retorna, em ordem, as mudanças de status gravadas depois de afterID que atendem ao filtro, lendo no máximo
+// streamReplayLimit mudanças; se o limite for atingido, Truncated indica que a busca deve continuar depois de LastID.
+func (s *service) GetStatusChangesAfter(ctx context.Context, afterID uint, filter StreamFilter) (*StatusReplay, error) {
+	statusEvents, err := s.repo.FindStatusChangesAfter(ctx, afterID, filter.ID, streamReplayLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &StatusReplay{Truncated: len(statusEvents) == streamReplayLimit}
+	for _, statusEvent := range statusEvents {
+		if filter.Matches(statusEvent.Change.Delivery) {
+			replay.Events = append(replay.Events, statusEvent)
+		}
+		replay.LastID = statusEvent.ID
+	}
+	return replay, nil
+}
+
+// CountDeliveriesByStatus implementa a lógica para contar as entregas de cada status.
+// Ele delega a operação para o repositório (Repository) e retorna um mapa status -> quantidade.
+func (s *service) CountDeliveriesByStatus(ctx context.Context) (map[string]int64, error) {
+	return s.repo.CountByStatus(ctx)
+}
+
+// checkImportRow valida uma entrega importada e retorna o motivo da rejeição (ou "" se ela for válida).
+func checkImportRow(delivery *Delivery, clientNames map[string]string) string {
+	if err := validateDelivery(delivery); err != nil {
+		return err.Error()
+	}
+
+	name, ok := clientNames[delivery.ClientCPF]
+	if !ok {
+		return "client not found for CPF " + delivery.ClientCPF
+	}
+	if delivery.ClientName == "" {
+		delivery.ClientName = name
+	} else if delivery.ClientName != name {
+		return "client_name does not match the registered client"
+	}
+	return ""
 }
 
 // isValidOrderStatus verifica se o status da entrega é válido.
@@ -114,4 +272,128 @@ func isValidOrderStatus(status string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// GetLateDeliveries implementa a lógica para buscar as entregas atrasadas.
+// São as entregas em andamento cujo prazo já venceu ou vence dentro de within, a partir de agora.
+func (s *service) GetLateDeliveries(ctx context.Context, within time.Duration) ([]Delivery, error) {
+	return s.repo.FindLate(ctx, time.Now().Add(within))
+}
+
+// FlagOverdueDeliveries implementa a lógica para sinalizar as entregas atrasadas.
+// As entregas em andamento com o prazo vencido ganham overdue_at e geram o evento delivery.overdue, uma única vez.
+// Elas são lidas em lotes até que não reste nenhuma. Retorna quantas entregas foram sinalizadas.
+func (s *service) FlagOverdueDeliveries(ctx context.Context) (int, error) {
+	now := time.Now()
+	total := 0
+	for {
+		flagged, err := s.repo.FlagOverdue(ctx, now, maintenanceBatchSize)
+		total += flagged
+		if err != nil || flagged == 0 {
+			return total, err
+		}
+	}
+}
+
+// CancelStalePending implementa a lógica para cancelar as entregas esquecidas como pendentes.
+// São canceladas as entregas criadas há mais de olderThan que continuam pendentes; o motivo é gravado em cancel_reason.
+// Elas são lidas em lotes até que não reste nenhuma. Retorna quantas entregas foram canceladas.
+func (s *service) CancelStalePending(ctx context.Context, olderThan time.Duration, reason string) (int, error) {
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("pending age must be greater than zero")
+	}
+	createdBefore := time.Now().Add(-olderThan)
+	total := 0
+	for {
+		canceled, err := s.repo.CancelStale(ctx, createdBefore, reason, maintenanceBatchSize)
+		total += canceled
+		if err != nil || canceled == 0 {
+			return total, err
+		}
+	}
+}
+
+// RecomputeZones implementa a lógica para localizar de novo as entregas depois que as zonas mudam.
+// As zonas são carregadas uma vez, e as entregas em andamento (Pendente ou Enviado) são percorridas em lotes pelo ID;
+// as que mudaram de zona geram o evento DeliveryZoneChanged. As concluídas e canceladas mantêm a zona que tinham.
+// Retorna quantas entregas mudaram de zona.
+func (s *service) RecomputeZones(ctx context.Context) (int, error) {
+	index, err := s.repo.LoadZoneIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	afterID := uint(0)
+	for {
+		lastID, changed, err := s.repo.RelocateZones(ctx, index, afterID, maintenanceBatchSize)
+		total += changed
+		if err != nil || lastID == 0 {
+			return total, err
+		}
+		afterID = lastID
+	}
+}
+
+// RecomputeWeights implementa a lógica para recalcular os pesos depois que o CUBIC_WEIGHT_DIVISOR muda: todas as
+// entregas são percorridas em lotes pelo ID, e as que mudaram de peso cubado ou tarifado geram o evento DeliveryUpdated.
+// Retorna quantas entregas mudaram de peso.
+func (s *service) RecomputeWeights(ctx context.Context) (int, error) {
+	total := 0
+	afterID := uint(0)
+	for {
+		lastID, changed, err := s.repo.RecomputeWeights(ctx, afterID, maintenanceBatchSize)
+		total += changed
+		if err != nil || lastID == 0 {
+			return total, err
+		}
+		afterID = lastID
+	}
+}
+
+// GetDeliveryHistory implementa a lógica para buscar o histórico de uma entrega: criação, alterações, mudanças de
+// status, atribuições a entregadores e remoção, lidos da outbox. O histórico continua disponível depois da remoção.
+// Retorna ErrDeliveryNotFound se não houver eventos nem a entrega (entregas anteriores à outbox têm histórico vazio).
+func (s *service) GetDeliveryHistory(ctx context.Context, id uint) ([]HistoryEntry, error) {
+	history, err := s.repo.FindHistory(ctx, id)
+	if err != nil || len(history) > 0 {
+		return history, err
+	}
+	if _, err := s.repo.GetDeliveryByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetPackages implementa a lógica para listar os volumes de uma entrega.
+// Ele delega a operação para o repositório.
+func (s *service) GetPackages(ctx context.Context, deliveryID uint) ([]Package, error) {
+	return s.repo.FindPackages(ctx, deliveryID)
+}
+
+// AddPackage implementa a lógica para incluir um volume na entrega, que tem o peso e o valor declarado recalculados.
+// O volume é validado antes de delegar a operação para o repositório; a versão esperada (0 para não verificar)
+// é a da entrega. O ID gravado é preenchido em pkg. Retorna a entrega alterada.
+func (s *service) AddPackage(ctx context.Context, deliveryID uint, pkg *Package, version uint) (*Delivery, error) {
+	if err := validatePackage(pkg); err != nil {
+		return nil, err
+	}
+	pkg.ID = 0
+	return s.repo.SavePackage(ctx, deliveryID, pkg, version)
+}
+
+// UpdatePackage implementa a lógica para alterar um volume da entrega, que tem o peso e o valor declarado recalculados.
+// O volume é validado antes de delegar a operação para o repositório; a versão esperada (0 para não verificar)
+// é a da entrega. Retorna a entrega alterada.
+func (s *service) UpdatePackage(ctx context.Context, deliveryID, packageID uint, pkg *Package, version uint) (*Delivery, error) {
+	if err := validatePackage(pkg); err != nil {
+		return nil, err
+	}
+	pkg.ID = packageID
+	return s.repo.SavePackage(ctx, deliveryID, pkg, version)
+}
+
+// DeletePackage implementa a lógica para remover um volume da entrega, que tem o peso e o valor declarado
+// recalculados. Ele delega a operação para o repositório. Retorna a entrega alterada.
+func (s *service) DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*Delivery, error) {
+	return s.repo.DeletePackage(ctx, deliveryID, packageID, version)
+}
@@ -0,0 +1,143 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Níveis de serviço aceitos em service_level. O nível define, junto com o estado de destino, o prazo da entrega.
+const (
+	ServiceLevelStandard = "standard" // Entrega normal (padrão)
+	ServiceLevelExpress  = "express"  // Entrega expressa, com prazo menor
+)
+
+// Situação do prazo (SLA) da entrega, calculada no momento da resposta (campo sla_status).
+const (
+	SLAOnTime   = "on_time"  // Entregue dentro do prazo, ou ainda em andamento com folga
+	SLAAtRisk   = "at_risk"  // Em andamento e já no último quarto do prazo
+	SLABreached = "breached" // Entregue depois do prazo, ou ainda em andamento com o prazo vencido
+)
+
+// slaTarget é o prazo, em dias corridos, de cada nível de serviço em uma região.
+type slaTarget struct {
+	standard int
+	express  int
+}
+
+// slaRegions são os prazos por região de destino. Estados desconhecidos usam defaultSLATarget.
+var slaRegions = map[string]slaTarget{
+	"SP": {3, 1}, "RJ": {3, 1}, "MG": {3, 1}, "ES": {3, 1}, // Sudeste
+	"PR": {4, 2}, "SC": {4, 2}, "RS": {4, 2}, // Sul
+	"DF": {5, 2}, "GO": {5, 2}, "MT": {5, 2}, "MS": {5, 2}, // Centro-Oeste
+	"BA": {7, 3}, "SE": {7, 3}, "AL": {7, 3}, "PE": {7, 3}, "PB": {7, 3}, "RN": {7, 3}, "CE": {7, 3}, "PI": {7, 3}, "MA": {7, 3}, // Nordeste
+	"AM": {10, 4}, "PA": {10, 4}, "AC": {10, 4}, "RO": {10, 4}, "RR": {10, 4}, "AP": {10, 4}, "TO": {10, 4}, // Norte
+}
+
+// defaultSLATarget é o prazo usado quando o estado de destino não está na tabela (o mesmo da região Norte).
+var defaultSLATarget = slaTarget{10, 4}
+
+// SLATarget retorna o prazo de entrega para o estado de destino (UF) e o nível de serviço.
+func SLATarget(estado, serviceLevel string) time.Duration {
+	target, ok := slaRegions[strings.ToUpper(strings.TrimSpace(estado))]
+	if !ok {
+		target = defaultSLATarget
+	}
+	days := target.standard
+	if serviceLevel == ServiceLevelExpress {
+		days = target.express
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// isValidServiceLevel verifica se o nível de serviço é um dos aceitos.
+func isValidServiceLevel(level string) bool {
+	return level == ServiceLevelStandard || level == ServiceLevelExpress
+}
+
+// SLAStatusAt calcula a situação do prazo da entrega no instante informado.
+// Entregas canceladas ou sem prazo (criadas antes do controle de SLA) não têm situação ("").
+func (d *Delivery) SLAStatusAt(now time.Time) string {
+	if d.SLADueAt == nil || d.OrderStatus == OrderStatusCanceled {
+		return ""
+	}
+	due := *d.SLADueAt
+	if d.DeliveredAt != nil {
+		if d.DeliveredAt.After(due) {
+			return SLABreached
+		}
+		return SLAOnTime
+	}
+	if now.After(due) {
+		return SLABreached
+	}
+	// Em risco quando resta menos de um quarto do prazo total.
+	if !d.CreatedAt.IsZero() && due.Sub(now) < due.Sub(d.CreatedAt)/4 {
+		return SLAAtRisk
+	}
+	return SLAOnTime
+}
+
+// MarshalJSON inclui na resposta o sla_status calculado no momento da serialização, e o actual_weight,
+// em todas as rotas, exportações, eventos e webhooks que devolvem a entrega.
+func (d Delivery) MarshalJSON() ([]byte, error) {
+	type plain Delivery // Mesmo conteúdo, sem o MarshalJSON, para não entrar em recursão
+	d.SLAStatus = d.SLAStatusAt(time.Now())
+	d.ActualWeight = d.Weight
+	return json.Marshal(plain(d))
+}
+
+// start prepara uma entrega nova: horário de criação, nível de serviço padrão, prazo e, se ela já nasce
+// enviada ou entregue, os horários correspondentes. Os valores enviados pelo cliente nesses campos (e no entregador)
+// são ignorados.
+func (d *Delivery) start(now time.Time) {
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	d.ShippedAt, d.DeliveredAt = nil, nil
+	d.OverdueAt, d.CancelReason = nil, ""
+	d.CourierID, d.AssignedAt = nil, nil
+	if d.ServiceLevel == "" {
+		d.ServiceLevel = ServiceLevelStandard
+	}
+	due := now.Add(SLATarget(d.Estado, d.ServiceLevel))
+	d.SLADueAt = &due
+	changes := lifecycleChanges(&Delivery{}, d.OrderStatus, now)
+	if changes["shipped_at"] != nil {
+		d.ShippedAt = &now
+	}
+	if changes["delivered_at"] != nil {
+		d.DeliveredAt = &now
+	}
+}
+
+// lifecycleChanges retorna as colunas de horário alteradas quando a entrega current passa para o status to.
+// Enviado registra o envio; Entregue registra a entrega (e o envio, se ele não foi registrado);
+// voltar para Pendente ou Enviado limpa os horários das etapas seguintes. Cancelado não altera os horários.
+// Sair de Cancelado limpa o motivo do cancelamento.
+func lifecycleChanges(current *Delivery, to string, now time.Time) map[string]interface{} {
+	if current.OrderStatus == to {
+		return nil
+	}
+	changes := map[string]interface{}{}
+	if current.CancelReason != "" {
+		changes["cancel_reason"] = ""
+	}
+	switch to {
+	case OrderStatusPending:
+		changes["shipped_at"] = nil
+		changes["delivered_at"] = nil
+	case OrderStatusShipped:
+		if current.ShippedAt == nil {
+			changes["shipped_at"] = now
+		}
+		if current.DeliveredAt != nil {
+			changes["delivered_at"] = nil
+		}
+	case OrderStatusDelivered:
+		changes["delivered_at"] = now
+		if current.ShippedAt == nil {
+			changes["shipped_at"] = now
+		}
+	}
+	return changes
+}
@@ -0,0 +1,256 @@
+package deliveries
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// streamBufferSize é a quantidade de eventos que podem ficar na fila de cada cliente do stream.
+// Um cliente que não consome os eventos a tempo é desconectado e pode retomar com o Last-Event-ID.
+const streamBufferSize = 64
+
+// streamLookback é por quanto tempo Follow continua relendo a outbox abaixo da última mudança lida. Os IDs da outbox
+// são reservados na inserção, mas as transações são confirmadas em qualquer ordem: uma mudança com ID menor que a
+// última lida pode aparecer depois dela. As mudanças relidas são comparadas pelo ID e só as novas são repassadas.
+const streamLookback = 30 * time.Second
+
+// StatusEvent é uma mudança de status enviada aos clientes do stream.
+// O ID é o ID do evento na outbox, usado como "id" do Server-Sent Event e no Last-Event-ID.
+type StatusEvent struct {
+	ID     uint
+	Change StatusChange
+}
+
+// StatusReplay é o resultado da busca das mudanças de status para retomar o stream (veja GetStatusChangesAfter).
+type StatusReplay struct {
+	Events    []StatusEvent // Mudanças que atendem ao filtro, em ordem
+	LastID    uint          // ID da última mudança lida, atendendo ou não ao filtro (0 se nenhuma foi lida)
+	Truncated bool          // O limite foi atingido: ainda há mudanças depois de LastID
+}
+
+// StreamFilter reúne os filtros aceitos pelo stream de status. Campos vazios são ignorados.
+type StreamFilter struct {
+	ID        uint   `form:"id"`
+	ClientCPF string `form:"client_cpf"`
+	Cidade    string `form:"cidade"`
+}
+
+// Matches indica se a entrega atende ao filtro.
+func (f StreamFilter) Matches(delivery *Delivery) bool {
+	if delivery == nil {
+		return false
+	}
+	if f.ID != 0 && delivery.ID != f.ID {
+		return false
+	}
+	if f.ClientCPF != "" && delivery.ClientCPF != f.ClientCPF {
+		return false
+	}
+	if f.Cidade != "" && !strings.EqualFold(delivery.Cidade, f.Cidade) {
+		return false
+	}
+	return true
+}
+
+// StreamSubscription é a inscrição de um cliente no Broker.
+// O canal Events é fechado quando a inscrição é cancelada ou quando o cliente fica para trás.
+type StreamSubscription struct {
+	Events <-chan StatusEvent
+	events chan StatusEvent
+	filter StreamFilter
+}
+
+// Broker distribui as mudanças de status de entregas para todos os clientes conectados ao stream.
+// As mudanças são lidas da outbox por Follow, depois que a alteração foi gravada.
+type Broker struct {
+	mu            sync.Mutex
+	subscriptions map[*StreamSubscription]struct{}
+	closed        bool
+
+	// Mudanças lidas por Follow nos últimos streamLookback, na ordem em que foram repassadas (veja LateAfter).
+	recent   []recentEvent
+	recentID map[uint]bool
+}
+
+// recentEvent é uma mudança repassada por Follow e o horário em que foi lida.
+type recentEvent struct {
+	StatusEvent
+	at time.Time
+}
+
+// followCheckpoint é a última mudança lida por Follow no início de uma leitura.
+type followCheckpoint struct {
+	at       time.Time
+	lastSeen uint
+}
+
+// NewBroker cria um Broker sem clientes conectados.
+func NewBroker() *Broker {
+	return &Broker{subscriptions: make(map[*StreamSubscription]struct{}), recentID: make(map[uint]bool)}
+}
+
+// Subscribe inscreve um cliente para receber as mudanças de status que atendem ao filtro.
+// Depois de Close, a inscrição já nasce com o canal fechado.
+func (b *Broker) Subscribe(filter StreamFilter) *StreamSubscription {
+	ch := make(chan StatusEvent, streamBufferSize)
+	subscription := &StreamSubscription{Events: ch, events: ch, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return subscription
+	}
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Close encerra todas as inscrições e recusa as novas. É chamado no desligamento do servidor, para que as
+// conexões do stream terminem e não segurem o desligamento até o tempo limite; os clientes reconectam
+// em outra instância com o Last-Event-ID.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscriptions {
+		delete(b.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Unsubscribe cancela a inscrição e fecha o canal do cliente. Pode ser chamado mais de uma vez.
+func (b *Broker) Unsubscribe(subscription *StreamSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscriptions[subscription]; ok {
+		delete(b.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Follow acompanha a outbox até que o contexto seja cancelado: a cada interval, lê as mudanças de status gravadas
+// depois da última lida e as repassa aos clientes. Como as transações podem ser confirmadas fora de ordem, cada
+// leitura começa na última mudança lida há streamLookback, e as mudanças já repassadas são ignoradas.
+// Cada instância da API segue a outbox por conta própria, então os clientes recebem todas as mudanças, qualquer que
+// seja a instância que as gravou. A leitura começa no último evento gravado quando Follow é chamado; as mudanças
+// anteriores são recuperadas pelos clientes com o Last-Event-ID.
+func (b *Broker) Follow(ctx context.Context, repo Repository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastSeen uint
+	var checkpoints []followCheckpoint
+	started := false
+	for {
+		var err error
+		if !started {
+			lastSeen, err = repo.LastOutboxEventID(ctx)
+			started = err == nil
+		} else {
+			now := time.Now()
+			checkpoints = append(checkpoints, followCheckpoint{at: now, lastSeen: lastSeen})
+			for now.Sub(checkpoints[0].at) > streamLookback {
+				checkpoints = checkpoints[1:]
+			}
+			// As mudanças repassadas antes do checkpoint mais antigo têm ID até o dele e não são mais relidas.
+			b.forget(checkpoints[0].at)
+			lastSeen, err = b.catchUp(ctx, repo, checkpoints[0].lastSeen, lastSeen, now)
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "stream: failed to read status changes from the outbox", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUp repassa aos clientes as mudanças de status gravadas depois de from que ainda não foram repassadas e
+// retorna o maior ID lido (lastSeen, se nenhuma mudança nova foi lida).
+func (b *Broker) catchUp(ctx context.Context, repo Repository, from, lastSeen uint, now time.Time) (uint, error) {
+	for {
+		statusEvents, err := repo.FindStatusChangesAfter(ctx, from, 0, streamReplayLimit)
+		if err != nil {
+			return lastSeen, err
+		}
+		for _, statusEvent := range statusEvents {
+			b.deliver(statusEvent, now)
+			lastSeen = max(lastSeen, statusEvent.ID)
+			from = statusEvent.ID
+		}
+		if len(statusEvents) < streamReplayLimit {
+			return lastSeen, nil
+		}
+	}
+}
+
+// deliver repassa uma mudança lida por Follow, se ela ainda não foi repassada, e a guarda em recent.
+func (b *Broker) deliver(statusEvent StatusEvent, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.recentID[statusEvent.ID] {
+		return
+	}
+	b.recentID[statusEvent.ID] = true
+	b.recent = append(b.recent, recentEvent{StatusEvent: statusEvent, at: now})
+	b.broadcast(statusEvent)
+}
+
+// forget descarta as mudanças repassadas antes de before, que não são mais relidas por Follow.
+func (b *Broker) forget(before time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for n < len(b.recent) && b.recent[n].at.Before(before) {
+		delete(b.recentID, b.recent[n].ID)
+		n++
+	}
+	b.recent = b.recent[n:]
+}
+
+// LateAfter retorna as mudanças que atendem ao filtro com ID menor que afterID, mas repassadas depois dela: as
+// confirmadas fora de ordem, que um cliente que parou em afterID pode não ter recebido. Só são consideradas as
+// mudanças repassadas nos últimos streamLookback; se afterID não estiver entre elas, retorna nil.
+func (b *Broker) LateAfter(afterID uint, filter StreamFilter) []StatusEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var late []StatusEvent
+	found := false
+	for _, event := range b.recent {
+		switch {
+		case event.ID == afterID:
+			found = true
+		case found && event.ID < afterID && filter.Matches(event.Change.Delivery):
+			late = append(late, event.StatusEvent)
+		}
+	}
+	return late
+}
+
+// Broadcast envia a mudança de status a todos os clientes cujo filtro ela atende.
+func (b *Broker) Broadcast(statusEvent StatusEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.broadcast(statusEvent)
+}
+
+// broadcast envia a mudança aos clientes; deve ser chamado com b.mu bloqueado.
+func (b *Broker) broadcast(statusEvent StatusEvent) {
+	for subscription := range b.subscriptions {
+		if !subscription.filter.Matches(statusEvent.Change.Delivery) {
+			continue
+		}
+		select {
+		case subscription.events <- statusEvent:
+		default:
+			delete(b.subscriptions, subscription)
+			close(subscription.events)
+		}
+	}
+}
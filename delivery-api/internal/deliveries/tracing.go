@@ -0,0 +1,227 @@
+package deliveries
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{ErrDeliveryNotFound, ErrVersionConflict, ErrProofRequired, ErrPackageNotFound, ErrPackagesLocked,
+	ErrLastPackage, ErrCapacityExceeded}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// Os spans levam apenas IDs e status; CPFs, nomes e endereços não são gravados.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.CreateDelivery", attribute.String("delivery.order_status", delivery.OrderStatus))
+	created, err := s.next.CreateDelivery(ctx, delivery)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("delivery.id", int64(created.ID)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return created, err
+}
+
+func (s *tracedService) GetDeliveries(ctx context.Context, filter Filter) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveries")
+	list, err := s.next.GetDeliveries(ctx, filter)
+	span.SetAttributes(attribute.Int("deliveries.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) ExportDeliveries(ctx context.Context, filter Filter, fn func(*Delivery) error) error {
+	ctx, span := tracing.Start(ctx, "deliveries.ExportDeliveries")
+	err := s.next.ExportDeliveries(ctx, filter, fn)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) GetDeliveryByID(ctx context.Context, id uint) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveryByID", attribute.Int64("delivery.id", int64(id)))
+	delivery, err := s.next.GetDeliveryByID(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return delivery, err
+}
+
+func (s *tracedService) UpdateDelivery(ctx context.Context, id uint, delivery *Delivery) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.UpdateDelivery", attribute.Int64("delivery.id", int64(id)))
+	updated, err := s.next.UpdateDelivery(ctx, id, delivery)
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
+
+func (s *tracedService) DeleteDelivery(ctx context.Context, id uint, version uint) error {
+	ctx, span := tracing.Start(ctx, "deliveries.DeleteDelivery", attribute.Int64("delivery.id", int64(id)))
+	err := s.next.DeleteDelivery(ctx, id, version)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) GetDeliveriesByCPF(ctx context.Context, cpf string) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveriesByCPF")
+	list, err := s.next.GetDeliveriesByCPF(ctx, cpf)
+	span.SetAttributes(attribute.Int("deliveries.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) GetDeliveriesByCity(ctx context.Context, city string) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveriesByCity")
+	list, err := s.next.GetDeliveriesByCity(ctx, city)
+	span.SetAttributes(attribute.Int("deliveries.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) GetDeliveriesByClientName(ctx context.Context, clientName string) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveriesByClientName")
+	list, err := s.next.GetDeliveriesByClientName(ctx, clientName)
+	span.SetAttributes(attribute.Int("deliveries.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error {
+	ctx, span := tracing.Start(ctx, "deliveries.UpdateOrderStatus",
+		attribute.Int64("delivery.id", int64(id)),
+		attribute.String("delivery.order_status", status),
+	)
+	err := s.next.UpdateOrderStatus(ctx, id, status, version)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
+
+func (s *tracedService) ImportDeliveries(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.ImportDeliveries",
+		attribute.Int("import.rows", len(rows)),
+		attribute.Bool("import.dry_run", dryRun),
+	)
+	report, err := s.next.ImportDeliveries(ctx, rows, dryRun)
+	if err == nil {
+		span.SetAttributes(attribute.Int("import.rejected", len(report.Rejected)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return report, err
+}
+
+func (s *tracedService) GetStatusChangesAfter(ctx context.Context, afterID uint, filter StreamFilter) (*StatusReplay, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetStatusChangesAfter", attribute.Int64("stream.after_id", int64(afterID)))
+	replay, err := s.next.GetStatusChangesAfter(ctx, afterID, filter)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("stream.truncated", replay.Truncated))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return replay, err
+}
+
+func (s *tracedService) CountDeliveriesByStatus(ctx context.Context) (map[string]int64, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.CountDeliveriesByStatus")
+	counts, err := s.next.CountDeliveriesByStatus(ctx)
+	tracing.End(span, err, expectedErrors...)
+	return counts, err
+}
+
+func (s *tracedService) ClusterDeliveries(ctx context.Context, filter Filter, precision int) (*ClusterCollection, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.ClusterDeliveries", attribute.Int("deliveries.geohash_precision", precision))
+	collection, err := s.next.ClusterDeliveries(ctx, filter, precision)
+	if collection != nil {
+		span.SetAttributes(
+			attribute.Int("deliveries.count", collection.Total),
+			attribute.Int("deliveries.clusters", len(collection.Features)),
+		)
+	}
+	tracing.End(span, err, expectedErrors...)
+	return collection, err
+}
+
+func (s *tracedService) GetLateDeliveries(ctx context.Context, within time.Duration) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetLateDeliveries", attribute.String("deliveries.within", within.String()))
+	list, err := s.next.GetLateDeliveries(ctx, within)
+	span.SetAttributes(attribute.Int("deliveries.count", len(list)))
+	tracing.End(span, err, expectedErrors...)
+	return list, err
+}
+
+func (s *tracedService) FlagOverdueDeliveries(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.FlagOverdueDeliveries")
+	flagged, err := s.next.FlagOverdueDeliveries(ctx)
+	span.SetAttributes(attribute.Int("deliveries.count", flagged))
+	tracing.End(span, err, expectedErrors...)
+	return flagged, err
+}
+
+func (s *tracedService) CancelStalePending(ctx context.Context, olderThan time.Duration, reason string) (int, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.CancelStalePending", attribute.String("deliveries.older_than", olderThan.String()))
+	canceled, err := s.next.CancelStalePending(ctx, olderThan, reason)
+	span.SetAttributes(attribute.Int("deliveries.count", canceled))
+	tracing.End(span, err, expectedErrors...)
+	return canceled, err
+}
+
+func (s *tracedService) RecomputeZones(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.RecomputeZones")
+	changed, err := s.next.RecomputeZones(ctx)
+	span.SetAttributes(attribute.Int("deliveries.count", changed))
+	tracing.End(span, err, expectedErrors...)
+	return changed, err
+}
+
+func (s *tracedService) RecomputeWeights(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.RecomputeWeights")
+	changed, err := s.next.RecomputeWeights(ctx)
+	span.SetAttributes(attribute.Int("deliveries.count", changed))
+	tracing.End(span, err, expectedErrors...)
+	return changed, err
+}
+
+func (s *tracedService) GetDeliveryHistory(ctx context.Context, id uint) ([]HistoryEntry, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetDeliveryHistory", attribute.Int64("delivery.id", int64(id)))
+	history, err := s.next.GetDeliveryHistory(ctx, id)
+	if err == nil {
+		span.SetAttributes(attribute.Int("history.count", len(history)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return history, err
+}
+
+func (s *tracedService) GetPackages(ctx context.Context, deliveryID uint) ([]Package, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.GetPackages", attribute.Int64("delivery.id", int64(deliveryID)))
+	packages, err := s.next.GetPackages(ctx, deliveryID)
+	span.SetAttributes(attribute.Int("packages.count", len(packages)))
+	tracing.End(span, err, expectedErrors...)
+	return packages, err
+}
+
+func (s *tracedService) AddPackage(ctx context.Context, deliveryID uint, pkg *Package, version uint) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.AddPackage", attribute.Int64("delivery.id", int64(deliveryID)))
+	updated, err := s.next.AddPackage(ctx, deliveryID, pkg, version)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("package.id", int64(pkg.ID)))
+	}
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
+
+func (s *tracedService) UpdatePackage(ctx context.Context, deliveryID, packageID uint, pkg *Package, version uint) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.UpdatePackage",
+		attribute.Int64("delivery.id", int64(deliveryID)), attribute.Int64("package.id", int64(packageID)))
+	updated, err := s.next.UpdatePackage(ctx, deliveryID, packageID, pkg, version)
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
+
+func (s *tracedService) DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "deliveries.DeletePackage",
+		attribute.Int64("delivery.id", int64(deliveryID)), attribute.Int64("package.id", int64(packageID)))
+	updated, err := s.next.DeletePackage(ctx, deliveryID, packageID, version)
+	tracing.End(span, err, expectedErrors...)
+	return updated, err
+}
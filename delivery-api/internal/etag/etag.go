@@ -0,0 +1,80 @@
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrMalformed indica que o cabeçalho If-Match não pôde ser interpretado como uma versão.
+var ErrMalformed = errors.New("malformed If-Match header")
+
+// ErrWeak indica que o cabeçalho If-Match trouxe uma ETag fraca (W/"3"). O If-Match exige a comparação forte
+// (RFC 7232, seção 3.1), em que uma ETag fraca nunca corresponde ao recurso.
+var ErrWeak = errors.New("weak ETags cannot be used in If-Match")
+
+// Format converte a versão de um recurso no valor do cabeçalho ETag (por exemplo, "3").
+func Format(version uint) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10))
+}
+
+// Set escreve o cabeçalho ETag da resposta com base na versão do recurso.
+func Set(c *gin.Context, version uint) {
+	c.Header("ETag", Format(version))
+}
+
+// IfMatch lê o cabeçalho If-Match da requisição e retorna a versão esperada pelo cliente.
+// Retorna 0 quando o cabeçalho está ausente ou é "*", indicando que nenhuma verificação deve ser feita.
+// Aceita apenas ETags fortes ("3"): ETags fracas retornam ErrWeak, e qualquer outro formato, ErrMalformed.
+// As rotas que alteram ou removem um recurso usam Require, que exige o cabeçalho.
+func IfMatch(c *gin.Context) (uint, error) {
+	return Parse(c.GetHeader("If-Match"))
+}
+
+// Require lê o cabeçalho If-Match obrigatório das rotas PUT, PATCH e DELETE e retorna a versão esperada.
+// Se o cabeçalho estiver ausente, responde 428 (Precondition Required); se trouxer uma ETag fraca, 412
+// (Precondition Failed); se estiver mal formatado, 400. Nesses casos retorna false e a requisição não deve seguir.
+// "If-Match: *" continua aceito e indica, explicitamente, que nenhuma verificação de versão deve ser feita.
+func Require(c *gin.Context) (uint, bool) {
+	if strings.TrimSpace(c.GetHeader("If-Match")) == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+	version, err := IfMatch(c)
+	switch {
+	case errors.Is(err, ErrWeak):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return 0, false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+		return 0, false
+	}
+	return version, true
+}
+
+// Parse interpreta o valor de um cabeçalho If-Match.
+// Veja IfMatch para as regras de interpretação.
+func Parse(header string) (uint, error) {
+	value := strings.TrimSpace(header)
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(value, "W/") {
+		return 0, ErrWeak
+	}
+
+	// Remove as aspas obrigatórias.
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, ErrMalformed
+	}
+
+	version, err := strconv.ParseUint(value[1:len(value)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrMalformed
+	}
+	return uint(version), nil
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler recebe um evento de domínio. Um erro faz com que o evento seja entregue de novo mais tarde.
+// Como a entrega é "pelo menos uma vez", o handler deve tolerar eventos repetidos (o UUID identifica o evento).
+type Handler func(ctx context.Context, event *Event) error
+
+// subscriber é um handler registrado no barramento.
+type subscriber struct {
+	name    string
+	types   map[string]bool // Tipos de evento aceitos; vazio aceita todos
+	handler Handler
+}
+
+// Bus é o barramento de eventos em memória: guarda os assinantes e entrega cada evento aos interessados.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []subscriber
+}
+
+// NewBus cria um barramento sem assinantes.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registra um handler para os tipos de evento informados (nenhum tipo = todos os eventos).
+// O nome identifica o assinante nos logs e nas mensagens de erro.
+func (b *Bus) Subscribe(name string, handler Handler, eventTypes ...string) {
+	types := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		types[eventType] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber{name: name, types: types, handler: handler})
+}
+
+// Deliver entrega o evento a todos os assinantes interessados, na ordem em que se registraram.
+// A entrega para no primeiro erro; o evento inteiro é repetido depois, inclusive para os assinantes
+// que já o receberam.
+func (b *Bus) Deliver(ctx context.Context, event *Event) error {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if len(s.types) > 0 && !s.types[event.Type] {
+			continue
+		}
+		if err := s.handler(ctx, event); err != nil {
+			return &DeliveryError{Subscriber: s.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// DeliveryError indica qual assinante falhou ao receber um evento.
+type DeliveryError struct {
+	Subscriber string
+	Err        error
+}
+
+// Error implementa a interface error.
+func (e *DeliveryError) Error() string {
+	return e.Subscriber + ": " + e.Err.Error()
+}
+
+// Unwrap permite usar errors.Is e errors.As com o erro original do assinante.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
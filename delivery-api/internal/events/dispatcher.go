@@ -0,0 +1,152 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// DispatcherConfig reúne os parâmetros de leitura da outbox e de novas tentativas.
+type DispatcherConfig struct {
+	PollInterval time.Duration // Intervalo entre leituras da outbox
+	BatchSize    int           // Quantidade de eventos lidos por vez
+	RetryBase    time.Duration // Espera após a primeira falha; dobra a cada nova falha
+	RetryMax     time.Duration // Espera máxima entre tentativas
+	ClaimTimeout time.Duration // Tempo de reserva de um evento; depois dele, outra instância pode entregá-lo
+	MaxAttempts  int           // Tentativas antes de o evento ser marcado como morto
+}
+
+// DefaultDispatcherConfig retorna a configuração padrão do dispatcher.
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		RetryBase:    time.Second,
+		RetryMax:     5 * time.Minute,
+		ClaimTimeout: 30 * time.Second,
+		MaxAttempts:  20,
+	}
+}
+
+// Dispatcher lê os eventos gravados na outbox e os entrega aos assinantes do barramento.
+//
+// A entrega é "pelo menos uma vez": o evento só é marcado como entregue depois que todos os assinantes
+// o aceitaram, e é repetido (com backoff exponencial) enquanto algum deles falhar.
+// A ordem é garantida por agregado: enquanto um evento de uma entrega (ou cliente) não for entregue,
+// os eventos seguintes do mesmo agregado ficam esperando. Eventos de outros agregados seguem normalmente.
+// Depois de MaxAttempts falhas, o evento é marcado como morto e os seguintes do agregado voltam a ser entregues.
+type Dispatcher struct {
+	repo   Repository
+	bus    *Bus
+	config DispatcherConfig
+}
+
+// NewDispatcher cria um novo Dispatcher.
+func NewDispatcher(repo Repository, bus *Bus, config DispatcherConfig) *Dispatcher {
+	return &Dispatcher{repo: repo, bus: bus, config: config}
+}
+
+// Run entrega os eventos pendentes até que o contexto seja cancelado.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "events: failed to process outbox", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending entrega os eventos pendentes da outbox e retorna quantos foram entregues.
+func (d *Dispatcher) ProcessPending(ctx context.Context) (int, error) {
+	pending, err := d.repo.FindPending(ctx, time.Now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Agregados em que um evento falhou (ou foi reservado por outra instância) nesta leitura;
+	// os eventos seguintes deles esperam a próxima leitura.
+	blocked := make(map[string]bool)
+	dispatched := 0
+	for i := range pending {
+		if ctx.Err() != nil {
+			return dispatched, ctx.Err()
+		}
+		event := &pending[i]
+		key := fmt.Sprintf("%s:%d", event.AggregateType, event.AggregateID)
+		if blocked[key] {
+			continue
+		}
+
+		claimed, err := d.repo.ClaimEvent(ctx, event, time.Now().Add(d.config.ClaimTimeout))
+		if err != nil {
+			return dispatched, err
+		}
+		if !claimed {
+			blocked[key] = true
+			continue
+		}
+
+		if err := d.deliver(ctx, event); err != nil {
+			blocked[key] = true
+			slog.ErrorContext(ctx, "events: failed to deliver event",
+				"event_type", event.Type, "event_id", event.ID, "attempt", event.Attempts+1, "err", err)
+			if err := d.fail(ctx, event, err); err != nil {
+				return dispatched, err
+			}
+			continue
+		}
+
+		if err := d.repo.MarkDispatched(ctx, event, time.Now()); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// fail registra a falha do evento: agenda uma nova tentativa ou, se as tentativas se esgotaram, marca o evento como morto.
+func (d *Dispatcher) fail(ctx context.Context, event *Event, cause error) error {
+	attempts := event.Attempts + 1
+	if attempts >= d.config.MaxAttempts {
+		slog.ErrorContext(ctx, "events: giving up on event", "event_type", event.Type, "event_id", event.ID, "attempts", attempts)
+		return d.repo.MarkDead(ctx, event, time.Now(), cause)
+	}
+	return d.repo.MarkFailed(ctx, event, time.Now().Add(d.backoff(attempts)), cause)
+}
+
+// deliver entrega o evento aos assinantes dentro de um span que continua o trace da requisição que o gerou.
+func (d *Dispatcher) deliver(ctx context.Context, event *Event) error {
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, event.TraceParent), "outbox.Deliver "+event.Type,
+		attribute.Int64("outbox.event_id", int64(event.ID)),
+		attribute.String("outbox.event_uuid", event.UUID),
+		attribute.Int("outbox.attempt", event.Attempts+1),
+	)
+	err := d.bus.Deliver(ctx, event)
+	tracing.End(span, err)
+	return err
+}
+
+// backoff calcula a espera antes da próxima tentativa: RetryBase * 2^(tentativas-1), limitada a RetryMax.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.RetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.RetryMax {
+			return d.config.RetryMax
+		}
+	}
+	return delay
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"delivery-api/internal/tracing"
+)
+
+// Tipos de agregado que geram eventos. Junto com o ID, identificam a sequência em que a ordem é garantida.
+const (
+	AggregateDelivery = "delivery"
+	AggregateClient   = "client"
+)
+
+// Tipos de evento de domínio gravados na outbox.
+const (
+	DeliveryCreated       = "DeliveryCreated"       // Entrega criada (conteúdo: a entrega)
+	DeliveryUpdated       = "DeliveryUpdated"       // Entrega alterada pelo PUT (conteúdo: a entrega)
+	DeliveryStatusChanged = "DeliveryStatusChanged" // Status da entrega alterado (conteúdo: deliveries.StatusChange)
+	DeliveryDeleted       = "DeliveryDeleted"       // Entrega removida (conteúdo: a entrega antes da remoção)
+	DeliveryOverdue       = "DeliveryOverdue"       // Prazo (SLA) da entrega em andamento venceu (conteúdo: a entrega)
+	DeliveryAssigned      = "DeliveryAssigned"      // Entrega atribuída a um entregador (conteúdo: deliveries.Assignment)
+	DeliveryUnassigned    = "DeliveryUnassigned"    // Entrega retirada do entregador (conteúdo: deliveries.Assignment)
+	DeliveryZoneChanged   = "DeliveryZoneChanged"   // Zona da entrega alterada pelo recálculo das zonas (conteúdo: deliveries.ZoneChange)
+	ClientCreated         = "ClientCreated"         // Cliente criado (conteúdo: o cliente)
+	ClientUpdated         = "ClientUpdated"         // Cliente alterado (conteúdo: o cliente)
+	ClientDeleted         = "ClientDeleted"         // Cliente removido (conteúdo: o cliente antes da remoção)
+)
+
+// @description Evento de domínio gravado na outbox
+// @type object
+type Event struct {
+	ID            uint       `json:"id" gorm:"primaryKey"` // Crescente; define a ordem de entrega dos eventos
+	UUID          string     `json:"uuid" gorm:"size:36;not null;uniqueIndex"`
+	AggregateType string     `json:"aggregate_type" gorm:"size:50;not null;index:idx_outbox_aggregate"`
+	AggregateID   uint       `json:"aggregate_id" gorm:"not null;index:idx_outbox_aggregate"`
+	Type          string     `json:"type" gorm:"size:100;not null;index"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"` // Conteúdo do evento em JSON
+	CreatedAt     time.Time  `json:"created_at"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_outbox_pending"`
+	DispatchedAt  *time.Time `json:"dispatched_at" gorm:"index:idx_outbox_pending"`
+	DeadAt        *time.Time `json:"dead_at"` // Preenchido quando o evento esgota as tentativas e sai da fila
+	LastError     string     `json:"last_error" gorm:"type:text"`
+	TraceParent   string     `json:"trace_parent,omitempty" gorm:"size:55"` // Trace da requisição que gerou o evento (W3C traceparent)
+}
+
+// TableName define o nome da tabela da outbox.
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// Decode converte o conteúdo JSON do evento para v.
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
+
+// New monta um evento pronto para ser gravado, com o conteúdo serializado em JSON.
+func New(aggregateType string, aggregateID uint, eventType string, data interface{}) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Event{
+		UUID:          id,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       string(payload),
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Record grava um evento na outbox usando a transação informada.
+// Deve ser chamado dentro da mesma transação que altera o agregado: assim o evento só existe se a alteração
+// for confirmada, e toda alteração confirmada tem o seu evento.
+// O trace do contexto da transação é guardado no evento, para que a entrega aos assinantes continue o mesmo trace.
+func Record(tx *gorm.DB, aggregateType string, aggregateID uint, eventType string, data interface{}) error {
+	event, err := New(aggregateType, aggregateID, eventType, data)
+	if err != nil {
+		return err
+	}
+	if ctx := tx.Statement.Context; ctx != nil {
+		event.TraceParent = tracing.TraceParent(ctx)
+	}
+	return tx.Create(event).Error
+}
+
+// newUUID gera um identificador aleatório no formato de um UUID v4.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
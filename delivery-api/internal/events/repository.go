@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository é uma interface que define os métodos de acesso à outbox usados pelo dispatcher.
+type Repository interface {
+	FindPending(ctx context.Context, now time.Time, limit int) ([]Event, error)         // Lista os eventos prontos para entrega, do mais antigo ao mais novo
+	ClaimEvent(ctx context.Context, event *Event, until time.Time) (bool, error)        // Reserva um evento para entrega
+	MarkDispatched(ctx context.Context, event *Event, at time.Time) error               // Marca um evento como entregue a todos os assinantes
+	MarkFailed(ctx context.Context, event *Event, retryAt time.Time, cause error) error // Registra uma falha e agenda uma nova tentativa
+	MarkDead(ctx context.Context, event *Event, at time.Time, cause error) error        // Registra a última falha e tira o evento da fila
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório da outbox.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindPending retorna, em ordem de criação, os eventos pendentes cuja tentativa já pode ser feita em now.
+// Eventos de um agregado que tem um evento anterior esperando uma nova tentativa (ou reservado por outra instância)
+// ficam de fora, para não serem entregues fora de ordem. Assim, eventos bloqueados não ocupam o lote e não
+// atrasam os dos outros agregados. Eventos mortos não são retornados nem bloqueiam os seguintes.
+func (r *repository) FindPending(ctx context.Context, now time.Time, limit int) ([]Event, error) {
+	var events []Event
+	if err := r.db.WithContext(ctx).
+		Where("dispatched_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox_events earlier
+			WHERE earlier.aggregate_type = outbox_events.aggregate_type AND earlier.aggregate_id = outbox_events.aggregate_id
+			AND earlier.id < outbox_events.id AND earlier.dispatched_at IS NULL AND earlier.dead_at IS NULL
+			AND earlier.next_attempt_at > ?)`, now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClaimEvent reserva o evento para entrega, adiando a próxima tentativa para until.
+// A atualização só acontece se o evento não tiver sido reservado por outra instância nesse meio tempo.
+// Se o processo cair durante a entrega, o evento volta a ficar disponível em until.
+func (r *repository) ClaimEvent(ctx context.Context, event *Event, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Event{}).
+		Where("id = ? AND dispatched_at IS NULL AND dead_at IS NULL AND next_attempt_at = ?", event.ID, event.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	event.NextAttemptAt = until
+	return true, nil
+}
+
+// MarkDispatched grava o horário em que o evento foi entregue a todos os assinantes.
+func (r *repository) MarkDispatched(ctx context.Context, event *Event, at time.Time) error {
+	event.DispatchedAt = &at
+	event.LastError = ""
+	return r.db.WithContext(ctx).Model(event).Updates(map[string]interface{}{
+		"dispatched_at": at,
+		"last_error":    "",
+	}).Error
+}
+
+// MarkFailed incrementa as tentativas do evento, guarda o erro e agenda a próxima tentativa.
+func (r *repository) MarkFailed(ctx context.Context, event *Event, retryAt time.Time, cause error) error {
+	event.Attempts++
+	event.NextAttemptAt = retryAt
+	event.LastError = cause.Error()
+	return r.db.WithContext(ctx).Model(event).Updates(map[string]interface{}{
+		"attempts":        event.Attempts,
+		"next_attempt_at": retryAt,
+		"last_error":      event.LastError,
+	}).Error
+}
+
+// MarkDead incrementa as tentativas do evento, guarda o erro e grava o horário em que ele saiu da fila.
+// O evento continua na outbox (e no histórico), mas não é mais entregue.
+func (r *repository) MarkDead(ctx context.Context, event *Event, at time.Time, cause error) error {
+	event.Attempts++
+	event.DeadAt = &at
+	event.LastError = cause.Error()
+	return r.db.WithContext(ctx).Model(event).Updates(map[string]interface{}{
+		"attempts":   event.Attempts,
+		"dead_at":    at,
+		"last_error": event.LastError,
+	}).Error
+}
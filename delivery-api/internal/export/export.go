@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formatos de exportação suportados.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ChunkSize é a quantidade de linhas enviadas ao cliente a cada flush durante a exportação.
+const ChunkSize = 500
+
+// formulaPrefixes são os caracteres que, no início de uma célula, fazem o Excel e outras planilhas
+// interpretarem o conteúdo como fórmula (CSV injection).
+const formulaPrefixes = "=+-@\t\r"
+
+// utf8BOM é gravado no início dos arquivos CSV para que o Excel reconheça a codificação UTF-8.
+const utf8BOM = "\xef\xbb\xbf"
+
+// ParseFormat valida o formato solicitado na query string. Quando vazio, usa CSV.
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("invalid format %q, must be one of: 'csv', 'ndjson'", format)
+	}
+}
+
+// ContentType retorna o tipo de conteúdo HTTP correspondente ao formato.
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Writer grava linhas de exportação em CSV ou NDJSON.
+// O CSV segue o padrão que o Excel em pt-BR abre diretamente: BOM UTF-8, ";" como separador
+// e vírgula como separador decimal (veja Decimal).
+type Writer struct {
+	format string
+	out    *bufio.Writer
+	csv    *csv.Writer
+	json   *json.Encoder
+}
+
+// NewWriter cria um Writer para o formato informado.
+// Para CSV, o BOM e a linha de cabeçalho com as colunas são gravados imediatamente.
+func NewWriter(w io.Writer, format string, columns []string) (*Writer, error) {
+	out := bufio.NewWriter(w)
+	writer := &Writer{format: format, out: out}
+
+	if format == FormatNDJSON {
+		writer.json = json.NewEncoder(out)
+		return writer, nil
+	}
+
+	if _, err := out.WriteString(utf8BOM); err != nil {
+		return nil, err
+	}
+	writer.csv = csv.NewWriter(out)
+	writer.csv.Comma = ';'
+	writer.csv.UseCRLF = true // Quebra de linha padrão do Windows/Excel
+	if err := writer.csv.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write grava uma linha. Em CSV é usado record; em NDJSON, value é serializado como um objeto JSON por linha.
+// Em CSV, as células que seriam interpretadas como fórmula recebem o prefixo "'" (veja escapeFormula).
+func (w *Writer) Write(record []string, value interface{}) error {
+	if w.json != nil {
+		return w.json.Encode(value)
+	}
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = escapeFormula(cell)
+	}
+	return w.csv.Write(escaped)
+}
+
+// escapeFormula prefixa com "'" as células que começam com =, +, -, @, tab ou CR, para que a planilha as trate
+// como texto e não execute fórmulas vindas dos dados (por exemplo, um nome de cliente "=HYPERLINK(...)").
+// Números negativos, como as latitudes formatadas por Decimal, não são fórmulas e ficam inalterados.
+func escapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(strings.Replace(cell, ",", ".", 1), 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+// Flush envia para o io.Writer de destino tudo que ainda está em memória.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
+
+// Decimal formata um número com vírgula como separador decimal (por exemplo, 10.5 -> "10,5").
+func Decimal(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', -1, 64), ".", ",", 1)
+}
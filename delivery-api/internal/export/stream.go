@@ -0,0 +1,77 @@
+package export
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmitFunc grava uma linha da exportação (veja Writer.Write).
+type EmitFunc func(record []string, value interface{}) error
+
+// Stream escreve a resposta HTTP de uma exportação, enviando as linhas ao cliente em blocos de ChunkSize.
+// produce deve chamar emit para cada linha lida do banco de dados, sem acumular o resultado em memória.
+// A resposta só é iniciada na primeira linha, então um erro antes disso ainda vira um 500 (Internal Server Error).
+// Se o cliente desconectar, emit retorna o erro do contexto e a leitura é interrompida.
+func Stream(c *gin.Context, format, name string, columns []string, produce func(emit EmitFunc) error) {
+	var writer *Writer
+	count := 0
+
+	// start envia os cabeçalhos HTTP e cria o Writer (que grava o cabeçalho do CSV).
+	start := func() error {
+		c.Header("Content-Type", ContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+		c.Status(http.StatusOK)
+
+		var err error
+		writer, err = NewWriter(c.Writer, format, columns)
+		return err
+	}
+
+	err := produce(func(record []string, value interface{}) error {
+		if err := c.Request.Context().Err(); err != nil {
+			return err
+		}
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(record, value); err != nil {
+			return err
+		}
+
+		// A cada bloco completo, envia o que foi gerado até agora.
+		count++
+		if count%ChunkSize == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err != nil {
+		if writer == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export " + name})
+			return
+		}
+		// A resposta já começou: resta registrar o erro e encerrar o arquivo como está.
+		slog.ErrorContext(c.Request.Context(), "export interrupted", "export", name, "rows", count, "err", err)
+	}
+
+	// Nenhuma linha encontrada: ainda assim devolve o arquivo (apenas com o cabeçalho, no caso do CSV).
+	if writer == nil {
+		if err := start(); err != nil {
+			slog.ErrorContext(c.Request.Context(), "export failed", "export", name, "err", err)
+			return
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "export failed to flush", "export", name, "err", err)
+	}
+	c.Writer.Flush()
+}
@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// geohashAlphabet é o alfabeto base 32 do geohash (sem a, i, l e o).
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision é a maior precisão (número de caracteres) aceita em Geohash; com 12, a célula tem poucos centímetros.
+const MaxGeohashPrecision = 12
+
+// Geohash codifica o ponto no geohash com a precisão informada (de 1 a MaxGeohashPrecision caracteres). Pontos
+// próximos compartilham o início do geohash, então a célula de um geohash mais curto contém as dos mais longos.
+func Geohash(lat, lon float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > MaxGeohashPrecision {
+		precision = MaxGeohashPrecision
+	}
+
+	minLat, maxLat, minLon, maxLon := -90.0, 90.0, -180.0, 180.0
+	hash := make([]byte, 0, precision)
+	bits, value := 0, 0
+	even := true // Os bits alternam entre longitude (posições pares) e latitude
+	for len(hash) < precision {
+		if even {
+			middle := (minLon + maxLon) / 2
+			if lon >= middle {
+				value = value<<1 | 1
+				minLon = middle
+			} else {
+				value <<= 1
+				maxLon = middle
+			}
+		} else {
+			middle := (minLat + maxLat) / 2
+			if lat >= middle {
+				value = value<<1 | 1
+				minLat = middle
+			} else {
+				value <<= 1
+				maxLat = middle
+			}
+		}
+		even = !even
+		if bits++; bits == 5 {
+			hash = append(hash, geohashAlphabet[value])
+			bits, value = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// zoomPrecision relaciona o zoom do mapa (tiles web de 256 pixels) à precisão do geohash: a partir de cada zoom da
+// lista, a precisão é a do índice + 1, de modo que a célula tenha de um quarto a um oitavo da largura do tile.
+var zoomPrecision = []int{0, 3, 5, 8, 10, 13, 15, 18}
+
+// MaxZoom é o maior zoom aceito em PrecisionForZoom.
+const MaxZoom = 22
+
+// PrecisionForZoom retorna a precisão do geohash usada para agrupar os pontos no zoom informado (de 0 a MaxZoom).
+func PrecisionForZoom(zoom int) int {
+	precision := 1
+	for i, from := range zoomPrecision {
+		if zoom >= from {
+			precision = i + 1
+		}
+	}
+	return precision
+}
+
+// ParseBBox lê um retângulo no formato do bbox do GeoJSON: "oeste,sul,leste,norte" (longitude e latitude mínimas,
+// depois as máximas). Retângulos que cruzam o antimeridiano não são aceitos.
+func ParseBBox(value string) (Bounds, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return Bounds{}, fmt.Errorf("%w: bbox must be min_lon,min_lat,max_lon,max_lat", ErrInvalidGeometry)
+	}
+	var numbers [4]float64
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Bounds{}, fmt.Errorf("%w: bbox value %q is not a number", ErrInvalidGeometry, part)
+		}
+		numbers[i] = number
+	}
+
+	bounds := Bounds{MinLon: numbers[0], MinLat: numbers[1], MaxLon: numbers[2], MaxLat: numbers[3]}
+	switch {
+	case bounds.MinLon < -180 || bounds.MaxLon > 180 || bounds.MinLat < -90 || bounds.MaxLat > 90:
+		return Bounds{}, fmt.Errorf("%w: bbox is out of range", ErrInvalidGeometry)
+	case bounds.MinLon > bounds.MaxLon || bounds.MinLat > bounds.MaxLat:
+		return Bounds{}, fmt.Errorf("%w: bbox minimums must not exceed the maximums", ErrInvalidGeometry)
+	}
+	return bounds, nil
+}
@@ -0,0 +1,225 @@
+// Package geo implementa a geometria usada nas zonas de entrega, sem depender de extensões GIS do banco:
+// leitura e escrita de polígonos GeoJSON (RFC 7946), teste de ponto no polígono, retângulo envolvente e área.
+// As coordenadas seguem a ordem do GeoJSON, [longitude, latitude], em graus (WGS 84). Os cálculos tratam as
+// coordenadas como um plano, o que é suficiente para áreas do tamanho de uma cidade.
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidGeometry é retornado quando o GeoJSON não descreve um polígono válido.
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+// Position é um ponto na ordem do GeoJSON: [longitude, latitude].
+type Position [2]float64
+
+// Ring é um anel fechado de um polígono: a última posição repete a primeira.
+type Ring []Position
+
+// Polygon é um polígono: o primeiro anel é o contorno externo e os demais são buracos.
+type Polygon []Ring
+
+// MultiPolygon é um conjunto de polígonos. Um Polygon do GeoJSON é lido como um MultiPolygon de um polígono só.
+type MultiPolygon []Polygon
+
+// Bounds é o retângulo envolvente de uma geometria.
+type Bounds struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// Contains indica se o ponto está dentro do retângulo, incluindo as bordas.
+func (b Bounds) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// Point é uma geometria GeoJSON do tipo Point.
+type Point struct {
+	Type        string   `json:"type"`
+	Coordinates Position `json:"coordinates" swaggertype:"array,number"`
+}
+
+// NewPoint cria o Point do GeoJSON para a latitude e a longitude informadas.
+func NewPoint(lat, lon float64) Point {
+	return Point{Type: "Point", Coordinates: Position{lon, lat}}
+}
+
+// geometryObject é o formato de um objeto geometry do GeoJSON.
+type geometryObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// MarshalJSON escreve a geometria como um objeto GeoJSON do tipo MultiPolygon.
+func (m MultiPolygon) MarshalJSON() ([]byte, error) {
+	coordinates := [][][]Position{}
+	for _, polygon := range m {
+		rings := [][]Position{}
+		for _, ring := range polygon {
+			rings = append(rings, []Position(ring))
+		}
+		coordinates = append(coordinates, rings)
+	}
+	return json.Marshal(struct {
+		Type        string         `json:"type"`
+		Coordinates [][][]Position `json:"coordinates"`
+	}{Type: "MultiPolygon", Coordinates: coordinates})
+}
+
+// UnmarshalJSON lê um objeto geometry do GeoJSON do tipo Polygon ou MultiPolygon e valida os anéis (veja Validate).
+func (m *MultiPolygon) UnmarshalJSON(data []byte) error {
+	var object geometryObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+
+	var parsed MultiPolygon
+	switch object.Type {
+	case "Polygon":
+		var polygon Polygon
+		if err := json.Unmarshal(object.Coordinates, &polygon); err != nil {
+			return fmt.Errorf("%w: polygon coordinates: %v", ErrInvalidGeometry, err)
+		}
+		parsed = MultiPolygon{polygon}
+	case "MultiPolygon":
+		var polygons []Polygon // Sem o UnmarshalJSON do MultiPolygon, que espera o objeto geometry
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("%w: multipolygon coordinates: %v", ErrInvalidGeometry, err)
+		}
+		parsed = MultiPolygon(polygons)
+	default:
+		return fmt.Errorf("%w: geometry type must be Polygon or MultiPolygon, got %q", ErrInvalidGeometry, object.Type)
+	}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Validate confere se a geometria tem ao menos um polígono e se cada anel tem ao menos quatro posições, é fechado
+// e tem coordenadas dentro dos limites de latitude e longitude.
+func (m MultiPolygon) Validate() error {
+	if len(m) == 0 {
+		return fmt.Errorf("%w: at least one polygon is required", ErrInvalidGeometry)
+	}
+	for i, polygon := range m {
+		if len(polygon) == 0 {
+			return fmt.Errorf("%w: polygon %d has no rings", ErrInvalidGeometry, i)
+		}
+		for j, ring := range polygon {
+			if len(ring) < 4 {
+				return fmt.Errorf("%w: polygon %d ring %d must have at least 4 positions", ErrInvalidGeometry, i, j)
+			}
+			if ring[0] != ring[len(ring)-1] {
+				return fmt.Errorf("%w: polygon %d ring %d is not closed", ErrInvalidGeometry, i, j)
+			}
+			for _, position := range ring {
+				if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+					return fmt.Errorf("%w: position %v is out of range", ErrInvalidGeometry, position)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Contains indica se o ponto está em algum dos polígonos: dentro do contorno externo e fora dos buracos. Um ponto
+// sobre qualquer borda, inclusive a de um buraco, pertence ao polígono. O teste é o do raio (ray casting), com as
+// bordas verificadas à parte para que um ponto sobre a divisa entre duas zonas pertença às duas.
+func (m MultiPolygon) Contains(lat, lon float64) bool {
+	for _, polygon := range m {
+		if len(polygon) == 0 || !polygon[0].contains(lat, lon, true) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if hole.contains(lat, lon, false) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// contains testa se o ponto está dentro do anel; boundary indica o resultado para um ponto sobre a borda.
+func (r Ring) contains(lat, lon float64, boundary bool) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[j], r[i]
+		if onSegment(a, b, lon, lat) {
+			return boundary
+		}
+		// A aresta cruza a horizontal do ponto? Cada cruzamento à direita do ponto alterna dentro/fora.
+		if (a[1] > lat) != (b[1] > lat) {
+			crossing := a[0] + (lat-a[1])*(b[0]-a[0])/(b[1]-a[1])
+			if lon < crossing {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// onSegment indica se o ponto (x, y) está sobre o segmento ab.
+func onSegment(a, b Position, x, y float64) bool {
+	const tolerance = 1e-12
+	cross := (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+	if math.Abs(cross) > tolerance {
+		return false
+	}
+	return x >= math.Min(a[0], b[0])-tolerance && x <= math.Max(a[0], b[0])+tolerance &&
+		y >= math.Min(a[1], b[1])-tolerance && y <= math.Max(a[1], b[1])+tolerance
+}
+
+// Bounds retorna o retângulo envolvente da geometria (dos contornos externos).
+func (m MultiPolygon) Bounds() Bounds {
+	bounds := Bounds{MinLat: math.Inf(1), MinLon: math.Inf(1), MaxLat: math.Inf(-1), MaxLon: math.Inf(-1)}
+	for _, polygon := range m {
+		if len(polygon) == 0 {
+			continue
+		}
+		for _, position := range polygon[0] {
+			bounds.MinLon = math.Min(bounds.MinLon, position[0])
+			bounds.MaxLon = math.Max(bounds.MaxLon, position[0])
+			bounds.MinLat = math.Min(bounds.MinLat, position[1])
+			bounds.MaxLat = math.Max(bounds.MaxLat, position[1])
+		}
+	}
+	return bounds
+}
+
+// Area retorna a área da geometria no plano das coordenadas, em graus ao quadrado (contornos menos buracos).
+// Serve para comparar geometrias próximas entre si, não como medida de superfície.
+func (m MultiPolygon) Area() float64 {
+	total := 0.0
+	for _, polygon := range m {
+		for i, ring := range polygon {
+			if i == 0 {
+				total += ring.area()
+			} else {
+				total -= ring.area()
+			}
+		}
+	}
+	return total
+}
+
+// area calcula a área do anel pela fórmula do laço (shoelace), sem sinal.
+func (r Ring) area() float64 {
+	sum := 0.0
+	for i := 0; i+1 < len(r); i++ {
+		sum += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	return math.Abs(sum) / 2
+}
@@ -0,0 +1,121 @@
+// Package health implementa os endpoints de liveness (/healthz) e readiness (/readyz) usados pelo orquestrador.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status possíveis de uma verificação e do serviço como um todo.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// defaultCheckTimeout é o tempo máximo de cada verificação de dependência.
+const defaultCheckTimeout = 2 * time.Second
+
+// CheckFunc verifica uma dependência e retorna um erro se ela não estiver pronta.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult é o resultado da verificação de uma dependência.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report é a resposta do /readyz: o status geral e o de cada dependência.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// check é uma dependência registrada no Checker.
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker reúne as verificações de prontidão do serviço.
+// Durante o desligamento (SetDraining), o /readyz passa a responder 503 para que o orquestrador pare de enviar tráfego,
+// enquanto as requisições em andamento terminam.
+type Checker struct {
+	Timeout  time.Duration // Tempo máximo de cada verificação (padrão: 2s)
+	checks   []check
+	draining atomic.Bool
+}
+
+// NewChecker cria um Checker sem verificações.
+func NewChecker() *Checker {
+	return &Checker{Timeout: defaultCheckTimeout}
+}
+
+// Register adiciona uma verificação de dependência, identificada pelo nome no relatório.
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// SetDraining marca o serviço como em desligamento: a partir daí ele deixa de estar pronto.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Check executa todas as verificações em paralelo, cada uma com o seu limite de tempo, e monta o relatório.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			result := CheckResult{Status: StatusOK}
+			if err := chk.fn(checkCtx); err != nil {
+				result.Status = StatusUnavailable
+				result.Error = err.Error()
+			}
+			result.Duration = time.Since(start).String()
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[chk.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(chk)
+	}
+	wg.Wait()
+
+	if c.draining.Load() {
+		report.Status = StatusUnavailable
+		report.Checks["shutdown"] = CheckResult{Status: StatusUnavailable, Error: "server is shutting down", Duration: "0s"}
+	}
+	return report
+}
+
+// Liveness é o handler do /healthz: responde 200 enquanto o processo estiver respondendo requisições.
+// Não verifica dependências, para que uma falha no banco não faça o orquestrador reiniciar todas as instâncias.
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readiness é o handler do /readyz: responde 200 se todas as dependências estiverem prontas e 503 caso contrário,
+// com o status de cada uma.
+func (c *Checker) Readiness(ctx *gin.Context) {
+	report := c.Check(ctx.Request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"delivery-api/internal/users"
+)
+
+// HeaderKey é o cabeçalho em que o cliente envia a chave de idempotência.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed é adicionado às respostas reproduzidas a partir de um registro salvo.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// maxKeyLength é o tamanho máximo aceito para a chave de idempotência.
+const maxKeyLength = 255
+
+// Middleware retorna um middleware do Gin que torna a rota idempotente quando o cabeçalho Idempotency-Key é enviado.
+// A primeira resposta (exceto erros 5xx) é armazenada pelo tempo definido em ttl junto com a impressão digital da requisição.
+// A chave vale por usuário (users.FromContext; deve vir depois de users.Identify): a mesma chave enviada por outro
+// usuário, ou sem token, é uma requisição independente e nunca recebe a resposta guardada.
+// Uma nova tentativa com a mesma chave e o mesmo corpo recebe a resposta original;
+// com um corpo diferente recebe 422 (Unprocessable Entity); enquanto a primeira ainda está em andamento recebe 409 (Conflict).
+func Middleware(store Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			// Sem chave, a requisição segue o fluxo normal.
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		// Lê o corpo para calcular a impressão digital e o devolve para o handler.
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var userID uint
+		if user := users.FromContext(c.Request.Context()); user != nil {
+			userID = user.ID
+		}
+		now := time.Now()
+		record := &Record{
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.FullPath(),
+			UserID:      userID,
+			Fingerprint: fingerprint(c.Request.Method, c.FullPath(), userID, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		existing, err := store.Reserve(c.Request.Context(), record)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "idempotency: failed to reserve key", "err", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to process Idempotency-Key"})
+			return
+		}
+		if existing != nil {
+			replay(c, existing, record.Fingerprint)
+			return
+		}
+
+		// A resposta é gravada (ou a chave liberada) mesmo que a requisição tenha sido cancelada ou estourado o tempo.
+		storeCtx := context.WithoutCancel(c.Request.Context())
+
+		// Captura a resposta produzida pelo handler para salvá-la junto com a chave.
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Se o handler entrar em pânico, a chave é liberada para não ficar presa até o fim do TTL.
+		handled := false
+		defer func() {
+			if !handled {
+				if err := store.Release(storeCtx, record); err != nil {
+					slog.ErrorContext(storeCtx, "idempotency: failed to release key", "err", err)
+				}
+			}
+		}()
+		c.Next()
+		handled = true
+
+		// Erros 5xx não são definitivos: a chave é liberada para que o cliente possa tentar de novo.
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := store.Release(storeCtx, record); err != nil {
+				slog.ErrorContext(storeCtx, "idempotency: failed to release key", "err", err)
+			}
+			return
+		}
+
+		record.StatusCode = recorder.Status()
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+		if err := store.Complete(storeCtx, record); err != nil {
+			slog.ErrorContext(storeCtx, "idempotency: failed to store response", "err", err)
+		}
+	}
+}
+
+// replay responde a uma nova tentativa com base no registro existente para a chave.
+func replay(c *gin.Context, existing *Record, fingerprint string) {
+	// A mesma chave não pode ser reutilizada com um conteúdo diferente.
+	if existing.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request payload"})
+		return
+	}
+
+	// A requisição original ainda está sendo processada.
+	if !existing.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+		return
+	}
+
+	c.Header(HeaderReplayed, "true")
+	c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+	c.Abort()
+}
+
+// fingerprint calcula o SHA-256 do método, da rota, do usuário e do corpo da requisição.
+func fingerprint(method, path string, userID uint, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write([]byte(strconv.FormatUint(uint64(userID), 10)))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder é um gin.ResponseWriter que guarda uma cópia de tudo que é escrito na resposta.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write escreve os dados na resposta e guarda uma cópia.
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString escreve a string na resposta e guarda uma cópia.
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
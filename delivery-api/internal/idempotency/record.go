@@ -0,0 +1,26 @@
+package idempotency
+
+import "time"
+
+// Record guarda a primeira resposta produzida para uma chave de idempotência.
+// A chave é única por método, rota e usuário, então a mesma chave pode ser usada em endpoints diferentes, e um
+// usuário nunca recebe a resposta guardada para a chave de outro. Requisições anônimas ficam com o usuário 0.
+type Record struct {
+	ID          uint   `gorm:"primaryKey"`
+	Key         string `gorm:"column:idempotency_key;size:255;not null;uniqueIndex:idx_idempotency_scope"`
+	Method      string `gorm:"size:10;not null;uniqueIndex:idx_idempotency_scope"`
+	Path        string `gorm:"size:255;not null;uniqueIndex:idx_idempotency_scope"`
+	UserID      uint   `gorm:"not null;default:0;uniqueIndex:idx_idempotency_scope"` // Usuário autenticado (0 se anônimo)
+	Fingerprint string `gorm:"size:64;not null"`                                     // SHA-256 do usuário e do corpo da requisição
+	Completed   bool   `gorm:"not null;default:false"`
+	StatusCode  int    `gorm:"not null;default:0"`
+	ContentType string `gorm:"size:255"`
+	Body        []byte
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+// TableName define o nome da tabela usada para armazenar os registros de idempotência.
+func (Record) TableName() string {
+	return "idempotency_records"
+}
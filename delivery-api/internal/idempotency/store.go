@@ -0,0 +1,112 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store é uma interface que define como os registros de idempotência são persistidos.
+type Store interface {
+	// Reserve tenta reservar a chave do registro informado.
+	// Retorna (nil, nil) quando a reserva foi feita, ou o registro existente quando a chave já está em uso.
+	Reserve(ctx context.Context, record *Record) (*Record, error)
+	Complete(ctx context.Context, record *Record) error             // Salva a resposta produzida para a chave reservada
+	Release(ctx context.Context, record *Record) error              // Libera a chave para que a requisição possa ser refeita
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error) // Remove os registros vencidos
+}
+
+// purgeInterval é o intervalo mínimo entre duas limpezas completas dos registros vencidos, feitas por Reserve.
+const purgeInterval = time.Hour
+
+// store é uma struct que implementa a interface Store usando o GORM.
+type store struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastPurge time.Time // Momento da última limpeza completa feita por Reserve
+}
+
+// NewStore cria uma nova instância do Store baseado no banco de dados.
+func NewStore(db *gorm.DB) Store {
+	return &store{db: db}
+}
+
+// Reserve insere o registro caso a chave ainda não exista para o método, a rota e o usuário.
+// Registros vencidos com a mesma chave são removidos antes da tentativa, liberando a chave para reuso.
+// No máximo uma vez a cada purgeInterval, os registros vencidos de todas as chaves também são removidos,
+// para que a tabela não cresça com chaves que nunca mais são usadas.
+func (s *store) Reserve(ctx context.Context, record *Record) (*Record, error) {
+	s.purgeIfDue(ctx, record.CreatedAt)
+
+	db := s.db.WithContext(ctx)
+	scope := db.Where("idempotency_key = ? AND method = ? AND path = ? AND user_id = ?",
+		record.Key, record.Method, record.Path, record.UserID)
+
+	if err := scope.Session(&gorm.Session{}).Where("expires_at <= ?", record.CreatedAt).Delete(&Record{}).Error; err != nil {
+		return nil, err
+	}
+
+	// ON CONFLICT DO NOTHING: se nenhuma linha foi inserida, outra requisição já reservou a chave.
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var existing Record
+	if err := scope.Session(&gorm.Session{}).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// O registro foi liberado entre o INSERT e o SELECT; tenta reservar novamente.
+			return s.Reserve(ctx, record)
+		}
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// Complete marca o registro como concluído e salva o status, o tipo de conteúdo e o corpo da resposta.
+func (s *store) Complete(ctx context.Context, record *Record) error {
+	record.Completed = true
+	return s.db.WithContext(ctx).Model(&Record{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"completed":    true,
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+	}).Error
+}
+
+// Release remove o registro, permitindo que uma nova requisição com a mesma chave seja processada.
+func (s *store) Release(ctx context.Context, record *Record) error {
+	return s.db.WithContext(ctx).Delete(&Record{}, record.ID).Error
+}
+
+// purgeIfDue chama PurgeExpired se a última limpeza completa foi há mais de purgeInterval.
+// Uma falha apenas é registrada no log: a reserva da chave não depende da limpeza.
+func (s *store) purgeIfDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPurge) < purgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.mu.Unlock()
+
+	if _, err := s.PurgeExpired(ctx, now); err != nil {
+		slog.ErrorContext(ctx, "idempotency: failed to purge expired records", "err", err)
+	}
+}
+
+// PurgeExpired remove todos os registros cujo prazo de validade terminou antes do instante informado.
+// Retorna a quantidade de registros removidos.
+func (s *store) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
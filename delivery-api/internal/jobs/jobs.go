@@ -0,0 +1,93 @@
+// Package jobs define as rotinas em segundo plano da aplicação, executadas pelo scheduler:
+// sinalização das entregas atrasadas, cancelamento das pendentes esquecidas, o resumo diário e os recálculos das zonas
+// e dos pesos.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"delivery-api/internal/analytics"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/scheduler"
+)
+
+// Nomes das rotinas, usados na tabela scheduler_jobs e na rota de execução manual.
+const (
+	FlagOverdue        = "flag-overdue-deliveries"
+	CancelStalePending = "cancel-stale-pending"
+	DailySummary       = "daily-summary"
+	RecomputeZones     = "recompute-delivery-zones"
+	RecomputeWeights   = "recompute-delivery-weights"
+)
+
+// Config reúne as agendas (expressões cron) e os parâmetros das rotinas.
+// Uma agenda vazia deixa a rotina apenas com a execução manual.
+type Config struct {
+	OverdueSchedule string        // Agenda da sinalização das entregas atrasadas
+	StaleSchedule   string        // Agenda do cancelamento das entregas pendentes esquecidas
+	StalePendingAge time.Duration // Tempo como pendente a partir do qual a entrega é cancelada
+	SummarySchedule string        // Agenda do resumo diário (do dia anterior)
+	ZonesSchedule   string        // Agenda do recálculo das zonas das entregas em andamento
+}
+
+// New monta as rotinas da aplicação sobre os serviços de entregas e de indicadores.
+func New(deliveryService deliveries.Service, analyticsService analytics.Service, config Config) []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:        FlagOverdue,
+			Description: "Flags in-progress deliveries past their SLA due date and publishes delivery.overdue",
+			Schedule:    config.OverdueSchedule,
+			Run: func(ctx context.Context) (string, error) {
+				flagged, err := deliveryService.FlagOverdueDeliveries(ctx)
+				return fmt.Sprintf("%d deliveries flagged as overdue", flagged), err
+			},
+		},
+		{
+			Name:        CancelStalePending,
+			Description: fmt.Sprintf("Cancels deliveries still pending %s after creation", config.StalePendingAge),
+			Schedule:    config.StaleSchedule,
+			Run: func(ctx context.Context) (string, error) {
+				reason := staleReason(config.StalePendingAge)
+				canceled, err := deliveryService.CancelStalePending(ctx, config.StalePendingAge, reason)
+				return fmt.Sprintf("%d stale pending deliveries canceled", canceled), err
+			},
+		},
+		{
+			Name:        DailySummary,
+			Description: "Writes the summary of the deliveries created on the previous day",
+			Schedule:    config.SummarySchedule,
+			Run: func(ctx context.Context) (string, error) {
+				summary, err := analyticsService.WriteDailySummary(ctx, time.Now().AddDate(0, 0, -1))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("summary for %s written (%d deliveries)", summary.Day, summary.Report.Deliveries), nil
+			},
+		},
+		{
+			Name:        RecomputeZones,
+			Description: "Locates the zone of in-progress deliveries again after the zones change",
+			Schedule:    config.ZonesSchedule,
+			Run: func(ctx context.Context) (string, error) {
+				changed, err := deliveryService.RecomputeZones(ctx)
+				return fmt.Sprintf("%d deliveries moved to another zone", changed), err
+			},
+		},
+		{
+			// Sem agenda: a rotina é executada manualmente depois de mudar o CUBIC_WEIGHT_DIVISOR.
+			Name:        RecomputeWeights,
+			Description: "Recomputes cubed and chargeable weights with the current CUBIC_WEIGHT_DIVISOR",
+			Run: func(ctx context.Context) (string, error) {
+				changed, err := deliveryService.RecomputeWeights(ctx)
+				return fmt.Sprintf("%d deliveries reweighed", changed), err
+			},
+		},
+	}
+}
+
+// staleReason é o motivo gravado em cancel_reason nas entregas canceladas por CancelStalePending.
+func staleReason(age time.Duration) string {
+	return fmt.Sprintf("automatically canceled: still pending %s after creation", age)
+}
@@ -0,0 +1,119 @@
+// Package logging configura os logs estruturados da API (log/slog): o log de cada requisição com o request ID,
+// o usuário, a rota, a latência e o status, e a máscara de dados pessoais (CPF, CNPJ, e-mail e telefone)
+// aplicada a tudo o que é logado.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formatos de log aceitos em LOG_FORMAT.
+const (
+	FormatJSON = "json" // Uma linha JSON por registro, para agregadores de log
+	FormatText = "text" // Chave=valor, mais fácil de ler no terminal
+)
+
+// Setup cria o logger da aplicação e o torna o padrão, tanto do slog quanto do pacote log.
+// Assim, as mensagens já existentes (log.Printf) e as do GORM também saem no formato escolhido e mascaradas.
+func Setup(w io.Writer, level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(level)}
+	var handler slog.Handler
+	if format == FormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	logger := slog.New(NewHandler(handler))
+	slog.SetDefault(logger)
+	return logger
+}
+
+// parseLevel converte o LOG_LEVEL (debug, info, warn ou error) para o nível do slog.
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Handler envolve outro slog.Handler, mascarando os dados pessoais da mensagem e dos atributos de texto e
+// de erro, e acrescentando o request ID e o trace ID do contexto, quando houver.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler cria um Handler que repassa os registros, já mascarados, para next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implementa slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implementa slog.Handler.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	if id := RequestID(ctx); id != "" {
+		redacted.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		redacted.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implementa slog.Handler, mascarando os atributos fixos do logger.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup implementa slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr mascara o valor do atributo se ele for um texto, um erro, outro valor não numérico ou um grupo.
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, a := range group {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		// Erros e valores estruturados (como uma entrega) viram texto, para que possam ser mascarados.
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, Redact(err.Error()))
+		}
+		return slog.String(attr.Key, Redact(fmt.Sprintf("%+v", value.Any())))
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
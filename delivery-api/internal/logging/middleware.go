@@ -0,0 +1,221 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"delivery-api/internal/users"
+)
+
+// HeaderRequestID é o cabeçalho com o ID da requisição, recebido do cliente (ou do proxy) e devolvido na resposta.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength é o tamanho máximo de um request ID recebido; IDs maiores são substituídos por um novo.
+const maxRequestIDLength = 128
+
+// maxLoggedBody é a quantidade máxima de bytes do corpo guardada no log.
+const maxLoggedBody = 2 << 10
+
+// quietRoutes são as rotas chamadas o tempo todo pelo orquestrador e pelo Prometheus; são logadas em debug.
+var quietRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// contextKey é a chave do request ID no contexto da requisição.
+type contextKey struct{}
+
+// WithRequestID retorna uma cópia do contexto com o request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// RequestID retorna o request ID guardado no contexto, ou "" se não houver.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Middleware registra um log estruturado de cada requisição, com o request ID, o usuário, o modelo da rota,
+// a latência e o status. O caminho, o corpo da resposta de erro e (em debug) o corpo da requisição são
+// mascarados antes de ir para o log.
+//
+// O request ID vem do cabeçalho X-Request-ID, se for válido, ou é gerado; ele é devolvido na resposta,
+// guardado no contexto (os logs feitos com o contexto da requisição o incluem) e gravado no span do trace.
+func Middleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(HeaderRequestID, id)
+		ctx := WithRequestID(c.Request.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", id))
+		c.Request = c.Request.WithContext(ctx)
+
+		route := c.FullPath()
+		level := slog.LevelInfo
+		if quietRoutes[route] {
+			level = slog.LevelDebug
+		}
+		var requestBody string
+		if logger.Enabled(ctx, slog.LevelDebug) && strings.HasPrefix(c.ContentType(), "application/json") {
+			requestBody = peekBody(c.Request)
+		}
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", redactedPath(c.Request.URL)),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if user := users.FromContext(c.Request.Context()); user != nil {
+			attrs = append(attrs, slog.Group("user", slog.Uint64("id", uint64(user.ID)), slog.String("role", user.Role)))
+		}
+		if requestBody != "" {
+			attrs = append(attrs, slog.String("request_body", requestBody))
+		}
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		if status >= http.StatusBadRequest && writer.body.Len() > 0 {
+			attrs = append(attrs, slog.String("response_body", writer.body.String()))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// Recovery responde 500 quando um handler entra em pânico e registra o pânico, com a pilha, no log.
+// Substitui o gin.Recovery, que escreve fora do formato estruturado e sem a máscara de dados pessoais.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler é a forma padrão de abortar a resposta; o servidor HTTP já trata esse caso.
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.String("panic", fmt.Sprint(recovered)),
+				slog.String("stack", string(debug.Stack())),
+			)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}()
+		c.Next()
+	}
+}
+
+// validRequestID indica se o request ID recebido pode ser usado: não vazio, de tamanho limitado e apenas
+// com caracteres visíveis, para que não seja possível injetar quebras de linha nos logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID gera um request ID aleatório de 32 caracteres hexadecimais.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// redactedPath retorna o caminho e a query string decodificados (para que e-mails com %40 também sejam
+// reconhecidos) e com os dados pessoais mascarados.
+func redactedPath(u *url.URL) string {
+	path := u.Path
+	if u.RawQuery != "" {
+		query, err := url.QueryUnescape(u.RawQuery)
+		if err != nil {
+			query = u.RawQuery
+		}
+		path += "?" + query
+	}
+	return Redact(path)
+}
+
+// peekBody lê até maxLoggedBody bytes do corpo da requisição sem consumi-lo para o handler.
+func peekBody(req *http.Request) string {
+	if req.Body == nil {
+		return ""
+	}
+	buf := make([]byte, maxLoggedBody)
+	n, _ := io.ReadFull(req.Body, buf)
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf[:n]), req.Body), Closer: req.Body}
+	return string(buf[:n])
+}
+
+// readCloser junta o corpo já lido com o restante, mantendo o Close do corpo original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter guarda os primeiros bytes da resposta, para logar a mensagem das respostas de erro.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implementa io.Writer, guardando até maxLoggedBody bytes.
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString implementa io.StringWriter, guardando até maxLoggedBody bytes.
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture guarda o trecho escrito enquanto o limite não for atingido.
+func (w *bodyWriter) capture(b []byte) {
+	if rest := maxLoggedBody - w.body.Len(); rest > 0 {
+		if len(b) > rest {
+			b = b[:rest]
+		}
+		w.body.Write(b)
+	}
+}
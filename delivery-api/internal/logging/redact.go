@@ -0,0 +1,28 @@
+package logging
+
+import "regexp"
+
+// redaction é um padrão de dado pessoal e o texto que o substitui nos logs.
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// redactions são os dados pessoais mascarados nos logs, na ordem em que são aplicados.
+// O CNPJ vem antes do CPF, e ambos antes do telefone, para que os dígitos de um documento
+// não sejam confundidos com um número de telefone.
+var redactions = []redaction{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`), "[CNPJ]"},
+	{regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`), "[CPF]"},
+	{regexp.MustCompile(`(?:\+55\s?)?(?:\(\d{2}\)\s?|\b\d{2}\s)?\b9?\d{4}-\d{4}\b`), "[PHONE]"},
+}
+
+// Redact mascara CPFs, CNPJs, e-mails e telefones no texto, com ou sem pontuação.
+// É aplicado a todas as mensagens e atributos de log: caminhos, corpos e mensagens de erro.
+func Redact(text string) string {
+	for _, r := range redactions {
+		text = r.pattern.ReplaceAllString(text, r.replacement)
+	}
+	return text
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+)
+
+// BusinessEventTypes são os eventos de domínio usados pelos contadores de negócio.
+func BusinessEventTypes() []string {
+	return []string{events.DeliveryCreated, events.DeliveryStatusChanged}
+}
+
+// HandleEvent é o handler do barramento de eventos que atualiza os contadores de negócio:
+// entregas criadas por estado e mudanças de status.
+// Os eventos chegam pela outbox depois que a alteração foi gravada, então transações desfeitas não são contadas.
+// A entrega é "pelo menos uma vez": um evento repetido após uma falha de outro assinante pode ser contado de novo.
+func (m *Metrics) HandleEvent(ctx context.Context, event *events.Event) error {
+	switch event.Type {
+	case events.DeliveryCreated:
+		var delivery deliveries.Delivery
+		if err := event.Decode(&delivery); err != nil {
+			return err
+		}
+		m.createdByEstado.WithLabelValues(delivery.Estado).Inc()
+	case events.DeliveryStatusChanged:
+		var change deliveries.StatusChange
+		if err := event.Decode(&change); err != nil {
+			return err
+		}
+		if change.Delivery != nil {
+			m.transitions.WithLabelValues(change.PreviousStatus, change.Delivery.OrderStatus).Inc()
+		}
+	}
+	return nil
+}
+
+// countTimeout é o tempo máximo da consulta feita a cada coleta do Prometheus.
+const countTimeout = 5 * time.Second
+
+// RegisterDeliveryStatusGauge publica a quantidade atual de entregas em cada status.
+// O valor é lido do banco a cada coleta do Prometheus, então reflete todas as instâncias da API.
+func (m *Metrics) RegisterDeliveryStatusGauge(count func(ctx context.Context) (map[string]int64, error)) error {
+	return m.Registry.Register(&statusCollector{count: count, desc: prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "deliveries"),
+		"Entregas cadastradas, por status do pedido.",
+		[]string{"order_status"}, nil,
+	)})
+}
+
+// statusCollector é o coletor que consulta a quantidade de entregas por status a cada coleta.
+type statusCollector struct {
+	count func(ctx context.Context) (map[string]int64, error)
+	desc  *prometheus.Desc
+}
+
+// Describe envia a descrição da métrica.
+func (c *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect consulta as quantidades e envia uma série por status.
+// Os status conhecidos sem entregas aparecem com zero, para que os painéis não fiquem sem dados.
+// Se a consulta falhar, a métrica é omitida nessa coleta.
+func (c *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+	counts, err := c.count(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "metrics: failed to count deliveries by status", "err", err)
+		return
+	}
+	for _, status := range []string{
+		deliveries.OrderStatusPending, deliveries.OrderStatusShipped,
+		deliveries.OrderStatusDelivered, deliveries.OrderStatusCanceled,
+	} {
+		if _, ok := counts[status]; !ok {
+			counts[status] = 0
+		}
+	}
+	for status, total := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(total), status)
+	}
+}
@@ -0,0 +1,103 @@
+// Package metrics expõe as métricas da API no formato do Prometheus (/metrics):
+// tráfego HTTP, consultas e pool de conexões do banco de dados e indicadores de negócio das entregas.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace é o prefixo de todas as métricas da aplicação.
+const namespace = "delivery_api"
+
+// unmatchedRoute é o rótulo de rota das requisições que não correspondem a nenhuma rota (404),
+// para que caminhos arbitrários não criem uma série nova cada um.
+const unmatchedRoute = "unmatched"
+
+// Metrics reúne os coletores da aplicação e o registro em que eles são publicados.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests    *prometheus.HistogramVec
+	httpInFlight    prometheus.Gauge
+	dbQueries       *prometheus.HistogramVec
+	createdByEstado *prometheus.CounterVec
+	transitions     *prometheus.CounterVec
+}
+
+// New cria as métricas da aplicação em um registro próprio, junto com as métricas do runtime do Go e do processo.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duração das requisições HTTP, por método, rota e status da resposta.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Requisições HTTP em andamento.",
+		}),
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duração das consultas ao banco de dados, por operação, tabela e resultado.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table", "result"}),
+		createdByEstado: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deliveries_created_total",
+			Help:      "Entregas criadas, por estado (UF) de destino.",
+		}, []string{"estado"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "delivery_status_transitions_total",
+			Help:      "Mudanças de status das entregas, pelo status anterior e pelo novo.",
+		}, []string{"from", "to"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpInFlight,
+		m.dbQueries,
+		m.createdByEstado,
+		m.transitions,
+	)
+	return m
+}
+
+// Handler é o handler HTTP do /metrics, no formato de exposição do Prometheus.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	handler := promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+	return gin.WrapH(handler)
+}
+
+// Middleware mede a duração de cada requisição HTTP.
+// A rota é o modelo registrado no Gin (por exemplo, "/api/v1/deliveries/:id"), e não o caminho da requisição,
+// para que cada ID não vire uma série diferente.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.httpInFlight.Inc()
+		defer m.httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.httpRequests.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
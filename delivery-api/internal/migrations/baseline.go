@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"delivery-api/internal/analytics"
+	"delivery-api/internal/clients"
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/idempotency"
+	"delivery-api/internal/planning"
+	"delivery-api/internal/proofs"
+	"delivery-api/internal/scheduler"
+	"delivery-api/internal/users"
+	"delivery-api/internal/webhooks"
+	"delivery-api/internal/zones"
+)
+
+// Models retorna todos os models persistidos pela aplicação, na ordem em que as tabelas devem ser criadas.
+// É a lista usada para gerar a migração de base e para conferir se as migrações acompanham os models.
+func Models() []interface{} {
+	return []interface{}{
+		&clients.Client{},
+		&deliveries.Delivery{},
+		&idempotency.Record{},
+		&webhooks.Subscription{},
+		&webhooks.Message{},
+		&events.Event{},
+		&users.User{},
+		&scheduler.JobState{},
+		&analytics.DailySummary{},
+		&proofs.Proof{},
+		&couriers.Courier{},
+		&planning.Plan{},
+		&zones.Zone{},
+		&deliveries.Package{},
+	}
+}
+
+// GenerateBaseline gera os scripts de criação (up) e remoção (down) das tabelas dos models no dialeto informado,
+// sem se conectar a nenhum banco: o GORM roda em modo DryRun e os comandos são capturados pelo logger.
+// Serve para criar a primeira migração e como ponto de partida para revisar novas migrações.
+func GenerateBaseline(dialect string, models ...interface{}) (up string, down string, err error) {
+	dialector, err := dryRunDialector(dialect)
+	if err != nil {
+		return "", "", err
+	}
+
+	capture := &captureLogger{}
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: capture})
+	if err != nil {
+		return "", "", err
+	}
+
+	var drops []string
+	for _, model := range models {
+		if err := db.Migrator().CreateTable(model); err != nil {
+			return "", "", err
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return "", "", err
+		}
+		drops = append([]string{fmt.Sprintf("DROP TABLE IF EXISTS %s;", quote(dialect, stmt.Schema.Table))}, drops...)
+	}
+
+	return strings.Join(capture.statements, ";\n\n") + ";\n", strings.Join(drops, "\n") + "\n", nil
+}
+
+// dryRunDialector retorna um dialector que não abre conexão com o banco de dados.
+func dryRunDialector(dialect string) (gorm.Dialector, error) {
+	switch dialect {
+	case DialectMySQL:
+		return mysql.New(mysql.Config{DSN: "baseline@tcp(localhost:3306)/baseline", SkipInitializeWithVersion: true}), nil
+	case DialectPostgres:
+		return postgres.New(postgres.Config{DSN: "host=localhost dbname=baseline"}), nil
+	case DialectSQLite:
+		return sqlite.Open(":memory:"), nil
+	default:
+		return nil, fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+// quote coloca o nome da tabela entre as aspas usadas pelo dialeto.
+func quote(dialect, name string) string {
+	if dialect == DialectMySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// captureLogger é um logger do GORM que guarda os comandos SQL gerados em vez de registrá-los.
+type captureLogger struct {
+	statements []string
+}
+
+// LogMode implementa logger.Interface.
+func (l *captureLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+// Info implementa logger.Interface.
+func (l *captureLogger) Info(context.Context, string, ...interface{}) {}
+
+// Warn implementa logger.Interface.
+func (l *captureLogger) Warn(context.Context, string, ...interface{}) {}
+
+// Error implementa logger.Interface.
+func (l *captureLogger) Error(context.Context, string, ...interface{}) {}
+
+// Trace guarda o comando SQL executado.
+func (l *captureLogger) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	l.statements = append(l.statements, sql)
+}
@@ -0,0 +1,330 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dialetos com migrações próprias. Correspondem ao nome do dialector do GORM (db.Dialector.Name()).
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialects lista os dialetos suportados. Toda migração deve existir para todos eles.
+var Dialects = []string{DialectMySQL, DialectPostgres, DialectSQLite}
+
+// files contém os scripts de migração, organizados em sql/<dialeto>/<versão>_<nome>.<up|down>.sql.
+//
+//go:embed sql
+var files embed.FS
+
+// ErrSchemaOutdated é retornado por Check quando há migrações ainda não aplicadas no banco de dados.
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+// ErrUnknownMigration é retornado por Check quando o banco tem migrações que esta versão da aplicação não conhece,
+// ou seja, o schema é mais novo que o código.
+var ErrUnknownMigration = errors.New("database schema has migrations unknown to this build")
+
+// Migration é uma migração versionada, com os scripts de aplicação (Up) e de reversão (Down).
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status indica se uma migração já foi aplicada no banco de dados.
+type Status struct {
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"` // nil se a migração ainda não foi aplicada
+}
+
+// appliedMigration é o registro de uma migração aplicada, na tabela schema_migrations.
+type appliedMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName define o nome da tabela de controle das migrações.
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Load lê as migrações embutidas do dialeto informado, ordenadas pela versão.
+func Load(dialect string) ([]Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", dialect, err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		version, migrationName, direction, err := parseFileName(name)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(files, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: migrationName}
+			byVersion[version] = migration
+		} else if migration.Name != migrationName {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, migration.Name, migrationName)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s (%s) must have both up and down files", migration.Version, migration.Name, dialect)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseFileName extrai a versão, o nome e a direção de um arquivo no formato 0001_nome.up.sql.
+func parseFileName(name string) (uint, string, string, error) {
+	base, ok := strings.CutSuffix(name, ".sql")
+	if !ok {
+		return 0, "", "", fmt.Errorf("invalid migration file %q: expected .sql extension", name)
+	}
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("invalid migration file %q: expected .up.sql or .down.sql", name)
+	}
+	base = strings.TrimSuffix(base, direction)
+
+	versionPart, migrationName, ok := strings.Cut(base, "_")
+	version, err := strconv.ParseUint(versionPart, 10, 32)
+	if !ok || err != nil || version == 0 || migrationName == "" {
+		return 0, "", "", fmt.Errorf("invalid migration file %q: expected <version>_<name>", name)
+	}
+	return uint(version), migrationName, strings.TrimPrefix(direction, "."), nil
+}
+
+// Migrator aplica e reverte as migrações de um banco de dados, registrando-as na tabela schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New cria um Migrator com as migrações do dialeto do banco informado.
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrations retorna as migrações conhecidas, ordenadas pela versão.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Status retorna todas as migrações conhecidas indicando quais já foram aplicadas.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Check verifica se o banco está exatamente na versão das migrações conhecidas.
+// Retorna ErrSchemaOutdated se houver migrações pendentes e ErrUnknownMigration se o banco tiver migrações
+// que esta versão da aplicação não conhece.
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[uint]bool, len(m.migrations))
+	var pending []string
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	for version, record := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: %04d_%s", ErrUnknownMigration, version, record.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up aplica, em ordem, todas as migrações pendentes e retorna as que foram aplicadas.
+// Cada migração roda em uma transação junto com o seu registro em schema_migrations.
+// No MySQL, comandos DDL confirmam a transação implicitamente; se uma migração falhar no meio,
+// o banco pode ficar parcialmente migrado e precisar de correção manual.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverte as últimas migrações aplicadas (no máximo steps), da mais nova para a mais antiga,
+// e retorna as que foram revertidas.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback of migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Mark registra como aplicadas, sem executá-las, as migrações até a versão informada.
+// Serve para adotar as migrações em um banco que já foi criado por outro meio (por exemplo, pelo AutoMigrate).
+func (m *Migrator) Mark(version uint) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		record := &appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		if err := m.db.Create(record).Error; err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// applied cria a tabela schema_migrations, se necessário, e retorna as migrações já aplicadas.
+func (m *Migrator) applied() (map[uint]appliedMigration, error) {
+	if !m.db.Migrator().HasTable(&appliedMigration{}) {
+		if err := m.db.Migrator().CreateTable(&appliedMigration{}); err != nil {
+			return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+	}
+
+	var records []appliedMigration
+	if err := m.db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// execScript executa os comandos de um script de migração, um por vez.
+// Os comandos são separados por ";" no fim da linha; linhas iniciadas por "--" são comentários.
+func execScript(tx *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements divide um script em comandos separados por ";" no fim da linha.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,12 @@
+-- Gerado por "delivery-api migrate baseline" a partir dos models (mysql).
+CREATE TABLE `clients` (`id` bigint unsigned AUTO_INCREMENT,`name` longtext,`cpf` varchar(191) NOT NULL,`cnpj` varchar(191) NOT NULL,`birth_date` longtext,`email` longtext,`phone` longtext,`version` bigint unsigned NOT NULL DEFAULT 1,PRIMARY KEY (`id`),CONSTRAINT `uni_clients_cpf` UNIQUE (`cpf`),CONSTRAINT `uni_clients_cnpj` UNIQUE (`cnpj`));
+
+CREATE TABLE `deliveries` (`id` bigint unsigned AUTO_INCREMENT,`client_cpf` varchar(191) NOT NULL,`client_name` longtext NOT NULL,`test_name` longtext NOT NULL,`weight` double NOT NULL,`logradouro` longtext NOT NULL,`numero` longtext NOT NULL,`bairro` longtext NOT NULL,`complemento` longtext NOT NULL,`cidade` longtext NOT NULL,`estado` longtext NOT NULL,`pais` longtext NOT NULL,`latitude` double NOT NULL,`longitude` double NOT NULL,`order_status` longtext NOT NULL,`version` bigint unsigned NOT NULL DEFAULT 1,PRIMARY KEY (`id`),INDEX `idx_deliveries_client_cpf` (`client_cpf`),CONSTRAINT `fk_clients_deliveries` FOREIGN KEY (`client_cpf`) REFERENCES `clients`(`cpf`));
+
+CREATE TABLE `idempotency_records` (`id` bigint unsigned AUTO_INCREMENT,`idempotency_key` varchar(255) NOT NULL,`method` varchar(10) NOT NULL,`path` varchar(255) NOT NULL,`fingerprint` varchar(64) NOT NULL,`completed` boolean NOT NULL DEFAULT false,`status_code` bigint NOT NULL DEFAULT 0,`content_type` varchar(255),`body` longblob,`created_at` datetime(3) NOT NULL,`expires_at` datetime(3) NOT NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_idempotency_scope` (`idempotency_key`,`method`,`path`),INDEX `idx_idempotency_records_expires_at` (`expires_at`));
+
+CREATE TABLE `webhook_subscriptions` (`id` bigint unsigned AUTO_INCREMENT,`url` varchar(2048) NOT NULL,`secret` varchar(255) NOT NULL,`events` text NOT NULL,`description` varchar(255),`active` boolean NOT NULL DEFAULT true,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`));
+
+CREATE TABLE `webhook_messages` (`id` bigint unsigned AUTO_INCREMENT,`subscription_id` bigint unsigned NOT NULL,`event_id` varchar(36) NOT NULL,`event` varchar(100) NOT NULL,`payload` text NOT NULL,`status` varchar(20) NOT NULL,`attempts` bigint NOT NULL DEFAULT 0,`next_attempt_at` datetime(3) NOT NULL,`last_status_code` bigint,`last_error` text,`created_at` datetime(3) NULL,`delivered_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_webhook_messages_subscription_id` (`subscription_id`),UNIQUE INDEX `idx_webhook_messages_event` (`subscription_id`,`event_id`),INDEX `idx_webhook_messages_due` (`status`,`next_attempt_at`));
+
+CREATE TABLE `outbox_events` (`id` bigint unsigned AUTO_INCREMENT,`uuid` varchar(36) NOT NULL,`aggregate_type` varchar(50) NOT NULL,`aggregate_id` bigint unsigned NOT NULL,`type` varchar(100) NOT NULL,`payload` text NOT NULL,`created_at` datetime(3) NULL,`attempts` bigint NOT NULL DEFAULT 0,`next_attempt_at` datetime(3) NOT NULL,`dispatched_at` datetime(3) NULL,`last_error` text,PRIMARY KEY (`id`),INDEX `idx_outbox_events_type` (`type`),INDEX `idx_outbox_pending` (`next_attempt_at`,`dispatched_at`),UNIQUE INDEX `idx_outbox_events_uuid` (`uuid`),INDEX `idx_outbox_aggregate` (`aggregate_type`,`aggregate_id`));
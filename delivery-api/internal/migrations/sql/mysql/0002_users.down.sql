@@ -0,0 +1,11 @@
+-- Volta as chaves de idempotência ao escopo sem o usuário e remove a tabela de usuários da API.
+-- Os registros de idempotência são descartados, já que a mesma chave pode ter sido usada por usuários diferentes.
+DELETE FROM `idempotency_records`;
+
+ALTER TABLE `idempotency_records` DROP INDEX `idx_idempotency_scope`;
+
+ALTER TABLE `idempotency_records` DROP COLUMN `user_id`;
+
+CREATE UNIQUE INDEX `idx_idempotency_scope` ON `idempotency_records`(`idempotency_key`,`method`,`path`);
+
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,9 @@
+-- Cria a tabela de usuários da API. O token fica guardado apenas como hash SHA-256.
+CREATE TABLE `users` (`id` bigint unsigned AUTO_INCREMENT,`name` varchar(255) NOT NULL,`email` varchar(255) NOT NULL,`role` varchar(20) NOT NULL,`token_hash` varchar(64) NOT NULL,`token_prefix` varchar(12) NOT NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_users_email` (`email`),UNIQUE INDEX `idx_users_token_hash` (`token_hash`));
+
+-- Inclui o usuário autenticado no escopo das chaves de idempotência (0 nas requisições anônimas).
+ALTER TABLE `idempotency_records` ADD `user_id` bigint unsigned NOT NULL DEFAULT 0;
+
+ALTER TABLE `idempotency_records` DROP INDEX `idx_idempotency_scope`;
+
+CREATE UNIQUE INDEX `idx_idempotency_scope` ON `idempotency_records`(`idempotency_key`,`method`,`path`,`user_id`);
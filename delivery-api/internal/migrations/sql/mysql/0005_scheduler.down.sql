@@ -0,0 +1,8 @@
+-- Remove as tabelas das rotinas em segundo plano e as colunas preenchidas por elas nas entregas.
+DROP TABLE IF EXISTS `daily_summaries`;
+
+DROP TABLE IF EXISTS `scheduler_jobs`;
+
+ALTER TABLE `deliveries` DROP COLUMN `cancel_reason`;
+
+ALTER TABLE `deliveries` DROP COLUMN `overdue_at`;
@@ -0,0 +1,9 @@
+-- Rotinas em segundo plano: sinalização de atraso e motivo do cancelamento automático nas entregas,
+-- reserva (lease) das rotinas entre as instâncias e resumos diários.
+ALTER TABLE `deliveries` ADD `overdue_at` datetime(3);
+
+ALTER TABLE `deliveries` ADD `cancel_reason` varchar(255);
+
+CREATE TABLE `scheduler_jobs` (`name` varchar(100),`locked_by` varchar(100),`locked_until` datetime(3) NULL,`last_scheduled_at` datetime(3) NULL,`last_started_at` datetime(3) NULL,`last_finished_at` datetime(3) NULL,`last_status` varchar(20),`last_result` text,`last_error` text,PRIMARY KEY (`name`));
+
+CREATE TABLE `daily_summaries` (`day` varchar(10),`report` text NOT NULL,`generated_at` datetime(3) NOT NULL,PRIMARY KEY (`day`));
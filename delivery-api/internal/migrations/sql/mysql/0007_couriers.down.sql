@@ -0,0 +1,8 @@
+-- Remove a atribuição das entregas e a tabela dos entregadores.
+DROP INDEX `idx_deliveries_courier_id` ON `deliveries`;
+
+ALTER TABLE `deliveries` DROP COLUMN `assigned_at`;
+
+ALTER TABLE `deliveries` DROP COLUMN `courier_id`;
+
+DROP TABLE IF EXISTS `couriers`;
@@ -0,0 +1,8 @@
+-- Entregadores (tipo de veículo, capacidade de carga e cidade de origem) e a atribuição das entregas a eles.
+CREATE TABLE `couriers` (`id` bigint unsigned AUTO_INCREMENT,`name` varchar(255) NOT NULL,`phone` varchar(30),`vehicle_type` varchar(20) NOT NULL,`capacity` double NOT NULL,`home_city` varchar(255) NOT NULL,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_couriers_home_city` (`home_city`));
+
+ALTER TABLE `deliveries` ADD `courier_id` bigint unsigned;
+
+ALTER TABLE `deliveries` ADD `assigned_at` datetime(3);
+
+CREATE INDEX `idx_deliveries_courier_id` ON `deliveries`(`courier_id`);
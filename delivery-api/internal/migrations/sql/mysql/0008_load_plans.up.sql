@@ -0,0 +1,3 @@
+-- Planos de carga: as entregas de um filtro distribuídas entre os veículos, com as sobras, gravados para revisão
+-- e, depois, aplicados como atribuições aos entregadores.
+CREATE TABLE `load_plans` (`id` bigint unsigned AUTO_INCREMENT,`status` varchar(20) NOT NULL,`filter` text NOT NULL,`vehicles` text NOT NULL,`unassigned` text NOT NULL,`total_deliveries` bigint,`total_weight` double,`planned_weight` double,`created_by` varchar(255),`created_at` datetime(3) NULL,`applied_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_load_plans_status` (`status`));
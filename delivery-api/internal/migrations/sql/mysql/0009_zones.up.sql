@@ -0,0 +1,6 @@
+-- Zonas operacionais (polígonos em GeoJSON com o retângulo envolvente) e a zona de cada entrega.
+CREATE TABLE `zones` (`id` bigint unsigned AUTO_INCREMENT,`name` varchar(100) NOT NULL,`cidade` varchar(255) NOT NULL DEFAULT '',`geometry` text NOT NULL,`min_lat` double NOT NULL,`min_lon` double NOT NULL,`max_lat` double NOT NULL,`max_lon` double NOT NULL,`area` double NOT NULL,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_zones_cidade` (`cidade`),UNIQUE INDEX `idx_zones_name` (`name`));
+
+ALTER TABLE `deliveries` ADD `zone_id` bigint unsigned;
+
+CREATE INDEX `idx_deliveries_zone_id` ON `deliveries`(`zone_id`);
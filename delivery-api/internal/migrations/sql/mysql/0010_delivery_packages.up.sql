@@ -0,0 +1,9 @@
+-- Volumes das entregas (descrição, quantidade, peso, dimensões e valor declarado) e o valor declarado total da entrega.
+CREATE TABLE `delivery_packages` (`id` bigint unsigned AUTO_INCREMENT,`delivery_id` bigint unsigned NOT NULL,`description` varchar(255) NOT NULL,`quantity` bigint NOT NULL,`weight` double NOT NULL,`length` double NOT NULL DEFAULT 0,`width` double NOT NULL DEFAULT 0,`height` double NOT NULL DEFAULT 0,`declared_value` double NOT NULL DEFAULT 0,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_delivery_packages_delivery_id` (`delivery_id`));
+
+ALTER TABLE `deliveries` ADD `declared_value` double NOT NULL DEFAULT 0;
+
+-- Cada entrega existente passa a ter um volume só, com o test_name e o peso dela.
+INSERT INTO `delivery_packages` (`delivery_id`, `description`, `quantity`, `weight`, `created_at`, `updated_at`)
+  SELECT `id`, LEFT(`test_name`, 255), 1, `weight`, COALESCE(`created_at`, CURRENT_TIMESTAMP(3)), COALESCE(`updated_at`, CURRENT_TIMESTAMP(3))
+  FROM `deliveries`;
@@ -0,0 +1,16 @@
+-- Unidade das dimensões e peso cubado dos volumes, e os pesos cubado e tarifado das entregas.
+ALTER TABLE `delivery_packages` ADD `dimension_unit` varchar(2) NOT NULL DEFAULT 'cm';
+
+ALTER TABLE `delivery_packages` ADD `cubed_weight` double NOT NULL DEFAULT 0;
+
+ALTER TABLE `deliveries` ADD `cubed_weight` double NOT NULL DEFAULT 0;
+
+ALTER TABLE `deliveries` ADD `chargeable_weight` double NOT NULL DEFAULT 0;
+
+-- Os volumes existentes estão em centímetros; o peso cubado usa o divisor padrão (6000), e não o CUBIC_WEIGHT_DIVISOR.
+-- Com outro divisor, recalcule os pesos depois da migração com "delivery-api recompute weights".
+UPDATE `delivery_packages` SET `cubed_weight` = ROUND(`length` * `width` * `height` / 6000, 3);
+
+UPDATE `deliveries` SET
+  `cubed_weight` = COALESCE((SELECT SUM(p.`quantity` * p.`cubed_weight`) FROM `delivery_packages` p WHERE p.`delivery_id` = `deliveries`.`id`), 0),
+  `chargeable_weight` = COALESCE((SELECT SUM(p.`quantity` * GREATEST(p.`weight`, p.`cubed_weight`)) FROM `delivery_packages` p WHERE p.`delivery_id` = `deliveries`.`id`), `weight`);
@@ -0,0 +1,2 @@
+-- Remove a marcação dos eventos mortos da outbox.
+ALTER TABLE `outbox_events` DROP COLUMN `dead_at`;
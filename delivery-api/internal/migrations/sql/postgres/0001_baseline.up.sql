@@ -0,0 +1,32 @@
+-- Gerado por "delivery-api migrate baseline" a partir dos models (postgres).
+CREATE TABLE "clients" ("id" bigserial,"name" text,"cpf" text NOT NULL,"cnpj" text NOT NULL,"birth_date" text,"email" text,"phone" text,"version" bigint NOT NULL DEFAULT 1,PRIMARY KEY ("id"),CONSTRAINT "uni_clients_cpf" UNIQUE ("cpf"),CONSTRAINT "uni_clients_cnpj" UNIQUE ("cnpj"));
+
+CREATE TABLE "deliveries" ("id" bigserial,"client_cpf" text NOT NULL,"client_name" text NOT NULL,"test_name" text NOT NULL,"weight" decimal NOT NULL,"logradouro" text NOT NULL,"numero" text NOT NULL,"bairro" text NOT NULL,"complemento" text NOT NULL,"cidade" text NOT NULL,"estado" text NOT NULL,"pais" text NOT NULL,"latitude" decimal NOT NULL,"longitude" decimal NOT NULL,"order_status" text NOT NULL,"version" bigint NOT NULL DEFAULT 1,PRIMARY KEY ("id"),CONSTRAINT "fk_clients_deliveries" FOREIGN KEY ("client_cpf") REFERENCES "clients"("cpf"));
+
+CREATE INDEX IF NOT EXISTS "idx_deliveries_client_cpf" ON "deliveries" ("client_cpf");
+
+CREATE TABLE "idempotency_records" ("id" bigserial,"idempotency_key" varchar(255) NOT NULL,"method" varchar(10) NOT NULL,"path" varchar(255) NOT NULL,"fingerprint" varchar(64) NOT NULL,"completed" boolean NOT NULL DEFAULT false,"status_code" bigint NOT NULL DEFAULT 0,"content_type" varchar(255),"body" bytea,"created_at" timestamptz NOT NULL,"expires_at" timestamptz NOT NULL,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_idempotency_records_expires_at" ON "idempotency_records" ("expires_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_scope" ON "idempotency_records" ("idempotency_key","method","path");
+
+CREATE TABLE "webhook_subscriptions" ("id" bigserial,"url" varchar(2048) NOT NULL,"secret" varchar(255) NOT NULL,"events" text NOT NULL,"description" varchar(255),"active" boolean NOT NULL DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE TABLE "webhook_messages" ("id" bigserial,"subscription_id" bigint NOT NULL,"event_id" varchar(36) NOT NULL,"event" varchar(100) NOT NULL,"payload" text NOT NULL,"status" varchar(20) NOT NULL,"attempts" bigint NOT NULL DEFAULT 0,"next_attempt_at" timestamptz NOT NULL,"last_status_code" bigint,"last_error" text,"created_at" timestamptz,"delivered_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_webhook_messages_event" ON "webhook_messages" ("subscription_id","event_id");
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_messages_subscription_id" ON "webhook_messages" ("subscription_id");
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_messages_due" ON "webhook_messages" ("status","next_attempt_at");
+
+CREATE TABLE "outbox_events" ("id" bigserial,"uuid" varchar(36) NOT NULL,"aggregate_type" varchar(50) NOT NULL,"aggregate_id" bigint NOT NULL,"type" varchar(100) NOT NULL,"payload" text NOT NULL,"created_at" timestamptz,"attempts" bigint NOT NULL DEFAULT 0,"next_attempt_at" timestamptz NOT NULL,"dispatched_at" timestamptz,"last_error" text,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_outbox_events_uuid" ON "outbox_events" ("uuid");
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_pending" ON "outbox_events" ("next_attempt_at","dispatched_at");
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_type" ON "outbox_events" ("type");
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_aggregate" ON "outbox_events" ("aggregate_type","aggregate_id");
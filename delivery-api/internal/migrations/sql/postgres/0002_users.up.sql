@@ -0,0 +1,13 @@
+-- Cria a tabela de usuários da API. O token fica guardado apenas como hash SHA-256.
+CREATE TABLE "users" ("id" bigserial,"name" varchar(255) NOT NULL,"email" varchar(255) NOT NULL,"role" varchar(20) NOT NULL,"token_hash" varchar(64) NOT NULL,"token_prefix" varchar(12) NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_token_hash" ON "users" ("token_hash");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+-- Inclui o usuário autenticado no escopo das chaves de idempotência (0 nas requisições anônimas).
+ALTER TABLE "idempotency_records" ADD "user_id" bigint NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS "idx_idempotency_scope";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_scope" ON "idempotency_records" ("idempotency_key","method","path","user_id");
@@ -0,0 +1,4 @@
+-- Remove o trace guardado nos eventos da outbox e nas mensagens de webhook.
+ALTER TABLE "outbox_events" DROP COLUMN "trace_parent";
+
+ALTER TABLE "webhook_messages" DROP COLUMN "trace_parent";
@@ -0,0 +1,4 @@
+-- Guarda o trace (W3C traceparent) da requisição que gerou cada evento da outbox e cada mensagem de webhook.
+ALTER TABLE "outbox_events" ADD "trace_parent" varchar(55);
+
+ALTER TABLE "webhook_messages" ADD "trace_parent" varchar(55);
@@ -0,0 +1,14 @@
+-- Remove os horários do ciclo de vida e o prazo (SLA) das entregas.
+DROP INDEX IF EXISTS "idx_deliveries_sla_due_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "sla_due_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "delivered_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "shipped_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "updated_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "created_at";
+
+ALTER TABLE "deliveries" DROP COLUMN "service_level";
@@ -0,0 +1,42 @@
+-- Horários do ciclo de vida e prazo (SLA) das entregas.
+ALTER TABLE "deliveries" ADD "service_level" varchar(20) NOT NULL DEFAULT 'standard';
+
+ALTER TABLE "deliveries" ADD "created_at" timestamptz;
+
+ALTER TABLE "deliveries" ADD "updated_at" timestamptz;
+
+ALTER TABLE "deliveries" ADD "shipped_at" timestamptz;
+
+ALTER TABLE "deliveries" ADD "delivered_at" timestamptz;
+
+ALTER TABLE "deliveries" ADD "sla_due_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_deliveries_sla_due_at" ON "deliveries" ("sla_due_at");
+
+-- Preenche os horários das entregas existentes a partir dos eventos gravados na outbox.
+-- Entregas sem o evento de criação recebem o horário da migração.
+UPDATE "deliveries" SET "created_at" = COALESCE((SELECT MIN(e."created_at") FROM "outbox_events" e
+  WHERE e."aggregate_type" = 'delivery' AND e."aggregate_id" = "deliveries"."id" AND e."type" = 'DeliveryCreated'), CURRENT_TIMESTAMP);
+
+UPDATE "deliveries" SET "updated_at" = COALESCE((SELECT MAX(e."created_at") FROM "outbox_events" e
+  WHERE e."aggregate_type" = 'delivery' AND e."aggregate_id" = "deliveries"."id"), "created_at");
+
+UPDATE "deliveries" SET "shipped_at" = (SELECT MIN(e."created_at") FROM "outbox_events" e
+  WHERE e."aggregate_type" = 'delivery' AND e."aggregate_id" = "deliveries"."id" AND e."type" = 'DeliveryStatusChanged'
+  AND e."payload" LIKE '%"order_status":"Enviado"%')
+  WHERE "order_status" IN ('Enviado', 'Entregue');
+
+UPDATE "deliveries" SET "delivered_at" = (SELECT MAX(e."created_at") FROM "outbox_events" e
+  WHERE e."aggregate_type" = 'delivery' AND e."aggregate_id" = "deliveries"."id" AND e."type" = 'DeliveryStatusChanged'
+  AND e."payload" LIKE '%"order_status":"Entregue"%')
+  WHERE "order_status" = 'Entregue';
+
+UPDATE "deliveries" SET "shipped_at" = "delivered_at" WHERE "shipped_at" IS NULL AND "delivered_at" IS NOT NULL;
+
+-- Prazo do nível standard (o único existente até aqui), pela região do estado de destino.
+UPDATE "deliveries" SET "sla_due_at" = "created_at" + (CASE
+  WHEN "estado" IN ('SP', 'RJ', 'MG', 'ES') THEN 3
+  WHEN "estado" IN ('PR', 'SC', 'RS') THEN 4
+  WHEN "estado" IN ('DF', 'GO', 'MT', 'MS') THEN 5
+  WHEN "estado" IN ('BA', 'SE', 'AL', 'PE', 'PB', 'RN', 'CE', 'PI', 'MA') THEN 7
+  ELSE 10 END) * INTERVAL '1 day';
@@ -0,0 +1,2 @@
+-- Remove a tabela dos comprovantes de entrega (os arquivos no armazenamento não são removidos).
+DROP TABLE IF EXISTS "delivery_proofs";
@@ -0,0 +1,5 @@
+-- Comprovantes de entrega: quem recebeu, as chaves da assinatura e da foto no armazenamento de arquivos,
+-- a posição do entregador e o horário da entrega. Cada entrega tem no máximo um comprovante.
+CREATE TABLE "delivery_proofs" ("id" bigserial,"delivery_id" bigint NOT NULL,"recipient_name" varchar(255) NOT NULL,"recipient_document" varchar(30) NOT NULL,"signature_key" varchar(255) NOT NULL,"signature_type" varchar(50) NOT NULL,"signature_sha256" varchar(64) NOT NULL,"photo_key" varchar(255),"photo_type" varchar(50),"photo_sha256" varchar(64),"latitude" decimal,"longitude" decimal,"delivered_at" timestamptz NOT NULL,"recorded_by" varchar(255),"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_delivery_proofs_delivery_id" ON "delivery_proofs" ("delivery_id");
@@ -0,0 +1,10 @@
+-- Entregadores (tipo de veículo, capacidade de carga e cidade de origem) e a atribuição das entregas a eles.
+CREATE TABLE "couriers" ("id" bigserial,"name" varchar(255) NOT NULL,"phone" varchar(30),"vehicle_type" varchar(20) NOT NULL,"capacity" decimal NOT NULL,"home_city" varchar(255) NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_couriers_home_city" ON "couriers" ("home_city");
+
+ALTER TABLE "deliveries" ADD "courier_id" bigint;
+
+ALTER TABLE "deliveries" ADD "assigned_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_deliveries_courier_id" ON "deliveries" ("courier_id");
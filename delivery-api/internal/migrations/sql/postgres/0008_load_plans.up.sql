@@ -0,0 +1,5 @@
+-- Planos de carga: as entregas de um filtro distribuídas entre os veículos, com as sobras, gravados para revisão
+-- e, depois, aplicados como atribuições aos entregadores.
+CREATE TABLE "load_plans" ("id" bigserial,"status" varchar(20) NOT NULL,"filter" text NOT NULL,"vehicles" text NOT NULL,"unassigned" text NOT NULL,"total_deliveries" bigint,"total_weight" decimal,"planned_weight" decimal,"created_by" varchar(255),"created_at" timestamptz,"applied_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_load_plans_status" ON "load_plans" ("status");
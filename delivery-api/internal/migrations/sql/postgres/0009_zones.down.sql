@@ -0,0 +1,6 @@
+-- Remove a zona das entregas e a tabela das zonas.
+DROP INDEX IF EXISTS "idx_deliveries_zone_id";
+
+ALTER TABLE "deliveries" DROP COLUMN "zone_id";
+
+DROP TABLE IF EXISTS "zones";
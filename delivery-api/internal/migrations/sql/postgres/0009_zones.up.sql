@@ -0,0 +1,10 @@
+-- Zonas operacionais (polígonos em GeoJSON com o retângulo envolvente) e a zona de cada entrega.
+CREATE TABLE "zones" ("id" bigserial,"name" varchar(100) NOT NULL,"cidade" varchar(255) NOT NULL DEFAULT '',"geometry" text NOT NULL,"min_lat" decimal NOT NULL,"min_lon" decimal NOT NULL,"max_lat" decimal NOT NULL,"max_lon" decimal NOT NULL,"area" decimal NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_zones_cidade" ON "zones" ("cidade");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_zones_name" ON "zones" ("name");
+
+ALTER TABLE "deliveries" ADD "zone_id" bigint;
+
+CREATE INDEX IF NOT EXISTS "idx_deliveries_zone_id" ON "deliveries" ("zone_id");
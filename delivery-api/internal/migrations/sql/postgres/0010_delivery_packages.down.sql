@@ -0,0 +1,4 @@
+-- Remove os volumes das entregas; o peso total continua gravado na entrega.
+ALTER TABLE "deliveries" DROP COLUMN "declared_value";
+
+DROP TABLE IF EXISTS "delivery_packages";
@@ -0,0 +1,11 @@
+-- Volumes das entregas (descrição, quantidade, peso, dimensões e valor declarado) e o valor declarado total da entrega.
+CREATE TABLE "delivery_packages" ("id" bigserial,"delivery_id" bigint NOT NULL,"description" varchar(255) NOT NULL,"quantity" bigint NOT NULL,"weight" decimal NOT NULL,"length" decimal NOT NULL DEFAULT 0,"width" decimal NOT NULL DEFAULT 0,"height" decimal NOT NULL DEFAULT 0,"declared_value" decimal NOT NULL DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_delivery_packages_delivery_id" ON "delivery_packages" ("delivery_id");
+
+ALTER TABLE "deliveries" ADD "declared_value" decimal NOT NULL DEFAULT 0;
+
+-- Cada entrega existente passa a ter um volume só, com o test_name e o peso dela.
+INSERT INTO "delivery_packages" ("delivery_id", "description", "quantity", "weight", "created_at", "updated_at")
+  SELECT "id", LEFT("test_name", 255), 1, "weight", COALESCE("created_at", CURRENT_TIMESTAMP), COALESCE("updated_at", CURRENT_TIMESTAMP)
+  FROM "deliveries";
@@ -0,0 +1,2 @@
+-- Marca os eventos da outbox que esgotaram as tentativas de entrega, para que saiam da fila.
+ALTER TABLE "outbox_events" ADD "dead_at" timestamptz;
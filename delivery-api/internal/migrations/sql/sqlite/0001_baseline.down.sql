@@ -0,0 +1,7 @@
+-- Gerado por "delivery-api migrate baseline" a partir dos models (sqlite).
+DROP TABLE IF EXISTS "outbox_events";
+DROP TABLE IF EXISTS "webhook_messages";
+DROP TABLE IF EXISTS "webhook_subscriptions";
+DROP TABLE IF EXISTS "idempotency_records";
+DROP TABLE IF EXISTS "deliveries";
+DROP TABLE IF EXISTS "clients";
@@ -0,0 +1,32 @@
+-- Gerado por "delivery-api migrate baseline" a partir dos models (sqlite).
+CREATE TABLE `clients` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`cpf` text NOT NULL,`cnpj` text NOT NULL,`birth_date` text,`email` text,`phone` text,`version` integer NOT NULL DEFAULT 1,CONSTRAINT `uni_clients_cpf` UNIQUE (`cpf`),CONSTRAINT `uni_clients_cnpj` UNIQUE (`cnpj`));
+
+CREATE TABLE `deliveries` (`id` integer PRIMARY KEY AUTOINCREMENT,`client_cpf` text NOT NULL,`client_name` text NOT NULL,`test_name` text NOT NULL,`weight` real NOT NULL,`logradouro` text NOT NULL,`numero` text NOT NULL,`bairro` text NOT NULL,`complemento` text NOT NULL,`cidade` text NOT NULL,`estado` text NOT NULL,`pais` text NOT NULL,`latitude` real NOT NULL,`longitude` real NOT NULL,`order_status` text NOT NULL,`version` integer NOT NULL DEFAULT 1,CONSTRAINT `fk_clients_deliveries` FOREIGN KEY (`client_cpf`) REFERENCES `clients`(`cpf`));
+
+CREATE INDEX `idx_deliveries_client_cpf` ON `deliveries`(`client_cpf`);
+
+CREATE TABLE `idempotency_records` (`id` integer PRIMARY KEY AUTOINCREMENT,`idempotency_key` text NOT NULL,`method` text NOT NULL,`path` text NOT NULL,`fingerprint` text NOT NULL,`completed` numeric NOT NULL DEFAULT false,`status_code` integer NOT NULL DEFAULT 0,`content_type` text,`body` blob,`created_at` datetime NOT NULL,`expires_at` datetime NOT NULL);
+
+CREATE INDEX `idx_idempotency_records_expires_at` ON `idempotency_records`(`expires_at`);
+
+CREATE UNIQUE INDEX `idx_idempotency_scope` ON `idempotency_records`(`idempotency_key`,`method`,`path`);
+
+CREATE TABLE `webhook_subscriptions` (`id` integer PRIMARY KEY AUTOINCREMENT,`url` text NOT NULL,`secret` text NOT NULL,`events` text NOT NULL,`description` text,`active` numeric NOT NULL DEFAULT true,`created_at` datetime,`updated_at` datetime);
+
+CREATE TABLE `webhook_messages` (`id` integer PRIMARY KEY AUTOINCREMENT,`subscription_id` integer NOT NULL,`event_id` text NOT NULL,`event` text NOT NULL,`payload` text NOT NULL,`status` text NOT NULL,`attempts` integer NOT NULL DEFAULT 0,`next_attempt_at` datetime NOT NULL,`last_status_code` integer,`last_error` text,`created_at` datetime,`delivered_at` datetime);
+
+CREATE INDEX `idx_webhook_messages_due` ON `webhook_messages`(`status`,`next_attempt_at`);
+
+CREATE UNIQUE INDEX `idx_webhook_messages_event` ON `webhook_messages`(`subscription_id`,`event_id`);
+
+CREATE INDEX `idx_webhook_messages_subscription_id` ON `webhook_messages`(`subscription_id`);
+
+CREATE TABLE `outbox_events` (`id` integer PRIMARY KEY AUTOINCREMENT,`uuid` text NOT NULL,`aggregate_type` text NOT NULL,`aggregate_id` integer NOT NULL,`type` text NOT NULL,`payload` text NOT NULL,`created_at` datetime,`attempts` integer NOT NULL DEFAULT 0,`next_attempt_at` datetime NOT NULL,`dispatched_at` datetime,`last_error` text);
+
+CREATE INDEX `idx_outbox_pending` ON `outbox_events`(`next_attempt_at`,`dispatched_at`);
+
+CREATE INDEX `idx_outbox_events_type` ON `outbox_events`(`type`);
+
+CREATE INDEX `idx_outbox_aggregate` ON `outbox_events`(`aggregate_type`,`aggregate_id`);
+
+CREATE UNIQUE INDEX `idx_outbox_events_uuid` ON `outbox_events`(`uuid`);
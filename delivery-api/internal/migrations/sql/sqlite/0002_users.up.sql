@@ -0,0 +1,13 @@
+-- Cria a tabela de usuários da API. O token fica guardado apenas como hash SHA-256.
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`email` text NOT NULL,`role` text NOT NULL,`token_hash` text NOT NULL,`token_prefix` text NOT NULL,`created_at` datetime);
+
+CREATE UNIQUE INDEX `idx_users_token_hash` ON `users`(`token_hash`);
+
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+
+-- Inclui o usuário autenticado no escopo das chaves de idempotência (0 nas requisições anônimas).
+ALTER TABLE `idempotency_records` ADD `user_id` integer NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS `idx_idempotency_scope`;
+
+CREATE UNIQUE INDEX `idx_idempotency_scope` ON `idempotency_records`(`idempotency_key`,`method`,`path`,`user_id`);
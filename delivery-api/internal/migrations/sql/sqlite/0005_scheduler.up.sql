@@ -0,0 +1,9 @@
+-- Rotinas em segundo plano: sinalização de atraso e motivo do cancelamento automático nas entregas,
+-- reserva (lease) das rotinas entre as instâncias e resumos diários.
+ALTER TABLE `deliveries` ADD `overdue_at` datetime;
+
+ALTER TABLE `deliveries` ADD `cancel_reason` text;
+
+CREATE TABLE `scheduler_jobs` (`name` text,`locked_by` text,`locked_until` datetime,`last_scheduled_at` datetime,`last_started_at` datetime,`last_finished_at` datetime,`last_status` text,`last_result` text,`last_error` text,PRIMARY KEY (`name`));
+
+CREATE TABLE `daily_summaries` (`day` text,`report` text NOT NULL,`generated_at` datetime NOT NULL,PRIMARY KEY (`day`));
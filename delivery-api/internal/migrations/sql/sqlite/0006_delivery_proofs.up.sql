@@ -0,0 +1,5 @@
+-- Comprovantes de entrega: quem recebeu, as chaves da assinatura e da foto no armazenamento de arquivos,
+-- a posição do entregador e o horário da entrega. Cada entrega tem no máximo um comprovante.
+CREATE TABLE `delivery_proofs` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_id` integer NOT NULL,`recipient_name` text NOT NULL,`recipient_document` text NOT NULL,`signature_key` text NOT NULL,`signature_type` text NOT NULL,`signature_sha256` text NOT NULL,`photo_key` text,`photo_type` text,`photo_sha256` text,`latitude` real,`longitude` real,`delivered_at` datetime NOT NULL,`recorded_by` text,`created_at` datetime);
+
+CREATE UNIQUE INDEX `idx_delivery_proofs_delivery_id` ON `delivery_proofs`(`delivery_id`);
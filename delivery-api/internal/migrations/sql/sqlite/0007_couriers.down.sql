@@ -0,0 +1,8 @@
+-- Remove a atribuição das entregas e a tabela dos entregadores.
+DROP INDEX IF EXISTS `idx_deliveries_courier_id`;
+
+ALTER TABLE `deliveries` DROP COLUMN `assigned_at`;
+
+ALTER TABLE `deliveries` DROP COLUMN `courier_id`;
+
+DROP TABLE IF EXISTS "couriers";
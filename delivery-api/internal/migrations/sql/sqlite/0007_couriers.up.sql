@@ -0,0 +1,10 @@
+-- Entregadores (tipo de veículo, capacidade de carga e cidade de origem) e a atribuição das entregas a eles.
+CREATE TABLE `couriers` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`phone` text,`vehicle_type` text NOT NULL,`capacity` real NOT NULL,`home_city` text NOT NULL,`created_at` datetime,`updated_at` datetime);
+
+CREATE INDEX `idx_couriers_home_city` ON `couriers`(`home_city`);
+
+ALTER TABLE `deliveries` ADD `courier_id` integer;
+
+ALTER TABLE `deliveries` ADD `assigned_at` datetime;
+
+CREATE INDEX `idx_deliveries_courier_id` ON `deliveries`(`courier_id`);
@@ -0,0 +1,2 @@
+-- Remove a tabela dos planos de carga (as atribuições feitas pelos planos aplicados são mantidas).
+DROP TABLE IF EXISTS "load_plans";
@@ -0,0 +1,5 @@
+-- Planos de carga: as entregas de um filtro distribuídas entre os veículos, com as sobras, gravados para revisão
+-- e, depois, aplicados como atribuições aos entregadores.
+CREATE TABLE `load_plans` (`id` integer PRIMARY KEY AUTOINCREMENT,`status` text NOT NULL,`filter` text NOT NULL,`vehicles` text NOT NULL,`unassigned` text NOT NULL,`total_deliveries` integer,`total_weight` real,`planned_weight` real,`created_by` text,`created_at` datetime,`applied_at` datetime);
+
+CREATE INDEX `idx_load_plans_status` ON `load_plans`(`status`);
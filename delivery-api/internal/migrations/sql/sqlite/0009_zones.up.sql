@@ -0,0 +1,10 @@
+-- Zonas operacionais (polígonos em GeoJSON com o retângulo envolvente) e a zona de cada entrega.
+CREATE TABLE `zones` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`cidade` text NOT NULL DEFAULT "",`geometry` text NOT NULL,`min_lat` real NOT NULL,`min_lon` real NOT NULL,`max_lat` real NOT NULL,`max_lon` real NOT NULL,`area` real NOT NULL,`created_at` datetime,`updated_at` datetime);
+
+CREATE INDEX `idx_zones_cidade` ON `zones`(`cidade`);
+
+CREATE UNIQUE INDEX `idx_zones_name` ON `zones`(`name`);
+
+ALTER TABLE `deliveries` ADD `zone_id` integer;
+
+CREATE INDEX `idx_deliveries_zone_id` ON `deliveries`(`zone_id`);
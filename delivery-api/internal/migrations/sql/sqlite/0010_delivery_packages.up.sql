@@ -0,0 +1,11 @@
+-- Volumes das entregas (descrição, quantidade, peso, dimensões e valor declarado) e o valor declarado total da entrega.
+CREATE TABLE `delivery_packages` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_id` integer NOT NULL,`description` text NOT NULL,`quantity` integer NOT NULL,`weight` real NOT NULL,`length` real NOT NULL DEFAULT 0,`width` real NOT NULL DEFAULT 0,`height` real NOT NULL DEFAULT 0,`declared_value` real NOT NULL DEFAULT 0,`created_at` datetime,`updated_at` datetime);
+
+CREATE INDEX `idx_delivery_packages_delivery_id` ON `delivery_packages`(`delivery_id`);
+
+ALTER TABLE `deliveries` ADD `declared_value` real NOT NULL DEFAULT 0;
+
+-- Cada entrega existente passa a ter um volume só, com o test_name e o peso dela.
+INSERT INTO `delivery_packages` (`delivery_id`, `description`, `quantity`, `weight`, `created_at`, `updated_at`)
+  SELECT `id`, `test_name`, 1, `weight`, COALESCE(`created_at`, CURRENT_TIMESTAMP), COALESCE(`updated_at`, CURRENT_TIMESTAMP)
+  FROM `deliveries`;
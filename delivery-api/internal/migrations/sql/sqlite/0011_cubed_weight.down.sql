@@ -0,0 +1,8 @@
+-- Remove os pesos cubado e tarifado; o peso real continua gravado nas entregas e nos volumes.
+ALTER TABLE `deliveries` DROP COLUMN `chargeable_weight`;
+
+ALTER TABLE `deliveries` DROP COLUMN `cubed_weight`;
+
+ALTER TABLE `delivery_packages` DROP COLUMN `cubed_weight`;
+
+ALTER TABLE `delivery_packages` DROP COLUMN `dimension_unit`;
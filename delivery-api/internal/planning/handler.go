@@ -0,0 +1,144 @@
+package planning
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+)
+
+// Handler expõe os planos de carga pela API.
+type Handler struct {
+	Service Service
+}
+
+// CreatePlan é um handler HTTP para calcular um plano de carga.
+// @Summary Calcula um plano de carga
+// @Description Distribui as entregas do filtro (cidade e/ou estado; status Pendente, o padrão, ou Enviado) que ainda
+// @Description não têm entregador entre os veículos informados, sem passar da capacidade de cada um e mantendo juntas
+// @Description as entregas próximas. Veículos com courier_id usam a capacidade livre do entregador. As entregas que
+// @Description não couberem são listadas em unassigned. Com dry_run=true, o plano não é gravado.
+// @Tags Load plans
+// @Accept json
+// @Produce json
+// @Param Request body Request true "Filtro das entregas e veículos disponíveis"
+// @Param dry_run query bool false "Apenas calcula, sem gravar"
+// @Success 200 {object} Plan "Plano calculado (dry_run)"
+// @Success 201 {object} Plan "Plano gravado"
+// @Failure 400 "Dados inválidos"
+// @Failure 500 "Internal Server Error"
+// @Router /load-plans [post]
+func (h *Handler) CreatePlan(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+		return
+	}
+	var request Request
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.Service.CreatePlan(c.Request.Context(), &request, !dryRun)
+	if err != nil {
+		respondError(c, err, "Failed to create load plan")
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+	c.JSON(http.StatusCreated, plan)
+}
+
+// GetPlans é um handler HTTP para listar os planos de carga.
+// @Summary Lista os planos de carga
+// @Description Retorna os 100 planos gravados mais recentes.
+// @Tags Load plans
+// @Produce json
+// @Success 200 {array} Plan
+// @Failure 500 "Internal Server Error"
+// @Router /load-plans [get]
+func (h *Handler) GetPlans(c *gin.Context) {
+	plans, err := h.Service.GetPlans(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Failed to fetch load plans")
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetPlan é um handler HTTP para consultar um plano de carga.
+// @Summary Consulta um plano de carga
+// @Tags Load plans
+// @Produce json
+// @Param id path int true "ID do plano"
+// @Success 200 {object} Plan
+// @Failure 400 "ID inválido"
+// @Failure 404 "Plano não encontrado"
+// @Failure 500 "Internal Server Error"
+// @Router /load-plans/{id} [get]
+func (h *Handler) GetPlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	plan, err := h.Service.GetPlanByID(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to get load plan")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// ApplyPlan é um handler HTTP para converter um plano de carga em atribuições aos entregadores.
+// @Summary Aplica um plano de carga
+// @Description Atribui cada entrega do plano ao entregador do seu veículo, todas na mesma transação: se uma delas
+// @Description falhar (entrega atribuída a outro entregador, concluída ou cancelada, ou que não cabe mais no veículo),
+// @Description nada é aplicado. Todos os veículos com entregas precisam ter courier_id. Um plano só é aplicado uma vez.
+// @Tags Load plans
+// @Produce json
+// @Param id path int true "ID do plano"
+// @Success 200 {object} Plan
+// @Failure 400 "ID inválido ou veículo sem entregador"
+// @Failure 404 "Plano ou entrega não encontrados"
+// @Failure 409 "Plano já aplicado ou desatualizado"
+// @Failure 500 "Internal Server Error"
+// @Router /load-plans/{id}/apply [post]
+func (h *Handler) ApplyPlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	plan, err := h.Service.ApplyPlan(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to apply load plan")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// respondError traduz os erros do serviço em respostas HTTP.
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidPlan):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrPlanNotFound), errors.Is(err, deliveries.ErrDeliveryNotFound),
+		errors.Is(err, couriers.ErrCourierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrPlanApplied), errors.Is(err, ErrPlanOutdated),
+		errors.Is(err, couriers.ErrCapacityExceeded), errors.Is(err, couriers.ErrNotAssignable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,123 @@
+package planning
+
+import (
+	"math"
+	"sort"
+
+	"delivery-api/internal/deliveries"
+)
+
+// epsilon absorve os erros de arredondamento ao somar pesos fracionários.
+const epsilon = 1e-9
+
+// Pack distribui as entregas entre os veículos, sem passar da capacidade de nenhum, e retorna a carga de cada
+// veículo (na ordem recebida) e as entregas que ficaram de fora.
+//
+// A heurística é a da varredura (sweep): as entregas com coordenadas são ordenadas pelo ângulo em torno do
+// centro delas, começando logo depois do maior intervalo vazio (para não separar um grupo de entregas próximas
+// no início e no fim da volta); as sem coordenadas vêm por último, das mais pesadas para as mais leves. Nessa
+// ordem, cada entrega vai para o veículo que está sendo carregado ou, se não couber, para o próximo que a
+// comporte (first fit a partir do veículo atual), de modo que cada veículo recebe um setor contínuo da cidade.
+// Só depois que todos os veículos foram abertos as sobras de capacidade dos anteriores são aproveitadas.
+// O peso considerado é o peso tarifado das entregas (chargeable_weight), que já leva em conta o volume das caixas.
+func Pack(vehicles []Vehicle, candidates []deliveries.Delivery) ([]VehicleLoad, []Leftover) {
+	loads := make([]VehicleLoad, len(vehicles))
+	maxCapacity := 0.0
+	for i, vehicle := range vehicles {
+		loads[i] = VehicleLoad{
+			Label:       vehicle.Label,
+			CourierID:   vehicle.CourierID,
+			Capacity:    vehicle.Capacity,
+			DeliveryIDs: []uint{},
+		}
+		maxCapacity = math.Max(maxCapacity, vehicle.Capacity)
+	}
+
+	leftovers := []Leftover{}
+	current := 0
+	for _, delivery := range sweepOrder(candidates) {
+		if delivery.ChargeableWeight > maxCapacity+epsilon {
+			leftovers = append(leftovers, Leftover{DeliveryID: delivery.ID, Weight: delivery.ChargeableWeight, Reason: ReasonTooHeavy})
+			continue
+		}
+		target := -1
+		for i := current; i < len(loads) && target < 0; i++ {
+			if fits(&loads[i], delivery.ChargeableWeight) {
+				target, current = i, i
+			}
+		}
+		for i := 0; i < current && target < 0; i++ {
+			if fits(&loads[i], delivery.ChargeableWeight) {
+				target = i
+			}
+		}
+		if target < 0 {
+			leftovers = append(leftovers, Leftover{DeliveryID: delivery.ID, Weight: delivery.ChargeableWeight, Reason: ReasonNoCapacity})
+			continue
+		}
+		loads[target].Weight += delivery.ChargeableWeight
+		loads[target].DeliveryIDs = append(loads[target].DeliveryIDs, delivery.ID)
+	}
+	return loads, leftovers
+}
+
+// fits indica se a entrega com o peso informado ainda cabe no veículo.
+func fits(load *VehicleLoad, weight float64) bool {
+	return load.Weight+weight <= load.Capacity+epsilon
+}
+
+// sweepOrder retorna as entregas na ordem da varredura descrita em Pack. Entregas com latitude e longitude
+// iguais a zero são tratadas como sem coordenadas.
+func sweepOrder(candidates []deliveries.Delivery) []deliveries.Delivery {
+	type point struct {
+		delivery deliveries.Delivery
+		angle    float64
+	}
+	var located []point
+	var unlocated []deliveries.Delivery
+	var sumLat, sumLon float64
+	for _, delivery := range candidates {
+		if delivery.Latitude == 0 && delivery.Longitude == 0 {
+			unlocated = append(unlocated, delivery)
+			continue
+		}
+		located = append(located, point{delivery: delivery})
+		sumLat += delivery.Latitude
+		sumLon += delivery.Longitude
+	}
+
+	ordered := make([]deliveries.Delivery, 0, len(candidates))
+	if len(located) > 0 {
+		centerLat, centerLon := sumLat/float64(len(located)), sumLon/float64(len(located))
+		// A longitude é corrigida pelo cosseno da latitude, para que as distâncias leste-oeste não fiquem esticadas.
+		scale := math.Cos(centerLat * math.Pi / 180)
+		for i := range located {
+			located[i].angle = math.Atan2(located[i].delivery.Latitude-centerLat, (located[i].delivery.Longitude-centerLon)*scale)
+		}
+		sort.SliceStable(located, func(i, j int) bool {
+			if located[i].angle != located[j].angle {
+				return located[i].angle < located[j].angle
+			}
+			return located[i].delivery.ID < located[j].delivery.ID
+		})
+
+		// Começa depois do maior intervalo entre dois ângulos consecutivos (contando a volta do último ao primeiro).
+		start, largestGap := 0, located[0].angle+2*math.Pi-located[len(located)-1].angle
+		for i := 1; i < len(located); i++ {
+			if gap := located[i].angle - located[i-1].angle; gap > largestGap {
+				start, largestGap = i, gap
+			}
+		}
+		for i := range located {
+			ordered = append(ordered, located[(start+i)%len(located)].delivery)
+		}
+	}
+
+	sort.SliceStable(unlocated, func(i, j int) bool {
+		if unlocated[i].ChargeableWeight != unlocated[j].ChargeableWeight {
+			return unlocated[i].ChargeableWeight > unlocated[j].ChargeableWeight
+		}
+		return unlocated[i].ID < unlocated[j].ID
+	})
+	return append(ordered, unlocated...)
+}
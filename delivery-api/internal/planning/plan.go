@@ -0,0 +1,89 @@
+// Package planning monta planos de carga: distribui as entregas em andamento de uma cidade (ou de outro filtro)
+// entre os veículos informados, sem passar da capacidade de cada um e mantendo juntas as entregas próximas.
+// O plano pode ser apenas calculado, gravado para revisão e, depois, aplicado: cada veículo ligado a um entregador
+// tem as suas entregas atribuídas a ele (veja o pacote couriers), todas na mesma transação.
+package planning
+
+import (
+	"time"
+)
+
+// Status de um plano de carga.
+const (
+	PlanStatusDraft   = "draft"   // Gravado, ainda não aplicado
+	PlanStatusApplied = "applied" // Convertido em atribuições aos entregadores
+)
+
+// Motivos pelos quais uma entrega ficou fora do plano.
+const (
+	ReasonTooHeavy   = "too_heavy"   // O peso da entrega passa da capacidade de todos os veículos
+	ReasonNoCapacity = "no_capacity" // Os veículos que comportariam a entrega já estavam cheios
+)
+
+// Limites de um plano.
+const (
+	MaxVehicles   = 100
+	MaxDeliveries = 5000
+)
+
+// @description Filtro das entregas a serem distribuídas
+// @type object
+type PlanFilter struct {
+	Cidade      string `json:"cidade"`       // Cidade de destino (sem diferenciar maiúsculas)
+	Estado      string `json:"estado"`       // UF de destino
+	OrderStatus string `json:"order_status"` // Pendente (padrão) ou Enviado
+}
+
+// @description Veículo disponível para o plano
+// @type object
+type Vehicle struct {
+	Label     string  `json:"label"`      // Identificação do veículo; padrão: o nome do entregador ou "veículo N"
+	CourierID *uint   `json:"courier_id"` // Entregador do veículo, necessário para aplicar o plano
+	Capacity  float64 `json:"capacity"`   // Peso máximo; com courier_id, limitado à capacidade livre do entregador
+}
+
+// @description Pedido de um plano de carga
+// @type object
+type Request struct {
+	Filter   PlanFilter `json:"filter"`
+	Vehicles []Vehicle  `json:"vehicles" binding:"required"`
+}
+
+// @description Carga planejada para um veículo
+// @type object
+type VehicleLoad struct {
+	Label       string  `json:"label"`
+	CourierID   *uint   `json:"courier_id"`
+	Capacity    float64 `json:"capacity"`
+	Weight      float64 `json:"weight"`       // Soma do peso tarifado (chargeable_weight) das entregas do veículo
+	DeliveryIDs []uint  `json:"delivery_ids"` // Entregas do veículo, na ordem da varredura (as próximas ficam juntas)
+}
+
+// @description Entrega que ficou fora do plano
+// @type object
+type Leftover struct {
+	DeliveryID uint    `json:"delivery_id"`
+	Weight     float64 `json:"weight"` // Peso tarifado da entrega
+	Reason     string  `json:"reason"` // too_heavy ou no_capacity
+}
+
+// @description Plano de carga
+// @type object
+type Plan struct {
+	ID              uint          `json:"id" gorm:"primaryKey"`
+	Status          string        `json:"status" gorm:"size:20;not null;index"` // draft ou applied; vazio quando o plano não foi gravado
+	Filter          PlanFilter    `json:"filter" gorm:"serializer:json;type:text;not null"`
+	Vehicles        []VehicleLoad `json:"vehicles" gorm:"serializer:json;type:text;not null"`
+	Unassigned      []Leftover    `json:"unassigned" gorm:"serializer:json;type:text;not null"`
+	TotalDeliveries int           `json:"total_deliveries"` // Entregas que atendiam ao filtro
+	TotalWeight     float64       `json:"total_weight"`     // Soma do peso tarifado das entregas que atendiam ao filtro
+	PlannedWeight   float64       `json:"planned_weight"`   // Peso distribuído entre os veículos
+	CreatedBy       string        `json:"created_by" gorm:"size:255"`
+	CreatedAt       time.Time     `json:"created_at"`
+	AppliedAt       *time.Time    `json:"applied_at"`
+}
+
+// TableName define o nome da tabela dos planos de carga.
+func (Plan) TableName() string {
+	return "load_plans"
+}
@@ -0,0 +1,144 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+)
+
+// ErrPlanNotFound é retornado quando o plano de carga solicitado não existe.
+var ErrPlanNotFound = errors.New("load plan not found")
+
+// ErrPlanApplied é retornado ao aplicar um plano que já foi aplicado.
+var ErrPlanApplied = errors.New("load plan was already applied")
+
+// ErrPlanOutdated é retornado ao aplicar um plano cuja entrega foi atribuída a outro entregador depois do cálculo.
+var ErrPlanOutdated = errors.New("load plan is outdated")
+
+// plansLimit é a quantidade máxima de planos retornados pela listagem.
+const plansLimit = 100
+
+// Repository é uma interface que define o acesso aos planos de carga no banco de dados.
+type Repository interface {
+	FindCandidates(ctx context.Context, filter PlanFilter) ([]deliveries.Delivery, error) // Entregas a serem distribuídas
+	CreatePlan(ctx context.Context, plan *Plan) error                                     // Grava um plano
+	GetPlans(ctx context.Context) ([]Plan, error)                                         // Lista os planos mais recentes
+	GetPlanByID(ctx context.Context, id uint) (*Plan, error)                              // Retorna um plano pelo ID
+	ApplyPlan(ctx context.Context, id uint) (*Plan, error)                                // Atribui as entregas do plano aos entregadores
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório dos planos de carga.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindCandidates retorna as entregas que atendem ao filtro e ainda não foram atribuídas a um entregador, até
+// MaxDeliveries+1 (o excesso é recusado pelo serviço).
+func (r *repository) FindCandidates(ctx context.Context, filter PlanFilter) ([]deliveries.Delivery, error) {
+	query := r.db.WithContext(ctx).
+		Where("order_status = ? AND courier_id IS NULL", filter.OrderStatus)
+	if filter.Cidade != "" {
+		query = query.Where("LOWER(cidade) = LOWER(?)", filter.Cidade)
+	}
+	if filter.Estado != "" {
+		query = query.Where("estado = ?", filter.Estado)
+	}
+	var found []deliveries.Delivery
+	if err := query.Order("id").Limit(MaxDeliveries + 1).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// CreatePlan grava o plano como rascunho.
+func (r *repository) CreatePlan(ctx context.Context, plan *Plan) error {
+	plan.ID = 0
+	plan.Status = PlanStatusDraft
+	plan.AppliedAt = nil
+	return r.db.WithContext(ctx).Create(plan).Error
+}
+
+// GetPlans retorna os planos mais recentes primeiro, até plansLimit.
+func (r *repository) GetPlans(ctx context.Context) ([]Plan, error) {
+	var plans []Plan
+	if err := r.db.WithContext(ctx).Order("id DESC").Limit(plansLimit).Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// GetPlanByID retorna o plano pelo ID, ou ErrPlanNotFound.
+func (r *repository) GetPlanByID(ctx context.Context, id uint) (*Plan, error) {
+	var plan Plan
+	if err := r.db.WithContext(ctx).First(&plan, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ApplyPlan atribui, em uma única transação, cada entrega do plano ao entregador do veículo (couriers.AssignTx),
+// e marca o plano como aplicado. A mudança de status é feita primeiro, com a condição status = draft, o que bloqueia
+// o plano e impede que duas aplicações simultâneas atribuam as mesmas entregas.
+// Todos os veículos com entregas precisam ter um entregador (ErrInvalidPlan).
+// Se uma entrega foi atribuída a outro entregador depois do cálculo (ErrPlanOutdated), mudou de status ou não cabe
+// mais no veículo, nada é aplicado; o erro indica a entrega.
+func (r *repository) ApplyPlan(ctx context.Context, id uint) (*Plan, error) {
+	var plan Plan
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&Plan{}).Where("id = ? AND status = ?", id, PlanStatusDraft).
+			Updates(map[string]interface{}{"status": PlanStatusApplied, "applied_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := tx.First(&plan, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlanNotFound
+			}
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return ErrPlanApplied
+		}
+
+		for _, vehicle := range plan.Vehicles {
+			if len(vehicle.DeliveryIDs) > 0 && vehicle.CourierID == nil {
+				return fmt.Errorf("%w: vehicle %q has no courier_id", ErrInvalidPlan, vehicle.Label)
+			}
+			for _, deliveryID := range vehicle.DeliveryIDs {
+				var delivery deliveries.Delivery
+				if err := tx.Select("id", "courier_id").First(&delivery, deliveryID).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fmt.Errorf("delivery %d: %w", deliveryID, deliveries.ErrDeliveryNotFound)
+					}
+					return err
+				}
+				if delivery.CourierID != nil && *delivery.CourierID != *vehicle.CourierID {
+					return fmt.Errorf("%w: delivery %d was assigned to courier %d", ErrPlanOutdated, deliveryID, *delivery.CourierID)
+				}
+				if _, err := couriers.AssignTx(tx, *vehicle.CourierID, deliveryID); err != nil {
+					return fmt.Errorf("delivery %d: %w", deliveryID, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
@@ -0,0 +1,167 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/users"
+)
+
+// ErrInvalidPlan é retornado quando o pedido de um plano de carga é inválido.
+var ErrInvalidPlan = errors.New("invalid load plan")
+
+// Service é uma interface que define as operações de negócio dos planos de carga.
+type Service interface {
+	CreatePlan(ctx context.Context, request *Request, save bool) (*Plan, error) // Calcula (e, se save, grava) um plano
+	GetPlans(ctx context.Context) ([]Plan, error)                               // Lista os planos gravados
+	GetPlanByID(ctx context.Context, id uint) (*Plan, error)                    // Retorna um plano pelo ID
+	ApplyPlan(ctx context.Context, id uint) (*Plan, error)                      // Converte o plano em atribuições
+}
+
+// service é uma struct que implementa a interface Service.
+// A capacidade livre dos veículos ligados a entregadores vem do serviço dos entregadores.
+type service struct {
+	repo     Repository
+	couriers couriers.Service
+}
+
+// NewService cria uma nova instância do serviço dos planos de carga.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository, courierService couriers.Service) Service {
+	return &tracedService{next: &service{repo: repo, couriers: courierService}}
+}
+
+// CreatePlan valida o pedido, busca as entregas do filtro que ainda não têm entregador e as distribui entre os
+// veículos (veja Pack). Veículos ligados a um entregador têm a capacidade limitada ao que ele ainda pode carregar,
+// descontadas as entregas que já estão com ele. Com save, o plano é gravado como rascunho, junto com o e-mail do
+// usuário que o criou (se identificado); sem save, ele é apenas calculado.
+func (s *service) CreatePlan(ctx context.Context, request *Request, save bool) (*Plan, error) {
+	filter, err := normalizeFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	vehicles, err := s.resolveVehicles(ctx, request.Vehicles)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.FindCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > MaxDeliveries {
+		return nil, fmt.Errorf("%w: the filter matches more than %d deliveries", ErrInvalidPlan, MaxDeliveries)
+	}
+
+	loads, leftovers := Pack(vehicles, candidates)
+	plan := &Plan{
+		Filter:          filter,
+		Vehicles:        loads,
+		Unassigned:      leftovers,
+		TotalDeliveries: len(candidates),
+	}
+	for _, delivery := range candidates {
+		plan.TotalWeight += delivery.ChargeableWeight
+	}
+	for _, load := range loads {
+		plan.PlannedWeight += load.Weight
+	}
+	if user := users.FromContext(ctx); user != nil {
+		plan.CreatedBy = user.Email
+	}
+
+	if !save {
+		return plan, nil
+	}
+	if err := s.repo.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// GetPlans lista os planos gravados, dos mais recentes para os mais antigos.
+func (s *service) GetPlans(ctx context.Context) ([]Plan, error) {
+	return s.repo.GetPlans(ctx)
+}
+
+// GetPlanByID retorna o plano pelo ID.
+func (s *service) GetPlanByID(ctx context.Context, id uint) (*Plan, error) {
+	return s.repo.GetPlanByID(ctx, id)
+}
+
+// ApplyPlan atribui as entregas do plano aos entregadores dos veículos, todas de uma vez.
+func (s *service) ApplyPlan(ctx context.Context, id uint) (*Plan, error) {
+	return s.repo.ApplyPlan(ctx, id)
+}
+
+// normalizeFilter confere o filtro das entregas: é preciso informar a cidade ou o estado, e apenas entregas
+// Pendente (o padrão) ou Enviado podem ser distribuídas.
+func normalizeFilter(filter PlanFilter) (PlanFilter, error) {
+	filter.Cidade = strings.TrimSpace(filter.Cidade)
+	filter.Estado = strings.ToUpper(strings.TrimSpace(filter.Estado))
+	if filter.OrderStatus == "" {
+		filter.OrderStatus = deliveries.OrderStatusPending
+	}
+	switch {
+	case filter.Cidade == "" && filter.Estado == "":
+		return filter, fmt.Errorf("%w: filter.cidade or filter.estado is required", ErrInvalidPlan)
+	case filter.OrderStatus != deliveries.OrderStatusPending && filter.OrderStatus != deliveries.OrderStatusShipped:
+		return filter, fmt.Errorf("%w: filter.order_status must be %s or %s", ErrInvalidPlan,
+			deliveries.OrderStatusPending, deliveries.OrderStatusShipped)
+	}
+	return filter, nil
+}
+
+// resolveVehicles confere os veículos e preenche o nome e a capacidade dos que estão ligados a um entregador.
+// A capacidade informada para esses veículos só pode reduzir a capacidade livre do entregador, nunca aumentá-la.
+func (s *service) resolveVehicles(ctx context.Context, requested []Vehicle) ([]Vehicle, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one vehicle is required", ErrInvalidPlan)
+	}
+	if len(requested) > MaxVehicles {
+		return nil, fmt.Errorf("%w: at most %d vehicles are allowed", ErrInvalidPlan, MaxVehicles)
+	}
+
+	vehicles := make([]Vehicle, len(requested))
+	seen := make(map[uint]bool)
+	for i, vehicle := range requested {
+		vehicle.Label = strings.TrimSpace(vehicle.Label)
+		if vehicle.Capacity < 0 {
+			return nil, fmt.Errorf("%w: vehicles[%d].capacity must not be negative", ErrInvalidPlan, i)
+		}
+
+		if vehicle.CourierID != nil {
+			if seen[*vehicle.CourierID] {
+				return nil, fmt.Errorf("%w: courier %d appears in more than one vehicle", ErrInvalidPlan, *vehicle.CourierID)
+			}
+			seen[*vehicle.CourierID] = true
+			workload, err := s.couriers.GetWorkload(ctx, *vehicle.CourierID)
+			if errors.Is(err, couriers.ErrCourierNotFound) {
+				return nil, fmt.Errorf("%w: vehicles[%d]: courier %d not found", ErrInvalidPlan, i, *vehicle.CourierID)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if vehicle.Label == "" {
+				vehicle.Label = workload.Courier.Name
+			}
+			if vehicle.Capacity == 0 {
+				vehicle.Capacity = workload.Available
+			}
+			vehicle.Capacity = math.Min(vehicle.Capacity, workload.Available)
+		} else if vehicle.Capacity == 0 {
+			return nil, fmt.Errorf("%w: vehicles[%d].capacity is required without courier_id", ErrInvalidPlan, i)
+		}
+
+		if vehicle.Label == "" {
+			vehicle.Label = fmt.Sprintf("veículo %d", i+1)
+		}
+		vehicles[i] = vehicle
+	}
+	return vehicles, nil
+}
@@ -0,0 +1,59 @@
+package planning
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{
+	ErrInvalidPlan, ErrPlanNotFound, ErrPlanApplied, ErrPlanOutdated, deliveries.ErrDeliveryNotFound,
+	couriers.ErrCourierNotFound, couriers.ErrCapacityExceeded, couriers.ErrNotAssignable,
+}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) CreatePlan(ctx context.Context, request *Request, save bool) (*Plan, error) {
+	ctx, span := tracing.Start(ctx, "planning.CreatePlan",
+		attribute.Int("plan.vehicles", len(request.Vehicles)),
+		attribute.Bool("plan.save", save),
+	)
+	plan, err := s.next.CreatePlan(ctx, request, save)
+	if plan != nil {
+		span.SetAttributes(
+			attribute.Int("plan.deliveries", plan.TotalDeliveries),
+			attribute.Int("plan.unassigned", len(plan.Unassigned)),
+		)
+	}
+	tracing.End(span, err, expectedErrors...)
+	return plan, err
+}
+
+func (s *tracedService) GetPlans(ctx context.Context) ([]Plan, error) {
+	ctx, span := tracing.Start(ctx, "planning.GetPlans")
+	plans, err := s.next.GetPlans(ctx)
+	tracing.End(span, err, expectedErrors...)
+	return plans, err
+}
+
+func (s *tracedService) GetPlanByID(ctx context.Context, id uint) (*Plan, error) {
+	ctx, span := tracing.Start(ctx, "planning.GetPlanByID", attribute.Int64("plan.id", int64(id)))
+	plan, err := s.next.GetPlanByID(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return plan, err
+}
+
+func (s *tracedService) ApplyPlan(ctx context.Context, id uint) (*Plan, error) {
+	ctx, span := tracing.Start(ctx, "planning.ApplyPlan", attribute.Int64("plan.id", int64(id)))
+	plan, err := s.next.ApplyPlan(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return plan, err
+}
@@ -0,0 +1,232 @@
+package proofs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"delivery-api/internal/deliveries"
+)
+
+// maxFormSize é o tamanho máximo do formulário do comprovante: os dois arquivos e uma folga para os demais campos.
+const maxFormSize = 2*MaxFileSize + 1<<20
+
+// Handler expõe o registro e a consulta dos comprovantes de entrega pela API.
+type Handler struct {
+	Service Service
+}
+
+// SubmitProof é um handler HTTP para registrar o comprovante de uma entrega.
+// @Summary Registra o comprovante de entrega
+// @Description Recebe, em um formulário multipart, quem recebeu, a assinatura, uma foto opcional, a posição do
+// @Description entregador e o horário da entrega. A assinatura e a foto devem ser imagens PNG ou JPEG de até 5 MB.
+// @Description O comprovante é obrigatório para mudar a entrega para Entregue e não pode ser substituído depois.
+// @Tags Proofs
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID da entrega"
+// @Param recipient_name formData string true "Nome de quem recebeu"
+// @Param recipient_document formData string true "Documento de quem recebeu (CPF, RG, ...)"
+// @Param signature formData file true "Imagem da assinatura (PNG ou JPEG)"
+// @Param photo formData file false "Foto da entrega (PNG ou JPEG)"
+// @Param latitude formData number true "Latitude do entregador"
+// @Param longitude formData number true "Longitude do entregador"
+// @Param delivered_at formData string true "Horário da entrega (RFC 3339, por exemplo 2024-05-10T14:30:00-03:00)"
+// @Success 201 {object} Proof
+// @Failure 400 "Dados inválidos"
+// @Failure 404 "Entrega não encontrada"
+// @Failure 409 "Entrega cancelada ou com comprovante já registrado"
+// @Failure 413 "Formulário maior que o permitido"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/proof [post]
+func (h *Handler) SubmitProof(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFormSize)
+	submission, err := parseSubmission(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("form must have at most %d bytes", maxFormSize)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proof, err := h.Service.SubmitProof(c.Request.Context(), uint(id), submission)
+	if err != nil {
+		respondError(c, err, "Failed to record proof of delivery")
+		return
+	}
+	c.JSON(http.StatusCreated, withURLs(proof))
+}
+
+// GetProof é um handler HTTP para consultar o comprovante de uma entrega.
+// @Summary Consulta o comprovante de entrega
+// @Description Retorna os dados do comprovante e as rotas de download da assinatura e da foto.
+// @Description O comprovante continua disponível mesmo que a entrega seja removida.
+// @Tags Proofs
+// @Produce json
+// @Param id path int true "ID da entrega"
+// @Success 200 {object} Proof
+// @Failure 400 "ID inválido"
+// @Failure 404 "Comprovante não encontrado"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/proof [get]
+func (h *Handler) GetProof(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	proof, err := h.Service.GetProof(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to get proof of delivery")
+		return
+	}
+	c.JSON(http.StatusOK, withURLs(proof))
+}
+
+// DownloadFile é um handler HTTP para baixar a assinatura ou a foto do comprovante de uma entrega.
+// @Summary Baixa um arquivo do comprovante de entrega
+// @Description Retorna a imagem da assinatura (signature) ou da foto (photo), com o tipo original.
+// @Tags Proofs
+// @Produce png,jpeg
+// @Param id path int true "ID da entrega"
+// @Param file path string true "Arquivo" Enums(signature, photo)
+// @Success 200 {file} file
+// @Failure 400 "ID inválido"
+// @Failure 404 "Comprovante ou arquivo não encontrado"
+// @Failure 500 "Internal Server Error"
+// @Router /deliveries/{id}/proof/{file} [get]
+func (h *Handler) DownloadFile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	file := c.Param("file")
+	reader, contentType, err := h.Service.OpenFile(c.Request.Context(), uint(id), file)
+	if err != nil {
+		respondError(c, err, "Failed to open proof file")
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="delivery-%d-%s.%s"`, id, file, imageExtensions[contentType]))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, reader)
+}
+
+// parseSubmission lê os campos e os arquivos do formulário multipart.
+// Os valores são apenas convertidos aqui; as regras (obrigatoriedade, limites, tipos) ficam no serviço.
+func parseSubmission(c *gin.Context) (*Submission, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return nil, errors.New("request must be a multipart/form-data form")
+	}
+	if err := c.Request.ParseMultipartForm(maxFormSize); err != nil {
+		return nil, err
+	}
+
+	submission := &Submission{
+		RecipientName:     c.PostForm("recipient_name"),
+		RecipientDocument: c.PostForm("recipient_document"),
+	}
+	var err error
+	if submission.Latitude, err = parseCoordinate(c, "latitude"); err != nil {
+		return nil, err
+	}
+	if submission.Longitude, err = parseCoordinate(c, "longitude"); err != nil {
+		return nil, err
+	}
+	if value := c.PostForm("delivered_at"); value != "" {
+		if submission.DeliveredAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("invalid delivered_at %q: expected RFC 3339 (e.g. 2024-05-10T14:30:00-03:00)", value)
+		}
+	}
+	if submission.Signature, err = readFile(c, FileSignature); err != nil {
+		return nil, err
+	}
+	if submission.Photo, err = readFile(c, FilePhoto); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// parseCoordinate converte uma coordenada obrigatória do formulário.
+func parseCoordinate(c *gin.Context, field string) (float64, error) {
+	value := c.PostForm(field)
+	if value == "" {
+		return 0, fmt.Errorf("%s is required", field)
+	}
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", field, value)
+	}
+	return coordinate, nil
+}
+
+// readFile lê um arquivo do formulário, até MaxFileSize+1 bytes (o excesso é rejeitado pelo serviço).
+// Retorna nil se o arquivo não foi enviado.
+func readFile(c *gin.Context, field string) ([]byte, error) {
+	header, err := c.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readUpload(header)
+}
+
+// readUpload lê o conteúdo de um arquivo enviado no formulário.
+func readUpload(header *multipart.FileHeader) ([]byte, error) {
+	upload, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer upload.Close()
+	return io.ReadAll(io.LimitReader(upload, MaxFileSize+1))
+}
+
+// withURLs preenche as rotas de download dos arquivos do comprovante.
+func withURLs(proof *Proof) *Proof {
+	base := fmt.Sprintf("/api/v1/deliveries/%d/proof/", proof.DeliveryID)
+	proof.SignatureURL = base + FileSignature
+	if proof.PhotoKey != "" {
+		proof.PhotoURL = base + FilePhoto
+	}
+	return proof
+}
+
+// respondError traduz os erros do serviço em respostas HTTP.
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidProof):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, deliveries.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+	case errors.Is(err, ErrProofNotFound), errors.Is(err, ErrFileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrProofExists), errors.Is(err, ErrDeliveryCanceled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,59 @@
+// Package proofs registra os comprovantes de entrega: quem recebeu, a assinatura, uma foto opcional e a posição
+// do entregador no momento da entrega. Os arquivos ficam em um armazenamento de arquivos (pacote blob) e os dados
+// na tabela delivery_proofs. Uma entrega só passa a Entregue depois que o comprovante é registrado
+// (veja deliveries.ErrProofRequired), e o comprovante pode ser consultado depois, em caso de contestação.
+package proofs
+
+import (
+	"time"
+)
+
+// Arquivos de um comprovante, usados na rota de download.
+const (
+	FileSignature = "signature" // Imagem da assinatura de quem recebeu (obrigatória)
+	FilePhoto     = "photo"     // Foto da entrega (opcional)
+)
+
+// MaxFileSize é o tamanho máximo de cada arquivo do comprovante (5 MB).
+const MaxFileSize = 5 << 20
+
+// maxClockSkew é o quanto o horário informado pelo entregador pode estar à frente do relógio do servidor.
+const maxClockSkew = 5 * time.Minute
+
+// @description Comprovante de entrega
+// @type object
+type Proof struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID        uint      `json:"delivery_id" gorm:"not null;uniqueIndex"` // Uma entrega tem no máximo um comprovante
+	RecipientName     string    `json:"recipient_name" gorm:"size:255;not null"`
+	RecipientDocument string    `json:"recipient_document" gorm:"size:30;not null"` // Documento de quem recebeu (CPF, RG, ...)
+	SignatureKey      string    `json:"-" gorm:"size:255;not null"`                 // Chave da assinatura no armazenamento de arquivos
+	SignatureType     string    `json:"signature_type" gorm:"size:50;not null"`
+	SignatureSHA256   string    `json:"signature_sha256" gorm:"size:64;not null"` // Hash do arquivo, para comprovar que ele não foi alterado
+	PhotoKey          string    `json:"-" gorm:"size:255"`
+	PhotoType         string    `json:"photo_type,omitempty" gorm:"size:50"`
+	PhotoSHA256       string    `json:"photo_sha256,omitempty" gorm:"size:64"`
+	Latitude          float64   `json:"latitude"` // Posição do entregador (GPS) no momento da entrega
+	Longitude         float64   `json:"longitude"`
+	DeliveredAt       time.Time `json:"delivered_at" gorm:"not null"`          // Horário da entrega informado pelo entregador
+	RecordedBy        string    `json:"recorded_by,omitempty" gorm:"size:255"` // E-mail do usuário que registrou o comprovante
+	CreatedAt         time.Time `json:"created_at"`
+	SignatureURL      string    `json:"signature_url" gorm:"-"` // Rota de download da assinatura
+	PhotoURL          string    `json:"photo_url,omitempty" gorm:"-"`
+}
+
+// TableName define o nome da tabela dos comprovantes de entrega.
+func (Proof) TableName() string {
+	return "delivery_proofs"
+}
+
+// Submission reúne os dados enviados para registrar um comprovante de entrega.
+type Submission struct {
+	RecipientName     string
+	RecipientDocument string
+	Latitude          float64
+	Longitude         float64
+	DeliveredAt       time.Time
+	Signature         []byte // Imagem PNG ou JPEG
+	Photo             []byte // Imagem PNG ou JPEG; vazia se não houver foto
+}
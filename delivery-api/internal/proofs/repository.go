@@ -0,0 +1,76 @@
+package proofs
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+)
+
+// ErrProofNotFound é retornado quando a entrega não tem comprovante registrado.
+var ErrProofNotFound = errors.New("proof of delivery not found")
+
+// ErrProofExists é retornado ao registrar o comprovante de uma entrega que já tem um.
+// O comprovante não pode ser substituído, para que continue valendo em uma contestação.
+var ErrProofExists = errors.New("delivery already has a proof of delivery")
+
+// ErrDeliveryCanceled é retornado ao registrar o comprovante de uma entrega cancelada.
+var ErrDeliveryCanceled = errors.New("delivery is canceled")
+
+// Repository é uma interface que define o acesso aos comprovantes de entrega no banco de dados.
+type Repository interface {
+	CreateProof(ctx context.Context, proof *Proof) error           // Grava o comprovante de uma entrega
+	GetProof(ctx context.Context, deliveryID uint) (*Proof, error) // Retorna o comprovante de uma entrega
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório dos comprovantes de entrega.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreateProof grava o comprovante dentro de uma transação que, antes, confere a entrega: ela precisa existir
+// (deliveries.ErrDeliveryNotFound), não pode estar cancelada (ErrDeliveryCanceled) e não pode ter comprovante
+// (ErrProofExists). O índice único em delivery_id impede dois comprovantes mesmo com requisições simultâneas.
+func (r *repository) CreateProof(ctx context.Context, proof *Proof) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var delivery deliveries.Delivery
+		if err := tx.Select("id", "order_status").First(&delivery, proof.DeliveryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return deliveries.ErrDeliveryNotFound
+			}
+			return err
+		}
+		if delivery.OrderStatus == deliveries.OrderStatusCanceled {
+			return ErrDeliveryCanceled
+		}
+
+		var count int64
+		if err := tx.Model(&Proof{}).Where("delivery_id = ?", proof.DeliveryID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrProofExists
+		}
+		return tx.Create(proof).Error
+	})
+}
+
+// GetProof retorna o comprovante da entrega, ou ErrProofNotFound se não houver.
+// O comprovante continua disponível mesmo que a entrega seja removida.
+func (r *repository) GetProof(ctx context.Context, deliveryID uint) (*Proof, error) {
+	var proof Proof
+	if err := r.db.WithContext(ctx).Where("delivery_id = ?", deliveryID).First(&proof).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProofNotFound
+		}
+		return nil, err
+	}
+	return &proof, nil
+}
@@ -0,0 +1,203 @@
+package proofs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"delivery-api/internal/blob"
+	"delivery-api/internal/users"
+)
+
+// ErrInvalidProof é retornado quando os dados do comprovante são inválidos.
+var ErrInvalidProof = errors.New("invalid proof of delivery")
+
+// ErrFileNotFound é retornado ao baixar um arquivo que o comprovante não tem (por exemplo, a foto, que é opcional).
+var ErrFileNotFound = errors.New("proof file not found")
+
+// imageExtensions são os tipos de imagem aceitos nos arquivos do comprovante, com a extensão usada na chave.
+var imageExtensions = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpg",
+}
+
+// Service é uma interface que define as operações de negócio dos comprovantes de entrega.
+type Service interface {
+	SubmitProof(ctx context.Context, deliveryID uint, submission *Submission) (*Proof, error)  // Registra o comprovante de uma entrega
+	GetProof(ctx context.Context, deliveryID uint) (*Proof, error)                             // Retorna o comprovante de uma entrega
+	OpenFile(ctx context.Context, deliveryID uint, file string) (io.ReadCloser, string, error) // Abre um arquivo do comprovante
+}
+
+// service é uma struct que implementa a interface Service.
+// Os arquivos são gravados no armazenamento (blob.Store) e os dados no repositório.
+type service struct {
+	repo  Repository
+	store blob.Store
+}
+
+// NewService cria uma nova instância do serviço dos comprovantes de entrega.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository, store blob.Store) Service {
+	return &tracedService{next: &service{repo: repo, store: store}}
+}
+
+// SubmitProof valida e registra o comprovante de entrega.
+// Os arquivos são gravados antes do registro no banco; se o registro falhar, eles são removidos.
+// O usuário que registrou o comprovante (se identificado) é gravado em recorded_by.
+func (s *service) SubmitProof(ctx context.Context, deliveryID uint, submission *Submission) (*Proof, error) {
+	signatureType, photoType, err := validateSubmission(submission, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// Evita gravar os arquivos quando o comprovante já existe; a conferência definitiva é feita em CreateProof.
+	if _, err := s.repo.GetProof(ctx, deliveryID); err == nil {
+		return nil, ErrProofExists
+	} else if !errors.Is(err, ErrProofNotFound) {
+		return nil, err
+	}
+
+	proof := &Proof{
+		DeliveryID:        deliveryID,
+		RecipientName:     strings.TrimSpace(submission.RecipientName),
+		RecipientDocument: strings.TrimSpace(submission.RecipientDocument),
+		SignatureType:     signatureType,
+		SignatureSHA256:   checksum(submission.Signature),
+		Latitude:          submission.Latitude,
+		Longitude:         submission.Longitude,
+		DeliveredAt:       submission.DeliveredAt,
+	}
+	if user := users.FromContext(ctx); user != nil {
+		proof.RecordedBy = user.Email
+	}
+
+	var keys []string
+	cleanup := func() {
+		for _, key := range keys {
+			_ = s.store.Delete(context.WithoutCancel(ctx), key)
+		}
+	}
+	proof.SignatureKey = fileKey(deliveryID, FileSignature, signatureType)
+	if err := s.store.Put(ctx, proof.SignatureKey, bytes.NewReader(submission.Signature)); err != nil {
+		return nil, fmt.Errorf("failed to store signature: %w", err)
+	}
+	keys = append(keys, proof.SignatureKey)
+	if len(submission.Photo) > 0 {
+		proof.PhotoKey = fileKey(deliveryID, FilePhoto, photoType)
+		proof.PhotoType = photoType
+		proof.PhotoSHA256 = checksum(submission.Photo)
+		if err := s.store.Put(ctx, proof.PhotoKey, bytes.NewReader(submission.Photo)); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to store photo: %w", err)
+		}
+		keys = append(keys, proof.PhotoKey)
+	}
+
+	if err := s.repo.CreateProof(ctx, proof); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return proof, nil
+}
+
+// GetProof retorna o comprovante da entrega, ou ErrProofNotFound se não houver.
+func (s *service) GetProof(ctx context.Context, deliveryID uint) (*Proof, error) {
+	return s.repo.GetProof(ctx, deliveryID)
+}
+
+// OpenFile abre um arquivo do comprovante (FileSignature ou FilePhoto) e retorna também o tipo dele.
+// Retorna ErrProofNotFound se a entrega não tiver comprovante e ErrFileNotFound se o arquivo não existir.
+func (s *service) OpenFile(ctx context.Context, deliveryID uint, file string) (io.ReadCloser, string, error) {
+	proof, err := s.repo.GetProof(ctx, deliveryID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var key, contentType string
+	switch file {
+	case FileSignature:
+		key, contentType = proof.SignatureKey, proof.SignatureType
+	case FilePhoto:
+		key, contentType = proof.PhotoKey, proof.PhotoType
+	}
+	if key == "" {
+		return nil, "", ErrFileNotFound
+	}
+
+	reader, err := s.store.Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, "", ErrFileNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return reader, contentType, nil
+}
+
+// validateSubmission verifica os dados do comprovante e retorna o tipo das imagens da assinatura e da foto.
+// O horário da entrega não pode estar no futuro (com uma tolerância de maxClockSkew para o relógio do aparelho).
+func validateSubmission(submission *Submission, now time.Time) (signatureType, photoType string, err error) {
+	switch {
+	case strings.TrimSpace(submission.RecipientName) == "":
+		return "", "", fmt.Errorf("%w: recipient_name is required", ErrInvalidProof)
+	case strings.TrimSpace(submission.RecipientDocument) == "":
+		return "", "", fmt.Errorf("%w: recipient_document is required", ErrInvalidProof)
+	case len(strings.TrimSpace(submission.RecipientDocument)) > 30:
+		return "", "", fmt.Errorf("%w: recipient_document must have at most 30 characters", ErrInvalidProof)
+	case submission.Latitude < -90 || submission.Latitude > 90:
+		return "", "", fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidProof)
+	case submission.Longitude < -180 || submission.Longitude > 180:
+		return "", "", fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidProof)
+	case submission.DeliveredAt.IsZero():
+		return "", "", fmt.Errorf("%w: delivered_at is required", ErrInvalidProof)
+	case submission.DeliveredAt.After(now.Add(maxClockSkew)):
+		return "", "", fmt.Errorf("%w: delivered_at must not be in the future", ErrInvalidProof)
+	}
+
+	if signatureType, err = imageType(FileSignature, submission.Signature); err != nil {
+		return "", "", err
+	}
+	if len(submission.Photo) > 0 {
+		if photoType, err = imageType(FilePhoto, submission.Photo); err != nil {
+			return "", "", err
+		}
+	}
+	return signatureType, photoType, nil
+}
+
+// imageType identifica o tipo do arquivo pelo conteúdo (e não pelo nome ou pelo cabeçalho enviado),
+// aceitando apenas PNG e JPEG dentro do tamanho máximo.
+func imageType(file string, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: %s is required", ErrInvalidProof, file)
+	}
+	if len(data) > MaxFileSize {
+		return "", fmt.Errorf("%w: %s must have at most %d bytes", ErrInvalidProof, file, MaxFileSize)
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := imageExtensions[contentType]; !ok {
+		return "", fmt.Errorf("%w: %s must be a PNG or JPEG image", ErrInvalidProof, file)
+	}
+	return contentType, nil
+}
+
+// fileKey monta a chave do arquivo no armazenamento. O sufixo aleatório evita que um envio substitua os arquivos
+// de outro (por exemplo, em uma nova tentativa depois de uma falha).
+func fileKey(deliveryID uint, file, contentType string) string {
+	suffix := make([]byte, 8)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("proofs/%d/%s-%s.%s", deliveryID, file, hex.EncodeToString(suffix), imageExtensions[contentType])
+}
+
+// checksum retorna o SHA-256 do arquivo em hexadecimal.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+package proofs
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{
+	ErrInvalidProof, ErrProofExists, ErrProofNotFound, ErrFileNotFound, ErrDeliveryCanceled, deliveries.ErrDeliveryNotFound,
+}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// Os spans levam apenas o ID da entrega; o nome e o documento de quem recebeu não são gravados.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) SubmitProof(ctx context.Context, deliveryID uint, submission *Submission) (*Proof, error) {
+	ctx, span := tracing.Start(ctx, "proofs.SubmitProof",
+		attribute.Int64("delivery.id", int64(deliveryID)),
+		attribute.Bool("proof.photo", len(submission.Photo) > 0),
+	)
+	proof, err := s.next.SubmitProof(ctx, deliveryID, submission)
+	tracing.End(span, err, expectedErrors...)
+	return proof, err
+}
+
+func (s *tracedService) GetProof(ctx context.Context, deliveryID uint) (*Proof, error) {
+	ctx, span := tracing.Start(ctx, "proofs.GetProof", attribute.Int64("delivery.id", int64(deliveryID)))
+	proof, err := s.next.GetProof(ctx, deliveryID)
+	tracing.End(span, err, expectedErrors...)
+	return proof, err
+}
+
+func (s *tracedService) OpenFile(ctx context.Context, deliveryID uint, file string) (io.ReadCloser, string, error) {
+	ctx, span := tracing.Start(ctx, "proofs.OpenFile",
+		attribute.Int64("delivery.id", int64(deliveryID)),
+		attribute.String("proof.file", file),
+	)
+	reader, contentType, err := s.next.OpenFile(ctx, deliveryID, file)
+	tracing.End(span, err, expectedErrors...)
+	return reader, contentType, err
+}
@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule é uma expressão cron já interpretada.
+//
+// O formato é o tradicional, com cinco campos separados por espaço: minuto (0-59), hora (0-23), dia do mês (1-31),
+// mês (1-12) e dia da semana (0-6, domingo = 0; 7 também é aceito como domingo). Cada campo aceita "*", valores,
+// intervalos ("1-5"), listas ("1,15") e passos ("*/15", "8-18/2"). Também são aceitos os atalhos @hourly, @daily,
+// @weekly e @monthly. Os horários são avaliados no fuso horário do servidor.
+type Schedule struct {
+	expr   string
+	minute uint64 // Um bit por valor permitido
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	anyDom bool // Dia do mês "*": apenas o dia da semana restringe o dia
+	anyDow bool // Dia da semana "*": apenas o dia do mês restringe o dia
+}
+
+// cronAliases são os atalhos aceitos no lugar dos cinco campos.
+var cronAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronField descreve os limites de um campo da expressão.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse interpreta uma expressão cron.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	normalized := expr
+	if alias, ok := cronAliases[strings.ToLower(expr)]; ok {
+		normalized = alias
+	}
+	parts := strings.Fields(normalized)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day month weekday)", expr)
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+	// 7 é outra forma de escrever domingo.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		expr:   expr,
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		anyDom: parts[2] == "*", anyDow: parts[4] == "*",
+	}, nil
+}
+
+// parseField interpreta um campo (lista de itens separados por vírgula) e retorna os valores permitidos.
+func parseField(text string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepText, field.name)
+			}
+			step = n
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangeText == "*":
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = parseValue(lowText, field); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highText, field); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s", rangeText, field.name)
+			}
+		default:
+			value, err := parseValue(rangeText, field)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue interpreta um valor numérico dentro dos limites do campo.
+func parseValue(text string, field cronField) (int, error) {
+	value, err := strconv.Atoi(text)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("invalid %s %q (expected %d-%d)", field.name, text, field.min, field.max)
+	}
+	return value, nil
+}
+
+// String retorna a expressão original.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next retorna o primeiro horário da agenda depois de after, com precisão de minuto.
+// Retorna o instante zero se não houver nenhum horário nos próximos cinco anos (por exemplo, 30 de fevereiro).
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay aplica a regra usual do cron: se o dia do mês e o dia da semana forem restritos,
+// basta que um deles corresponda.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dowMatch
+	case s.anyDow:
+		return domMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler expõe a listagem e a execução manual das rotinas agendadas (rotas de administração).
+type Handler struct {
+	Scheduler *Scheduler
+}
+
+// ListJobs é um handler HTTP que lista as rotinas agendadas.
+// @Summary Lista as rotinas agendadas
+// @Description Retorna as rotinas em segundo plano, com a agenda (cron), a próxima execução nesta instância e a
+// @Description situação da última execução (compartilhada entre as instâncias). Apenas administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} JobInfo
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 500 "Internal Server Error"
+// @Router /admin/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	jobs, err := h.Scheduler.Jobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// RunJob é um handler HTTP que executa uma rotina imediatamente, fora da agenda.
+// @Summary Executa uma rotina agendada
+// @Description Inicia a rotina em segundo plano e responde sem esperar o fim; o resultado aparece em GET /admin/jobs.
+// @Description Se a rotina já estiver em execução (nesta ou em outra instância), responde 409. Apenas administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Nome da rotina"
+// @Success 202 "Execução iniciada"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 404 "Rotina não encontrada"
+// @Failure 409 "Rotina já em execução"
+// @Failure 500 "Internal Server Error"
+// @Router /admin/jobs/{name}/run [post]
+func (h *Handler) RunJob(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.Scheduler.Trigger(c.Request.Context(), name); err != nil {
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start job"})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": name, "status": StatusRunning})
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Situação da última execução de uma rotina (last_status).
+const (
+	StatusRunning   = "running"   // Em execução (ou a instância caiu durante a execução e a reserva ainda não venceu)
+	StatusSucceeded = "succeeded" // Terminou sem erro
+	StatusFailed    = "failed"    // Terminou com erro (veja last_error)
+)
+
+// @description Situação de uma rotina agendada, compartilhada entre as instâncias da aplicação
+// @type object
+type JobState struct {
+	Name            string     `json:"name" gorm:"primaryKey;size:100"`
+	LockedBy        string     `json:"locked_by,omitempty" gorm:"size:100"` // Instância que está executando a rotina
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`              // Fim da reserva; depois dele, outra instância pode executar
+	LastScheduledAt *time.Time `json:"last_scheduled_at"`                   // Horário agendado da última execução automática
+	LastStartedAt   *time.Time `json:"last_started_at"`
+	LastFinishedAt  *time.Time `json:"last_finished_at"`
+	LastStatus      string     `json:"last_status,omitempty" gorm:"size:20"` // running, succeeded ou failed
+	LastResult      string     `json:"last_result,omitempty" gorm:"type:text"`
+	LastError       string     `json:"last_error,omitempty" gorm:"type:text"`
+}
+
+// TableName define o nome da tabela das rotinas agendadas.
+func (JobState) TableName() string {
+	return "scheduler_jobs"
+}
+
+// Repository é uma interface que define o acesso à tabela das rotinas, usada como reserva (lease) entre as instâncias.
+type Repository interface {
+	Ensure(ctx context.Context, names []string) error                                                        // Cria as linhas das rotinas que ainda não existem
+	Acquire(ctx context.Context, name, owner string, slot *time.Time, now, until time.Time) (bool, error)    // Reserva a rotina para execução
+	Release(ctx context.Context, name, owner string, finishedAt time.Time, result string, cause error) error // Libera a reserva e grava o resultado
+	List(ctx context.Context) ([]JobState, error)                                                            // Lista a situação das rotinas
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório das rotinas agendadas.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Ensure cria as linhas das rotinas informadas, ignorando as que já existem.
+func (r *repository) Ensure(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	states := make([]JobState, len(names))
+	for i, name := range names {
+		states[i] = JobState{Name: name}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&states).Error
+}
+
+// Acquire reserva a rotina para owner até until, com uma atualização condicional: ela só acontece se nenhuma outra
+// instância tiver uma reserva válida. Para uma execução agendada (slot informado), a rotina também não pode ter
+// rodado nesse horário, o que impede que duas instâncias executem a mesma ocorrência uma depois da outra.
+// Retorna false se a rotina já estiver reservada ou se a ocorrência já tiver sido executada.
+func (r *repository) Acquire(ctx context.Context, name, owner string, slot *time.Time, now, until time.Time) (bool, error) {
+	changes := map[string]interface{}{
+		"locked_by":       owner,
+		"locked_until":    until,
+		"last_started_at": now,
+		"last_status":     StatusRunning,
+	}
+	query := r.db.WithContext(ctx).Model(&JobState{}).
+		Where("name = ? AND (locked_until IS NULL OR locked_until < ?)", name, now)
+	if slot != nil {
+		query = query.Where("(last_scheduled_at IS NULL OR last_scheduled_at < ?)", *slot)
+		changes["last_scheduled_at"] = *slot
+	}
+
+	result := query.Updates(changes)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release grava o resultado da execução e libera a reserva, se ela ainda for de owner.
+// Se a reserva venceu e outra instância assumiu a rotina, o resultado não é gravado.
+func (r *repository) Release(ctx context.Context, name, owner string, finishedAt time.Time, result string, cause error) error {
+	changes := map[string]interface{}{
+		"locked_by":        "",
+		"locked_until":     nil,
+		"last_finished_at": finishedAt,
+		"last_status":      StatusSucceeded,
+		"last_result":      result,
+		"last_error":       "",
+	}
+	if cause != nil {
+		changes["last_status"] = StatusFailed
+		changes["last_error"] = cause.Error()
+	}
+	return r.db.WithContext(ctx).Model(&JobState{}).Where("name = ? AND locked_by = ?", name, owner).Updates(changes).Error
+}
+
+// List retorna a situação de todas as rotinas, em ordem de nome.
+func (r *repository) List(ctx context.Context) ([]JobState, error) {
+	var states []JobState
+	if err := r.db.WithContext(ctx).Order("name").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
@@ -0,0 +1,287 @@
+// Package scheduler executa rotinas em segundo plano, dentro do próprio processo da API, em horários definidos por
+// expressões cron. Quando várias instâncias da aplicação rodam ao mesmo tempo, cada ocorrência de uma rotina é
+// executada por apenas uma delas: a execução depende de uma reserva (lease) gravada na tabela scheduler_jobs.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// ErrJobNotFound é retornado quando a rotina solicitada não está registrada.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobRunning é retornado quando a rotina já está em execução, nesta ou em outra instância.
+var ErrJobRunning = errors.New("job is already running")
+
+// Job é uma rotina executada pelo scheduler.
+type Job struct {
+	Name        string // Identificador da rotina (também usado na rota de execução manual)
+	Description string
+	Schedule    string // Expressão cron (veja Schedule); vazia, a rotina só roda manualmente
+	// Run executa a rotina e retorna um resumo do que foi feito, gravado em last_result.
+	// O contexto é cancelado quando a reserva vence (Config.LeaseTTL) ou no desligamento.
+	Run func(ctx context.Context) (string, error)
+}
+
+// Config reúne a configuração do scheduler.
+type Config struct {
+	Enabled  bool          // Executa as rotinas nos horários agendados; desativado, elas só rodam manualmente
+	LeaseTTL time.Duration // Tempo máximo de cada execução; depois dele, a reserva vence e outra instância pode executar
+	Owner    string        // Identificação desta instância na reserva
+}
+
+// DefaultConfig retorna a configuração padrão do scheduler, identificando a instância pelo host e pelo PID.
+func DefaultConfig() Config {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Config{
+		Enabled:  true,
+		LeaseTTL: 5 * time.Minute,
+		Owner:    fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}
+}
+
+// @description Rotina agendada, com a próxima execução e a situação da última
+// @type object
+type JobInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule,omitempty"` // Vazio: a rotina só roda manualmente
+	NextRunAt   *time.Time `json:"next_run_at"`        // Próxima execução agendada nesta instância; null se não houver
+	State       *JobState  `json:"state"`
+}
+
+// entry é uma rotina registrada, com a agenda interpretada e a próxima ocorrência.
+type entry struct {
+	job      Job
+	schedule *Schedule
+	next     time.Time
+}
+
+// Scheduler executa as rotinas registradas nos horários agendados e sob demanda.
+type Scheduler struct {
+	repo    Repository
+	config  Config
+	mu      sync.Mutex
+	entries []*entry
+	byName  map[string]*entry
+	ctx     context.Context // Contexto das execuções, cancelado no desligamento
+	stop    context.CancelFunc
+	running sync.WaitGroup
+}
+
+// New cria um novo Scheduler.
+func New(repo Repository, config Config) *Scheduler {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Scheduler{repo: repo, config: config, byName: make(map[string]*entry), ctx: ctx, stop: stop}
+}
+
+// Register registra as rotinas e cria as linhas delas na tabela scheduler_jobs.
+// Deve ser chamado antes de Run. Retorna um erro se uma agenda for inválida ou um nome se repetir.
+func (s *Scheduler) Register(ctx context.Context, jobs ...Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(jobs))
+	now := time.Now()
+	for _, job := range jobs {
+		if _, ok := s.byName[job.Name]; ok || job.Name == "" {
+			return fmt.Errorf("scheduler: job name %q is empty or already registered", job.Name)
+		}
+		e := &entry{job: job}
+		if job.Schedule != "" {
+			schedule, err := Parse(job.Schedule)
+			if err != nil {
+				return fmt.Errorf("scheduler: job %s: %w", job.Name, err)
+			}
+			e.schedule = schedule
+			e.next = schedule.Next(now)
+		}
+		s.entries = append(s.entries, e)
+		s.byName[job.Name] = e
+		names = append(names, job.Name)
+	}
+	return s.repo.Ensure(ctx, names)
+}
+
+// Run executa as rotinas nos horários agendados até que o contexto seja cancelado (se Config.Enabled).
+// No desligamento, as execuções em andamento, agendadas ou manuais, são canceladas e Run espera que terminem.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer func() {
+		s.stop()
+		s.running.Wait()
+	}()
+	if !s.config.Enabled {
+		<-ctx.Done()
+		return
+	}
+
+	for {
+		now := time.Now()
+		s.startDue(now, &s.running)
+
+		// A espera é limitada a um minuto para acompanhar mudanças no relógio do servidor.
+		wait := time.Minute
+		if next, ok := s.nextRun(); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// RunDue executa as rotinas com ocorrência agendada até now e espera que terminem.
+// É o mesmo passo executado por Run a cada ocorrência; serve para testes e execuções pontuais.
+func (s *Scheduler) RunDue(now time.Time) {
+	var wg sync.WaitGroup
+	s.startDue(now, &wg)
+	wg.Wait()
+}
+
+// startDue inicia, em segundo plano, as rotinas com ocorrência agendada até now.
+// Ocorrências perdidas (por exemplo, com o processo parado) não são repetidas: a próxima é calculada a partir de now.
+func (s *Scheduler) startDue(now time.Time, wg *sync.WaitGroup) {
+	s.mu.Lock()
+	var due []*entry
+	var slots []time.Time
+	for _, e := range s.entries {
+		if e.schedule == nil || e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		due = append(due, e)
+		slots = append(slots, e.next)
+		e.next = e.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for i, e := range due {
+		slot := slots[i]
+		startedAt := time.Now()
+		acquired, err := s.repo.Acquire(s.ctx, e.job.Name, s.config.Owner, &slot, startedAt, startedAt.Add(s.config.LeaseTTL))
+		if err != nil {
+			slog.ErrorContext(s.ctx, "scheduler: failed to acquire job", "job", e.job.Name, "err", err)
+			continue
+		}
+		if !acquired {
+			continue // Outra instância está executando ou já executou esta ocorrência
+		}
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.execute(e, false)
+		}(e)
+	}
+}
+
+// nextRun retorna a próxima ocorrência agendada entre todas as rotinas.
+func (s *Scheduler) nextRun() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, e := range s.entries {
+		if e.schedule != nil && !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+			next = e.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Trigger executa a rotina imediatamente, fora da agenda, em segundo plano.
+// A reserva é obtida antes de retornar: se a rotina já estiver em execução, retorna ErrJobRunning.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	s.mu.Lock()
+	e, ok := s.byName[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	startedAt := time.Now()
+	acquired, err := s.repo.Acquire(ctx, name, s.config.Owner, nil, startedAt, startedAt.Add(s.config.LeaseTTL))
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrJobRunning
+	}
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.execute(e, true)
+	}()
+	return nil
+}
+
+// execute roda a rotina já reservada, dentro de um span, e grava o resultado liberando a reserva.
+// Um pânico na rotina é tratado como erro, para que a reserva não fique presa até vencer.
+func (s *Scheduler) execute(e *entry, manual bool) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.LeaseTTL)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "scheduler.Run "+e.job.Name,
+		attribute.String("scheduler.job", e.job.Name),
+		attribute.Bool("scheduler.manual", manual),
+	)
+
+	result, err := func() (result string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		return e.job.Run(ctx)
+	}()
+	tracing.End(span, err)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler: job failed", "job", e.job.Name, "err", err)
+	} else {
+		slog.InfoContext(ctx, "scheduler: job finished", "job", e.job.Name, "result", result)
+	}
+	// O resultado é gravado mesmo que a execução tenha sido cancelada no desligamento.
+	if err := s.repo.Release(context.WithoutCancel(ctx), e.job.Name, s.config.Owner, time.Now(), result, err); err != nil {
+		slog.ErrorContext(ctx, "scheduler: failed to release job", "job", e.job.Name, "err", err)
+	}
+}
+
+// Jobs lista as rotinas registradas, na ordem de registro, com a próxima execução e a situação gravada no banco.
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobInfo, error) {
+	states, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*JobState, len(states))
+	for i := range states {
+		byName[states[i].Name] = &states[i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		info := JobInfo{Name: e.job.Name, Description: e.job.Description, Schedule: e.job.Schedule, State: byName[e.job.Name]}
+		if s.config.Enabled && e.schedule != nil && !e.next.IsZero() {
+			next := e.next
+			info.NextRunAt = &next
+		}
+		jobs = append(jobs, info)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,228 @@
+// Package seed gera dados fictícios, mas realistas, de clientes e entregas brasileiros para desenvolvimento e testes:
+// nomes, CPFs e CNPJs com dígitos verificadores válidos, telefones, endereços e coordenadas de cidades reais.
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"delivery-api/internal/clients"
+	"delivery-api/internal/deliveries"
+)
+
+// City é uma cidade usada nos endereços gerados, com as coordenadas do centro e os bairros sorteados.
+type City struct {
+	Name      string
+	State     string
+	Latitude  float64
+	Longitude float64
+	Districts []string
+}
+
+// Cities são as cidades em que as entregas são geradas.
+var Cities = []City{
+	{"São Paulo", "SP", -23.5505, -46.6333, []string{"Pinheiros", "Moema", "Vila Mariana", "Mooca", "Santana", "Tatuapé"}},
+	{"Rio de Janeiro", "RJ", -22.9068, -43.1729, []string{"Copacabana", "Tijuca", "Botafogo", "Barra da Tijuca", "Méier"}},
+	{"Belo Horizonte", "MG", -19.9167, -43.9345, []string{"Savassi", "Funcionários", "Pampulha", "Buritis", "Santa Efigênia"}},
+	{"Curitiba", "PR", -25.4284, -49.2733, []string{"Batel", "Água Verde", "Centro Cívico", "Portão", "Boqueirão"}},
+	{"Porto Alegre", "RS", -30.0346, -51.2177, []string{"Moinhos de Vento", "Cidade Baixa", "Menino Deus", "Petrópolis"}},
+	{"Salvador", "BA", -12.9777, -38.5016, []string{"Barra", "Pituba", "Rio Vermelho", "Itapuã", "Brotas"}},
+	{"Recife", "PE", -8.0476, -34.8770, []string{"Boa Viagem", "Casa Forte", "Graças", "Madalena", "Espinheiro"}},
+	{"Fortaleza", "CE", -3.7319, -38.5267, []string{"Aldeota", "Meireles", "Benfica", "Messejana", "Fátima"}},
+	{"Brasília", "DF", -15.7939, -47.8828, []string{"Asa Sul", "Asa Norte", "Lago Sul", "Sudoeste", "Guará"}},
+	{"Manaus", "AM", -3.1190, -60.0217, []string{"Adrianópolis", "Ponta Negra", "Flores", "Cidade Nova"}},
+	{"Goiânia", "GO", -16.6869, -49.2648, []string{"Setor Bueno", "Setor Marista", "Setor Oeste", "Jardim Goiás"}},
+	{"Belém", "PA", -1.4558, -48.4902, []string{"Nazaré", "Umarizal", "Batista Campos", "Marco"}},
+	{"Florianópolis", "SC", -27.5954, -48.5480, []string{"Trindade", "Lagoa da Conceição", "Itacorubi", "Estreito"}},
+	{"Campinas", "SP", -22.9099, -47.0626, []string{"Cambuí", "Taquaral", "Barão Geraldo", "Guanabara"}},
+}
+
+// Listas usadas para montar nomes, endereços e produtos.
+var (
+	firstNames = []string{
+		"Ana", "Beatriz", "Camila", "Fernanda", "Gabriela", "Juliana", "Larissa", "Mariana", "Patrícia", "Renata",
+		"André", "Bruno", "Carlos", "Daniel", "Eduardo", "Felipe", "Gustavo", "João", "Lucas", "Rafael", "Thiago",
+	}
+	lastNames = []string{
+		"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+		"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+	}
+	streets = []string{
+		"Rua das Flores", "Avenida Brasil", "Rua XV de Novembro", "Avenida Paulista", "Rua Sete de Setembro",
+		"Rua Tiradentes", "Avenida Getúlio Vargas", "Rua Dom Pedro II", "Rua Santos Dumont", "Avenida Atlântica",
+	}
+	complements = []string{"", "", "", "Apto 12", "Apto 304", "Casa 2", "Bloco B", "Sala 1101", "Fundos"}
+	products    = []string{
+		"Notebook", "Smartphone", "Cafeteira", "Livros", "Tênis", "Fone de ouvido", "Cadeira de escritório",
+		"Monitor", "Kit de panelas", "Bicicleta", "Roupas", "Brinquedos", "Ração para pets", "Ferramentas",
+	}
+	orderStatuses = []string{
+		deliveries.OrderStatusPending, deliveries.OrderStatusPending, deliveries.OrderStatusShipped,
+		deliveries.OrderStatusDelivered, deliveries.OrderStatusDelivered, deliveries.OrderStatusCanceled,
+	}
+	emailDomains = []string{"gmail.com", "hotmail.com", "outlook.com", "yahoo.com.br", "uol.com.br"}
+)
+
+// Generator gera os dados fictícios. Com a mesma semente, gera sempre os mesmos dados.
+type Generator struct {
+	rnd *rand.Rand
+}
+
+// NewGenerator cria um Generator a partir da semente informada.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// CPF gera um CPF válido no formato XXX.XXX.XXX-XX.
+func (g *Generator) CPF() string {
+	digits := g.digits(9)
+	digits = append(digits, checkDigit(digits, 10))
+	digits = append(digits, checkDigit(digits, 11))
+	return fmt.Sprintf("%d%d%d.%d%d%d.%d%d%d-%d%d", toAny(digits)...)
+}
+
+// CNPJ gera um CNPJ válido de matriz (filial 0001) no formato XX.XXX.XXX/0001-XX.
+func (g *Generator) CNPJ() string {
+	digits := append(g.digits(8), 0, 0, 0, 1)
+	digits = append(digits, cnpjCheckDigit(digits))
+	digits = append(digits, cnpjCheckDigit(digits))
+	return fmt.Sprintf("%d%d.%d%d%d.%d%d%d/%d%d%d%d-%d%d", toAny(digits)...)
+}
+
+// Client gera um cliente maior de idade com CPF, CNPJ, e-mail e telefone válidos.
+func (g *Generator) Client() clients.Client {
+	first, last := g.pick(firstNames), g.pick(lastNames)
+	name := fmt.Sprintf("%s %s %s", first, g.pick(lastNames), last)
+	birthDate := time.Now().AddDate(-18-g.rnd.Intn(50), 0, -g.rnd.Intn(365))
+	return clients.Client{
+		Name:      name,
+		CPF:       g.CPF(),
+		CNPJ:      g.CNPJ(),
+		BirthDate: birthDate.Format("2006-01-02"),
+		Email:     fmt.Sprintf("%s.%s%d@%s", asciiLower(first), asciiLower(last), g.rnd.Intn(1000), g.pick(emailDomains)),
+		Phone:     fmt.Sprintf("(%d) 9%04d-%04d", 11+g.rnd.Intn(89), g.rnd.Intn(10000), g.rnd.Intn(10000)),
+	}
+}
+
+// Delivery gera uma entrega para o cliente, em uma cidade sorteada e com coordenadas próximas do centro dela
+// (até cerca de 5 km).
+func (g *Generator) Delivery(client *clients.Client) deliveries.Delivery {
+	city := Cities[g.rnd.Intn(len(Cities))]
+	return deliveries.Delivery{
+		ClientCPF:   client.CPF,
+		ClientName:  client.Name,
+		TestName:    g.pick(products),
+		Weight:      float64(1+g.rnd.Intn(3000)) / 100,
+		Logradouro:  g.pick(streets),
+		Numero:      fmt.Sprint(1 + g.rnd.Intn(2500)),
+		Bairro:      g.pick(city.Districts),
+		Complemento: g.pick(complements),
+		Cidade:      city.Name,
+		Estado:      city.State,
+		Pais:        "Brasil",
+		Latitude:    roundCoordinate(city.Latitude + (g.rnd.Float64()-0.5)*0.09),
+		Longitude:   roundCoordinate(city.Longitude + (g.rnd.Float64()-0.5)*0.09),
+		OrderStatus: g.pick(orderStatuses),
+	}
+}
+
+// digits sorteia n dígitos decimais.
+func (g *Generator) digits(n int) []int {
+	digits := make([]int, n)
+	for i := range digits {
+		digits[i] = g.rnd.Intn(10)
+	}
+	return digits
+}
+
+// pick sorteia um item da lista.
+func (g *Generator) pick(values []string) string {
+	return values[g.rnd.Intn(len(values))]
+}
+
+// checkDigit calcula um dígito verificador do CPF: os pesos começam em firstWeight e decrescem até 2.
+func checkDigit(digits []int, firstWeight int) int {
+	sum := 0
+	for i, d := range digits {
+		sum += d * (firstWeight - i)
+	}
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+	return 0
+}
+
+// cnpjCheckDigit calcula o próximo dígito verificador do CNPJ: pesos de 2 a 9, da direita para a esquerda.
+func cnpjCheckDigit(digits []int) int {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += digits[i] * weight
+		if weight++; weight > 9 {
+			weight = 2
+		}
+	}
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+	return 0
+}
+
+// ValidCPF verifica os dígitos verificadores de um CPF, com ou sem pontuação.
+func ValidCPF(cpf string) bool {
+	digits := onlyDigits(cpf)
+	if len(digits) != 11 || allEqual(digits) {
+		return false
+	}
+	return checkDigit(digits[:9], 10) == digits[9] && checkDigit(digits[:10], 11) == digits[10]
+}
+
+// ValidCNPJ verifica os dígitos verificadores de um CNPJ, com ou sem pontuação.
+func ValidCNPJ(cnpj string) bool {
+	digits := onlyDigits(cnpj)
+	if len(digits) != 14 || allEqual(digits) {
+		return false
+	}
+	return cnpjCheckDigit(digits[:12]) == digits[12] && cnpjCheckDigit(digits[:13]) == digits[13]
+}
+
+// onlyDigits retorna os dígitos do texto, ignorando a pontuação.
+func onlyDigits(value string) []int {
+	var digits []int
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	return digits
+}
+
+// allEqual indica se todos os dígitos são iguais (111.111.111-11 passa no cálculo, mas não é um documento válido).
+func allEqual(digits []int) bool {
+	for _, d := range digits[1:] {
+		if d != digits[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// toAny converte os dígitos para os argumentos do fmt.Sprintf.
+func toAny(digits []int) []interface{} {
+	values := make([]interface{}, len(digits))
+	for i, d := range digits {
+		values[i] = d
+	}
+	return values
+}
+
+// asciiLower remove os acentos e converte o nome para minúsculas, para uso no e-mail.
+func asciiLower(name string) string {
+	return strings.ToLower(strings.NewReplacer("á", "a", "ã", "a", "â", "a", "é", "e", "ê", "e", "í", "i", "ó", "o", "ô", "o", "ú", "u", "ç", "c").Replace(name))
+}
+
+// roundCoordinate arredonda a coordenada para 6 casas decimais (cerca de 10 cm).
+func roundCoordinate(value float64) float64 {
+	return float64(int64(value*1e6)) / 1e6
+}
@@ -0,0 +1,170 @@
+package analytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/analytics"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/proofs"
+)
+
+// setup cria o banco em memória com as tabelas de entregas, da outbox e dos comprovantes e o router com a rota de indicadores.
+func setup(t *testing.T) (*gorm.DB, *gin.Engine) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&deliveries.Delivery{}, &deliveries.Package{}, &events.Event{}, &proofs.Proof{}, &analytics.DailySummary{}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := analytics.Handler{Service: analytics.NewService(analytics.NewRepository(db))}
+	router.GET("/analytics/deliveries", handler.GetDeliveryAnalytics)
+	router.GET("/analytics/daily/:day", handler.GetDailySummary)
+	return db, router
+}
+
+// day retorna a data informada às 10h, no fuso horário do servidor.
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 10, 0, 0, 0, time.Local)
+}
+
+// seed cria a entrega pelo repositório, muda o status (se informado) e ajusta os horários de criação
+// e de entrega para os dias do teste. As entregas marcadas como entregues ganham um comprovante.
+func seed(t *testing.T, db *gorm.DB, cpf, cidade, estado string, weight float64, created time.Time, status string, delivered time.Time) {
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+	delivery, err := repo.CreateDelivery(ctx, &deliveries.Delivery{
+		ClientCPF: cpf, ClientName: "Cliente " + cpf, TestName: "Pedido", Weight: weight,
+		Logradouro: "Rua A", Numero: "1", Bairro: "Centro", Cidade: cidade, Estado: estado, Pais: "Brasil",
+		OrderStatus: deliveries.OrderStatusPending,
+	})
+	require.NoError(t, err)
+	if status == deliveries.OrderStatusDelivered {
+		require.NoError(t, db.Create(&proofs.Proof{DeliveryID: delivery.ID, RecipientName: "Cliente", RecipientDocument: cpf,
+			SignatureKey: "proofs/signature.png", SignatureType: "image/png", SignatureSHA256: "-", DeliveredAt: delivered}).Error)
+	}
+	if status != "" {
+		require.NoError(t, repo.UpdateOrderStatus(ctx, delivery.ID, status, delivery.Version))
+	}
+	times := map[string]interface{}{"created_at": created}
+	if status == deliveries.OrderStatusDelivered {
+		times["delivered_at"] = delivered
+	}
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id = ?", delivery.ID).UpdateColumns(times).Error)
+}
+
+// get chama a rota de indicadores e decodifica o relatório.
+func get(t *testing.T, router *gin.Engine, query string) (int, analytics.DeliveryReport) {
+	req, _ := http.NewRequest("GET", "/analytics/deliveries?"+query, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var report analytics.DeliveryReport
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	}
+	return w.Code, report
+}
+
+// TestDeliveryAnalytics testa os agrupamentos, a taxa de cancelamento, o tempo médio até a entrega,
+// o ranking de clientes e o filtro de período.
+func TestDeliveryAnalytics(t *testing.T) {
+	db, router := setup(t)
+	seed(t, db, "11111111111", "São Paulo", "SP", 2, day(2024, 5, 6), deliveries.OrderStatusDelivered, day(2024, 5, 7))
+	seed(t, db, "11111111111", "São Paulo", "SP", 3, day(2024, 5, 8), deliveries.OrderStatusCanceled, day(2024, 5, 8))
+	seed(t, db, "22222222222", "Recife", "PE", 5, day(2024, 5, 13), "", time.Time{})
+	seed(t, db, "22222222222", "Recife", "PE", 7, day(2024, 4, 1), "", time.Time{}) // Fora do período
+
+	code, report := get(t, router, "from=2024-05-06&to=2024-05-13&top=1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2024-05-06", report.From)
+	assert.Equal(t, "2024-05-13", report.To)
+	assert.Equal(t, int64(3), report.Deliveries)
+	assert.Equal(t, 10.0, report.TotalWeight)
+	assert.InDelta(t, 1.0/3, report.CancellationRate, 1e-9)
+	require.NotNil(t, report.AvgDeliveryHours)
+	assert.InDelta(t, 24, *report.AvgDeliveryHours, 0.01)
+	assert.Equal(t, []analytics.Group{{Key: "SP", Deliveries: 2, TotalWeight: 5}, {Key: "PE", Deliveries: 1, TotalWeight: 5}}, report.ByEstado)
+	assert.Equal(t, []analytics.CityGroup{
+		{Cidade: "São Paulo", Estado: "SP", Deliveries: 2, TotalWeight: 5},
+		{Cidade: "Recife", Estado: "PE", Deliveries: 1, TotalWeight: 5},
+	}, report.ByCidade)
+	assert.Len(t, report.ByStatus, 3)
+	assert.Equal(t, []analytics.PeriodGroup{
+		{Period: "2024-05-06", Deliveries: 1, TotalWeight: 2},
+		{Period: "2024-05-08", Deliveries: 1, TotalWeight: 3},
+		{Period: "2024-05-13", Deliveries: 1, TotalWeight: 5},
+	}, report.ByPeriod)
+	assert.Equal(t, []analytics.ClientVolume{{ClientCPF: "11111111111", ClientName: "Cliente 11111111111", Deliveries: 2, TotalWeight: 5}}, report.TopClients)
+
+	// Por semana, os grupos são identificados pela segunda-feira.
+	code, report = get(t, router, "from=2024-05-06&to=2024-05-13&interval=week")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []analytics.PeriodGroup{
+		{Period: "2024-05-06", Deliveries: 2, TotalWeight: 5},
+		{Period: "2024-05-13", Deliveries: 1, TotalWeight: 5},
+	}, report.ByPeriod)
+
+	// Sem período, todas as entregas entram; sem entregas concluídas no período, o tempo médio é nulo.
+	_, report = get(t, router, "")
+	assert.Equal(t, int64(4), report.Deliveries)
+	_, report = get(t, router, "from=2024-05-13")
+	assert.Nil(t, report.AvgDeliveryHours)
+}
+
+// TestDeliveryAnalytics_InvalidFilters testa a validação dos filtros.
+func TestDeliveryAnalytics_InvalidFilters(t *testing.T) {
+	_, router := setup(t)
+	for _, query := range []string{"from=06/05/2024", "to=2024-13-01", "from=2024-05-10&to=2024-05-01", "interval=month", "top=0", "top=101"} {
+		code, _ := get(t, router, query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+// TestDailySummary testa a gravação do resumo de um dia, a substituição ao gerar de novo e a consulta pela rota.
+func TestDailySummary(t *testing.T) {
+	db, router := setup(t)
+	service := analytics.NewService(analytics.NewRepository(db))
+	ctx := context.Background()
+	seed(t, db, "11111111111", "São Paulo", "SP", 2, day(2024, 5, 6), "", time.Time{})
+	seed(t, db, "22222222222", "Recife", "PE", 5, day(2024, 5, 7), "", time.Time{})
+
+	summary, err := service.WriteDailySummary(ctx, day(2024, 5, 6))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-06", summary.Day)
+	assert.Equal(t, int64(1), summary.Report.Deliveries)
+
+	seed(t, db, "22222222222", "Recife", "PE", 5, day(2024, 5, 6), "", time.Time{})
+	_, err = service.WriteDailySummary(ctx, day(2024, 5, 6))
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/analytics/daily/2024-05-06", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored analytics.DailySummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.Equal(t, int64(2), stored.Report.Deliveries)
+	assert.Equal(t, 7.0, stored.Report.TotalWeight)
+	assert.Equal(t, "2024-05-06", stored.Report.From)
+
+	for path, code := range map[string]int{"/analytics/daily/2024-05-07": http.StatusNotFound, "/analytics/daily/06-05-2024": http.StatusBadRequest} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, path)
+	}
+}
@@ -0,0 +1,53 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/blob"
+)
+
+// TestLocalStore testa a gravação, a leitura, a substituição e a remoção de arquivos no diretório local.
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := blob.NewLocalStore(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "proofs/1/signature.png", strings.NewReader("first")))
+	require.NoError(t, store.Put(ctx, "proofs/1/signature.png", strings.NewReader("second")))
+	reader, err := store.Open(ctx, "proofs/1/signature.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, reader.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	// Nenhum arquivo temporário fica para trás.
+	entries, err := os.ReadDir(filepath.Join(dir, "proofs", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "proofs/1/signature.png"))
+	require.NoError(t, store.Delete(ctx, "proofs/1/signature.png"))
+	_, err = store.Open(ctx, "proofs/1/signature.png")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+// TestLocalStore_InvalidKeys testa se as chaves que apontariam para fora do diretório são rejeitadas.
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "proofs/../../secret", "/etc/passwd", "proofs//1", `proofs\1`, "proofs/ 1"} {
+		err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.ErrorIs(t, err, blob.ErrInvalidKey, key)
+	}
+}
@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -20,49 +21,60 @@ type MockService struct {
 }
 
 // CreateClient simula a criação de um cliente.
-func (m *MockService) CreateClient(client *clients.Client) (*clients.Client, error) {
+func (m *MockService) CreateClient(ctx context.Context, client *clients.Client) (*clients.Client, error) {
 	args := m.Called(client)
 	return args.Get(0).(*clients.Client), args.Error(1)
 }
 
 // GetClients simula a busca de todos os clientes.
-func (m *MockService) GetClients() ([]clients.Client, error) {
-	args := m.Called()
+func (m *MockService) GetClients(ctx context.Context, filter clients.Filter) ([]clients.Client, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]clients.Client), args.Error(1)
 }
 
+// ExportClients simula a exportação de clientes, chamando fn para cada cliente configurado no mock.
+func (m *MockService) ExportClients(ctx context.Context, filter clients.Filter, fn func(*clients.Client) error) error {
+	args := m.Called(filter, fn)
+	for _, client := range args.Get(0).([]clients.Client) {
+		if err := fn(&client); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 // GetClientByID simula a busca de um cliente por ID.
-func (m *MockService) GetClientByID(id uint) (*clients.Client, error) {
+func (m *MockService) GetClientByID(ctx context.Context, id uint) (*clients.Client, error) {
 	args := m.Called(id)
 	return args.Get(0).(*clients.Client), args.Error(1)
 }
 
 // UpdateClient simula a atualização de um cliente.
-func (m *MockService) UpdateClient(id uint, client *clients.Client) (*clients.Client, error) {
+func (m *MockService) UpdateClient(ctx context.Context, id uint, client *clients.Client) (*clients.Client, error) {
 	args := m.Called(id, client)
 	return args.Get(0).(*clients.Client), args.Error(1)
 }
 
 // DeleteClient simula a exclusão de um cliente.
-func (m *MockService) DeleteClient(id uint) error {
-	args := m.Called(id)
+func (m *MockService) DeleteClient(ctx context.Context, id uint, version uint) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
 
 // GetClientByCPF simula a busca de um cliente por CPF.
-func (m *MockService) GetClientByCPF(cpf string) (*clients.Client, error) {
+func (m *MockService) GetClientByCPF(ctx context.Context, cpf string) (*clients.Client, error) {
 	args := m.Called(cpf)
 	return args.Get(0).(*clients.Client), args.Error(1)
 }
 
 // GetClientByName simula a busca de clientes por nome.
-func (m *MockService) GetClientByName(name string) ([]clients.Client, error) {
+func (m *MockService) GetClientByName(ctx context.Context, name string) ([]clients.Client, error) {
 	args := m.Called(name)
 	return args.Get(0).([]clients.Client), args.Error(1)
 }
 
 // GetTotalClients simula a obtenção da contagem total de clientes.
-func (m *MockService) GetTotalClients() (int64, error) {
+func (m *MockService) GetTotalClients(ctx context.Context) (int64, error) {
 	args := m.Called()
 	return args.Get(0).(int64), args.Error(1)
 }
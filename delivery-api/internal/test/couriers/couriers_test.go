@@ -0,0 +1,195 @@
+package couriers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/proofs"
+	"delivery-api/internal/test/fixtures"
+)
+
+// setup cria o banco em memória (veja fixtures.OpenDB) e os serviços das entregas e dos entregadores.
+func setup(t *testing.T) (*gorm.DB, deliveries.Service, couriers.Service) {
+	db := fixtures.OpenDB(t)
+	return db, deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)), couriers.NewService(couriers.NewRepository(db))
+}
+
+// createDelivery cria uma entrega pendente com o peso e o estado de destino informados.
+func createDelivery(t *testing.T, service deliveries.Service, weight float64, estado string) *deliveries.Delivery {
+	input := fixtures.NewDelivery()
+	input.Weight, input.Estado = weight, estado
+	delivery, err := service.CreateDelivery(context.Background(), input)
+	require.NoError(t, err)
+	return delivery
+}
+
+// createCourier cadastra um entregador de moto com a capacidade informada.
+func createCourier(t *testing.T, service couriers.Service, name string, capacity float64) *couriers.Courier {
+	courier, err := service.CreateCourier(context.Background(), &couriers.Courier{
+		Name: name, VehicleType: couriers.VehicleMotorcycle, Capacity: capacity, HomeCity: "Recife",
+	})
+	require.NoError(t, err)
+	return courier
+}
+
+// TestAssignDelivery testa a conferência da capacidade, a troca de entregador, a retirada e o registro
+// das atribuições no histórico da entrega.
+func TestAssignDelivery(t *testing.T) {
+	db, deliveryService, courierService := setup(t)
+	ctx := context.Background()
+	first := createCourier(t, courierService, "Ana", 10)
+	second := createCourier(t, courierService, "Bruno", 5)
+	light := createDelivery(t, deliveryService, 4, "PE")
+	heavy := createDelivery(t, deliveryService, 7, "PE")
+
+	assigned, err := courierService.AssignDelivery(ctx, first.ID, light.ID)
+	require.NoError(t, err)
+	require.NotNil(t, assigned.CourierID)
+	assert.Equal(t, first.ID, *assigned.CourierID)
+	assert.NotNil(t, assigned.AssignedAt)
+	assert.Equal(t, light.Version+1, assigned.Version)
+
+	// 4 + 7 passa da capacidade de 10.
+	_, err = courierService.AssignDelivery(ctx, first.ID, heavy.ID)
+	assert.ErrorIs(t, err, couriers.ErrCapacityExceeded)
+
+	// A capacidade não pode ficar abaixo do que já foi atribuído.
+	_, err = courierService.UpdateCourier(ctx, first.ID, &couriers.Courier{
+		Name: "Ana", VehicleType: couriers.VehicleBicycle, Capacity: 3, HomeCity: "Recife",
+	})
+	assert.ErrorIs(t, err, couriers.ErrCapacityExceeded)
+
+	// A entrega passa para o segundo entregador, liberando a capacidade do primeiro.
+	_, err = courierService.AssignDelivery(ctx, second.ID, light.ID)
+	require.NoError(t, err)
+	_, err = courierService.AssignDelivery(ctx, first.ID, heavy.ID)
+	require.NoError(t, err)
+
+	_, err = courierService.UnassignDelivery(ctx, first.ID, light.ID)
+	assert.ErrorIs(t, err, couriers.ErrNotAssigned)
+	assert.ErrorIs(t, courierService.DeleteCourier(ctx, second.ID), couriers.ErrCourierBusy)
+	unassigned, err := courierService.UnassignDelivery(ctx, second.ID, light.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unassigned.CourierID)
+	assert.Nil(t, unassigned.AssignedAt)
+	require.NoError(t, courierService.DeleteCourier(ctx, second.ID))
+
+	// Entregas canceladas não podem ser atribuídas.
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id = ?", light.ID).
+		UpdateColumn("order_status", deliveries.OrderStatusCanceled).Error)
+	_, err = courierService.AssignDelivery(ctx, first.ID, light.ID)
+	assert.ErrorIs(t, err, couriers.ErrNotAssignable)
+
+	history, err := deliveryService.GetDeliveryHistory(ctx, light.ID)
+	require.NoError(t, err)
+	var types []string
+	for _, entry := range history {
+		types = append(types, entry.Type)
+	}
+	assert.Equal(t, []string{events.DeliveryCreated, events.DeliveryAssigned, events.DeliveryAssigned, events.DeliveryUnassigned}, types)
+	var reassignment deliveries.Assignment
+	require.NoError(t, json.Unmarshal(history[2].Data, &reassignment))
+	require.NotNil(t, reassignment.PreviousCourierID)
+	assert.Equal(t, first.ID, *reassignment.PreviousCourierID)
+}
+
+// TestGetWorkload testa a ordem das entregas do dia: as em andamento pelo prazo, seguidas das concluídas hoje.
+func TestGetWorkload(t *testing.T) {
+	db, deliveryService, courierService := setup(t)
+	ctx := context.Background()
+	courier := createCourier(t, courierService, "Ana", 100)
+	north := createDelivery(t, deliveryService, 2, "AM") // Prazo de 10 dias
+	south := createDelivery(t, deliveryService, 3, "SP") // Prazo de 3 dias
+	done := createDelivery(t, deliveryService, 5, "PE")
+	other := createDelivery(t, deliveryService, 1, "SP") // Não atribuída
+	for _, delivery := range []*deliveries.Delivery{north, south, done} {
+		_, err := courierService.AssignDelivery(ctx, courier.ID, delivery.ID)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Create(&proofs.Proof{DeliveryID: done.ID, RecipientName: "Maria", RecipientDocument: "1",
+		SignatureKey: "k", SignatureType: "image/png", SignatureSHA256: "x", DeliveredAt: done.CreatedAt}).Error)
+	require.NoError(t, deliveryService.UpdateOrderStatus(ctx, done.ID, deliveries.OrderStatusDelivered, 0))
+
+	workload, err := courierService.GetWorkload(ctx, courier.ID)
+	require.NoError(t, err)
+	var ids []uint
+	for _, delivery := range workload.Deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	assert.Equal(t, []uint{south.ID, north.ID, done.ID}, ids)
+	assert.NotContains(t, ids, other.ID)
+	assert.Equal(t, 5.0, workload.AssignedWeight)
+	assert.Equal(t, 95.0, workload.Available)
+
+	_, err = courierService.GetWorkload(ctx, courier.ID+100)
+	assert.ErrorIs(t, err, couriers.ErrCourierNotFound)
+}
+
+// TestHandler_AssignDelivery testa as respostas da rota de atribuição.
+func TestHandler_AssignDelivery(t *testing.T) {
+	_, deliveryService, courierService := setup(t)
+	courier := createCourier(t, courierService, "Ana", 5)
+	small := createDelivery(t, deliveryService, 2, "PE")
+	large := createDelivery(t, deliveryService, 8, "PE")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := couriers.Handler{Service: courierService}
+	router.POST("/couriers/:id/deliveries", handler.AssignDelivery)
+
+	for name, tc := range map[string]struct {
+		courierID uint
+		body      string
+		status    int
+	}{
+		"assigned":         {courier.ID, fmt.Sprintf(`{"delivery_id": %d}`, small.ID), http.StatusOK},
+		"over capacity":    {courier.ID, fmt.Sprintf(`{"delivery_id": %d}`, large.ID), http.StatusConflict},
+		"missing delivery": {courier.ID, `{"delivery_id": 999}`, http.StatusNotFound},
+		"missing courier":  {courier.ID + 100, fmt.Sprintf(`{"delivery_id": %d}`, small.ID), http.StatusNotFound},
+		"missing body":     {courier.ID, `{}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/couriers/%d/deliveries", tc.courierID), bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, name)
+	}
+}
+
+// TestAssignDelivery_ChargeableWeight testa se a capacidade é conferida pelo peso tarifado: uma caixa leve, mas
+// volumosa, ocupa a capacidade do peso cubado.
+func TestAssignDelivery_ChargeableWeight(t *testing.T) {
+	_, deliveryService, courierService := setup(t)
+	ctx := context.Background()
+	courier := createCourier(t, courierService, "Ana", 10)
+	bulky, err := deliveryService.CreateDelivery(ctx, &deliveries.Delivery{
+		ClientCPF: "12345678909", ClientName: "Cliente",
+		Logradouro: "Rua A", Numero: "1", Bairro: "Centro", Cidade: "Cidade", Estado: "PE", Pais: "Brasil",
+		OrderStatus: deliveries.OrderStatusPending,
+		Packages:    []deliveries.Package{{Description: "Travesseiros", Quantity: 1, Weight: 2, Length: 60, Width: 50, Height: 40}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 20.0, bulky.ChargeableWeight) // 60 x 50 x 40 / 6000
+
+	_, err = courierService.AssignDelivery(ctx, courier.ID, bulky.ID)
+	assert.ErrorIs(t, err, couriers.ErrCapacityExceeded)
+	light := createDelivery(t, deliveryService, 4, "PE")
+	_, err = courierService.AssignDelivery(ctx, courier.ID, light.ID)
+	require.NoError(t, err)
+	workload, err := courierService.GetWorkload(ctx, courier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, workload.AssignedWeight)
+}
@@ -1,11 +1,16 @@
 package deliveries_test
 
 import (
+	"context"
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,59 +25,159 @@ type MockService struct {
 }
 
 // CreateDelivery simula a criação de uma entrega.
-func (m *MockService) CreateDelivery(delivery *deliveries.Delivery) (*deliveries.Delivery, error) {
+func (m *MockService) CreateDelivery(ctx context.Context, delivery *deliveries.Delivery) (*deliveries.Delivery, error) {
 	args := m.Called(delivery)
 	return args.Get(0).(*deliveries.Delivery), args.Error(1)
 }
 
 // GetDeliveries simula a busca de todas as entregas.
-func (m *MockService) GetDeliveries() ([]deliveries.Delivery, error) {
-	args := m.Called()
+func (m *MockService) GetDeliveries(ctx context.Context, filter deliveries.Filter) ([]deliveries.Delivery, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]deliveries.Delivery), args.Error(1)
 }
 
+// ExportDeliveries simula a exportação de entregas, chamando fn para cada entrega configurada no mock.
+func (m *MockService) ExportDeliveries(ctx context.Context, filter deliveries.Filter, fn func(*deliveries.Delivery) error) error {
+	args := m.Called(filter, fn)
+	for _, delivery := range args.Get(0).([]deliveries.Delivery) {
+		if err := fn(&delivery); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 // GetDeliveryByID simula a busca de uma entrega por ID.
-func (m *MockService) GetDeliveryByID(id uint) (*deliveries.Delivery, error) {
+func (m *MockService) GetDeliveryByID(ctx context.Context, id uint) (*deliveries.Delivery, error) {
 	args := m.Called(id)
 	return args.Get(0).(*deliveries.Delivery), args.Error(1)
 }
 
 // UpdateDelivery simula a atualização de uma entrega.
-func (m *MockService) UpdateDelivery(id uint, delivery *deliveries.Delivery) (*deliveries.Delivery, error) {
+func (m *MockService) UpdateDelivery(ctx context.Context, id uint, delivery *deliveries.Delivery) (*deliveries.Delivery, error) {
 	args := m.Called(id, delivery)
 	return args.Get(0).(*deliveries.Delivery), args.Error(1)
 }
 
 // DeleteDelivery simula a exclusão de uma entrega.
-func (m *MockService) DeleteDelivery(id uint) error {
-	args := m.Called(id)
+func (m *MockService) DeleteDelivery(ctx context.Context, id uint, version uint) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
 
 // GetDeliveriesByCPF simula a busca de entregas por CPF.
-func (m *MockService) GetDeliveriesByCPF(cpf string) ([]deliveries.Delivery, error) {
+func (m *MockService) GetDeliveriesByCPF(ctx context.Context, cpf string) ([]deliveries.Delivery, error) {
 	args := m.Called(cpf)
 	return args.Get(0).([]deliveries.Delivery), args.Error(1)
 }
 
 // GetDeliveriesByClientName simula a busca de entregas por nome do cliente.
-func (m *MockService) GetDeliveriesByClientName(name string) ([]deliveries.Delivery, error) {
+func (m *MockService) GetDeliveriesByClientName(ctx context.Context, name string) ([]deliveries.Delivery, error) {
 	args := m.Called(name)
 	return args.Get(0).([]deliveries.Delivery), args.Error(1)
 }
 
 // GetDeliveriesByCity simula a busca de entregas por cidade.
-func (m *MockService) GetDeliveriesByCity(city string) ([]deliveries.Delivery, error) {
+func (m *MockService) GetDeliveriesByCity(ctx context.Context, city string) ([]deliveries.Delivery, error) {
 	args := m.Called(city)
 	return args.Get(0).([]deliveries.Delivery), args.Error(1)
 }
 
 // UpdateOrderStatus simula a atualização do status de uma entrega.
-func (m *MockService) UpdateOrderStatus(id uint, status string) error {
-	args := m.Called(id, status)
+func (m *MockService) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) error {
+	args := m.Called(id, status, version)
 	return args.Error(0)
 }
 
+// ImportDeliveries simula a importação de entregas em lote.
+func (m *MockService) ImportDeliveries(ctx context.Context, rows []deliveries.ImportRow, dryRun bool) (*deliveries.ImportReport, error) {
+	args := m.Called(rows, dryRun)
+	return args.Get(0).(*deliveries.ImportReport), args.Error(1)
+}
+
+// GetStatusChangesAfter simula a busca das mudanças de status para retomar o stream.
+func (m *MockService) GetStatusChangesAfter(ctx context.Context, afterID uint, filter deliveries.StreamFilter) (*deliveries.StatusReplay, error) {
+	args := m.Called(afterID, filter)
+	return args.Get(0).(*deliveries.StatusReplay), args.Error(1)
+}
+
+// CountDeliveriesByStatus simula a contagem das entregas de cada status.
+func (m *MockService) CountDeliveriesByStatus(ctx context.Context) (map[string]int64, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+// GetLateDeliveries simula a busca das entregas atrasadas.
+func (m *MockService) GetLateDeliveries(ctx context.Context, within time.Duration) ([]deliveries.Delivery, error) {
+	args := m.Called(within)
+	return args.Get(0).([]deliveries.Delivery), args.Error(1)
+}
+
+// ClusterDeliveries simula o agrupamento das entregas do mapa.
+func (m *MockService) ClusterDeliveries(ctx context.Context, filter deliveries.Filter, precision int) (*deliveries.ClusterCollection, error) {
+	args := m.Called(filter, precision)
+	return args.Get(0).(*deliveries.ClusterCollection), args.Error(1)
+}
+
+// FlagOverdueDeliveries simula a sinalização das entregas atrasadas.
+func (m *MockService) FlagOverdueDeliveries(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// CancelStalePending simula o cancelamento das entregas pendentes esquecidas.
+func (m *MockService) CancelStalePending(ctx context.Context, olderThan time.Duration, reason string) (int, error) {
+	args := m.Called(olderThan, reason)
+	return args.Int(0), args.Error(1)
+}
+
+// RecomputeZones simula o recálculo das zonas das entregas.
+func (m *MockService) RecomputeZones(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// RecomputeWeights simula o recálculo dos pesos das entregas.
+func (m *MockService) RecomputeWeights(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// GetDeliveryHistory simula a busca do histórico de uma entrega.
+func (m *MockService) GetDeliveryHistory(ctx context.Context, id uint) ([]deliveries.HistoryEntry, error) {
+	args := m.Called(id)
+	history, _ := args.Get(0).([]deliveries.HistoryEntry)
+	return history, args.Error(1)
+}
+
+// GetPackages simula a listagem dos volumes de uma entrega.
+func (m *MockService) GetPackages(ctx context.Context, deliveryID uint) ([]deliveries.Package, error) {
+	args := m.Called(deliveryID)
+	packages, _ := args.Get(0).([]deliveries.Package)
+	return packages, args.Error(1)
+}
+
+// AddPackage simula a inclusão de um volume na entrega.
+func (m *MockService) AddPackage(ctx context.Context, deliveryID uint, pkg *deliveries.Package, version uint) (*deliveries.Delivery, error) {
+	args := m.Called(deliveryID, pkg, version)
+	delivery, _ := args.Get(0).(*deliveries.Delivery)
+	return delivery, args.Error(1)
+}
+
+// UpdatePackage simula a alteração de um volume da entrega.
+func (m *MockService) UpdatePackage(ctx context.Context, deliveryID, packageID uint, pkg *deliveries.Package, version uint) (*deliveries.Delivery, error) {
+	args := m.Called(deliveryID, packageID, pkg, version)
+	delivery, _ := args.Get(0).(*deliveries.Delivery)
+	return delivery, args.Error(1)
+}
+
+// DeletePackage simula a remoção de um volume da entrega.
+func (m *MockService) DeletePackage(ctx context.Context, deliveryID, packageID uint, version uint) (*deliveries.Delivery, error) {
+	args := m.Called(deliveryID, packageID, version)
+	delivery, _ := args.Get(0).(*deliveries.Delivery)
+	return delivery, args.Error(1)
+}
+
 // setupRouter inicializa o router do Gin com o handler de entregas.
 func setupRouter(service deliveries.Service) *gin.Engine {
 	handler := deliveries.Handler{Service: service}
@@ -86,6 +191,8 @@ func setupRouter(service deliveries.Service) *gin.Engine {
 	router.GET("/deliveries/client/name/:name", handler.GetDeliveriesByClientName)
 	router.GET("/deliveries/city/:city", handler.GetDeliveriesByCity)
 	router.PATCH("/deliveries/:id/status", handler.UpdateOrderStatus)
+	router.POST("/deliveries/import", handler.ImportDeliveries)
+	router.GET("/deliveries/export", handler.ExportDeliveries)
 	return router
 }
 
@@ -109,7 +216,9 @@ func TestCreateDelivery_Success(t *testing.T) {
 		Pais:        "Brasil",
 		Latitude:    -23.5505,
 		Longitude:   -46.6333,
-		OrderStatus: "Pending",
+		OrderStatus: "Pendente",
+		// O JSON da entrega sempre traz o actual_weight (igual ao weight), que volta no corpo da requisição.
+		ActualWeight: 10.5,
 	}
 
 	// Configura o mock para retornar a entrega quando CreateDelivery for chamado
@@ -143,7 +252,7 @@ func TestCreateDelivery_Success(t *testing.T) {
 	assert.Equal(t, "Brasil", response.Pais)
 	assert.Equal(t, -23.5505, response.Latitude)
 	assert.Equal(t, -46.6333, response.Longitude)
-	assert.Equal(t, "Pending", response.OrderStatus)
+	assert.Equal(t, "Pendente", response.OrderStatus)
 
 	// Verifica se o método CreateDelivery foi chamado com a entrega correta
 	mockService.AssertCalled(t, "CreateDelivery", &delivery)
@@ -368,12 +477,13 @@ func TestUpdateOrderStatus_Success(t *testing.T) {
 	router := setupRouter(mockService)
 
 	// Configura o mock para retornar sucesso quando UpdateOrderStatus for chamado
-	mockService.On("UpdateOrderStatus", uint(1), "Shipped").Return(nil)
+	mockService.On("UpdateOrderStatus", uint(1), "Shipped", uint(1)).Return(nil)
 
-	// Cria a requisição PATCH para atualizar o status da entrega
+	// Cria a requisição PATCH para atualizar o status da entrega, com a versão lida (If-Match)
 	body, _ := json.Marshal(map[string]string{"status": "Shipped"})
 	req, _ := http.NewRequest("PATCH", "/deliveries/1/status", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
 
 	// Executa a requisição
 	w := httptest.NewRecorder()
@@ -383,5 +493,328 @@ func TestUpdateOrderStatus_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// Verifica se o método UpdateOrderStatus foi chamado com os parâmetros corretos
-	mockService.AssertCalled(t, "UpdateOrderStatus", uint(1), "Shipped")
-}
\ No newline at end of file
+	mockService.AssertCalled(t, "UpdateOrderStatus", uint(1), "Shipped", uint(1))
+}
+
+// TestUpdateDelivery_IgnoresPackages testa se a atualização não valida os volumes, o peso e o valor declarado, que
+// são ignorados (os volumes são alterados em /deliveries/{id}/packages).
+func TestUpdateDelivery_IgnoresPackages(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	updated := &deliveries.Delivery{ID: 1, OrderStatus: "Pendente", Weight: 10.5, Version: 2}
+	mockService.On("UpdateDelivery", uint(1), mock.Anything).Return(updated, nil)
+
+	// Peso zerado, valor declarado negativo e um volume inválido seriam recusados na criação
+	body, _ := json.Marshal(map[string]interface{}{
+		"client_cpf":     "123.456.789-00",
+		"order_status":   "Pendente",
+		"weight":         0,
+		"declared_value": -1,
+		"packages":       []map[string]interface{}{{"weight": -5}},
+	})
+	req, _ := http.NewRequest("PUT", "/deliveries/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se a entrega foi atualizada com a nova versão no ETag
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	mockService.AssertCalled(t, "UpdateDelivery", uint(1), mock.Anything)
+}
+
+// TestGetDeliveryByID_SetsETag testa se a busca por ID devolve a versão da entrega no cabeçalho ETag.
+func TestGetDeliveryByID_SetsETag(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Configura o mock para retornar uma entrega na versão 3
+	delivery := deliveries.Delivery{ID: 1, ClientName: "João Silva", Version: 3}
+	mockService.On("GetDeliveryByID", uint(1)).Return(&delivery, nil)
+
+	// Cria a requisição GET para buscar a entrega
+	req, _ := http.NewRequest("GET", "/deliveries/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 200 (OK) e se o ETag corresponde à versão
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+// TestUpdateOrderStatus_PreconditionFailed testa a atualização de status com um If-Match desatualizado.
+func TestUpdateOrderStatus_PreconditionFailed(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Configura o mock para simular que a entrega já está em outra versão
+	mockService.On("UpdateOrderStatus", uint(1), "Enviado", uint(2)).Return(deliveries.ErrVersionConflict)
+
+	// Cria a requisição PATCH com o cabeçalho If-Match da versão 2
+	body, _ := json.Marshal(map[string]string{"status": "Enviado"})
+	req, _ := http.NewRequest("PATCH", "/deliveries/1/status", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+
+	// Executa a requisição
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 412 (Precondition Failed)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertCalled(t, "UpdateOrderStatus", uint(1), "Enviado", uint(2))
+}
+
+// TestUpdateOrderStatus_ProofRequired testa a mudança para Entregue sem o comprovante de entrega registrado.
+func TestUpdateOrderStatus_ProofRequired(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Configura o mock para simular que a entrega ainda não tem comprovante
+	mockService.On("UpdateOrderStatus", uint(1), "Entregue", uint(0)).Return(deliveries.ErrProofRequired)
+
+	// If-Match: * dispensa a verificação de versão
+	body, _ := json.Marshal(map[string]string{"status": "Entregue"})
+	req, _ := http.NewRequest("PATCH", "/deliveries/1/status", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 409 (Conflict)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "proof of delivery is required")
+}
+
+// TestDeleteDelivery_InvalidIfMatch testa a exclusão com um cabeçalho If-Match mal formatado.
+func TestDeleteDelivery_InvalidIfMatch(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Cria a requisição DELETE com um If-Match sem aspas
+	req, _ := http.NewRequest("DELETE", "/deliveries/1", nil)
+	req.Header.Set("If-Match", "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 400 (Bad Request) e se o serviço não foi chamado
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "DeleteDelivery", mock.Anything, mock.Anything)
+}
+
+// TestWriteRoutes_IfMatchRequired testa se as rotas que alteram ou removem a entrega exigem o If-Match (428)
+// e recusam ETags fracas (412), sem chamar o serviço.
+func TestWriteRoutes_IfMatchRequired(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	for _, ifMatch := range []string{"", `W/"1"`} {
+		expected := http.StatusPreconditionRequired
+		if ifMatch != "" {
+			expected = http.StatusPreconditionFailed
+		}
+		for _, method := range []string{"PATCH", "DELETE"} {
+			path := "/deliveries/1"
+			if method == "PATCH" {
+				path = "/deliveries/1/status"
+			}
+			body, _ := json.Marshal(map[string]string{"status": "Enviado"})
+			req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, expected, w.Code, method+" "+ifMatch)
+		}
+	}
+	mockService.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "DeleteDelivery", mock.Anything, mock.Anything)
+}
+
+// TestImportDeliveries_DryRun testa a importação de um CSV com cabeçalho mapeado em modo dry-run.
+func TestImportDeliveries_DryRun(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// CSV separado por ponto e vírgula, com vírgula decimal e uma coluna renomeada ("CPF")
+	csv := "CPF;client_name;test_name;weight;logradouro;numero;bairro;complemento;cidade;estado;pais;latitude;longitude;order_status\n" +
+		"123.456.789-00;João Silva;Caixa;10,5;Rua das Flores;123;Centro;Apto 101;São Paulo;SP;Brasil;-23,5505;-46,6333;Pendente\n" +
+		"123.456.789-00;João Silva;Caixa;abc;Rua das Flores;123;Centro;Apto 101;São Paulo;SP;Brasil;-23,5505;-46,6333;Pendente\n"
+
+	// Verifica se o handler converteu as linhas corretamente antes de chamar o serviço
+	rowsMatch := mock.MatchedBy(func(rows []deliveries.ImportRow) bool {
+		return len(rows) == 2 &&
+			rows[0].Row == 2 && rows[0].ParseError == "" &&
+			rows[0].Delivery.ClientCPF == "123.456.789-00" && rows[0].Delivery.Weight == 10.5 &&
+			rows[1].Row == 3 && rows[1].ParseError != ""
+	})
+	report := &deliveries.ImportReport{
+		DryRun:   true,
+		Total:    2,
+		Accepted: []deliveries.ImportAccepted{{Row: 2}},
+		Rejected: []deliveries.ImportRejected{{Row: 3, Reason: "invalid number"}},
+	}
+	mockService.On("ImportDeliveries", rowsMatch, true).Return(report, nil)
+
+	// Cria a requisição POST com o CSV no corpo e o mapeamento da coluna "CPF"
+	req, _ := http.NewRequest("POST", `/deliveries/import?dry_run=true&mapping={"CPF":"client_cpf"}`, bytes.NewBufferString(csv))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 200 (OK) e se o relatório foi retornado
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response deliveries.ImportReport
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.True(t, response.DryRun)
+	assert.Equal(t, 3, response.Rejected[0].Row)
+	mockService.AssertExpectations(t)
+}
+
+// TestImportDeliveries_UnknownColumn testa a importação de um CSV com uma coluna desconhecida.
+func TestImportDeliveries_UnknownColumn(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Cria a requisição POST com um cabeçalho que não corresponde a nenhum campo
+	req, _ := http.NewRequest("POST", "/deliveries/import", bytes.NewBufferString("foo,bar\n1,2\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 400 (Bad Request) e se o serviço não foi chamado
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ImportDeliveries", mock.Anything, mock.Anything)
+}
+
+// TestExportDeliveries_CSV testa a exportação em CSV no formato do Excel em pt-BR.
+func TestExportDeliveries_CSV(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Configura o mock para exportar uma entrega filtrando pela cidade
+	deliveriesList := []deliveries.Delivery{{ID: 7, ClientName: "João Silva", Weight: 10.5, Cidade: "São Paulo", OrderStatus: "Pendente"}}
+	mockService.On("ExportDeliveries", deliveries.Filter{Cidade: "São Paulo"}, mock.Anything).Return(deliveriesList, nil)
+
+	// Cria a requisição GET para exportar as entregas
+	req, _ := http.NewRequest("GET", "/deliveries/export?format=csv&cidade=S%C3%A3o+Paulo", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica o status, o BOM, o separador e a vírgula decimal do peso
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "\xef\xbb\xbfid;client_cpf;"))
+	assert.Contains(t, body, "7;;João Silva;;10,5;")
+}
+
+// TestExportDeliveries_InvalidFormat testa a exportação com um formato não suportado.
+func TestExportDeliveries_InvalidFormat(t *testing.T) {
+	mockService := new(MockService)
+	router := setupRouter(mockService)
+
+	// Cria a requisição GET com um formato inválido
+	req, _ := http.NewRequest("GET", "/deliveries/export?format=xlsx", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Verifica se o status da resposta é 400 (Bad Request)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestStreamDeliveries_ReplayAndLive testa se o stream reenvia as mudanças perdidas a partir do Last-Event-ID
+// e depois envia as novas mudanças que atendem ao filtro, sem repetir as já reenviadas.
+func TestStreamDeliveries_ReplayAndLive(t *testing.T) {
+	mockService := new(MockService)
+	broker := deliveries.NewBroker()
+	handler := deliveries.Handler{Service: mockService, Broker: broker}
+	router := gin.New()
+	router.GET("/deliveries/stream", handler.StreamDeliveries)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	filter := deliveries.StreamFilter{ClientCPF: "12345678900"}
+	delivery := &deliveries.Delivery{ID: 1, ClientCPF: "12345678900", OrderStatus: "Enviado"}
+	missed := deliveries.StatusEvent{ID: 6, Change: deliveries.StatusChange{Delivery: delivery, PreviousStatus: "Pendente"}}
+	mockService.On("GetStatusChangesAfter", uint(5), filter).Return(&deliveries.StatusReplay{Events: []deliveries.StatusEvent{missed}, LastID: 6}, nil)
+
+	// Conecta ao stream informando o último evento recebido
+	req, _ := http.NewRequest("GET", server.URL+"/deliveries/stream?client_cpf=12345678900", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// readIDs lê o stream até receber a quantidade de eventos informada e retorna os ids recebidos
+	reader := bufio.NewReader(resp.Body)
+	readIDs := func(count int) []string {
+		var ids []string
+		for len(ids) < count {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("stream closed: %v", err)
+			}
+			if strings.HasPrefix(line, "id: ") {
+				ids = append(ids, strings.TrimSpace(strings.TrimPrefix(line, "id: ")))
+			}
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"6"}, readIDs(1))
+
+	// O evento repetido e o de outro cliente são ignorados; o novo evento é enviado
+	other := &deliveries.Delivery{ID: 2, ClientCPF: "99999999999", OrderStatus: "Entregue"}
+	broker.Broadcast(missed)
+	broker.Broadcast(deliveries.StatusEvent{ID: 7, Change: deliveries.StatusChange{Delivery: other}})
+	broker.Broadcast(deliveries.StatusEvent{ID: 8, Change: deliveries.StatusChange{Delivery: delivery, PreviousStatus: "Enviado"}})
+	assert.Equal(t, []string{"8"}, readIDs(1))
+}
+
+// TestStreamDeliveries_TruncatedReplay testa se, quando o reenvio atinge o limite, o stream envia o id da última
+// mudança lida e é encerrado, para que o cliente reconecte a partir dele.
+func TestStreamDeliveries_TruncatedReplay(t *testing.T) {
+	mockService := new(MockService)
+	handler := deliveries.Handler{Service: mockService, Broker: deliveries.NewBroker()}
+	router := gin.New()
+	router.GET("/deliveries/stream", handler.StreamDeliveries)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	delivery := &deliveries.Delivery{ID: 1, OrderStatus: "Enviado"}
+	missed := deliveries.StatusEvent{ID: 6, Change: deliveries.StatusChange{Delivery: delivery, PreviousStatus: "Pendente"}}
+	replay := &deliveries.StatusReplay{Events: []deliveries.StatusEvent{missed}, LastID: 1005, Truncated: true}
+	mockService.On("GetStatusChangesAfter", uint(5), deliveries.StreamFilter{}).Return(replay, nil)
+
+	req, _ := http.NewRequest("GET", server.URL+"/deliveries/stream", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// O corpo termina (a conexão é encerrada) depois do id da última mudança lida
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "id: 6\nevent: status_changed\n")
+	assert.True(t, strings.HasSuffix(string(body), "id: 1005\n\n"))
+}
+
+// TestBroker_Close testa se Close encerra as inscrições abertas e as que forem feitas depois dele.
+func TestBroker_Close(t *testing.T) {
+	broker := deliveries.NewBroker()
+	open := broker.Subscribe(deliveries.StreamFilter{})
+
+	broker.Close()
+	_, ok := <-open.Events
+	assert.False(t, ok)
+
+	late := broker.Subscribe(deliveries.StreamFilter{})
+	_, ok = <-late.Events
+	assert.False(t, ok)
+	broker.Unsubscribe(late)
+}
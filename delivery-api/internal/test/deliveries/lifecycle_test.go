@@ -0,0 +1,276 @@
+package deliveries_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/proofs"
+	"delivery-api/internal/test/fixtures"
+)
+
+// newLifecycleDelivery monta uma entrega válida (veja fixtures.NewDelivery) para o estado e o nível de serviço informados.
+func newLifecycleDelivery(estado, serviceLevel string) *deliveries.Delivery {
+	delivery := fixtures.NewDelivery()
+	delivery.Estado, delivery.ServiceLevel = estado, serviceLevel
+	return delivery
+}
+
+// TestRepository_LifecycleTimestamps testa o prazo calculado na criação e os horários gravados
+// a cada mudança de status, inclusive os enviados pelo cliente, que são ignorados.
+func TestRepository_LifecycleTimestamps(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+
+	input := newLifecycleDelivery("SP", deliveries.ServiceLevelExpress)
+	forged := time.Now().Add(-72 * time.Hour)
+	input.ShippedAt, input.SLADueAt = &forged, &forged
+	created, err := repo.CreateDelivery(ctx, input)
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Nil(t, created.ShippedAt)
+	require.NotNil(t, created.SLADueAt)
+	assert.Equal(t, 24*time.Hour, created.SLADueAt.Sub(created.CreatedAt))
+
+	require.NoError(t, repo.UpdateOrderStatus(ctx, created.ID, deliveries.OrderStatusShipped, 0))
+	shipped, err := repo.GetDeliveryByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, shipped.ShippedAt)
+	assert.Nil(t, shipped.DeliveredAt)
+	assert.False(t, shipped.UpdatedAt.Before(shipped.CreatedAt))
+
+	require.NoError(t, db.Create(&proofs.Proof{DeliveryID: created.ID, RecipientName: "Cliente", RecipientDocument: "12345678909",
+		SignatureKey: "proofs/signature.png", SignatureType: "image/png", SignatureSHA256: "-", DeliveredAt: time.Now()}).Error)
+	require.NoError(t, repo.UpdateOrderStatus(ctx, created.ID, deliveries.OrderStatusDelivered, 0))
+	delivered, err := repo.GetDeliveryByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, delivered.DeliveredAt)
+	assert.True(t, shipped.ShippedAt.Equal(*delivered.ShippedAt), "o horário de envio não muda na entrega")
+	assert.Equal(t, deliveries.SLAOnTime, delivered.SLAStatusAt(time.Now()))
+
+	// Mudar o destino recalcula o prazo a partir da criação; voltar para Pendente limpa os horários.
+	update := *delivered
+	update.Estado, update.ServiceLevel, update.OrderStatus, update.Version = "AM", deliveries.ServiceLevelStandard, deliveries.OrderStatusPending, 0
+	updated, err := repo.UpdateDelivery(ctx, created.ID, &update)
+	require.NoError(t, err)
+	assert.Nil(t, updated.ShippedAt)
+	assert.Nil(t, updated.DeliveredAt)
+	assert.Equal(t, 10*24*time.Hour, updated.SLADueAt.Sub(updated.CreatedAt))
+}
+
+// TestDelivery_SLAStatus testa a situação do prazo em andamento, em risco, vencido e na resposta JSON.
+func TestDelivery_SLAStatus(t *testing.T) {
+	created := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	due := created.Add(4 * 24 * time.Hour)
+	delivery := deliveries.Delivery{OrderStatus: deliveries.OrderStatusShipped, CreatedAt: created, SLADueAt: &due}
+
+	assert.Equal(t, deliveries.SLAOnTime, delivery.SLAStatusAt(created.Add(48*time.Hour)))
+	assert.Equal(t, deliveries.SLAAtRisk, delivery.SLAStatusAt(due.Add(-12*time.Hour)))
+	assert.Equal(t, deliveries.SLABreached, delivery.SLAStatusAt(due.Add(time.Minute)))
+
+	late := due.Add(time.Hour)
+	delivery.OrderStatus, delivery.DeliveredAt = deliveries.OrderStatusDelivered, &late
+	assert.Equal(t, deliveries.SLABreached, delivery.SLAStatusAt(late))
+
+	delivery.OrderStatus = deliveries.OrderStatusCanceled
+	assert.Empty(t, delivery.SLAStatusAt(late))
+
+	// A resposta inclui o sla_status calculado no momento da serialização.
+	delivery.OrderStatus, delivery.DeliveredAt = deliveries.OrderStatusPending, nil
+	body, err := json.Marshal(delivery)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"sla_status":"breached"`)
+}
+
+// TestService_GetLateDeliveries testa a busca das entregas em andamento com o prazo vencido ou vencendo.
+// A entrega concluída só passa a Entregue depois que o comprovante é registrado, e uma versão desatualizada
+// é recusada por conflito antes da verificação do comprovante.
+func TestService_GetLateDeliveries(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	service := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	ctx := context.Background()
+
+	late, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	soon, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", deliveries.ServiceLevelExpress))
+	require.NoError(t, err)
+	done, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	require.ErrorIs(t, service.UpdateOrderStatus(ctx, done.ID, deliveries.OrderStatusDelivered, done.Version+1), deliveries.ErrVersionConflict)
+	stale := *done
+	stale.OrderStatus, stale.Version = deliveries.OrderStatusDelivered, done.Version+1
+	_, err = service.UpdateDelivery(ctx, done.ID, &stale)
+	require.ErrorIs(t, err, deliveries.ErrVersionConflict)
+	require.ErrorIs(t, service.UpdateOrderStatus(ctx, done.ID, deliveries.OrderStatusDelivered, 0), deliveries.ErrProofRequired)
+	require.NoError(t, db.Create(&proofs.Proof{DeliveryID: done.ID, RecipientName: "Cliente", RecipientDocument: "12345678909",
+		SignatureKey: "proofs/signature.png", SignatureType: "image/png", SignatureSHA256: "-", DeliveredAt: time.Now()}).Error)
+	require.NoError(t, service.UpdateOrderStatus(ctx, done.ID, deliveries.OrderStatusDelivered, 0))
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id IN ?", []uint{late.ID, done.ID}).
+		UpdateColumn("sla_due_at", time.Now().Add(-time.Hour)).Error)
+
+	found, err := service.GetLateDeliveries(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, late.ID, found[0].ID)
+
+	found, err = service.GetLateDeliveries(ctx, 48*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, []uint{late.ID, soon.ID}, []uint{found[0].ID, found[1].ID})
+}
+
+// TestService_FlagOverdueAndCancelStale testa as rotinas agendadas das entregas: a sinalização do atraso, feita
+// uma única vez e com o evento DeliveryOverdue, e o cancelamento das pendentes antigas com o motivo gravado.
+func TestService_FlagOverdueAndCancelStale(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	service := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	ctx := context.Background()
+
+	late, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	stale, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	shipped, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	_, err = service.CreateDelivery(ctx, newLifecycleDelivery("SP", "")) // Recente e no prazo
+	require.NoError(t, err)
+	require.NoError(t, service.UpdateOrderStatus(ctx, shipped.ID, deliveries.OrderStatusShipped, 0))
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id = ?", late.ID).
+		UpdateColumn("sla_due_at", time.Now().Add(-time.Hour)).Error)
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id IN ?", []uint{stale.ID, shipped.ID}).
+		UpdateColumn("created_at", time.Now().Add(-31*24*time.Hour)).Error)
+
+	flagged, err := service.FlagOverdueDeliveries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged)
+	flagged, err = service.FlagOverdueDeliveries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flagged, "uma entrega é sinalizada uma única vez")
+	overdue, err := service.GetDeliveryByID(ctx, late.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, overdue.OverdueAt)
+	assert.Equal(t, late.Version+1, overdue.Version)
+	var recorded int64
+	require.NoError(t, db.Model(&events.Event{}).Where("type = ? AND aggregate_id = ?", events.DeliveryOverdue, late.ID).Count(&recorded).Error)
+	assert.Equal(t, int64(1), recorded)
+
+	// Apenas a entrega pendente há mais de 30 dias é cancelada; a enviada, criada no mesmo dia, não.
+	canceled, err := service.CancelStalePending(ctx, 30*24*time.Hour, "still pending")
+	require.NoError(t, err)
+	assert.Equal(t, 1, canceled)
+	got, err := service.GetDeliveryByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, deliveries.OrderStatusCanceled, got.OrderStatus)
+	assert.Equal(t, "still pending", got.CancelReason)
+	replay, err := service.GetStatusChangesAfter(ctx, 0, deliveries.StreamFilter{ID: stale.ID})
+	require.NoError(t, err)
+	require.Len(t, replay.Events, 1)
+	assert.False(t, replay.Truncated)
+	assert.Equal(t, deliveries.OrderStatusPending, replay.Events[0].Change.PreviousStatus)
+
+	// Reabrir a entrega limpa o motivo do cancelamento.
+	require.NoError(t, service.UpdateOrderStatus(ctx, stale.ID, deliveries.OrderStatusPending, 0))
+	got, err = service.GetDeliveryByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.CancelReason)
+}
+
+// TestBroker_FollowsOutbox testa se cada Broker lê as mudanças de status da outbox por conta própria, como em
+// instâncias diferentes da API, e se as mudanças gravadas antes de Follow não são reenviadas.
+func TestBroker_FollowsOutbox(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivery, err := repo.CreateDelivery(ctx, newLifecycleDelivery("SP", deliveries.ServiceLevelStandard))
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateOrderStatus(ctx, delivery.ID, deliveries.OrderStatusShipped, 0))
+
+	var subscriptions []*deliveries.StreamSubscription
+	for i := 0; i < 2; i++ {
+		broker := deliveries.NewBroker()
+		subscriptions = append(subscriptions, broker.Subscribe(deliveries.StreamFilter{ID: delivery.ID}))
+		go broker.Follow(ctx, repo, 10*time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // Espera os Brokers lerem o ponto de partida da outbox
+
+	require.NoError(t, repo.UpdateOrderStatus(ctx, delivery.ID, deliveries.OrderStatusCanceled, 0))
+
+	// Os dois Brokers recebem a mudança nova, e só ela.
+	for _, subscription := range subscriptions {
+		select {
+		case statusEvent := <-subscription.Events:
+			assert.Equal(t, deliveries.OrderStatusCanceled, statusEvent.Change.Delivery.OrderStatus)
+			assert.Equal(t, deliveries.OrderStatusShipped, statusEvent.Change.PreviousStatus)
+		case <-time.After(time.Second):
+			t.Fatal("status change was not streamed")
+		}
+		select {
+		case statusEvent := <-subscription.Events:
+			t.Fatalf("unexpected status change %d", statusEvent.ID)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// TestBroker_FollowsLateCommits testa se o Broker repassa uma mudança confirmada depois de outra com ID maior,
+// como acontece com transações confirmadas fora de ordem, e se ela é devolvida por LateAfter para quem retomar o
+// stream a partir da mudança com ID maior.
+func TestBroker_FollowsLateCommits(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivery, err := repo.CreateDelivery(ctx, newLifecycleDelivery("SP", deliveries.ServiceLevelStandard))
+	require.NoError(t, err)
+	start, err := repo.LastOutboxEventID(ctx)
+	require.NoError(t, err)
+
+	broker := deliveries.NewBroker()
+	subscription := broker.Subscribe(deliveries.StreamFilter{})
+	go broker.Follow(ctx, repo, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Espera o Broker ler o ponto de partida da outbox
+
+	// record grava uma mudança de status com o ID informado, simulando a ordem de confirmação das transações.
+	record := func(id uint) {
+		event, err := events.New(events.AggregateDelivery, delivery.ID, events.DeliveryStatusChanged,
+			deliveries.StatusChange{Delivery: delivery, PreviousStatus: deliveries.OrderStatusPending})
+		require.NoError(t, err)
+		event.ID = id
+		require.NoError(t, db.Create(event).Error)
+	}
+	next := func() uint {
+		select {
+		case statusEvent := <-subscription.Events:
+			return statusEvent.ID
+		case <-time.After(time.Second):
+			t.Fatal("status change was not streamed")
+			return 0
+		}
+	}
+
+	record(start + 5)
+	assert.Equal(t, start+5, next())
+	record(start + 2)
+	assert.Equal(t, start+2, next())
+
+	// A mudança com ID menor não é repassada de novo, e quem parou na de ID maior a recebe com LateAfter.
+	select {
+	case statusEvent := <-subscription.Events:
+		t.Fatalf("unexpected status change %d", statusEvent.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+	late := broker.LateAfter(start+5, deliveries.StreamFilter{ID: delivery.ID})
+	require.Len(t, late, 1)
+	assert.Equal(t, start+2, late[0].ID)
+	assert.Empty(t, broker.LateAfter(start+2, deliveries.StreamFilter{}))
+}
@@ -0,0 +1,127 @@
+package deliveries_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/zones"
+)
+
+// setupMap cria o banco em memória com entregas em dois bairros de São Paulo, uma no Recife e uma sem coordenadas,
+// e o roteador com as rotas do mapa.
+func setupMap(t *testing.T) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&deliveries.Delivery{}, &deliveries.Package{}, &events.Event{}, &zones.Zone{}))
+
+	points := []struct {
+		lat, lon float64
+		status   string
+	}{
+		{-23.5489, -46.6361, deliveries.OrderStatusPending}, // Sé
+		{-23.5492, -46.6358, deliveries.OrderStatusShipped}, // Sé
+		{-23.5495, -46.6365, deliveries.OrderStatusPending}, // Sé
+		{-23.6010, -46.6950, deliveries.OrderStatusPending}, // Santo Amaro
+		{-8.0500, -34.9000, deliveries.OrderStatusPending},  // Recife, fora do bbox
+		{0, 0, deliveries.OrderStatusPending},               // Sem coordenadas
+	}
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+	for _, point := range points {
+		input := newLifecycleDelivery("SP", "")
+		input.Latitude, input.Longitude = point.lat, point.lon
+		created, err := repo.CreateDelivery(ctx, input)
+		require.NoError(t, err)
+		if point.status != deliveries.OrderStatusPending {
+			require.NoError(t, repo.UpdateOrderStatus(ctx, created.ID, point.status, 0))
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := deliveries.Handler{Service: deliveries.NewService(repo)}
+	router := gin.New()
+	router.GET("/deliveries.geojson", handler.GetDeliveriesGeoJSON)
+	router.GET("/deliveries/clusters", handler.GetDeliveryClusters)
+	return router
+}
+
+// get faz a requisição e retorna a resposta.
+func get(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// TestHandler_GetDeliveriesGeoJSON testa o FeatureCollection das entregas, sem as que não têm coordenadas e sem os
+// dados pessoais do cliente, e o recorte pelo bbox.
+func TestHandler_GetDeliveriesGeoJSON(t *testing.T) {
+	router := setupMap(t)
+
+	w := get(router, "/deliveries.geojson")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, deliveries.GeoJSONContentType, w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "client")
+	var collection struct {
+		Type     string               `json:"type"`
+		Features []deliveries.Feature `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.Len(t, collection.Features, 5)
+	assert.Equal(t, "Point", collection.Features[0].Geometry.Type)
+	assert.Equal(t, [2]float64{-46.6361, -23.5489}, [2]float64(collection.Features[0].Geometry.Coordinates))
+	assert.Equal(t, deliveries.OrderStatusPending, collection.Features[0].Properties.OrderStatus)
+
+	w = get(router, "/deliveries.geojson?bbox=-46.8,-23.7,-46.4,-23.4&order_status=Pendente")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &collection))
+	assert.Len(t, collection.Features, 3)
+
+	assert.Equal(t, http.StatusBadRequest, get(router, "/deliveries.geojson?bbox=1,2,3").Code)
+}
+
+// TestHandler_GetDeliveryClusters testa o agrupamento por geohash no zoom da cidade, com a contagem por status,
+// e a recusa de zoom e bbox ausentes ou inválidos.
+func TestHandler_GetDeliveryClusters(t *testing.T) {
+	router := setupMap(t)
+
+	w := get(router, "/deliveries/clusters?zoom=12&bbox=-46.8,-23.7,-46.4,-23.4")
+	require.Equal(t, http.StatusOK, w.Code)
+	var clusters deliveries.ClusterCollection
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusters))
+	assert.Equal(t, 5, clusters.Precision)
+	assert.Equal(t, 4, clusters.Total)
+	require.Len(t, clusters.Features, 2)
+	center, south := clusters.Features[0].Properties, clusters.Features[1].Properties
+	if center.Count < south.Count {
+		center, south = south, center
+	}
+	assert.Equal(t, 3, center.Count)
+	assert.Equal(t, map[string]int{deliveries.OrderStatusPending: 2, deliveries.OrderStatusShipped: 1}, center.ByStatus)
+	assert.Nil(t, center.DeliveryID)
+	assert.Equal(t, 1, south.Count)
+	require.NotNil(t, south.DeliveryID)
+	assert.Equal(t, uint(4), *south.DeliveryID)
+
+	for _, path := range []string{
+		"/deliveries/clusters?bbox=-46.8,-23.7,-46.4,-23.4",
+		"/deliveries/clusters?zoom=23&bbox=-46.8,-23.7,-46.4,-23.4",
+		"/deliveries/clusters?zoom=12",
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(router, path).Code, path)
+	}
+}
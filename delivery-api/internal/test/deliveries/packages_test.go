@@ -0,0 +1,228 @@
+package deliveries_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/test/fixtures"
+)
+
+// TestRepository_DeliveryPackages testa os totais calculados a partir dos volumes na criação (inclusive os pesos
+// cubado e tarifado), o volume único das entregas criadas sem volumes e o recálculo dos totais a cada volume
+// incluído, alterado ou removido.
+func TestRepository_DeliveryPackages(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	service := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	ctx := context.Background()
+
+	single, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	require.Len(t, single.Packages, 1)
+	assert.Equal(t, "Pedido", single.Packages[0].Description)
+	assert.Equal(t, 1.0, single.Weight)
+
+	input := newLifecycleDelivery("SP", "")
+	input.TestName, input.Weight = "", 99
+	input.Packages = []deliveries.Package{
+		{ID: single.Packages[0].ID, Description: "Caixa pequena", Quantity: 3, Weight: 1.5, DeclaredValue: 20},
+		{Description: "Caixa grande", Quantity: 1, Weight: 10, Length: 60, Width: 40, Height: 40, DeclaredValue: 300},
+	}
+	created, err := service.CreateDelivery(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, "Caixa pequena", created.TestName)
+	assert.Equal(t, 14.5, created.Weight)
+	assert.Equal(t, 360.0, created.DeclaredValue)
+	assert.Equal(t, deliveries.DimensionUnitCentimeter, created.Packages[1].DimensionUnit)
+	assert.Equal(t, 16.0, created.Packages[1].CubedWeight) // 60 x 40 x 40 / 6000
+	assert.Equal(t, 16.0, created.CubedWeight)
+	assert.Equal(t, 20.5, created.ChargeableWeight) // 3 x 1,5 (real) + 16 (cubado)
+	assert.NotEqual(t, single.Packages[0].ID, created.Packages[0].ID) // O ID enviado é ignorado
+
+	added := deliveries.Package{Description: "Envelope", Quantity: 2, Weight: 0.25}
+	updated, err := service.AddPackage(ctx, created.ID, &added, created.Version)
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, updated.Weight)
+	assert.Equal(t, created.Version+1, updated.Version)
+	require.Len(t, updated.Packages, 3)
+	_, err = service.AddPackage(ctx, created.ID, &deliveries.Package{Description: "Envelope", Quantity: 1, Weight: 1}, created.Version)
+	assert.ErrorIs(t, err, deliveries.ErrVersionConflict)
+
+	changed := deliveries.Package{Description: "Caixa grande", Quantity: 2, Weight: 10, DeclaredValue: 300}
+	updated, err = service.UpdatePackage(ctx, created.ID, created.Packages[1].ID, &changed, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, updated.Weight)
+	assert.Equal(t, 660.0, updated.DeclaredValue)
+	assert.Zero(t, updated.CubedWeight) // A caixa grande ficou sem dimensões
+	assert.Equal(t, 25.0, updated.ChargeableWeight)
+	_, err = service.UpdatePackage(ctx, created.ID, single.Packages[0].ID, &changed, 0)
+	assert.ErrorIs(t, err, deliveries.ErrPackageNotFound) // Volume de outra entrega
+
+	updated, err = service.DeletePackage(ctx, created.ID, added.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 24.5, updated.Weight)
+	_, err = service.DeletePackage(ctx, single.ID, single.Packages[0].ID, 0)
+	assert.ErrorIs(t, err, deliveries.ErrLastPackage)
+
+	require.NoError(t, service.UpdateOrderStatus(ctx, created.ID, deliveries.OrderStatusShipped, 0))
+	_, err = service.DeletePackage(ctx, created.ID, created.Packages[0].ID, 0)
+	assert.ErrorIs(t, err, deliveries.ErrPackagesLocked)
+
+	// A remoção da entrega leva os volumes junto.
+	require.NoError(t, service.DeleteDelivery(ctx, single.ID, 0))
+	var remaining int64
+	require.NoError(t, db.Model(&deliveries.Package{}).Where("delivery_id = ?", single.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+// TestService_RecomputeWeights testa se, depois de mudar o divisor do peso cubado, o recálculo grava os novos pesos
+// dos volumes e das entregas, com uma nova versão e o evento DeliveryUpdated, e se as entregas sem mudança ficam como estão.
+func TestService_RecomputeWeights(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	ctx := context.Background()
+
+	input := newLifecycleDelivery("SP", "")
+	input.Packages = []deliveries.Package{
+		{Description: "Caixa pequena", Quantity: 3, Weight: 1.5},
+		{Description: "Caixa grande", Quantity: 1, Weight: 10, Length: 60, Width: 40, Height: 40},
+	}
+	created, err := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)).CreateDelivery(ctx, input)
+	require.NoError(t, err)
+	plain, err := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)).CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+
+	service := deliveries.NewService(deliveries.NewRepository(db, 4000))
+	changed, err := service.RecomputeWeights(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed) // A entrega sem dimensões não muda de peso
+
+	got, err := service.GetDeliveryByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 24.0, got.Packages[1].CubedWeight) // 60 x 40 x 40 / 4000
+	assert.Equal(t, 24.0, got.CubedWeight)
+	assert.Equal(t, 28.5, got.ChargeableWeight) // 3 x 1,5 (real) + 24 (cubado)
+	assert.Equal(t, created.Version+1, got.Version)
+	var recorded int64
+	require.NoError(t, db.Model(&events.Event{}).Where("type = ? AND aggregate_id = ?", events.DeliveryUpdated, created.ID).Count(&recorded).Error)
+	assert.Equal(t, int64(1), recorded)
+	unchanged, err := service.GetDeliveryByID(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Equal(t, plain.Version, unchanged.Version)
+
+	// Com os pesos já recalculados, nada muda.
+	changed, err = service.RecomputeWeights(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, changed)
+}
+
+// TestRepository_PackagesRespectCourierCapacity testa se os volumes de uma entrega atribuída só podem aumentar o
+// peso tarifado até a capacidade do entregador, contando as outras entregas em andamento dele.
+func TestRepository_PackagesRespectCourierCapacity(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	service := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	ctx := context.Background()
+
+	courier := couriers.Courier{Name: "Entregador", VehicleType: couriers.VehicleCar, Capacity: 10, HomeCity: "Cidade"}
+	require.NoError(t, db.Create(&courier).Error)
+	other, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", "")) // Peso 1
+	require.NoError(t, err)
+	delivery, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", "")) // Peso 1
+	require.NoError(t, err)
+	courierRepo := couriers.NewRepository(db)
+	_, err = courierRepo.Assign(ctx, courier.ID, other.ID)
+	require.NoError(t, err)
+	_, err = courierRepo.Assign(ctx, courier.ID, delivery.ID)
+	require.NoError(t, err)
+
+	// 1 (outra entrega) + 1 + 8 = 10 cabe na capacidade.
+	updated, err := service.AddPackage(ctx, delivery.ID, &deliveries.Package{Description: "Caixa", Quantity: 1, Weight: 8}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, updated.ChargeableWeight)
+
+	// Mais um quilo passaria da capacidade, incluindo ou alterando um volume; a entrega não muda.
+	_, err = service.AddPackage(ctx, delivery.ID, &deliveries.Package{Description: "Envelope", Quantity: 1, Weight: 1}, 0)
+	assert.ErrorIs(t, err, deliveries.ErrCapacityExceeded)
+	_, err = service.UpdatePackage(ctx, delivery.ID, updated.Packages[1].ID, &deliveries.Package{Description: "Caixa", Quantity: 1, Weight: 9}, 0)
+	assert.ErrorIs(t, err, deliveries.ErrCapacityExceeded)
+	current, err := service.GetDeliveryByID(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, current.ChargeableWeight)
+	assert.Equal(t, updated.Version, current.Version)
+
+	// Reduzir o peso é sempre permitido, e uma entrega sem entregador não tem limite.
+	_, err = service.UpdatePackage(ctx, delivery.ID, updated.Packages[1].ID, &deliveries.Package{Description: "Caixa", Quantity: 1, Weight: 2}, 0)
+	require.NoError(t, err)
+	unassigned, err := service.CreateDelivery(ctx, newLifecycleDelivery("SP", ""))
+	require.NoError(t, err)
+	_, err = service.AddPackage(ctx, unassigned.ID, &deliveries.Package{Description: "Palete", Quantity: 1, Weight: 50}, 0)
+	require.NoError(t, err)
+}
+
+// TestHandler_Packages testa as rotas dos volumes: a validação de cada volume, na criação da entrega e na inclusão,
+// os pesos calculados, o ETag com a nova versão da entrega e as respostas de erro.
+func TestHandler_Packages(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	gin.SetMode(gin.TestMode)
+	handler := deliveries.Handler{Service: deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))}
+	router := gin.New()
+	router.POST("/deliveries", handler.CreateDelivery)
+	router.GET("/deliveries/:id/packages", handler.GetPackages)
+	router.POST("/deliveries/:id/packages", handler.AddPackage)
+	router.DELETE("/deliveries/:id/packages/:package_id", handler.DeletePackage)
+	send := func(method, path, body string, ifMatch ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for _, value := range ifMatch {
+			req.Header.Set("If-Match", value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	delivery := `{"client_cpf":"12345678909","client_name":"Cliente","logradouro":"Rua A","numero":"1","bairro":"Centro",
+		"cidade":"Recife","estado":"PE","pais":"Brasil","order_status":"Pendente","packages":[%s]}`
+	w := send(http.MethodPost, "/deliveries", strings.Replace(delivery, "%s", `{"description":"Caixa","quantity":0,"weight":2}`, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "package 1: quantity must be at least 1")
+
+	w = send(http.MethodPost, "/deliveries", strings.Replace(delivery, "%s", `{"description":"Caixa","quantity":1,"weight":2,"dimension_unit":"mm"}`, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid dimension unit")
+
+	// 12 x 12 x 12 polegadas = 28.316,8 cm³, ou 4,719 kg cubados com o divisor padrão.
+	w = send(http.MethodPost, "/deliveries", strings.Replace(delivery, "%s",
+		`{"description":"Caixa","quantity":2,"weight":2,"length":12,"width":12,"height":12,"dimension_unit":"in"}`, 1))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"weight":4`)
+	assert.Contains(t, w.Body.String(), `"actual_weight":4`)
+	assert.Contains(t, w.Body.String(), `"cubed_weight":4.719`)
+	assert.Contains(t, w.Body.String(), `"chargeable_weight":9.438`)
+
+	w = send(http.MethodPost, "/deliveries/1/packages", `{"description":"Sacola","quantity":1,"weight":-1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send(http.MethodPost, "/deliveries/1/packages", `{"description":"Sacola","quantity":1,"weight":0.5}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"delivery_id":1`)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/deliveries/9/packages", `{"description":"Sacola","quantity":1,"weight":0.5}`).Code)
+
+	w = send(http.MethodGet, "/deliveries/1/packages", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Sacola")
+
+	// A remoção exige o If-Match com a versão da entrega; a inclusão, não.
+	assert.Equal(t, http.StatusPreconditionRequired, send(http.MethodDelete, "/deliveries/1/packages/2", "").Code)
+	assert.Equal(t, http.StatusPreconditionFailed, send(http.MethodDelete, "/deliveries/1/packages/2", "", `W/"2"`).Code)
+	assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/deliveries/1/packages/2", "", `"2"`).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/deliveries/1/packages/2", "", `"3"`).Code)
+	assert.Equal(t, http.StatusConflict, send(http.MethodDelete, "/deliveries/1/packages/1", "", `"3"`).Code)
+}
@@ -0,0 +1,29 @@
+package deliveries_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+)
+
+// TestRepository_UsesContext testa se as consultas usam o contexto recebido e são canceladas junto com ele.
+func TestRepository_UsesContext(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&deliveries.Delivery{}, &deliveries.Package{}))
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+
+	_, err = repo.GetDeliveries(context.Background(), deliveries.Filter{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.GetDeliveries(ctx, deliveries.Filter{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,161 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/test/fixtures"
+)
+
+// newDelivery monta uma entrega válida (veja fixtures.NewDelivery) para o CPF informado.
+func newDelivery(cpf string) *deliveries.Delivery {
+	delivery := fixtures.NewDelivery()
+	delivery.ClientCPF = cpf
+	return delivery
+}
+
+// outboxTypes retorna os tipos dos eventos gravados na outbox, em ordem.
+func outboxTypes(t *testing.T, db *gorm.DB) []string {
+	var types []string
+	require.NoError(t, db.Model(&events.Event{}).Order("id").Pluck("type", &types).Error)
+	return types
+}
+
+// TestRepository_RecordsEventsInTransaction testa se as alterações gravam os eventos na outbox
+// e se uma alteração rejeitada não deixa evento para trás.
+func TestRepository_RecordsEventsInTransaction(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+
+	delivery, err := repo.CreateDelivery(ctx, newDelivery("12345678909"))
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateOrderStatus(ctx, delivery.ID, deliveries.OrderStatusShipped, 1))
+
+	// Versão desatualizada: a alteração é rejeitada e nenhum evento é gravado.
+	assert.ErrorIs(t, repo.UpdateOrderStatus(ctx, delivery.ID, deliveries.OrderStatusDelivered, 1), deliveries.ErrVersionConflict)
+	require.NoError(t, repo.DeleteDelivery(ctx, delivery.ID, 0))
+
+	assert.Equal(t, []string{events.DeliveryCreated, events.DeliveryStatusChanged, events.DeliveryDeleted}, outboxTypes(t, db))
+
+	var change deliveries.StatusChange
+	var event events.Event
+	require.NoError(t, db.Where("type = ?", events.DeliveryStatusChanged).First(&event).Error)
+	require.NoError(t, event.Decode(&change))
+	assert.Equal(t, deliveries.OrderStatusPending, change.PreviousStatus)
+	assert.Equal(t, deliveries.OrderStatusShipped, change.Delivery.OrderStatus)
+}
+
+// TestDispatcher_RetriesInOrderPerAggregate testa se uma falha segura os eventos seguintes do mesmo agregado,
+// sem atrasar os de outros agregados, e se o evento é entregue de novo na próxima leitura.
+func TestDispatcher_RetriesInOrderPerAggregate(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+
+	first, err := repo.CreateDelivery(ctx, newDelivery("12345678909"))
+	require.NoError(t, err)
+	_, err = repo.CreateDelivery(ctx, newDelivery("98765432100"))
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateOrderStatus(ctx, first.ID, deliveries.OrderStatusShipped, 0))
+
+	// O assinante falha uma vez no evento de criação da primeira entrega.
+	var received []uint
+	failed := false
+	bus := events.NewBus()
+	bus.Subscribe("test", func(ctx context.Context, event *events.Event) error {
+		if event.AggregateID == first.ID && event.Type == events.DeliveryCreated && !failed {
+			failed = true
+			return errors.New("temporary failure")
+		}
+		received = append(received, event.ID)
+		return nil
+	})
+
+	config := events.DefaultDispatcherConfig()
+	config.RetryBase = 0
+	dispatcher := events.NewDispatcher(events.NewRepository(db), bus, config)
+
+	dispatched, err := dispatcher.ProcessPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	assert.Equal(t, []uint{2}, received) // Apenas o evento da segunda entrega
+
+	dispatched, err = dispatcher.ProcessPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+	assert.Equal(t, []uint{2, 1, 3}, received) // A criação da primeira entrega chega antes da mudança de status
+
+	var pending int64
+	require.NoError(t, db.Model(&events.Event{}).Where("dispatched_at IS NULL").Count(&pending).Error)
+	assert.Zero(t, pending)
+}
+
+// TestDispatcher_SkipsBlockedAggregatesAndGivesUp testa se os eventos à espera de uma nova tentativa não ocupam o lote
+// (os de outros agregados continuam sendo entregues) e se o evento é marcado como morto depois de MaxAttempts falhas,
+// liberando os eventos seguintes do mesmo agregado.
+func TestDispatcher_SkipsBlockedAggregatesAndGivesUp(t *testing.T) {
+	db := fixtures.OpenDB(t)
+	repo := deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)
+	ctx := context.Background()
+
+	first, err := repo.CreateDelivery(ctx, newDelivery("12345678909"))
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateOrderStatus(ctx, first.ID, deliveries.OrderStatusShipped, 0))
+	_, err = repo.CreateDelivery(ctx, newDelivery("98765432100"))
+	require.NoError(t, err)
+
+	// O assinante sempre falha no evento de criação da primeira entrega.
+	var received []uint
+	bus := events.NewBus()
+	bus.Subscribe("test", func(ctx context.Context, event *events.Event) error {
+		if event.AggregateID == first.ID && event.Type == events.DeliveryCreated {
+			return errors.New("poison event")
+		}
+		received = append(received, event.ID)
+		return nil
+	})
+
+	config := events.DefaultDispatcherConfig()
+	config.BatchSize = 2
+	config.RetryBase = time.Hour
+	config.MaxAttempts = 2
+	dispatcher := events.NewDispatcher(events.NewRepository(db), bus, config)
+
+	// O lote traz os dois eventos da primeira entrega; a criação falha e segura a mudança de status.
+	dispatched, err := dispatcher.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, dispatched)
+
+	// Com a nova tentativa agendada, a primeira entrega sai do lote e a segunda é entregue.
+	dispatched, err = dispatcher.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	assert.Equal(t, []uint{3}, received)
+
+	// Na segunda falha o evento é marcado como morto.
+	require.NoError(t, db.Model(&events.Event{}).Where("id = ?", 1).Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+	dispatched, err = dispatcher.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, dispatched)
+
+	var dead events.Event
+	require.NoError(t, db.First(&dead, 1).Error)
+	assert.NotNil(t, dead.DeadAt)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Contains(t, dead.LastError, "poison event")
+
+	// O evento morto não volta a ser lido e deixa de segurar a mudança de status da primeira entrega.
+	dispatched, err = dispatcher.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	assert.Equal(t, []uint{3, 2}, received)
+}
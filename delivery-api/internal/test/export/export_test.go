@@ -0,0 +1,43 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/export"
+)
+
+// TestWriter_EscapesFormulasInCSV testa se as células que começam com =, +, -, @, tab ou CR recebem o prefixo "'"
+// no CSV, enquanto os números negativos e o NDJSON ficam inalterados.
+func TestWriter_EscapesFormulasInCSV(t *testing.T) {
+	record := []string{"=HYPERLINK(\"http://evil\")", "+1+1", "-2+3", "@SUM(A1)", "\tcmd", "\rcmd", "-23,5505", "João"}
+
+	var out bytes.Buffer
+	writer, err := export.NewWriter(&out, export.FormatCSV, []string{"a", "b", "c", "d", "e", "f", "g", "h"})
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(record, nil))
+	require.NoError(t, writer.Flush())
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(out.String(), "\xef\xbb\xbf")))
+	reader.Comma = ';'
+	rows, err := reader.ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for i, cell := range rows[1][:6] {
+		assert.True(t, strings.HasPrefix(cell, "'"), "coluna %d: %q", i, cell)
+	}
+	assert.Equal(t, `'=HYPERLINK("http://evil")`, rows[1][0])
+	assert.Equal(t, []string{"-23,5505", "João"}, rows[1][6:])
+
+	var ndjson bytes.Buffer
+	writer, err = export.NewWriter(&ndjson, export.FormatNDJSON, nil)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(nil, map[string]string{"name": "=1+1"}))
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "{\"name\":\"=1+1\"}\n", ndjson.String())
+}
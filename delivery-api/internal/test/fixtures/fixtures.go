@@ -0,0 +1,42 @@
+// Package fixtures reúne os dados compartilhados pelos testes em internal/test: o banco SQLite em memória, com o
+// schema criado pelas migrações SQL da aplicação, e uma entrega válida para ser criada pelos serviços.
+package fixtures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/migrations"
+)
+
+// OpenDB cria o banco SQLite em memória e aplica todas as migrações (migrations.Up), como o comando
+// "delivery-api migrate up", para que os testes rodem sobre o mesmo schema do banco da aplicação.
+func OpenDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return db
+}
+
+// NewDelivery monta uma entrega pendente válida, de 1 kg, com destino em Recife (PE) e sem coordenadas.
+// Os testes alteram os campos de que precisam antes de criá-la.
+func NewDelivery() *deliveries.Delivery {
+	return &deliveries.Delivery{
+		ClientCPF: "12345678909", ClientName: "Cliente", TestName: "Pedido", Weight: 1,
+		Logradouro: "Rua A", Numero: "1", Bairro: "Centro", Cidade: "Recife", Estado: "PE", Pais: "Brasil",
+		OrderStatus: deliveries.OrderStatusPending,
+	}
+}
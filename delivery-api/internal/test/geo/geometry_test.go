@@ -0,0 +1,83 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/geo"
+)
+
+// TestMultiPolygon_Contains testa o ponto no polígono com um quadrado com buraco e um segundo polígono separado,
+// incluindo os pontos sobre a borda externa e sobre a borda do buraco, que pertencem ao polígono.
+func TestMultiPolygon_Contains(t *testing.T) {
+	var area geo.MultiPolygon
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "MultiPolygon", "coordinates": [
+		[[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]], [[4, 4], [6, 4], [6, 6], [4, 6], [4, 4]]],
+		[[[20, 0], [25, 5], [20, 10], [20, 0]]]
+	]}`), &area))
+
+	cases := []struct {
+		name     string
+		lat, lon float64
+		inside   bool
+	}{
+		{"interior", 2, 2, true},
+		{"no buraco", 5, 5, false},
+		{"borda do buraco", 4, 5, true},
+		{"borda externa", 0, 5, true},
+		{"vértice", 10, 10, true},
+		{"fora", 5, 15, false},
+		{"segundo polígono", 5, 22, true},
+		{"fora do triângulo", 9, 24, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.inside, area.Contains(c.lat, c.lon), c.name)
+	}
+	assert.Equal(t, geo.Bounds{MinLat: 0, MinLon: 0, MaxLat: 10, MaxLon: 25}, area.Bounds())
+	assert.InDelta(t, 100-4+25, area.Area(), 1e-9)
+}
+
+// TestMultiPolygon_UnmarshalJSON testa a leitura de um Polygon como MultiPolygon, a escrita de volta e a recusa de
+// geometrias inválidas.
+func TestMultiPolygon_UnmarshalJSON(t *testing.T) {
+	var polygon geo.MultiPolygon
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`), &polygon))
+	require.Len(t, polygon, 1)
+	data, err := json.Marshal(polygon)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]]}`, string(data))
+
+	invalid := []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`,         // Menos de quatro posições
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`, // Anel aberto
+		`{"type": "Polygon", "coordinates": [[[0, 0], [181, 0], [1, 1], [0, 0]]]}`,
+		`{"type": "MultiPolygon", "coordinates": []}`,
+	}
+	for _, geometry := range invalid {
+		var m geo.MultiPolygon
+		assert.ErrorIs(t, json.Unmarshal([]byte(geometry), &m), geo.ErrInvalidGeometry, geometry)
+	}
+}
+
+// TestGeohash testa a codificação do geohash, a precisão usada em cada zoom e a leitura do bbox.
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", geo.Geohash(57.64911, 10.40744, 11))
+	assert.Equal(t, "6gyf4", geo.Geohash(-23.5489, -46.6361, 5))
+	assert.Equal(t, "6gy", geo.Geohash(-23.5489, -46.6361, 3))
+
+	for zoom, precision := range map[int]int{0: 1, 4: 2, 8: 4, 12: 5, 15: 7, 22: 8} {
+		assert.Equal(t, precision, geo.PrecisionForZoom(zoom), "zoom %d", zoom)
+	}
+
+	bounds, err := geo.ParseBBox("-46.8,-23.7,-46.4,-23.4")
+	require.NoError(t, err)
+	assert.Equal(t, geo.Bounds{MinLat: -23.7, MinLon: -46.8, MaxLat: -23.4, MaxLon: -46.4}, bounds)
+	for _, invalid := range []string{"", "1,2,3", "a,0,1,1", "10,0,-10,1", "0,-91,1,1"} {
+		_, err := geo.ParseBBox(invalid)
+		assert.ErrorIs(t, err, geo.ErrInvalidGeometry, invalid)
+	}
+}
@@ -0,0 +1,74 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/health"
+)
+
+// readyz envia uma requisição GET /readyz ao Checker e retorna o status HTTP e o relatório.
+func readyz(t *testing.T, checker *health.Checker) (int, health.Report) {
+	router := gin.New()
+	router.GET("/readyz", checker.Readiness)
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+// TestReadiness_AllChecksPass testa a resposta 200 quando todas as dependências estão prontas.
+func TestReadiness_AllChecksPass(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Register("database", func(ctx context.Context) error { return nil })
+	checker.Register("migrations", func(ctx context.Context) error { return nil })
+
+	code, report := readyz(t, checker)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Len(t, report.Checks, 2)
+}
+
+// TestReadiness_ReportsFailingDependency testa a resposta 503 com o erro da dependência que falhou,
+// inclusive quando a verificação passa do tempo limite.
+func TestReadiness_ReportsFailingDependency(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Timeout = 50 * time.Millisecond
+	checker.Register("database", func(ctx context.Context) error { return nil })
+	checker.Register("migrations", func(ctx context.Context) error { return errors.New("pending migrations 0002_users") })
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	code, report := readyz(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, "pending migrations 0002_users", report.Checks["migrations"].Error)
+	assert.Equal(t, health.StatusUnavailable, report.Checks["slow"].Status)
+}
+
+// TestReadiness_Draining testa se o serviço deixa de estar pronto quando o desligamento começa.
+func TestReadiness_Draining(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Register("database", func(ctx context.Context) error { return nil })
+	checker.SetDraining()
+
+	code, report := readyz(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusUnavailable, report.Checks["shutdown"].Status)
+}
@@ -0,0 +1,145 @@
+package idempotency_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/idempotency"
+	"delivery-api/internal/users"
+)
+
+// setupDB cria o banco em memória com as tabelas dos registros de idempotência e dos usuários.
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&idempotency.Record{}, &users.User{}))
+	return db
+}
+
+// setupRouter cria um router com uma rota POST protegida pelo middleware de idempotência.
+// O contador indica quantas vezes o handler foi realmente executado.
+func setupRouter(t *testing.T, calls *int) *gin.Engine {
+	return newRouter(setupDB(t), calls)
+}
+
+// newRouter é como setupRouter, mas usa o banco informado. O usuário é identificado pelo token, como na API.
+func newRouter(db *gorm.DB, calls *int) *gin.Engine {
+	router := gin.New()
+	router.Use(users.Identify(users.NewService(users.NewRepository(db))))
+	router.POST("/deliveries", idempotency.Middleware(idempotency.NewStore(db), time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusCreated, gin.H{"id": *calls})
+	})
+	return router
+}
+
+// post envia uma requisição POST com a chave de idempotência e o corpo informados, e o token, se informado.
+func post(router *gin.Engine, key, body string, token ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/deliveries", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotency.HeaderKey, key)
+	}
+	for _, value := range token {
+		req.Header.Set("Authorization", "Bearer "+value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotency_ReplaysOriginalResponse testa se uma nova tentativa recebe a resposta original sem executar o handler.
+func TestIdempotency_ReplaysOriginalResponse(t *testing.T) {
+	calls := 0
+	router := setupRouter(t, &calls)
+
+	first := post(router, "abc-123", `{"weight": 10}`)
+	second := post(router, "abc-123", `{"weight": 10}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(idempotency.HeaderReplayed))
+	assert.Equal(t, 1, calls)
+}
+
+// TestIdempotency_DifferentPayload testa se a reutilização da chave com outro corpo retorna 422.
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	calls := 0
+	router := setupRouter(t, &calls)
+
+	post(router, "abc-123", `{"weight": 10}`)
+	w := post(router, "abc-123", `{"weight": 20}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+// TestIdempotency_WithoutKey testa se requisições sem a chave são sempre processadas.
+func TestIdempotency_WithoutKey(t *testing.T) {
+	calls := 0
+	router := setupRouter(t, &calls)
+
+	post(router, "", `{"weight": 10}`)
+	post(router, "", `{"weight": 10}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+// TestIdempotency_ScopedByUser testa se a mesma chave enviada por outro usuário (ou sem token) é processada
+// como uma requisição nova, sem receber a resposta guardada para o primeiro.
+func TestIdempotency_ScopedByUser(t *testing.T) {
+	db := setupDB(t)
+	calls := 0
+	router := newRouter(db, &calls)
+	userService := users.NewService(users.NewRepository(db))
+	_, alice, err := userService.CreateUser(context.Background(), "Alice", "alice@example.com", users.RoleOperator)
+	require.NoError(t, err)
+	_, bob, err := userService.CreateUser(context.Background(), "Bob", "bob@example.com", users.RoleOperator)
+	require.NoError(t, err)
+
+	first := post(router, "abc-123", `{"weight": 10}`, alice)
+	other := post(router, "abc-123", `{"weight": 10}`, bob)
+	anonymous := post(router, "abc-123", `{"weight": 10}`)
+	again := post(router, "abc-123", `{"weight": 10}`, alice)
+
+	assert.Equal(t, 3, calls)
+	assert.NotEqual(t, first.Body.String(), other.Body.String())
+	assert.Empty(t, other.Header().Get(idempotency.HeaderReplayed))
+	assert.Empty(t, anonymous.Header().Get(idempotency.HeaderReplayed))
+	assert.Equal(t, first.Body.String(), again.Body.String())
+	assert.Equal(t, "true", again.Header().Get(idempotency.HeaderReplayed))
+}
+
+// TestStore_ReservePurgesExpired testa se a reserva de uma chave também remove os registros vencidos de outras chaves.
+func TestStore_ReservePurgesExpired(t *testing.T) {
+	db := setupDB(t)
+	now := time.Now()
+	for i, expiresAt := range []time.Time{now.Add(-time.Minute), now.Add(-time.Hour), now.Add(time.Hour)} {
+		require.NoError(t, db.Create(&idempotency.Record{Key: strconv.Itoa(i), Method: "POST", Path: "/deliveries",
+			Fingerprint: "x", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: expiresAt}).Error)
+	}
+
+	existing, err := idempotency.NewStore(db).Reserve(context.Background(), &idempotency.Record{Key: "new", Method: "POST", Path: "/deliveries",
+		Fingerprint: "x", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	var keys []string
+	require.NoError(t, db.Model(&idempotency.Record{}).Order("idempotency_key").Pluck("idempotency_key", &keys).Error)
+	assert.Equal(t, []string{"2", "new"}, keys)
+}
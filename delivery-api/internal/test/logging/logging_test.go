@@ -0,0 +1,140 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"delivery-api/internal/logging"
+	"delivery-api/internal/users"
+)
+
+// newLogger cria um logger em JSON, no nível debug, que escreve no buffer informado.
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(logging.NewHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+// lastEntry decodifica o último registro JSON escrito no buffer.
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+	return entry
+}
+
+// TestRedact testa a máscara de CPF, CNPJ, e-mail e telefone, com e sem pontuação.
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/clients/cpf/123.456.789-09":                "/api/v1/clients/cpf/[CPF]",
+		"cpf=12345678909&page=2":                            "cpf=[CPF]&page=2",
+		"cnpj 12.345.678/0001-95 ou 12345678000195":         "cnpj [CNPJ] ou [CNPJ]",
+		"contato: maria.silva+1@example.com.br":             "contato: [EMAIL]",
+		"tel (11) 98765-4321, +55 21 3456-7890, 98765-4321": "tel [PHONE], [PHONE], [PHONE]",
+		"entrega 42 criada em 2024-05-01 (versão 3)":        "entrega 42 criada em 2024-05-01 (versão 3)",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, logging.Redact(input), input)
+	}
+}
+
+// TestHandler_RedactsMessagesAndAttrs testa a máscara aplicada à mensagem, aos textos, aos erros e aos grupos.
+func TestHandler_RedactsMessagesAndAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf).With("client", "maria@example.com")
+
+	logger.Info("client 123.456.789-09 not found",
+		"error", errors.New("duplicate cpf 123.456.789-09"),
+		slog.Group("contact", "phone", "(11) 98765-4321"),
+		"attempt", 2,
+	)
+
+	output := buf.String()
+	assert.NotContains(t, output, "123.456.789-09")
+	assert.NotContains(t, output, "maria@example.com")
+	assert.NotContains(t, output, "98765-4321")
+	entry := lastEntry(t, &buf)
+	assert.Equal(t, "client [CPF] not found", entry["msg"])
+	assert.Equal(t, "duplicate cpf [CPF]", entry["error"])
+	assert.Equal(t, "[EMAIL]", entry["client"])
+	assert.Equal(t, float64(2), entry["attempt"])
+}
+
+// TestMiddleware_LogsRequest testa o log da requisição: request ID recebido, rota, caminho mascarado,
+// usuário e corpo da resposta de erro.
+func TestMiddleware_LogsRequest(t *testing.T) {
+	var buf bytes.Buffer
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(logging.Middleware(newLogger(&buf)))
+	router.GET("/clients/cpf/:cpf", func(c *gin.Context) {
+		user := &users.User{ID: 7, Role: users.RoleOperator}
+		c.Request = c.Request.WithContext(users.NewContext(c.Request.Context(), user))
+		assert.Equal(t, "req-1", logging.RequestID(c.Request.Context()))
+		c.JSON(http.StatusNotFound, gin.H{"error": "client " + c.Param("cpf") + " not found"})
+	})
+
+	req, _ := http.NewRequest("GET", "/clients/cpf/123.456.789-09", nil)
+	req.Header.Set(logging.HeaderRequestID, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-1", w.Header().Get(logging.HeaderRequestID))
+	assert.NotContains(t, buf.String(), "123.456.789-09")
+	entry := lastEntry(t, &buf)
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "/clients/cpf/:cpf", entry["route"])
+	assert.Equal(t, "/clients/cpf/[CPF]", entry["path"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	assert.Equal(t, `{"error":"client [CPF] not found"}`, entry["response_body"])
+	assert.Equal(t, map[string]interface{}{"id": float64(7), "role": users.RoleOperator}, entry["user"])
+	assert.Contains(t, entry, "latency_ms")
+}
+
+// TestMiddleware_GeneratesRequestID testa se um request ID é gerado quando o recebido está ausente ou é inválido.
+func TestMiddleware_GeneratesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(logging.Middleware(newLogger(&buf)))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, received := range []string{"", "bad\nid", strings.Repeat("x", 200)} {
+		req, _ := http.NewRequest("GET", "/ping", nil)
+		if received != "" {
+			req.Header[logging.HeaderRequestID] = []string{received}
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		id := w.Header().Get(logging.HeaderRequestID)
+		assert.Len(t, id, 32)
+		assert.Equal(t, id, lastEntry(t, &buf)["request_id"])
+	}
+}
+
+// TestRecovery testa se um pânico vira uma resposta 500 e um log de erro.
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+	router := gin.New()
+	router.Use(logging.Middleware(logger), logging.Recovery(logger))
+	router.GET("/panic", func(c *gin.Context) { panic("boom for maria@example.com") })
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, buf.String(), `"msg":"panic recovered"`)
+	assert.Contains(t, buf.String(), "boom for [EMAIL]")
+	assert.Equal(t, "ERROR", lastEntry(t, &buf)["level"])
+}
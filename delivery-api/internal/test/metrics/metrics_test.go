@@ -0,0 +1,103 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/metrics"
+)
+
+// scrape lê o /metrics e retorna o texto no formato do Prometheus.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	router := gin.New()
+	router.GET("/metrics", m.Handler())
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+// TestMiddleware_LabelsByRouteTemplate testa se as requisições são agrupadas pelo modelo da rota, e não pelo caminho.
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	m := metrics.New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/deliveries/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/deliveries/1", "/deliveries/2", "/unknown/path"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `delivery_api_http_request_duration_seconds_count{method="GET",route="/deliveries/:id",status="200"} 2`)
+	assert.Contains(t, body, `delivery_api_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, `route="/deliveries/1"`)
+}
+
+// TestHandleEvent_BusinessCounters testa os contadores de entregas criadas por estado e de mudanças de status.
+func TestHandleEvent_BusinessCounters(t *testing.T) {
+	m := metrics.New()
+	delivery := &deliveries.Delivery{ID: 1, Estado: "SP", OrderStatus: deliveries.OrderStatusShipped}
+
+	created, err := events.New(events.AggregateDelivery, 1, events.DeliveryCreated, delivery)
+	require.NoError(t, err)
+	changed, err := events.New(events.AggregateDelivery, 1, events.DeliveryStatusChanged,
+		deliveries.StatusChange{Delivery: delivery, PreviousStatus: deliveries.OrderStatusPending})
+	require.NoError(t, err)
+	require.NoError(t, m.HandleEvent(context.Background(), created))
+	require.NoError(t, m.HandleEvent(context.Background(), changed))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `delivery_api_deliveries_created_total{estado="SP"} 1`)
+	assert.Contains(t, body, `delivery_api_delivery_status_transitions_total{from="Pendente",to="Enviado"} 1`)
+}
+
+// TestDeliveryStatusGauge testa se a quantidade por status é lida a cada coleta, com zero para os status sem entregas.
+func TestDeliveryStatusGauge(t *testing.T) {
+	m := metrics.New()
+	require.NoError(t, m.RegisterDeliveryStatusGauge(func(ctx context.Context) (map[string]int64, error) {
+		return map[string]int64{deliveries.OrderStatusPending: 3}, nil
+	}))
+
+	expected := `
+# HELP delivery_api_deliveries Entregas cadastradas, por status do pedido.
+# TYPE delivery_api_deliveries gauge
+delivery_api_deliveries{order_status="Cancelado"} 0
+delivery_api_deliveries{order_status="Entregue"} 0
+delivery_api_deliveries{order_status="Enviado"} 0
+delivery_api_deliveries{order_status="Pendente"} 3
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry, strings.NewReader(expected), "delivery_api_deliveries"))
+}
+
+// TestInstrumentDB testa se as consultas do GORM e o pool de conexões são medidos.
+func TestInstrumentDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&deliveries.Delivery{}, &deliveries.Package{}))
+
+	m := metrics.New()
+	require.NoError(t, m.InstrumentDB(db))
+	require.NoError(t, db.Create(&deliveries.Delivery{ClientCPF: "1", Estado: "SP"}).Error)
+	var found []deliveries.Delivery
+	require.NoError(t, db.Find(&found).Error)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `delivery_api_db_query_duration_seconds_count{operation="create",result="ok",table="deliveries"} 1`)
+	assert.Contains(t, body, `delivery_api_db_query_duration_seconds_count{operation="query",result="ok",table="deliveries"} 1`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="sqlite"}`)
+}
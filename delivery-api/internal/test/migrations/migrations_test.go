@@ -0,0 +1,184 @@
+package migrations_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/migrations"
+)
+
+// TestMigrations_SameVersionsForAllDialects testa se toda migração existe para todos os dialetos.
+func TestMigrations_SameVersionsForAllDialects(t *testing.T) {
+	var expected []string
+	for _, dialect := range migrations.Dialects {
+		loaded, err := migrations.Load(dialect)
+		require.NoError(t, err, dialect)
+
+		var names []string
+		for _, migration := range loaded {
+			names = append(names, migration.Name)
+		}
+		if expected == nil {
+			expected = names
+			continue
+		}
+		assert.Equal(t, expected, names, dialect)
+	}
+}
+
+// TestMigrator_UpMatchesModelsAndDown testa se as migrações criam todas as colunas dos models,
+// se a verificação de inicialização acompanha as migrações aplicadas e se o down remove as tabelas.
+func TestMigrator_UpMatchesModelsAndDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	assert.ErrorIs(t, migrator.Check(), migrations.ErrSchemaOutdated)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.Migrations()))
+	assert.NoError(t, migrator.Check())
+
+	// Toda coluna de todo model deve existir depois das migrações.
+	for _, model := range migrations.Models() {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		require.True(t, db.Migrator().HasTable(model), stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(model, field.DBName), "%s.%s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	reverted, err := migrator.Down(len(applied))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(applied))
+	for _, model := range migrations.Models() {
+		assert.False(t, db.Migrator().HasTable(model))
+	}
+}
+
+// TestMigrator_BackfillsDeliveryLifecycle testa se a migração dos horários das entregas preenche a criação,
+// o envio, a entrega e o prazo das entregas existentes a partir dos eventos da outbox.
+func TestMigrator_BackfillsDeliveryLifecycle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	_, err = migrator.Down(len(migrator.Migrations()) - 3) // Volta para a 0003, antes dos horários das entregas (0004)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`INSERT INTO deliveries (id, client_cpf, client_name, test_name, weight, logradouro, numero, bairro,
+		complemento, cidade, estado, pais, latitude, longitude, order_status) VALUES
+		(1, '12345678909', 'Cliente', 'Pedido', 1, 'Rua A', '1', 'Centro', '', 'Recife', 'PE', 'Brasil', 0, 0, 'Entregue')`).Error)
+	for i, event := range []struct{ kind, payload, at string }{
+		{"DeliveryCreated", `{"order_status":"Pendente"}`, "2024-05-06 10:00:00+00:00"},
+		{"DeliveryStatusChanged", `{"delivery":{"order_status":"Enviado"},"previous_status":"Pendente"}`, "2024-05-07 10:00:00+00:00"},
+		{"DeliveryStatusChanged", `{"delivery":{"order_status":"Entregue"},"previous_status":"Enviado"}`, "2024-05-09 10:00:00+00:00"},
+	} {
+		require.NoError(t, db.Exec(`INSERT INTO outbox_events (uuid, aggregate_type, aggregate_id, type, payload, created_at, next_attempt_at)
+			VALUES (?, 'delivery', 1, ?, ?, ?, ?)`, i, event.kind, event.payload, event.at, event.at).Error)
+	}
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	var delivery deliveries.Delivery
+	require.NoError(t, db.First(&delivery, 1).Error)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 10, 0, 0, 0, time.UTC) }
+	assert.True(t, day(6).Equal(delivery.CreatedAt), delivery.CreatedAt)
+	assert.True(t, day(9).Equal(delivery.UpdatedAt), delivery.UpdatedAt)
+	require.NotNil(t, delivery.ShippedAt)
+	assert.True(t, day(7).Equal(*delivery.ShippedAt), delivery.ShippedAt)
+	require.NotNil(t, delivery.DeliveredAt)
+	assert.True(t, day(9).Equal(*delivery.DeliveredAt), delivery.DeliveredAt)
+	require.NotNil(t, delivery.SLADueAt)
+	assert.True(t, day(13).Equal(*delivery.SLADueAt), delivery.SLADueAt) // PE: 7 dias no nível standard
+	assert.Equal(t, deliveries.ServiceLevelStandard, delivery.ServiceLevel)
+}
+
+// TestMigrator_BackfillsDeliveryPackages testa se a migração dos volumes cria um volume para cada entrega existente,
+// com o test_name e o peso dela.
+func TestMigrator_BackfillsDeliveryPackages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	_, err = migrator.Down(len(migrator.Migrations()) - 9) // Volta para a 0009, antes dos volumes (0010)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`INSERT INTO deliveries (id, client_cpf, client_name, test_name, weight, logradouro, numero, bairro,
+		complemento, cidade, estado, pais, latitude, longitude, order_status) VALUES
+		(1, '12345678909', 'Cliente', 'Geladeira', 62.5, 'Rua A', '1', 'Centro', '', 'Recife', 'PE', 'Brasil', 0, 0, 'Pendente')`).Error)
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	var packages []deliveries.Package
+	require.NoError(t, db.Where("delivery_id = ?", 1).Find(&packages).Error)
+	require.Len(t, packages, 1)
+	assert.Equal(t, "Geladeira", packages[0].Description)
+	assert.Equal(t, 1, packages[0].Quantity)
+	assert.Equal(t, 62.5, packages[0].Weight)
+	assert.False(t, packages[0].CreatedAt.IsZero())
+}
+
+// TestMigrator_BackfillsCubedWeight testa o cálculo, na 0011, do peso cubado dos volumes existentes (em centímetros,
+// com o divisor padrão) e dos pesos cubado e tarifado das entregas.
+func TestMigrator_BackfillsCubedWeight(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	_, err = migrator.Down(len(migrator.Migrations()) - 10) // Volta para a 0010, antes do peso cubado (0011)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`INSERT INTO deliveries (id, client_cpf, client_name, test_name, weight, logradouro, numero, bairro,
+		complemento, cidade, estado, pais, latitude, longitude, order_status) VALUES
+		(1, '12345678909', 'Cliente', 'Travesseiros', 5, 'Rua A', '1', 'Centro', '', 'Recife', 'PE', 'Brasil', 0, 0, 'Pendente')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO delivery_packages (delivery_id, description, quantity, weight, length, width, height) VALUES
+		(1, 'Travesseiro', 2, 1, 60, 50, 20), (1, 'Manual', 1, 3, 0, 0, 0)`).Error)
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	var packages []deliveries.Package
+	require.NoError(t, db.Where("delivery_id = ?", 1).Order("id").Find(&packages).Error)
+	require.Len(t, packages, 2)
+	assert.Equal(t, deliveries.DimensionUnitCentimeter, packages[0].DimensionUnit)
+	assert.Equal(t, 10.0, packages[0].CubedWeight) // 60 x 50 x 20 / 6000
+	var delivery deliveries.Delivery
+	require.NoError(t, db.First(&delivery, 1).Error)
+	assert.Equal(t, 20.0, delivery.CubedWeight)
+	assert.Equal(t, 23.0, delivery.ChargeableWeight) // 2 x max(1, 10) + 1 x max(3, 0)
+}
@@ -0,0 +1,131 @@
+package planning_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/planning"
+	"delivery-api/internal/test/fixtures"
+)
+
+// located monta uma entrega com o peso (real e tarifado) e as coordenadas informados.
+func located(id uint, weight, latitude, longitude float64) deliveries.Delivery {
+	return deliveries.Delivery{ID: id, Weight: weight, ChargeableWeight: weight, Latitude: latitude, Longitude: longitude}
+}
+
+// TestPack testa se as entregas próximas ficam no mesmo veículo, se a capacidade é respeitada e se as sobras
+// são informadas com o motivo.
+func TestPack(t *testing.T) {
+	vehicles := []planning.Vehicle{{Label: "A", Capacity: 11}, {Label: "B", Capacity: 11}}
+	candidates := []deliveries.Delivery{
+		located(1, 4, -8.05, -34.95), // Oeste
+		located(2, 4, -8.05, -34.80), // Leste
+		located(3, 4, -8.06, -34.94), // Oeste
+		located(4, 4, -8.06, -34.81), // Leste
+		located(5, 15, -8.05, -34.88),
+		located(6, 3, 0, 0), // Sem coordenadas
+		located(7, 3, 0, 0), // Sem coordenadas
+		located(8, 3, 0, 0), // Sem coordenadas
+	}
+
+	loads, leftovers := planning.Pack(vehicles, candidates)
+	require.Len(t, loads, 2)
+	groups := [][]uint{loads[0].DeliveryIDs[:2], loads[1].DeliveryIDs[:2]}
+	assert.Contains(t, groups, []uint{1, 3})
+	assert.Contains(t, groups, []uint{4, 2})
+	for _, load := range loads {
+		assert.LessOrEqual(t, load.Weight, load.Capacity, load.Label)
+	}
+	assert.Equal(t, 22.0, loads[0].Weight+loads[1].Weight)
+	require.Len(t, leftovers, 2)
+	assert.Equal(t, planning.Leftover{DeliveryID: 5, Weight: 15, Reason: planning.ReasonTooHeavy}, leftovers[0])
+	assert.Equal(t, planning.ReasonNoCapacity, leftovers[1].Reason)
+}
+
+// setup cria o banco em memória (veja fixtures.OpenDB) e os serviços das entregas, dos entregadores e dos planos.
+func setup(t *testing.T) (*gorm.DB, deliveries.Service, couriers.Service, planning.Service) {
+	db := fixtures.OpenDB(t)
+	courierService := couriers.NewService(couriers.NewRepository(db))
+	return db, deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)), courierService,
+		planning.NewService(planning.NewRepository(db), courierService)
+}
+
+// createDelivery cria uma entrega pendente em Recife com o peso informado.
+func createDelivery(t *testing.T, service deliveries.Service, weight float64) *deliveries.Delivery {
+	input := fixtures.NewDelivery()
+	input.Weight = weight
+	delivery, err := service.CreateDelivery(context.Background(), input)
+	require.NoError(t, err)
+	return delivery
+}
+
+// TestApplyPlan testa o cálculo com a capacidade livre dos entregadores, a gravação, a aplicação (que atribui as
+// entregas) e a recusa de uma segunda aplicação e de planos com veículos sem entregador.
+func TestApplyPlan(t *testing.T) {
+	db, deliveryService, courierService, planningService := setup(t)
+	ctx := context.Background()
+	courier, err := courierService.CreateCourier(ctx, &couriers.Courier{
+		Name: "Ana", VehicleType: couriers.VehicleVan, Capacity: 10, HomeCity: "Recife",
+	})
+	require.NoError(t, err)
+	carried := createDelivery(t, deliveryService, 4)
+	_, err = courierService.AssignDelivery(ctx, courier.ID, carried.ID)
+	require.NoError(t, err)
+	first := createDelivery(t, deliveryService, 5)
+	second := createDelivery(t, deliveryService, 5)
+
+	// Restam 6 de capacidade livre ao entregador, então apenas uma das entregas cabe.
+	request := &planning.Request{
+		Filter:   planning.PlanFilter{Cidade: "recife"},
+		Vehicles: []planning.Vehicle{{CourierID: &courier.ID, Capacity: 50}},
+	}
+	preview, err := planningService.CreatePlan(ctx, request, false)
+	require.NoError(t, err)
+	assert.Zero(t, preview.ID)
+	assert.Equal(t, 2, preview.TotalDeliveries)
+	assert.Equal(t, "Ana", preview.Vehicles[0].Label)
+	assert.Equal(t, 6.0, preview.Vehicles[0].Capacity)
+	assert.Equal(t, []uint{first.ID}, preview.Vehicles[0].DeliveryIDs)
+	assert.Equal(t, []planning.Leftover{{DeliveryID: second.ID, Weight: 5, Reason: planning.ReasonNoCapacity}}, preview.Unassigned)
+	plans, err := planningService.GetPlans(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+
+	plan, err := planningService.CreatePlan(ctx, request, true)
+	require.NoError(t, err)
+	assert.Equal(t, planning.PlanStatusDraft, plan.Status)
+
+	applied, err := planningService.ApplyPlan(ctx, plan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, planning.PlanStatusApplied, applied.Status)
+	assert.NotNil(t, applied.AppliedAt)
+	var assigned deliveries.Delivery
+	require.NoError(t, db.First(&assigned, first.ID).Error)
+	require.NotNil(t, assigned.CourierID)
+	assert.Equal(t, courier.ID, *assigned.CourierID)
+
+	_, err = planningService.ApplyPlan(ctx, plan.ID)
+	assert.ErrorIs(t, err, planning.ErrPlanApplied)
+
+	// Um veículo sem entregador impede a aplicação, e o plano continua como rascunho.
+	anonymous, err := planningService.CreatePlan(ctx, &planning.Request{
+		Filter:   planning.PlanFilter{Estado: "pe"},
+		Vehicles: []planning.Vehicle{{Label: "Van 1", Capacity: 20}},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{second.ID}, anonymous.Vehicles[0].DeliveryIDs)
+	_, err = planningService.ApplyPlan(ctx, anonymous.ID)
+	assert.ErrorIs(t, err, planning.ErrInvalidPlan)
+	stored, err := planningService.GetPlanByID(ctx, anonymous.ID)
+	require.NoError(t, err)
+	assert.Equal(t, planning.PlanStatusDraft, stored.Status)
+
+	_, err = planningService.CreatePlan(ctx, &planning.Request{Vehicles: []planning.Vehicle{{Capacity: 1}}}, false)
+	assert.ErrorIs(t, err, planning.ErrInvalidPlan)
+}
@@ -0,0 +1,185 @@
+package proofs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/blob"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/proofs"
+	"delivery-api/internal/test/fixtures"
+)
+
+// pngImage é o início de um arquivo PNG, suficiente para que o tipo seja reconhecido pelo conteúdo.
+var pngImage = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+
+// setup cria o banco em memória, o armazenamento de arquivos em um diretório temporário, uma entrega enviada
+// e o router com as rotas dos comprovantes.
+func setup(t *testing.T) (*gorm.DB, string, deliveries.Service, *gin.Engine, uint) {
+	db := fixtures.OpenDB(t)
+
+	dir := t.TempDir()
+	store, err := blob.NewLocalStore(dir)
+	require.NoError(t, err)
+
+	deliveryService := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	input := fixtures.NewDelivery()
+	input.OrderStatus = deliveries.OrderStatusShipped
+	delivery, err := deliveryService.CreateDelivery(context.Background(), input)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := proofs.Handler{Service: proofs.NewService(proofs.NewRepository(db), store)}
+	router.POST("/deliveries/:id/proof", handler.SubmitProof)
+	router.GET("/deliveries/:id/proof", handler.GetProof)
+	router.GET("/deliveries/:id/proof/:file", handler.DownloadFile)
+	return db, dir, deliveryService, router, delivery.ID
+}
+
+// proofForm monta o formulário multipart do comprovante, com os campos informados e a assinatura.
+func proofForm(t *testing.T, fields map[string]string, signature []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	if signature != nil {
+		part, err := writer.CreateFormFile("signature", "signature.png")
+		require.NoError(t, err)
+		_, err = part.Write(signature)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+// submit envia o formulário do comprovante da entrega.
+func submit(t *testing.T, router *gin.Engine, id uint, fields map[string]string, signature []byte) *httptest.ResponseRecorder {
+	body, contentType := proofForm(t, fields, signature)
+	req := httptest.NewRequest(http.MethodPost, proofPath(id, ""), body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// proofPath retorna a rota do comprovante da entrega, seguida de suffix.
+func proofPath(id uint, suffix string) string {
+	return fmt.Sprintf("/deliveries/%d/proof%s", id, suffix)
+}
+
+// validFields retorna os campos de um comprovante válido.
+func validFields() map[string]string {
+	return map[string]string{
+		"recipient_name":     "Maria Souza",
+		"recipient_document": "123.456.789-09",
+		"latitude":           "-8.0476",
+		"longitude":          "-34.8770",
+		"delivered_at":       time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}
+}
+
+// TestSubmitProof testa o registro do comprovante, a consulta, o download da assinatura, a recusa de um segundo
+// comprovante e a mudança para Entregue, que só é aceita depois do registro.
+func TestSubmitProof(t *testing.T) {
+	_, dir, deliveryService, router, id := setup(t)
+	ctx := context.Background()
+
+	err := deliveryService.UpdateOrderStatus(ctx, id, deliveries.OrderStatusDelivered, 0)
+	require.ErrorIs(t, err, deliveries.ErrProofRequired)
+
+	w := submit(t, router, id, validFields(), pngImage)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var proof proofs.Proof
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &proof))
+	assert.Equal(t, "Maria Souza", proof.RecipientName)
+	assert.Equal(t, "image/png", proof.SignatureType)
+	assert.Len(t, proof.SignatureSHA256, 64)
+	assert.Equal(t, "/api/v1"+proofPath(id, "/signature"), proof.SignatureURL)
+	assert.Empty(t, proof.PhotoURL)
+
+	req := httptest.NewRequest(http.MethodGet, proofPath(id, "/signature"), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, pngImage, w.Body.Bytes())
+
+	req = httptest.NewRequest(http.MethodGet, proofPath(id, "/photo"), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// O comprovante não pode ser substituído, e os arquivos do segundo envio não ficam no armazenamento.
+	w = submit(t, router, id, validFields(), pngImage)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	files, err := filepath.Glob(filepath.Join(dir, "proofs", fmt.Sprint(id), "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	require.NoError(t, deliveryService.UpdateOrderStatus(ctx, id, deliveries.OrderStatusDelivered, 0))
+
+	// O comprovante continua disponível depois que a entrega é removida.
+	require.NoError(t, deliveryService.DeleteDelivery(ctx, id, 0))
+	req = httptest.NewRequest(http.MethodGet, proofPath(id, ""), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestSubmitProof_Invalid testa a recusa de comprovantes incompletos ou com arquivos que não são imagens,
+// e de comprovantes de entregas inexistentes ou canceladas.
+func TestSubmitProof_Invalid(t *testing.T) {
+	db, dir, _, router, id := setup(t)
+
+	for name, tc := range map[string]struct {
+		field, value string
+		signature    []byte
+		message      string
+	}{
+		"missing recipient": {"recipient_name", "", pngImage, "recipient_name is required"},
+		"latitude range":    {"latitude", "91", pngImage, "latitude must be between -90 and 90"},
+		"future timestamp":  {"delivered_at", time.Now().Add(time.Hour).Format(time.RFC3339), pngImage, "must not be in the future"},
+		"bad timestamp":     {"delivered_at", "10/05/2024", pngImage, "invalid delivered_at"},
+		"missing signature": {"recipient_name", "Maria", nil, "signature is required"},
+		"not an image":      {"recipient_name", "Maria", []byte("%PDF-1.4"), "signature must be a PNG or JPEG image"},
+	} {
+		fields := validFields()
+		fields[tc.field] = tc.value
+		w := submit(t, router, id, fields, tc.signature)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), tc.message, name)
+	}
+
+	w := submit(t, router, id+100, validFields(), pngImage)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	require.NoError(t, db.Model(&deliveries.Delivery{}).Where("id = ?", id).
+		UpdateColumn("order_status", deliveries.OrderStatusCanceled).Error)
+	w = submit(t, router, id, validFields(), pngImage)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Os arquivos das tentativas recusadas pelo banco são removidos.
+	entries, err := os.ReadDir(filepath.Join(dir, "proofs"))
+	if err == nil {
+		for _, entry := range entries {
+			files, _ := os.ReadDir(filepath.Join(dir, "proofs", entry.Name()))
+			assert.Empty(t, files, entry.Name())
+		}
+	}
+}
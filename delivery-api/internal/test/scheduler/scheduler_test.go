@@ -0,0 +1,131 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/scheduler"
+)
+
+// setup cria o banco em memória com a tabela das rotinas agendadas.
+func setup(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&scheduler.JobState{}))
+	return db
+}
+
+// newScheduler cria um scheduler com a identificação de instância informada.
+func newScheduler(db *gorm.DB, owner string) *scheduler.Scheduler {
+	return scheduler.New(scheduler.NewRepository(db), scheduler.Config{Enabled: true, LeaseTTL: time.Minute, Owner: owner})
+}
+
+// TestSchedule_Next testa o próximo horário de expressões com passos, listas, intervalos e atalhos.
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2024, 5, 6, 10, 7, 30, 0, time.UTC) // Segunda-feira
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 5, 6, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 5, 6, 11, 0, 0, 0, time.UTC)},
+		{"5 0 * * *", time.Date(2024, 5, 7, 0, 5, 0, 0, time.UTC)},
+		{"30 8-18/2 * * 1-5", time.Date(2024, 5, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 0,6", time.Date(2024, 5, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)}, // Dia 13 ou sexta-feira
+		{"@weekly", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)}, // 7 também é domingo
+	}
+	for _, c := range cases {
+		schedule, err := scheduler.Parse(c.expr)
+		require.NoError(t, err, c.expr)
+		assert.Equal(t, c.want, schedule.Next(from), c.expr)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := scheduler.Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+// TestScheduler_RunsEachOccurrenceOnce testa se duas instâncias sobre o mesmo banco executam cada ocorrência
+// de uma rotina uma única vez, e se o resultado fica gravado.
+func TestScheduler_RunsEachOccurrenceOnce(t *testing.T) {
+	db := setup(t)
+	var runs atomic.Int32
+	job := scheduler.Job{Name: "count", Schedule: "* * * * *", Run: func(ctx context.Context) (string, error) {
+		runs.Add(1)
+		return "counted", nil
+	}}
+
+	first, second := newScheduler(db, "a"), newScheduler(db, "b")
+	require.NoError(t, first.Register(context.Background(), job))
+	require.NoError(t, second.Register(context.Background(), job))
+
+	now := time.Now().Add(2 * time.Minute)
+	first.RunDue(now)
+	second.RunDue(now)
+	assert.Equal(t, int32(1), runs.Load())
+
+	// A ocorrência seguinte roda de novo, em qualquer uma das instâncias.
+	second.RunDue(now.Add(time.Minute))
+	first.RunDue(now.Add(time.Minute))
+	assert.Equal(t, int32(2), runs.Load())
+
+	jobs, err := first.Jobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.NotNil(t, jobs[0].State)
+	assert.Equal(t, scheduler.StatusSucceeded, jobs[0].State.LastStatus)
+	assert.Equal(t, "counted", jobs[0].State.LastResult)
+	assert.Empty(t, jobs[0].State.LockedBy)
+	assert.NotNil(t, jobs[0].NextRunAt)
+}
+
+// TestScheduler_Trigger testa a execução manual: rotina desconhecida, rotina já em execução (nesta ou em outra
+// instância) e o erro gravado.
+func TestScheduler_Trigger(t *testing.T) {
+	db := setup(t)
+	release := make(chan struct{})
+	job := scheduler.Job{Name: "slow", Run: func(ctx context.Context) (string, error) {
+		<-release
+		return "", errors.New("boom")
+	}}
+	s, other := newScheduler(db, "a"), newScheduler(db, "b")
+	require.NoError(t, s.Register(context.Background(), job))
+	require.NoError(t, other.Register(context.Background(), job))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	assert.ErrorIs(t, s.Trigger(context.Background(), "missing"), scheduler.ErrJobNotFound)
+	require.NoError(t, s.Trigger(context.Background(), "slow"))
+	assert.ErrorIs(t, s.Trigger(context.Background(), "slow"), scheduler.ErrJobRunning)
+	assert.ErrorIs(t, other.Trigger(context.Background(), "slow"), scheduler.ErrJobRunning) // Reservada pela instância "a"
+
+	// Run espera a execução em andamento terminar no desligamento.
+	close(release)
+	cancel()
+	<-done
+
+	var state scheduler.JobState
+	require.NoError(t, db.First(&state, "name = ?", "slow").Error)
+	assert.Equal(t, scheduler.StatusFailed, state.LastStatus)
+	assert.Equal(t, "boom", state.LastError)
+	assert.Nil(t, state.LastScheduledAt)
+}
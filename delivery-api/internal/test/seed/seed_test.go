@@ -0,0 +1,43 @@
+package seed_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"delivery-api/internal/seed"
+)
+
+// TestGenerator_Documents testa se os CPFs e CNPJs gerados têm dígitos verificadores válidos
+// e estão no formato aceito pela API.
+func TestGenerator_Documents(t *testing.T) {
+	generator := seed.NewGenerator(42)
+	cpfFormat := regexp.MustCompile(`^\d{3}\.\d{3}\.\d{3}-\d{2}$`)
+	cnpjFormat := regexp.MustCompile(`^\d{2}\.\d{3}\.\d{3}/0001-\d{2}$`)
+
+	for i := 0; i < 500; i++ {
+		cpf, cnpj := generator.CPF(), generator.CNPJ()
+		assert.Regexp(t, cpfFormat, cpf)
+		assert.Regexp(t, cnpjFormat, cnpj)
+		assert.True(t, seed.ValidCPF(cpf), cpf)
+		assert.True(t, seed.ValidCNPJ(cnpj), cnpj)
+	}
+}
+
+// TestValidDocuments testa a verificação dos dígitos com documentos conhecidos.
+func TestValidDocuments(t *testing.T) {
+	assert.True(t, seed.ValidCPF("529.982.247-25"))
+	assert.False(t, seed.ValidCPF("529.982.247-24"))
+	assert.False(t, seed.ValidCPF("111.111.111-11"))
+	assert.True(t, seed.ValidCNPJ("11.222.333/0001-81"))
+	assert.False(t, seed.ValidCNPJ("11.222.333/0001-80"))
+}
+
+// TestGenerator_Deterministic testa se a mesma semente gera os mesmos dados.
+func TestGenerator_Deterministic(t *testing.T) {
+	first, second := seed.NewGenerator(7), seed.NewGenerator(7)
+	client := first.Client()
+	assert.Equal(t, client, second.Client())
+	assert.Equal(t, first.Delivery(&client), second.Delivery(&client))
+}
@@ -0,0 +1,61 @@
+package timeout_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"delivery-api/internal/timeout"
+)
+
+// setupRouter cria um router com o middleware de timeout e rotas que informam o prazo recebido no contexto.
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(timeout.Middleware(20*time.Millisecond, map[string]time.Duration{
+		"GET /export": time.Minute,
+		"GET /stream": 0,
+	}))
+
+	deadline := func(c *gin.Context) {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			c.String(http.StatusOK, time.Until(deadline).Round(time.Minute).String())
+			return
+		}
+		c.String(http.StatusOK, "none")
+	}
+	router.GET("/export", deadline)
+	router.GET("/stream", deadline)
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+	})
+	return router
+}
+
+// get envia uma requisição GET para o caminho informado.
+func get(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestMiddleware_PerRouteTimeouts testa os prazos específicos por rota e a rota sem prazo.
+func TestMiddleware_PerRouteTimeouts(t *testing.T) {
+	router := setupRouter()
+
+	assert.Equal(t, "1m0s", get(router, "/export").Body.String())
+	assert.Equal(t, "none", get(router, "/stream").Body.String())
+}
+
+// TestMiddleware_DeadlineExceeded testa se o erro causado pelo prazo estourado é respondido com 504.
+func TestMiddleware_DeadlineExceeded(t *testing.T) {
+	w := get(setupRouter(), "/slow")
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to fetch deliveries")
+}
@@ -0,0 +1,136 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/tracing"
+)
+
+// incomingTraceParent é o traceparent enviado pelo "cliente" nos testes.
+const incomingTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// setupTracing grava os spans em memória durante o teste.
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return exporter
+}
+
+// spanByName procura o span gravado com o nome informado.
+func spanByName(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+// TestSanitizeSQL testa a remoção dos valores literais do SQL gravado nos spans.
+func TestSanitizeSQL(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM `deliveries` WHERE client_cpf = ? LIMIT 10":         "SELECT * FROM `deliveries` WHERE client_cpf = ? LIMIT ?",
+		"SELECT * FROM clients WHERE cpf = '123.456.789-09' AND id = 42.5": "SELECT * FROM clients WHERE cpf = ? AND id = ?",
+		`UPDATE "deliveries" SET "order_status"=$1 WHERE id = $2`:          `UPDATE "deliveries" SET "order_status"=$1 WHERE id = $2`,
+		"SELECT name FROM table1 WHERE name = 'D''Ávila'":                  "SELECT name FROM table1 WHERE name = ?",
+	}
+	for sql, expected := range cases {
+		assert.Equal(t, expected, tracing.SanitizeSQL(sql))
+	}
+}
+
+// TestTracing_RequestPath testa se a requisição, o serviço e as consultas ficam no mesmo trace recebido no
+// traceparent, e se o trace é guardado no evento da outbox.
+func TestTracing_RequestPath(t *testing.T) {
+	exporter := setupTracing(t)
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&deliveries.Delivery{}, &deliveries.Package{}, &events.Event{}))
+	require.NoError(t, tracing.InstrumentDB(db))
+
+	service := deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.PATCH("/deliveries/:id/:status", func(c *gin.Context) {
+		err := service.UpdateOrderStatus(c.Request.Context(), 1, deliveries.OrderStatusShipped, 0)
+		require.NoError(t, err)
+		c.Status(http.StatusNoContent)
+	})
+	require.NoError(t, db.Create(&deliveries.Delivery{ClientCPF: "123.456.789-09", OrderStatus: deliveries.OrderStatusPending}).Error)
+	exporter.Reset()
+
+	req, _ := http.NewRequest(http.MethodPatch, "/deliveries/1/status", nil)
+	req.Header.Set("traceparent", incomingTraceParent)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	spans := exporter.GetSpans()
+	server := spanByName(spans, "PATCH /deliveries/:id/:status")
+	require.NotNil(t, server)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	serviceSpan := spanByName(spans, "deliveries.UpdateOrderStatus")
+	require.NotNil(t, serviceSpan)
+	assert.Equal(t, server.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+
+	update := spanByName(spans, "UPDATE deliveries")
+	require.NotNil(t, update)
+	assert.Equal(t, server.SpanContext.TraceID(), update.SpanContext.TraceID())
+	for _, attr := range update.Attributes {
+		if attr.Key == "db.query.text" {
+			assert.NotContains(t, attr.Value.AsString(), "123.456.789-09")
+		}
+	}
+
+	var event events.Event
+	require.NoError(t, db.Where("type = ?", events.DeliveryStatusChanged).First(&event).Error)
+	assert.True(t, strings.HasPrefix(event.TraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+}
+
+// TestTransport_PropagatesTraceParent testa se as requisições enviadas levam o trace no cabeçalho traceparent.
+func TestTransport_PropagatesTraceParent(t *testing.T) {
+	exporter := setupTracing(t)
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx := tracing.WithTraceParent(context.Background(), incomingTraceParent)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/hook?token=secret", nil)
+	client := &http.Client{Transport: tracing.Transport(nil)}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	traceparent := <-received
+	assert.True(t, strings.HasPrefix(traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, traceparent[36:52], spans[0].SpanContext.SpanID().String())
+	for _, attr := range spans[0].Attributes {
+		assert.NotContains(t, attr.Value.Emit(), "secret")
+	}
+}
@@ -0,0 +1,123 @@
+package users_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/users"
+)
+
+// setupService cria o serviço de usuários sobre um banco SQLite em memória.
+func setupService(t *testing.T) (users.Service, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&users.User{}))
+	return users.NewService(users.NewRepository(db)), db
+}
+
+// setupRouter cria um router com o middleware Identify e uma rota que devolve o e-mail do usuário identificado.
+func setupRouter(service users.Service) *gin.Engine {
+	router := gin.New()
+	router.Use(users.Identify(service))
+	router.GET("/me", func(c *gin.Context) {
+		email := "anonymous"
+		if user := users.FromContext(c.Request.Context()); user != nil {
+			email = user.Email
+		}
+		c.String(http.StatusOK, email)
+	})
+	return router
+}
+
+// get envia uma requisição GET /me com o cabeçalho Authorization informado.
+func get(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/me", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestCreateUser_StoresOnlyTokenHash testa se o token gerado identifica o usuário e se apenas o hash é gravado.
+func TestCreateUser_StoresOnlyTokenHash(t *testing.T) {
+	service, db := setupService(t)
+
+	user, token, err := service.CreateUser(context.Background(), "Maria Souza", "Maria@Example.com", users.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, "maria@example.com", user.Email)
+
+	var stored users.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Equal(t, users.HashToken(token), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, token)
+
+	authenticated, err := service.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, authenticated.ID)
+}
+
+// TestCreateUser_Rejects testa a rejeição de e-mail duplicado e de papel inválido.
+func TestCreateUser_Rejects(t *testing.T) {
+	service, _ := setupService(t)
+
+	_, _, err := service.CreateUser(context.Background(), "Maria Souza", "maria@example.com", users.RoleOperator)
+	require.NoError(t, err)
+
+	_, _, err = service.CreateUser(context.Background(), "Outra Maria", "maria@example.com", users.RoleOperator)
+	assert.ErrorIs(t, err, users.ErrEmailTaken)
+
+	_, _, err = service.CreateUser(context.Background(), "João Lima", "joao@example.com", "root")
+	assert.Error(t, err)
+}
+
+// TestIdentify testa o middleware com requisições anônimas, tokens válidos e tokens inválidos.
+func TestIdentify(t *testing.T) {
+	service, _ := setupService(t)
+	_, token, err := service.CreateUser(context.Background(), "Maria Souza", "maria@example.com", users.RoleOperator)
+	require.NoError(t, err)
+	router := setupRouter(service)
+
+	w := get(router, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "anonymous", w.Body.String())
+
+	w = get(router, "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "maria@example.com", w.Body.String())
+
+	w = get(router, "Bearer dk_invalid")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = get(router, "Basic "+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestRequireRole testa a restrição de rota por papel: anônimo (401), papel sem permissão (403) e administrador.
+func TestRequireRole(t *testing.T) {
+	service, _ := setupService(t)
+	_, operatorToken, err := service.CreateUser(context.Background(), "Maria Souza", "maria@example.com", users.RoleOperator)
+	require.NoError(t, err)
+	_, adminToken, err := service.CreateUser(context.Background(), "João Lima", "joao@example.com", users.RoleAdmin)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(users.Identify(service))
+	router.GET("/me", users.RequireRole(users.RoleAdmin), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	assert.Equal(t, http.StatusUnauthorized, get(router, "").Code)
+	assert.Equal(t, http.StatusForbidden, get(router, "Bearer "+operatorToken).Code)
+	assert.Equal(t, http.StatusOK, get(router, "Bearer "+adminToken).Code)
+}
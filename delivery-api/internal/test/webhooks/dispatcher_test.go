@@ -0,0 +1,190 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/webhooks"
+)
+
+// receiver é um parceiro falso que guarda as requisições recebidas e responde com o status configurado.
+type receiver struct {
+	mu         sync.Mutex
+	status     int
+	bodies     [][]byte
+	signatures []string
+}
+
+// ServeHTTP registra a requisição e responde com o status configurado.
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.signatures = append(r.signatures, req.Header.Get(webhooks.HeaderSignature))
+	w.WriteHeader(r.status)
+}
+
+// setup cria o banco em memória, o dispatcher e o serviço de webhooks.
+// Os parceiros falsos rodam em 127.0.0.1, por isso os destinos internos são permitidos.
+func setup(t *testing.T) (webhooks.Service, *webhooks.Dispatcher) {
+	service, dispatcher, _ := setupWith(t, true)
+	return service, dispatcher
+}
+
+// setupWith é como setup, mas permite escolher se os destinos internos são aceitos e também retorna o repositório.
+// A espera entre tentativas é zero para que as novas tentativas possam ser feitas imediatamente no teste.
+func setupWith(t *testing.T, allowPrivateTargets bool) (webhooks.Service, *webhooks.Dispatcher, webhooks.Repository) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Mantém todas as consultas no mesmo banco em memória
+	require.NoError(t, db.AutoMigrate(&webhooks.Subscription{}, &webhooks.Message{}))
+
+	config := webhooks.DefaultDispatcherConfig()
+	config.MaxAttempts = 3
+	config.RetryBase = 0
+	config.Timeout = time.Second
+	config.AllowPrivateTargets = allowPrivateTargets
+
+	repo := webhooks.NewRepository(db)
+	dispatcher := webhooks.NewDispatcher(repo, nil, config)
+	return webhooks.NewService(repo, dispatcher), dispatcher, repo
+}
+
+// TestDispatcher_SignsAndDelivers testa se o evento é entregue com uma assinatura HMAC válida.
+func TestDispatcher_SignsAndDelivers(t *testing.T) {
+	partner := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(partner)
+	defer server.Close()
+
+	service, dispatcher := setup(t)
+	subscription, err := service.CreateSubscription(context.Background(), &webhooks.Subscription{
+		URL:    server.URL,
+		Events: []string{deliveries.EventDeliveryCreated},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, subscription.Secret)
+
+	// Eventos não assinados não geram mensagens
+	require.NoError(t, service.Publish(context.Background(), "", deliveries.EventDeliveryDeleted, &deliveries.Delivery{ID: 1}))
+	require.NoError(t, service.Publish(context.Background(), "", deliveries.EventDeliveryCreated, &deliveries.Delivery{ID: 1}))
+
+	processed, err := dispatcher.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	require.Len(t, partner.bodies, 1)
+	assert.NoError(t, webhooks.Verify(subscription.Secret, partner.signatures[0], partner.bodies[0], time.Minute))
+	assert.ErrorIs(t, webhooks.Verify("outro-segredo", partner.signatures[0], partner.bodies[0], time.Minute), webhooks.ErrInvalidSignature)
+}
+
+// TestDispatcher_DeadLetterAndRedeliver testa se a mensagem vai para a lista de mortas após as tentativas
+// e se o reenvio manual a entrega quando o parceiro volta a responder.
+func TestDispatcher_DeadLetterAndRedeliver(t *testing.T) {
+	partner := &receiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(partner)
+	defer server.Close()
+
+	service, dispatcher := setup(t)
+	_, err := service.CreateSubscription(context.Background(), &webhooks.Subscription{
+		URL:    server.URL,
+		Events: []string{deliveries.EventDeliveryStatusChanged},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.Publish(context.Background(), "", deliveries.EventDeliveryStatusChanged, map[string]string{"status": "Enviado"}))
+
+	// Três tentativas com falha levam a mensagem para a lista de mortas
+	for i := 0; i < 3; i++ {
+		_, err := dispatcher.ProcessDue(context.Background())
+		require.NoError(t, err)
+	}
+	dead, err := service.GetDeadLetters(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, dead[0].LastStatusCode)
+
+	// Depois que o parceiro se recupera, o reenvio manual entrega a mensagem
+	partner.status = http.StatusNoContent
+	_, err = service.Redeliver(context.Background(), dead[0].ID)
+	require.NoError(t, err)
+	_, err = dispatcher.ProcessDue(context.Background())
+	require.NoError(t, err)
+
+	dead, err = service.GetDeadLetters(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+	assert.Len(t, partner.bodies, 4)
+}
+
+// TestCreateSubscription_RejectsPrivateTargets testa se URLs para endereços internos são recusadas.
+func TestCreateSubscription_RejectsPrivateTargets(t *testing.T) {
+	service, _, _ := setupWith(t, false)
+
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := service.CreateSubscription(context.Background(), &webhooks.Subscription{
+			URL:    target,
+			Events: []string{deliveries.EventDeliveryCreated},
+		})
+		assert.ErrorIs(t, err, webhooks.ErrInvalidSubscription, target)
+	}
+
+	_, err := service.CreateSubscription(context.Background(), &webhooks.Subscription{
+		URL:    "https://partner.example.com/hook",
+		Events: []string{deliveries.EventDeliveryCreated},
+	})
+	assert.NoError(t, err)
+}
+
+// TestDispatcher_RefusesPrivateAddressOnDial testa se o dialer recusa a conexão com um endereço interno
+// mesmo quando a assinatura passou pela validação (por exemplo, um nome que passou a resolver para 127.0.0.1).
+func TestDispatcher_RefusesPrivateAddressOnDial(t *testing.T) {
+	partner := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(partner)
+	defer server.Close()
+
+	service, dispatcher, repo := setupWith(t, false)
+	require.NoError(t, repo.CreateSubscription(context.Background(), &webhooks.Subscription{
+		URL:    server.URL,
+		Secret: "secret",
+		Events: []string{deliveries.EventDeliveryCreated},
+		Active: true,
+	}))
+	require.NoError(t, service.Publish(context.Background(), "", deliveries.EventDeliveryCreated, &deliveries.Delivery{ID: 1}))
+
+	processed, err := dispatcher.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	pending, err := repo.FindMessagesByStatus(context.Background(), webhooks.MessageStatusPending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Contains(t, pending[0].LastError, webhooks.ErrPrivateTarget.Error())
+
+	partner.mu.Lock()
+	defer partner.mu.Unlock()
+	assert.Empty(t, partner.bodies)
+}
@@ -0,0 +1,170 @@
+package zones_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/test/fixtures"
+	"delivery-api/internal/zones"
+)
+
+// square monta um Feature com um quadrado de lado size a partir do canto sudoeste (lat, lon).
+func square(name string, lat, lon, size float64) string {
+	ring := [][2]float64{{lon, lat}, {lon + size, lat}, {lon + size, lat + size}, {lon, lat + size}, {lon, lat}}
+	feature := map[string]interface{}{
+		"type":       "Feature",
+		"properties": map[string]string{"name": name, "cidade": "São Paulo"},
+		"geometry":   map[string]interface{}{"type": "Polygon", "coordinates": [][][2]float64{ring}},
+	}
+	data, _ := json.Marshal(feature)
+	return string(data)
+}
+
+// collection junta os Features em um FeatureCollection.
+func collection(features ...string) string {
+	body := `{"type": "FeatureCollection", "features": [`
+	for i, feature := range features {
+		if i > 0 {
+			body += ","
+		}
+		body += feature
+	}
+	return body + "]}"
+}
+
+// setup cria o banco em memória (veja fixtures.OpenDB), os serviços e o roteador das zonas. O contador indica
+// quantas vezes a mudança nas zonas foi avisada.
+func setup(t *testing.T) (*gorm.DB, deliveries.Service, *gin.Engine, *int) {
+	db := fixtures.OpenDB(t)
+
+	changes := 0
+	handler := zones.Handler{Service: zones.NewService(zones.NewRepository(db), func(ctx context.Context) { changes++ })}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/zones", handler.UploadZones)
+	router.DELETE("/zones/:id", handler.DeleteZone)
+	return db, deliveries.NewService(deliveries.NewRepository(db, deliveries.DefaultCubicDivisor)), router, &changes
+}
+
+// upload envia o GeoJSON e retorna o status e as zonas gravadas.
+func upload(t *testing.T, router *gin.Engine, body string) (int, []zones.Zone) {
+	req := httptest.NewRequest(http.MethodPost, "/zones", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var saved []zones.Zone
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &saved))
+	}
+	return w.Code, saved
+}
+
+// createDelivery cria uma entrega pendente nas coordenadas informadas.
+func createDelivery(t *testing.T, service deliveries.Service, lat, lon float64) *deliveries.Delivery {
+	input := fixtures.NewDelivery()
+	input.Cidade, input.Estado, input.Latitude, input.Longitude = "São Paulo", "SP", lat, lon
+	delivery, err := service.CreateDelivery(context.Background(), input)
+	require.NoError(t, err)
+	return delivery
+}
+
+// TestHandler_UploadZones testa a recusa de GeoJSON inválido e a substituição de uma zona enviada de novo pelo nome.
+func TestHandler_UploadZones(t *testing.T) {
+	_, _, router, changes := setup(t)
+
+	invalid := []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		collection(),
+		collection(square("", -23.6, -46.7, 0.1)),
+		collection(square("Centro", -23.6, -46.7, 0.1), square("centro", -23.5, -46.7, 0.1)),
+		`{"type": "Feature", "properties": {"name": "Aberta"}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}}`,
+	}
+	for _, body := range invalid {
+		status, _ := upload(t, router, body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+	assert.Zero(t, *changes)
+
+	status, saved := upload(t, router, square("Centro", -23.6, -46.7, 0.1))
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, saved, 1)
+	assert.Equal(t, -23.5, saved[0].MaxLat)
+
+	status, replaced := upload(t, router, collection(square("CENTRO", -23.0, -46.0, 0.2)))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, saved[0].ID, replaced[0].ID)
+	assert.Equal(t, "CENTRO", replaced[0].Name)
+	assert.Equal(t, -23.0, replaced[0].MinLat)
+	assert.Equal(t, 2, *changes)
+}
+
+// TestDeliveryZones testa a localização da zona na criação e na alteração da entrega (com a zona menor prevalecendo
+// sobre a maior), o filtro por zona e o recálculo depois que as zonas mudam.
+func TestDeliveryZones(t *testing.T) {
+	db, deliveryService, router, _ := setup(t)
+	ctx := context.Background()
+	status, saved := upload(t, router, collection(square("Cidade", -24, -47, 1), square("Centro", -23.6, -46.7, 0.1)))
+	require.Equal(t, http.StatusOK, status)
+	city, center := saved[0].ID, saved[1].ID
+
+	inCenter := createDelivery(t, deliveryService, -23.55, -46.65)
+	inCity := createDelivery(t, deliveryService, -23.2, -46.2)
+	outside := createDelivery(t, deliveryService, -8.05, -34.9)
+	withoutCoordinates := createDelivery(t, deliveryService, 0, 0)
+	require.NotNil(t, inCenter.ZoneID)
+	assert.Equal(t, center, *inCenter.ZoneID)
+	require.NotNil(t, inCity.ZoneID)
+	assert.Equal(t, city, *inCity.ZoneID)
+	assert.Nil(t, outside.ZoneID)
+	assert.Nil(t, withoutCoordinates.ZoneID)
+
+	// A alteração das coordenadas muda a zona; o zone_id enviado pelo cliente é ignorado.
+	update := *outside
+	update.Version = 0
+	update.Latitude, update.Longitude = -23.58, -46.68
+	update.ZoneID = &city
+	updated, err := deliveryService.UpdateDelivery(ctx, outside.ID, &update)
+	require.NoError(t, err)
+	require.NotNil(t, updated.ZoneID)
+	assert.Equal(t, center, *updated.ZoneID)
+
+	list, err := deliveryService.GetDeliveries(ctx, deliveries.Filter{ZoneID: center})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	// Depois que a zona do centro é removida, o recálculo passa as duas entregas dela para a zona da cidade.
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/zones/%d", center), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	changed, err := deliveryService.RecomputeZones(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+	list, err = deliveryService.GetDeliveries(ctx, deliveries.Filter{ZoneID: city})
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	var event events.Event
+	require.NoError(t, db.Where("aggregate_id = ? AND type = ?", inCenter.ID, events.DeliveryZoneChanged).First(&event).Error)
+	var change deliveries.ZoneChange
+	require.NoError(t, event.Decode(&change))
+	assert.Equal(t, city, *change.ZoneID)
+	assert.Equal(t, center, *change.PreviousZoneID)
+	assert.Equal(t, uint(2), change.Delivery.Version)
+
+	changed, err = deliveryService.RecomputeZones(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, changed)
+}
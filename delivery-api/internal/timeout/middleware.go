@@ -0,0 +1,49 @@
+// Package timeout limita o tempo de cada requisição HTTP pelo contexto repassado aos serviços e repositórios.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware aplica um prazo ao contexto da requisição. Quando o prazo estoura, as consultas ao banco feitas com esse
+// contexto são canceladas e a resposta de erro do handler é enviada com o status 504 (Gateway Timeout).
+//
+// O prazo é o de routes para a rota da requisição, no formato "MÉTODO /rota" com o modelo registrado no Gin
+// (por exemplo, "GET /api/v1/deliveries/export"), ou defaultTimeout para as demais. Um prazo 0 desativa o limite.
+func Middleware(defaultTimeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Next()
+	}
+}
+
+// timeoutWriter troca o status 500 por 504 quando o erro foi causado pelo prazo da requisição.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+// WriteHeader registra o status da resposta, trocando 500 por 504 se o prazo da requisição estourou.
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code == http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
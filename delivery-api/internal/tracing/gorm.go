@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey é a chave, na instância da consulta do GORM, do span aberto para ela.
+const spanKey = "tracing:span"
+
+// dbSystems relaciona os dialetos do GORM aos valores de db.system das convenções do OpenTelemetry.
+var dbSystems = map[string]attribute.KeyValue{
+	"sqlite":   semconv.DBSystemSqlite,
+	"mysql":    semconv.DBSystemMySQL,
+	"postgres": semconv.DBSystemPostgreSQL,
+}
+
+// InstrumentDB passa a criar um span para cada consulta feita pelo GORM, filho do span que estiver no contexto
+// da consulta (repassado pelos repositórios com WithContext). Consultas sem um span no contexto são ignoradas.
+// O SQL gravado no span é o comando com os parâmetros (? ou $1); valores literais são substituídos por ?,
+// para que CPFs, nomes e endereços não apareçam nos traces.
+func InstrumentDB(db *gorm.DB) error {
+	system, ok := dbSystems[db.Dialector.Name()]
+	if !ok {
+		system = semconv.DBSystemOtherSQL
+	}
+	return db.Use(&gormPlugin{system: system})
+}
+
+// gormPlugin é o plugin do GORM que abre um span antes de cada operação e o fecha depois dela.
+type gormPlugin struct {
+	system attribute.KeyValue
+}
+
+// Name identifica o plugin no GORM.
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registra os callbacks dos spans em todas as operações do GORM.
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("tracing:before_create", p.before("INSERT")); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("tracing:after_create", after); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tracing:before_query", p.before("SELECT")); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("tracing:after_query", after); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tracing:before_update", p.before("UPDATE")); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("tracing:after_update", after); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("DELETE")); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("tracing:after_delete", after); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("tracing:before_row", p.before("SELECT")); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("tracing:after_row", after); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("RAW")); err != nil {
+		return err
+	}
+	return callback.Raw().After("gorm:raw").Register("tracing:after_raw", after)
+}
+
+// before retorna o callback que abre o span da consulta. O nome do span é a operação seguida da tabela
+// (por exemplo, "SELECT deliveries").
+func (p *gormPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		// Consultas fora de um span (como as leituras periódicas da outbox) não geram traces próprios,
+		// para que cada leitura não vire um trace separado.
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		name := operation
+		if table := db.Statement.Table; table != "" {
+			name += " " + table
+		}
+		_, span := tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				p.system,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// after grava o SQL, as linhas afetadas e o erro no span da consulta e o encerra.
+// gorm.ErrRecordNotFound não é tratado como falha.
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(SanitizeSQL(db.Statement.SQL.String())),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// SanitizeSQL substitui os valores literais do comando SQL (textos entre aspas simples e números) por ?.
+// Os parâmetros (? e $1), os identificadores e as palavras-chave são mantidos.
+func SanitizeSQL(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == '\'':
+			// Texto entre aspas; '' dentro do texto é uma aspa escapada.
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case isDigit(ch) && (i == 0 || !isIdentifier(sql[i-1])):
+			// Número fora de um identificador (table1) e de um parâmetro ($1).
+			for i+1 < len(sql) && (isDigit(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// isDigit indica se o caractere é um dígito decimal.
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// isIdentifier indica se o caractere pode fazer parte de um identificador ou de um parâmetro posicional.
+func isIdentifier(ch byte) bool {
+	return ch == '_' || ch == '$' || isDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
@@ -0,0 +1,156 @@
+// Package tracing instrumenta a API com OpenTelemetry: o span de cada requisição HTTP, os spans dos métodos
+// dos serviços, os das consultas do GORM (com o SQL sem valores) e os dos envios de webhooks.
+// O contexto do trace segue o padrão W3C Trace Context (cabeçalho traceparent), tanto nas requisições recebidas
+// quanto nas enviadas, e é guardado na outbox para que o trabalho assíncrono continue no mesmo trace.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores de spans aceitos em TRACING_EXPORTER.
+const (
+	ExporterNone   = "none"   // Não exporta; o traceparent recebido continua sendo repassado adiante
+	ExporterStdout = "stdout" // Escreve os spans em JSON na saída padrão, para uso local
+	ExporterFile   = "file"   // Acrescenta os spans em JSON a um arquivo, para uso local
+	ExporterOTLP   = "otlp"   // Envia os spans para um coletor OpenTelemetry (OTLP/HTTP)
+)
+
+// instrumentationName identifica a instrumentação da API nos spans.
+const instrumentationName = "delivery-api"
+
+// Config reúne a configuração da exportação dos spans.
+type Config struct {
+	Exporter     string  // none, stdout, file ou otlp
+	File         string  // Arquivo usado pelo exportador file
+	OTLPEndpoint string  // Endereço (host:porta) do coletor OTLP; vazio usa OTEL_EXPORTER_OTLP_ENDPOINT ou localhost:4318
+	OTLPInsecure bool    // Envia para o coletor OTLP sem TLS
+	SampleRatio  float64 // Fração dos traces iniciados pela API que são gravados (de 0 a 1)
+	ServiceName  string  // Nome do serviço nos spans
+}
+
+// Setup configura o OpenTelemetry de acordo com cfg e retorna a função que envia os spans pendentes e
+// encerra o exportador, a ser chamada no desligamento.
+// A propagação do W3C Trace Context é configurada mesmo com o exportador none.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == ExporterNone || cfg.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	// Requisições que já chegam com um traceparent seguem a decisão de amostragem de quem chamou.
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+// newExporter cria o exportador configurado e a função que fecha o arquivo de saída, se houver.
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+	switch cfg.Exporter {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, noClose, err
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open tracing file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		return exporter, noClose, err
+	default:
+		return nil, nil, fmt.Errorf("tracing exporter %q is not supported", cfg.Exporter)
+	}
+}
+
+// tracer retorna o tracer da API. O provider global é consultado a cada chamada, então os spans criados antes
+// do Setup (por exemplo, nos testes) usam o provider padrão, que não grava nada.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start inicia um span interno, filho do span que estiver no contexto.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End registra o erro no span, se houver, e o encerra.
+// Erros esperados (como "não encontrado") são registrados como evento, mas não marcam o span como falha.
+func End(span trace.Span, err error, expected ...error) {
+	defer span.End()
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	for _, target := range expected {
+		if errors.Is(err, target) {
+			return
+		}
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// TraceParent retorna o cabeçalho traceparent do span que estiver no contexto, para ser guardado junto com um
+// trabalho assíncrono (como um evento da outbox). Retorna "" se não houver span.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceParent retorna um contexto derivado de ctx com o span remoto descrito pelo traceparent, para que os
+// spans criados a partir dele continuem o trace de origem. Um traceparent vazio ou inválido é ignorado.
+func WithTraceParent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+}
@@ -0,0 +1,74 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey é o tipo da chave do usuário autenticado no contexto da requisição.
+type contextKey struct{}
+
+// Identify é um middleware que identifica o usuário pelo cabeçalho "Authorization: Bearer <token>".
+// Requisições sem o cabeçalho seguem como anônimas; um token inválido é rejeitado com 401.
+// O usuário identificado é guardado no contexto da requisição (c.Request.Context()), que é repassado aos serviços
+// e repositórios, e pode ser lido em qualquer camada com FromContext.
+func Identify(service Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header must use the Bearer scheme"})
+			return
+		}
+		user, err := service.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate"})
+			return
+		}
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+// RequireRole é um middleware que restringe a rota aos usuários com um dos papéis informados.
+// Deve vir depois de Identify: requisições anônimas são rejeitadas com 401 e usuários sem o papel com 403.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := FromContext(c.Request.Context())
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+	}
+}
+
+// NewContext retorna uma cópia do contexto com o usuário autenticado.
+func NewContext(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// FromContext retorna o usuário identificado pelo middleware Identify, ou nil em requisições anônimas.
+func FromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(contextKey{}).(*User)
+	return user
+}
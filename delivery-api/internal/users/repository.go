@@ -0,0 +1,58 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Repository é uma interface que define os métodos de acesso aos usuários no banco de dados.
+type Repository interface {
+	CreateUser(ctx context.Context, user *User) (*User, error)            // Cria um novo usuário
+	FindByEmail(ctx context.Context, email string) (*User, error)         // Busca um usuário pelo e-mail
+	FindByTokenHash(ctx context.Context, tokenHash string) (*User, error) // Busca um usuário pelo hash do token
+}
+
+// ErrUserNotFound é retornado quando o usuário solicitado não existe.
+var ErrUserNotFound = errors.New("user not found")
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório de usuários.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreateUser grava um novo usuário no banco de dados.
+func (r *repository) CreateUser(ctx context.Context, user *User) (*User, error) {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// FindByEmail busca um usuário pelo e-mail. Retorna ErrUserNotFound se ele não existir.
+func (r *repository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return r.findOne(ctx, "email = ?", email)
+}
+
+// FindByTokenHash busca o usuário dono do token. Retorna ErrUserNotFound se nenhum usuário tiver o token.
+func (r *repository) FindByTokenHash(ctx context.Context, tokenHash string) (*User, error) {
+	return r.findOne(ctx, "token_hash = ?", tokenHash)
+}
+
+// findOne busca um único usuário pela condição informada.
+func (r *repository) findOne(ctx context.Context, query string, args ...interface{}) (*User, error) {
+	var user User
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
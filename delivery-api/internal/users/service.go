@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ErrEmailTaken é retornado ao criar um usuário com um e-mail já cadastrado.
+var ErrEmailTaken = errors.New("email already registered")
+
+// ErrInvalidToken é retornado quando o token de API informado não pertence a nenhum usuário.
+var ErrInvalidToken = errors.New("invalid API token")
+
+// Service é uma interface que define a lógica de negócio dos usuários da API.
+type Service interface {
+	CreateUser(ctx context.Context, name, email, role string) (*User, string, error) // Cria um usuário e retorna o token de API gerado
+	Authenticate(ctx context.Context, token string) (*User, error)                   // Identifica o usuário dono do token
+}
+
+// service é uma struct que implementa a interface Service.
+type service struct {
+	repo Repository
+}
+
+// NewService cria uma nova instância do serviço de usuários.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository) Service {
+	return &tracedService{next: &service{repo: repo}}
+}
+
+// CreateUser valida os dados, gera um token de API e grava o usuário.
+// O token é retornado apenas aqui: no banco fica só o hash, então ele não pode ser recuperado depois.
+func (s *service) CreateUser(ctx context.Context, name, email, role string) (*User, string, error) {
+	name = strings.TrimSpace(name)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, "", fmt.Errorf("invalid email: %s", email)
+	}
+	if !ValidRole(role) {
+		return nil, "", fmt.Errorf("invalid role, must be one of: %s", strings.Join(Roles, ", "))
+	}
+
+	if _, err := s.repo.FindByEmail(ctx, email); err == nil {
+		return nil, "", ErrEmailTaken
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return nil, "", err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	user := &User{
+		Name:        name,
+		Email:       email,
+		Role:        role,
+		TokenHash:   HashToken(token),
+		TokenPrefix: token[:len(tokenPrefix)+6],
+	}
+	created, err := s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return nil, "", err
+	}
+	return created, token, nil
+}
+
+// Authenticate busca o usuário pelo hash do token. Retorna ErrInvalidToken se o token não for reconhecido.
+func (s *service) Authenticate(ctx context.Context, token string) (*User, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.repo.FindByTokenHash(ctx, HashToken(token))
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	return user, err
+}
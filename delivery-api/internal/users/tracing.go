@@ -0,0 +1,32 @@
+package users
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+// O token e o e-mail não são gravados nos spans.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) CreateUser(ctx context.Context, name, email, role string) (*User, string, error) {
+	ctx, span := tracing.Start(ctx, "users.CreateUser", attribute.String("user.role", role))
+	user, token, err := s.next.CreateUser(ctx, name, email, role)
+	tracing.End(span, err, ErrEmailTaken)
+	return user, token, err
+}
+
+func (s *tracedService) Authenticate(ctx context.Context, token string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "users.Authenticate")
+	user, err := s.next.Authenticate(ctx, token)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("user.id", int64(user.ID)), attribute.String("user.role", user.Role))
+	}
+	tracing.End(span, err, ErrInvalidToken)
+	return user, err
+}
@@ -0,0 +1,57 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Papéis aceitos para os usuários da API.
+const (
+	RoleAdmin    = "admin"    // Acesso completo, incluindo as rotas administrativas
+	RoleOperator = "operator" // Operação do dia a dia: clientes e entregas
+)
+
+// Roles são os papéis que podem ser atribuídos a um usuário.
+var Roles = []string{RoleAdmin, RoleOperator}
+
+// tokenPrefix identifica os tokens emitidos pela API, para que sejam reconhecidos em logs e varreduras de segredos.
+const tokenPrefix = "dk_"
+
+// @description Usuário da API
+// @type object
+type User struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:255;not null"`
+	Email       string    `json:"email" gorm:"size:255;not null;uniqueIndex"`
+	Role        string    `json:"role" gorm:"size:20;not null"`
+	TokenHash   string    `json:"-" gorm:"size:64;not null;uniqueIndex"` // SHA-256 do token; o token em si nunca é gravado
+	TokenPrefix string    `json:"token_prefix" gorm:"size:12;not null"`  // Início do token, para identificá-lo sem expô-lo
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ValidRole indica se o papel informado é aceito.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// generateToken gera um novo token de API aleatório (256 bits).
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken calcula o SHA-256 do token, em hexadecimal, que é o valor gravado no banco.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// Cabeçalhos enviados em cada webhook.
+const (
+	HeaderEvent     = "X-Webhook-Event"     // Nome do evento
+	HeaderEventID   = "X-Webhook-ID"        // ID do evento (o mesmo em todas as tentativas)
+	HeaderSignature = "X-Webhook-Signature" // Assinatura no formato "t=<unix>,v1=<hex>"
+)
+
+// ErrInvalidSignature é retornado por Verify quando a assinatura não confere.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// DispatcherConfig reúne os parâmetros de envio e de novas tentativas.
+type DispatcherConfig struct {
+	MaxAttempts  int           // Tentativas antes de a mensagem ir para a lista de mensagens mortas
+	RetryBase    time.Duration // Espera após a primeira falha; dobra a cada nova falha
+	RetryMax     time.Duration // Espera máxima entre tentativas
+	PollInterval time.Duration // Intervalo entre buscas por mensagens pendentes
+	Timeout      time.Duration // Tempo máximo de cada requisição ao parceiro
+	BatchSize    int           // Quantidade de mensagens processadas por busca
+	// Permite destinos em endereços internos (loopback, redes privadas, link-local).
+	// Deve ficar desligado em produção; serve para desenvolvimento local e testes.
+	AllowPrivateTargets bool
+}
+
+// DefaultDispatcherConfig retorna a configuração padrão do dispatcher.
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		MaxAttempts:  8,
+		RetryBase:    30 * time.Second,
+		RetryMax:     6 * time.Hour,
+		PollInterval: 5 * time.Second,
+		Timeout:      10 * time.Second,
+		BatchSize:    50,
+	}
+}
+
+// Dispatcher envia as mensagens pendentes de forma assíncrona, com novas tentativas em backoff exponencial.
+type Dispatcher struct {
+	repo   Repository
+	client *http.Client
+	config DispatcherConfig
+	wake   chan struct{}
+}
+
+// NewDispatcher cria um novo Dispatcher. Se client for nil, é usado um http.Client com o timeout configurado,
+// que cria um span para cada envio e repassa o trace ao parceiro no cabeçalho traceparent.
+// A menos que AllowPrivateTargets esteja ligado, esse cliente recusa conexões com endereços internos.
+func NewDispatcher(repo Repository, client *http.Client, config DispatcherConfig) *Dispatcher {
+	if client == nil {
+		var base http.RoundTripper
+		if !config.AllowPrivateTargets {
+			base = safeTransport()
+		}
+		client = &http.Client{Timeout: config.Timeout, Transport: tracing.Transport(base)}
+	}
+	return &Dispatcher{repo: repo, client: client, config: config, wake: make(chan struct{}, 1)}
+}
+
+// Wake pede ao dispatcher que procure mensagens pendentes imediatamente, sem esperar o próximo intervalo.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run processa as mensagens pendentes até que o contexto seja cancelado.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "webhooks: failed to process pending messages", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// ProcessDue envia as mensagens cujo horário de envio já chegou e retorna quantas foram processadas.
+func (d *Dispatcher) ProcessDue(ctx context.Context) (int, error) {
+	messages, err := d.repo.FindDueMessages(ctx, time.Now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[uint]*Subscription)
+	processed := 0
+	for i := range messages {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+		message := &messages[i]
+
+		// Reserva a mensagem; se outra instância já a pegou, segue para a próxima.
+		claimed, err := d.repo.ClaimMessage(ctx, message, time.Now().Add(d.config.Timeout*2))
+		if err != nil {
+			return processed, err
+		}
+		if !claimed {
+			continue
+		}
+
+		subscription, ok := subscriptions[message.SubscriptionID]
+		if !ok {
+			subscription, err = d.repo.GetSubscriptionByID(ctx, message.SubscriptionID)
+			if err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+				return processed, err
+			}
+			subscriptions[message.SubscriptionID] = subscription
+		}
+
+		d.attempt(ctx, message, subscription)
+		if err := d.repo.SaveMessage(ctx, message); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// attempt faz uma tentativa de envio e atualiza o status da mensagem de acordo com o resultado.
+func (d *Dispatcher) attempt(ctx context.Context, message *Message, subscription *Subscription) {
+	message.Attempts++
+
+	// Assinaturas removidas ou desativadas não recebem mais mensagens.
+	if subscription == nil || !subscription.Active {
+		message.Status = MessageStatusDead
+		message.LastError = "subscription is inactive or was removed"
+		return
+	}
+
+	// O envio continua o trace do evento que gerou a mensagem.
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, message.TraceParent), "webhooks.Send "+message.Event,
+		attribute.Int64("webhook.message_id", int64(message.ID)),
+		attribute.Int64("webhook.subscription_id", int64(subscription.ID)),
+		attribute.Int("webhook.attempt", message.Attempts),
+	)
+	statusCode, err := d.send(ctx, message, subscription)
+	tracing.End(span, err)
+	message.LastStatusCode = statusCode
+	if err == nil {
+		now := time.Now()
+		message.Status = MessageStatusDelivered
+		message.DeliveredAt = &now
+		message.LastError = ""
+		return
+	}
+
+	message.LastError = err.Error()
+	if message.Attempts >= d.config.MaxAttempts {
+		message.Status = MessageStatusDead
+		return
+	}
+	message.Status = MessageStatusPending
+	message.NextAttemptAt = time.Now().Add(d.backoff(message.Attempts))
+}
+
+// send faz o POST assinado para a URL da assinatura. Qualquer resposta fora da faixa 2xx é considerada falha.
+func (d *Dispatcher) send(ctx context.Context, message *Message, subscription *Subscription) (int, error) {
+	body := []byte(message.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "delivery-api-webhooks/1.0")
+	req.Header.Set(HeaderEvent, message.Event)
+	req.Header.Set(HeaderEventID, message.EventID)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, time.Now().Unix(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff calcula a espera antes da próxima tentativa: RetryBase * 2^(tentativas-1), limitada a RetryMax.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.RetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.RetryMax {
+			return d.config.RetryMax
+		}
+	}
+	return delay
+}
+
+// Sign calcula o valor do cabeçalho X-Webhook-Signature.
+// A assinatura é o HMAC-SHA256, com o segredo da assinatura, de "<timestamp>.<corpo>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, computeSignature(secret, timestamp, body))
+}
+
+// Verify confere o cabeçalho X-Webhook-Signature recebido por um parceiro.
+// tolerance limita a diferença entre o timestamp assinado e o horário atual (0 desativa a verificação).
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := computeSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// computeSignature calcula o HMAC-SHA256 em hexadecimal de "<timestamp>.<corpo>".
+func computeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
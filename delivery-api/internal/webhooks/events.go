@@ -0,0 +1,41 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+)
+
+// domainEvents relaciona os eventos de domínio da outbox aos eventos de webhook correspondentes.
+var domainEvents = map[string]string{
+	events.DeliveryCreated:       deliveries.EventDeliveryCreated,
+	events.DeliveryStatusChanged: deliveries.EventDeliveryStatusChanged,
+	events.DeliveryDeleted:       deliveries.EventDeliveryDeleted,
+	events.DeliveryOverdue:       deliveries.EventDeliveryOverdue,
+	events.DeliveryAssigned:      deliveries.EventDeliveryAssigned,
+	events.DeliveryUnassigned:    deliveries.EventDeliveryUnassigned,
+}
+
+// DomainEventTypes são os eventos de domínio que geram webhooks, para usar na assinatura do barramento.
+func DomainEventTypes() []string {
+	types := make([]string, 0, len(domainEvents))
+	for eventType := range domainEvents {
+		types = append(types, eventType)
+	}
+	return types
+}
+
+// EventHandler retorna o handler que enfileira os webhooks a partir dos eventos de domínio.
+// O UUID do evento é usado como ID do webhook, então uma entrega repetida do mesmo evento
+// não gera mensagens duplicadas para os parceiros.
+func EventHandler(service Service) events.Handler {
+	return func(ctx context.Context, event *events.Event) error {
+		name, ok := domainEvents[event.Type]
+		if !ok {
+			return nil
+		}
+		return service.Publish(ctx, event.UUID, name, json.RawMessage(event.Payload))
+	}
+}
@@ -0,0 +1,247 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deadLetterLimit é a quantidade máxima de mensagens mortas retornadas por consulta.
+const deadLetterLimit = 100
+
+// Handler é uma struct que manipula as requisições HTTP relacionadas a webhooks.
+type Handler struct {
+	Service Service
+}
+
+// subscriptionRequest é o corpo aceito na criação e na atualização de assinaturas.
+// Active é um ponteiro para que a ausência do campo signifique "ativa".
+type subscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events" binding:"required"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// toSubscription converte o corpo da requisição em uma assinatura.
+func (r *subscriptionRequest) toSubscription() *Subscription {
+	return &Subscription{
+		URL:         r.URL,
+		Secret:      r.Secret,
+		Events:      r.Events,
+		Description: r.Description,
+		Active:      r.Active == nil || *r.Active,
+	}
+}
+
+// CreateSubscription é um handler HTTP para criar uma assinatura de webhook.
+// @Summary Cria uma assinatura de webhook
+// @Description Cria uma assinatura para os eventos informados (delivery.created, delivery.status_changed, delivery.deleted, delivery.overdue,
+// @Description delivery.assigned, delivery.unassigned).
+// @Description Se nenhum segredo for informado, um segredo é gerado e retornado apenas nesta resposta.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Subscription body Subscription true "Assinatura a ser criada"
+// @Success 201 {object} Subscription
+// @Failure 400 "Bad Request"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks [post]
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var request subscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.Service.CreateSubscription(c.Request.Context(), request.toSubscription())
+	if err != nil {
+		respondError(c, err, "Failed to create webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// GetSubscriptions é um handler HTTP para listar as assinaturas de webhook.
+// @Summary Lista as assinaturas de webhook
+// @Description Retorna todas as assinaturas (sem os segredos)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} Subscription
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks [get]
+func (h *Handler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.Service.GetSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscriptionByID é um handler HTTP para buscar uma assinatura de webhook pelo ID.
+// @Summary Obtém uma assinatura de webhook pelo ID
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da assinatura"
+// @Success 200 {object} Subscription
+// @Failure 400 "Bad Request"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 404 "Not Found"
+// @Router /webhooks/{id} [get]
+func (h *Handler) GetSubscriptionByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	subscription, err := h.Service.GetSubscriptionByID(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to fetch webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateSubscription é um handler HTTP para atualizar uma assinatura de webhook.
+// @Summary Atualiza uma assinatura de webhook
+// @Description Substitui a URL, os eventos, a descrição e o estado da assinatura. O segredo só é trocado se informado.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da assinatura"
+// @Param Subscription body Subscription true "Assinatura com dados atualizados"
+// @Success 200 {object} Subscription
+// @Failure 400 "Bad Request"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 404 "Not Found"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks/{id} [put]
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request subscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.Service.UpdateSubscription(c.Request.Context(), uint(id), request.toSubscription())
+	if err != nil {
+		respondError(c, err, "Failed to update webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription é um handler HTTP para remover uma assinatura de webhook.
+// @Summary Remove uma assinatura de webhook
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path int true "ID da assinatura"
+// @Success 204 {object} nil
+// @Failure 400 "Bad Request"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 404 "Not Found"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.Service.DeleteSubscription(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeadLetters é um handler HTTP para listar as mensagens que esgotaram as tentativas de envio.
+// @Summary Lista as mensagens mortas
+// @Description Retorna as mensagens de webhook que não foram entregues após todas as tentativas
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} Message
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks/dead-letters [get]
+func (h *Handler) GetDeadLetters(c *gin.Context) {
+	messages, err := h.Service.GetDeadLetters(c.Request.Context(), deadLetterLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// RedeliverMessage é um handler HTTP para reenviar manualmente uma mensagem de webhook.
+// @Summary Reenvia uma mensagem de webhook
+// @Description Coloca a mensagem de volta na fila com as tentativas zeradas
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da mensagem"
+// @Success 202 {object} Message
+// @Failure 400 "Bad Request"
+// @Failure 401 "Token ausente ou inválido"
+// @Failure 403 "Usuário sem permissão"
+// @Failure 404 "Not Found"
+// @Failure 500 "Internal Server Error"
+// @Router /webhooks/messages/{id}/redeliver [post]
+func (h *Handler) RedeliverMessage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	message, err := h.Service.Redeliver(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to redeliver webhook message")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, message)
+}
+
+// respondError traduz os erros do serviço de webhooks em respostas HTTP.
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSubscriptionNotFound), errors.Is(err, ErrMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
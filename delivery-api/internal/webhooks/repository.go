@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSubscriptionNotFound é retornado quando a assinatura solicitada não existe.
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// ErrMessageNotFound é retornado quando a mensagem solicitada não existe.
+var ErrMessageNotFound = errors.New("webhook message not found")
+
+// Repository é uma interface que define os métodos de acesso a dados de assinaturas e mensagens de webhook.
+type Repository interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) error              // Cria uma assinatura
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)                          // Retorna todas as assinaturas
+	GetSubscriptionByID(ctx context.Context, id uint) (*Subscription, error)               // Retorna uma assinatura pelo ID
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error              // Atualiza uma assinatura
+	DeleteSubscription(ctx context.Context, id uint) error                                 // Deleta uma assinatura e suas mensagens
+	CreateMessages(ctx context.Context, messages []Message) error                          // Enfileira mensagens para envio (ignorando repetidas)
+	GetMessageByID(ctx context.Context, id uint) (*Message, error)                         // Retorna uma mensagem pelo ID
+	FindMessagesByStatus(ctx context.Context, status string, limit int) ([]Message, error) // Lista mensagens por status
+	FindDueMessages(ctx context.Context, now time.Time, limit int) ([]Message, error)      // Lista mensagens prontas para envio
+	ClaimMessage(ctx context.Context, message *Message, until time.Time) (bool, error)     // Reserva uma mensagem para envio
+	SaveMessage(ctx context.Context, message *Message) error                               // Grava o resultado de uma tentativa
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório de webhooks.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreateSubscription grava uma nova assinatura no banco de dados.
+func (r *repository) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+// GetSubscriptions retorna todas as assinaturas cadastradas.
+func (r *repository) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetSubscriptionByID retorna a assinatura com o ID informado.
+func (r *repository) GetSubscriptionByID(ctx context.Context, id uint) (*Subscription, error) {
+	var subscription Subscription
+	if err := r.db.WithContext(ctx).First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscription grava todos os campos da assinatura.
+func (r *repository) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+// DeleteSubscription remove a assinatura e as mensagens associadas a ela em uma transação.
+func (r *repository) DeleteSubscription(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Subscription{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSubscriptionNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&Message{}).Error
+	})
+}
+
+// CreateMessages grava as mensagens que devem ser enviadas.
+// Mensagens de um evento que já foi enfileirado para a mesma assinatura são ignoradas, já que os eventos
+// de domínio podem ser entregues mais de uma vez.
+func (r *repository) CreateMessages(ctx context.Context, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
+}
+
+// GetMessageByID retorna a mensagem com o ID informado.
+func (r *repository) GetMessageByID(ctx context.Context, id uint) (*Message, error) {
+	var message Message
+	if err := r.db.WithContext(ctx).First(&message, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// FindMessagesByStatus retorna as mensagens com o status informado, das mais recentes para as mais antigas.
+func (r *repository) FindMessagesByStatus(ctx context.Context, status string, limit int) ([]Message, error) {
+	var messages []Message
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FindDueMessages retorna as mensagens pendentes cujo horário da próxima tentativa já chegou.
+func (r *repository) FindDueMessages(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	var messages []Message
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", MessageStatusPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ClaimMessage reserva a mensagem para envio, adiando a próxima tentativa para until.
+// A atualização só acontece se a mensagem não tiver sido reservada por outra instância nesse meio tempo,
+// o que evita envios duplicados. Se o processo cair durante o envio, a mensagem volta a ficar disponível em until.
+func (r *repository) ClaimMessage(ctx context.Context, message *Message, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Message{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", message.ID, MessageStatusPending, message.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	message.NextAttemptAt = until
+	return true, nil
+}
+
+// SaveMessage grava todos os campos da mensagem.
+func (r *repository) SaveMessage(ctx context.Context, message *Message) error {
+	return r.db.WithContext(ctx).Save(message).Error
+}
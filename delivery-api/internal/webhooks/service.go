@@ -0,0 +1,234 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"delivery-api/internal/tracing"
+)
+
+// ErrInvalidSubscription é retornado quando os dados de uma assinatura são inválidos.
+var ErrInvalidSubscription = errors.New("invalid webhook subscription")
+
+// Service é uma interface que define as operações de negócio dos webhooks.
+type Service interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)          // Cria uma assinatura
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)                                       // Retorna todas as assinaturas
+	GetSubscriptionByID(ctx context.Context, id uint) (*Subscription, error)                            // Retorna uma assinatura pelo ID
+	UpdateSubscription(ctx context.Context, id uint, subscription *Subscription) (*Subscription, error) // Atualiza uma assinatura
+	DeleteSubscription(ctx context.Context, id uint) error                                              // Deleta uma assinatura
+	Publish(ctx context.Context, eventID, event string, data interface{}) error                         // Enfileira um evento para as assinaturas
+	GetDeadLetters(ctx context.Context, limit int) ([]Message, error)                                   // Lista as mensagens mortas
+	Redeliver(ctx context.Context, messageID uint) (*Message, error)                                    // Agenda o reenvio de uma mensagem
+}
+
+// service é uma struct que implementa a interface Service.
+// O envio em si é feito de forma assíncrona pelo Dispatcher; o serviço apenas grava as mensagens e o acorda.
+type service struct {
+	repo       Repository
+	dispatcher *Dispatcher
+}
+
+// NewService cria uma nova instância do serviço de webhooks.
+// O dispatcher pode ser nil; nesse caso as mensagens ficam gravadas até serem processadas por outra instância.
+func NewService(repo Repository, dispatcher *Dispatcher) Service {
+	return &service{repo: repo, dispatcher: dispatcher}
+}
+
+// CreateSubscription valida e cria uma nova assinatura.
+// Se nenhum segredo for informado, um segredo aleatório é gerado e retornado apenas nesta resposta.
+func (s *service) CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error) {
+	if err := s.validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := randomHex(32)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	subscription.ID = 0
+	subscription.Active = true
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetSubscriptions retorna todas as assinaturas, sem os segredos.
+func (s *service) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subscriptions, err := s.repo.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// GetSubscriptionByID retorna uma assinatura pelo ID, sem o segredo.
+func (s *service) GetSubscriptionByID(ctx context.Context, id uint) (*Subscription, error) {
+	subscription, err := s.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// UpdateSubscription altera a URL, os eventos, a descrição e o estado (ativa/inativa) de uma assinatura.
+// O segredo só é trocado quando um novo valor é informado.
+func (s *service) UpdateSubscription(ctx context.Context, id uint, subscription *Subscription) (*Subscription, error) {
+	if err := s.validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.URL = subscription.URL
+	existing.Events = subscription.Events
+	existing.Description = subscription.Description
+	existing.Active = subscription.Active
+	if subscription.Secret != "" {
+		existing.Secret = subscription.Secret
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, existing); err != nil {
+		return nil, err
+	}
+	existing.Secret = ""
+	return existing, nil
+}
+
+// DeleteSubscription remove uma assinatura e as mensagens pendentes dela.
+func (s *service) DeleteSubscription(ctx context.Context, id uint) error {
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// Publish cria uma mensagem para cada assinatura ativa inscrita no evento e acorda o dispatcher.
+// Todas as mensagens de um mesmo evento compartilham o mesmo ID, que o parceiro pode usar para descartar duplicatas.
+// Se eventID for vazio, um novo ID é gerado; publicar de novo o mesmo ID não gera mensagens repetidas.
+// O trace do contexto é guardado nas mensagens, para que os envios continuem o trace de origem.
+func (s *service) Publish(ctx context.Context, eventID, event string, data interface{}) error {
+	subscriptions, err := s.repo.GetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if eventID == "" {
+		eventID, err = newEventID()
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	payload, err := json.Marshal(Envelope{ID: eventID, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+
+	var messages []Message
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event) {
+			continue
+		}
+		messages = append(messages, Message{
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         MessageStatusPending,
+			NextAttemptAt:  now,
+			TraceParent:    tracing.TraceParent(ctx),
+		})
+	}
+	if err := s.repo.CreateMessages(ctx, messages); err != nil {
+		return err
+	}
+
+	if len(messages) > 0 && s.dispatcher != nil {
+		s.dispatcher.Wake()
+	}
+	return nil
+}
+
+// GetDeadLetters lista as mensagens que esgotaram as tentativas de envio.
+func (s *service) GetDeadLetters(ctx context.Context, limit int) ([]Message, error) {
+	return s.repo.FindMessagesByStatus(ctx, MessageStatusDead, limit)
+}
+
+// Redeliver coloca a mensagem de volta na fila para ser enviada imediatamente, zerando as tentativas.
+// Pode ser usado tanto para mensagens mortas quanto para reenviar mensagens já entregues.
+func (s *service) Redeliver(ctx context.Context, messageID uint) (*Message, error) {
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Status = MessageStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+	message.DeliveredAt = nil
+	if err := s.repo.SaveMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	if s.dispatcher != nil {
+		s.dispatcher.Wake()
+	}
+	return message, nil
+}
+
+// validateSubscription verifica a URL e os eventos de uma assinatura.
+// Destinos internos são recusados, exceto quando o dispatcher permite (AllowPrivateTargets).
+func (s *service) validateSubscription(subscription *Subscription) error {
+	parsed, err := url.ParseRequestURI(subscription.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	if s.dispatcher == nil || !s.dispatcher.config.AllowPrivateTargets {
+		if err := checkHost(parsed.Hostname()); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+		}
+	}
+	if len(subscription.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidSubscription)
+	}
+	for _, event := range subscription.Events {
+		if !isSupportedEvent(event) {
+			return fmt.Errorf("%w: unsupported event %q", ErrInvalidSubscription, event)
+		}
+	}
+	return nil
+}
+
+// newEventID gera um identificador aleatório no formato de um UUID v4.
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// randomHex gera n bytes aleatórios codificados em hexadecimal.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
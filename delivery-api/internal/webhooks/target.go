@@ -0,0 +1,70 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateTarget é retornado quando o destino de um webhook é um endereço interno
+// (loopback, rede privada, link-local, etc.).
+var ErrPrivateTarget = errors.New("webhook target must not be a private, loopback or link-local address")
+
+// sharedAddressSpace é a faixa 100.64.0.0/10 (CGNAT, RFC 6598), que net.IP não classifica como privada.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isBlockedIP indica se o endereço não pode ser usado como destino de webhooks.
+// Cobre loopback, redes privadas, link-local (inclusive 169.254.169.254, usado por serviços de metadados de nuvem),
+// endereços não especificados e multicast.
+func isBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// checkHost rejeita nomes locais e IPs literais internos. Nomes comuns são conferidos apenas no momento da conexão,
+// pelo dialer de safeTransport, depois de resolvidos.
+func checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && isBlockedIP(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// safeTransport retorna um http.Transport cujo dialer confere o IP já resolvido de cada conexão,
+// inclusive as abertas por redirecionamentos, e recusa destinos internos. O proxy do ambiente é ignorado,
+// pois a conexão com o proxy esconderia o destino real do dialer.
+func safeTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || isBlockedIP(ip) {
+				return ErrPrivateTarget
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"time"
+
+	"delivery-api/internal/deliveries"
+)
+
+// SupportedEvents são os eventos que podem ser assinados por um webhook.
+var SupportedEvents = []string{
+	deliveries.EventDeliveryCreated,
+	deliveries.EventDeliveryStatusChanged,
+	deliveries.EventDeliveryDeleted,
+	deliveries.EventDeliveryOverdue,
+	deliveries.EventDeliveryAssigned,
+	deliveries.EventDeliveryUnassigned,
+}
+
+// Status possíveis de uma mensagem de webhook.
+const (
+	MessageStatusPending   = "pending"   // Aguardando envio (ou uma nova tentativa)
+	MessageStatusDelivered = "delivered" // Recebida pelo parceiro com uma resposta 2xx
+	MessageStatusDead      = "dead"      // Esgotou as tentativas e foi para a lista de mensagens mortas
+)
+
+// @description Assinatura de webhook
+// @type object
+type Subscription struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"size:2048;not null" binding:"required"`
+	Secret      string    `json:"secret,omitempty" gorm:"size:255;not null"` // Usado para assinar os envios (HMAC-SHA256)
+	Events      []string  `json:"events" gorm:"serializer:json;type:text;not null" binding:"required"`
+	Description string    `json:"description" gorm:"size:255"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName define o nome da tabela de assinaturas.
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Accepts indica se a assinatura está ativa e inscrita no evento informado.
+func (s *Subscription) Accepts(event string) bool {
+	if !s.Active {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// @description Mensagem de webhook enviada (ou a enviar) para uma assinatura
+// @type object
+type Message struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index;uniqueIndex:idx_webhook_messages_event"`
+	EventID        string     `json:"event_id" gorm:"size:36;not null;uniqueIndex:idx_webhook_messages_event"`
+	Event          string     `json:"event" gorm:"size:100;not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"size:20;not null;index:idx_webhook_messages_due"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_webhook_messages_due"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	TraceParent    string     `json:"-" gorm:"size:55"` // Trace do evento que gerou a mensagem (W3C traceparent)
+}
+
+// TableName define o nome da tabela de mensagens.
+func (Message) TableName() string {
+	return "webhook_messages"
+}
+
+// Envelope é o corpo JSON enviado ao parceiro em cada webhook.
+type Envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// isSupportedEvent verifica se o evento está na lista de eventos suportados.
+func isSupportedEvent(event string) bool {
+	for _, e := range SupportedEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
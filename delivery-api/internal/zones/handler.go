@@ -0,0 +1,140 @@
+package zones
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"delivery-api/internal/geo"
+)
+
+// maxUploadSize é o tamanho máximo aceito para o GeoJSON das zonas (10 MB).
+const maxUploadSize = 10 << 20
+
+// Handler expõe o cadastro das zonas pela API.
+type Handler struct {
+	Service Service
+}
+
+// UploadZones é um handler HTTP para enviar zonas em GeoJSON.
+// @Summary Envia zonas em GeoJSON
+// @Description Recebe um Feature ou um FeatureCollection do GeoJSON (RFC 7946, coordenadas [longitude, latitude]) com
+// @Description geometrias Polygon ou MultiPolygon. Cada Feature precisa de properties.name, que identifica a zona:
+// @Description uma zona com o mesmo nome tem a geometria substituída. properties.cidade é opcional. Depois do envio,
+// @Description as entregas em andamento são localizadas de novo em segundo plano (rotina recompute-delivery-zones).
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Param FeatureCollection body Feature true "Feature ou FeatureCollection do GeoJSON"
+// @Success 200 {array} Zone "Zonas gravadas"
+// @Failure 400 "GeoJSON inválido"
+// @Failure 413 "Arquivo maior que o permitido"
+// @Failure 500 "Internal Server Error"
+// @Router /zones [post]
+func (h *Handler) UploadZones(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("body must have at most %d bytes", maxUploadSize)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	features, err := ParseFeatures(body)
+	if err != nil {
+		respondError(c, err, "Failed to read zones")
+		return
+	}
+
+	zones, err := h.Service.SaveZones(c.Request.Context(), features)
+	if err != nil {
+		respondError(c, err, "Failed to save zones")
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+// GetZones é um handler HTTP para listar as zonas.
+// @Summary Lista as zonas
+// @Description Retorna as zonas em ordem de cidade e nome, com a geometria em GeoJSON (MultiPolygon).
+// @Tags Zones
+// @Produce json
+// @Success 200 {array} Zone
+// @Failure 500 "Internal Server Error"
+// @Router /zones [get]
+func (h *Handler) GetZones(c *gin.Context) {
+	zones, err := h.Service.GetZones(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Failed to fetch zones")
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+// GetZone é um handler HTTP para consultar uma zona.
+// @Summary Consulta uma zona
+// @Tags Zones
+// @Produce json
+// @Param id path int true "ID da zona"
+// @Success 200 {object} Zone
+// @Failure 400 "ID inválido"
+// @Failure 404 "Zona não encontrada"
+// @Failure 500 "Internal Server Error"
+// @Router /zones/{id} [get]
+func (h *Handler) GetZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	zone, err := h.Service.GetZoneByID(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to get zone")
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+// DeleteZone é um handler HTTP para remover uma zona.
+// @Summary Remove uma zona
+// @Description Remove a zona. As entregas em andamento que estavam nela são localizadas de novo em segundo plano;
+// @Description as concluídas e canceladas mantêm o zone_id que tinham.
+// @Tags Zones
+// @Param id path int true "ID da zona"
+// @Success 204 "Zona removida"
+// @Failure 400 "ID inválido"
+// @Failure 404 "Zona não encontrada"
+// @Failure 500 "Internal Server Error"
+// @Router /zones/{id} [delete]
+func (h *Handler) DeleteZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.Service.DeleteZone(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, err, "Failed to delete zone")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError traduz os erros do serviço em respostas HTTP.
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidZone), errors.Is(err, geo.ErrInvalidGeometry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrZoneNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,90 @@
+package zones
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrZoneNotFound é retornado quando a zona solicitada não existe.
+var ErrZoneNotFound = errors.New("zone not found")
+
+// Repository é uma interface que define o acesso às zonas no banco de dados.
+type Repository interface {
+	SaveZones(ctx context.Context, features []Feature) ([]Zone, error) // Cria ou substitui as zonas pelo nome
+	GetZones(ctx context.Context) ([]Zone, error)                      // Lista as zonas
+	GetZoneByID(ctx context.Context, id uint) (*Zone, error)           // Retorna uma zona pelo ID
+	DeleteZone(ctx context.Context, id uint) error                     // Remove uma zona
+}
+
+// repository é uma struct que implementa a interface Repository usando o GORM.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository cria uma nova instância do repositório das zonas.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// SaveZones grava as zonas em uma única transação: as que já existem com o mesmo nome (sem diferenciar maiúsculas)
+// têm a geometria e a cidade substituídas, e as demais são criadas. Retorna as zonas gravadas, na ordem recebida.
+func (r *repository) SaveZones(ctx context.Context, features []Feature) ([]Zone, error) {
+	saved := make([]Zone, len(features))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, feature := range features {
+			var zone Zone
+			err := tx.Where("LOWER(name) = LOWER(?)", feature.Properties.Name).First(&zone).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			zone.Name = feature.Properties.Name
+			zone.Cidade = feature.Properties.Cidade
+			zone.setGeometry(feature.Geometry)
+			if err := tx.Save(&zone).Error; err != nil {
+				return err
+			}
+			saved[i] = zone
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// GetZones retorna todas as zonas, em ordem de cidade e nome.
+func (r *repository) GetZones(ctx context.Context) ([]Zone, error) {
+	var zones []Zone
+	if err := r.db.WithContext(ctx).Order("cidade").Order("name").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// GetZoneByID retorna a zona pelo ID, ou ErrZoneNotFound.
+func (r *repository) GetZoneByID(ctx context.Context, id uint) (*Zone, error) {
+	var zone Zone
+	if err := r.db.WithContext(ctx).First(&zone, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// DeleteZone remove a zona, ou retorna ErrZoneNotFound. As entregas que estavam nela são tiradas da zona pela
+// rotina de recálculo.
+func (r *repository) DeleteZone(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&Zone{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrZoneNotFound
+	}
+	return nil
+}
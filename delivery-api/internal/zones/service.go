@@ -0,0 +1,61 @@
+package zones
+
+import "context"
+
+// Service é uma interface que define as operações de negócio das zonas.
+type Service interface {
+	SaveZones(ctx context.Context, features []Feature) ([]Zone, error) // Cria ou substitui as zonas pelo nome
+	GetZones(ctx context.Context) ([]Zone, error)                      // Lista as zonas
+	GetZoneByID(ctx context.Context, id uint) (*Zone, error)           // Retorna uma zona pelo ID
+	DeleteZone(ctx context.Context, id uint) error                     // Remove uma zona
+}
+
+// service é uma struct que implementa a interface Service.
+// onChange é chamado depois de cada alteração nas zonas, para que as entregas sejam localizadas de novo.
+type service struct {
+	repo     Repository
+	onChange func(ctx context.Context)
+}
+
+// NewService cria uma nova instância do serviço das zonas. onChange (opcional) é chamado depois que as zonas são
+// gravadas ou removidas; na aplicação, ele dispara a rotina recompute-delivery-zones.
+// Cada método do serviço gera um span do OpenTelemetry (veja tracedService).
+func NewService(repo Repository, onChange func(ctx context.Context)) Service {
+	return &tracedService{next: &service{repo: repo, onChange: onChange}}
+}
+
+// SaveZones grava as zonas lidas do GeoJSON (veja ParseFeatures) e avisa a mudança.
+func (s *service) SaveZones(ctx context.Context, features []Feature) ([]Zone, error) {
+	zones, err := s.repo.SaveZones(ctx, features)
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ctx)
+	return zones, nil
+}
+
+// GetZones lista as zonas cadastradas.
+func (s *service) GetZones(ctx context.Context) ([]Zone, error) {
+	return s.repo.GetZones(ctx)
+}
+
+// GetZoneByID retorna a zona pelo ID.
+func (s *service) GetZoneByID(ctx context.Context, id uint) (*Zone, error) {
+	return s.repo.GetZoneByID(ctx, id)
+}
+
+// DeleteZone remove a zona e avisa a mudança.
+func (s *service) DeleteZone(ctx context.Context, id uint) error {
+	if err := s.repo.DeleteZone(ctx, id); err != nil {
+		return err
+	}
+	s.changed(ctx)
+	return nil
+}
+
+// changed chama onChange, se houver.
+func (s *service) changed(ctx context.Context) {
+	if s.onChange != nil {
+		s.onChange(ctx)
+	}
+}
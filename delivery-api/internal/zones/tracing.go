@@ -0,0 +1,46 @@
+package zones
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"delivery-api/internal/tracing"
+)
+
+// expectedErrors são os erros que resultam em uma resposta 4xx e não marcam o span do serviço como falha.
+var expectedErrors = []error{ErrInvalidZone, ErrZoneNotFound}
+
+// tracedService envolve o Service criando um span para cada método, abaixo do span da requisição.
+type tracedService struct {
+	next Service
+}
+
+func (s *tracedService) SaveZones(ctx context.Context, features []Feature) ([]Zone, error) {
+	ctx, span := tracing.Start(ctx, "zones.SaveZones", attribute.Int("zones.count", len(features)))
+	zones, err := s.next.SaveZones(ctx, features)
+	tracing.End(span, err, expectedErrors...)
+	return zones, err
+}
+
+func (s *tracedService) GetZones(ctx context.Context) ([]Zone, error) {
+	ctx, span := tracing.Start(ctx, "zones.GetZones")
+	zones, err := s.next.GetZones(ctx)
+	span.SetAttributes(attribute.Int("zones.count", len(zones)))
+	tracing.End(span, err, expectedErrors...)
+	return zones, err
+}
+
+func (s *tracedService) GetZoneByID(ctx context.Context, id uint) (*Zone, error) {
+	ctx, span := tracing.Start(ctx, "zones.GetZoneByID", attribute.Int64("zone.id", int64(id)))
+	zone, err := s.next.GetZoneByID(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return zone, err
+}
+
+func (s *tracedService) DeleteZone(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "zones.DeleteZone", attribute.Int64("zone.id", int64(id)))
+	err := s.next.DeleteZone(ctx, id)
+	tracing.End(span, err, expectedErrors...)
+	return err
+}
@@ -0,0 +1,176 @@
+// Package zones cadastra as zonas operacionais (polígonos enviados em GeoJSON) e localiza a zona de um ponto.
+// Cada entrega recebe a zona que contém as suas coordenadas ao ser criada ou alterada (deliveries.Delivery.ZoneID);
+// quando as zonas mudam, a rotina recompute-delivery-zones refaz a localização das entregas em andamento.
+// A geometria é calculada em Go (pacote geo): o banco guarda o polígono em JSON e o retângulo envolvente, usado
+// para descartar as zonas distantes antes do teste de ponto no polígono.
+package zones
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"delivery-api/internal/geo"
+)
+
+// ErrInvalidZone é retornado quando o GeoJSON enviado não descreve zonas válidas.
+var ErrInvalidZone = errors.New("invalid zone")
+
+// MaxFeatures é o número máximo de zonas em um único envio.
+const MaxFeatures = 500
+
+// @description Zona operacional
+// @type object
+type Zone struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	Name      string           `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	Cidade    string           `json:"cidade" gorm:"size:255;not null;default:'';index"`
+	Geometry  geo.MultiPolygon `json:"geometry" gorm:"serializer:json;type:text;not null" swaggertype:"object"` // GeoJSON MultiPolygon
+	MinLat    float64          `json:"min_lat" gorm:"not null"`                                                 // Retângulo envolvente, calculado a partir da geometria
+	MinLon    float64          `json:"min_lon" gorm:"not null"`
+	MaxLat    float64          `json:"max_lat" gorm:"not null"`
+	MaxLon    float64          `json:"max_lon" gorm:"not null"`
+	Area      float64          `json:"area" gorm:"not null"` // Em graus ao quadrado; entre zonas sobrepostas, vale a de menor área
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Bounds retorna o retângulo envolvente gravado na zona.
+func (z *Zone) Bounds() geo.Bounds {
+	return geo.Bounds{MinLat: z.MinLat, MinLon: z.MinLon, MaxLat: z.MaxLat, MaxLon: z.MaxLon}
+}
+
+// setGeometry grava a geometria junto com o retângulo envolvente e a área.
+func (z *Zone) setGeometry(geometry geo.MultiPolygon) {
+	bounds := geometry.Bounds()
+	z.Geometry = geometry
+	z.MinLat, z.MinLon, z.MaxLat, z.MaxLon = bounds.MinLat, bounds.MinLon, bounds.MaxLat, bounds.MaxLon
+	z.Area = geometry.Area()
+}
+
+// Feature é uma zona no formato de um Feature do GeoJSON. O nome (properties.name) identifica a zona: enviar de novo
+// uma zona com o mesmo nome substitui a geometria e a cidade.
+type Feature struct {
+	Type       string            `json:"type"`
+	Properties FeatureProperties `json:"properties"`
+	Geometry   geo.MultiPolygon  `json:"geometry" swaggertype:"object"` // Polygon ou MultiPolygon
+}
+
+// FeatureProperties são as propriedades lidas de cada Feature.
+type FeatureProperties struct {
+	Name   string `json:"name"`
+	Cidade string `json:"cidade"`
+}
+
+// ParseFeatures lê as zonas de um Feature ou de um FeatureCollection do GeoJSON e confere o nome de cada uma.
+func ParseFeatures(data []byte) ([]Feature, error) {
+	var object struct {
+		Type     string            `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidZone, err)
+	}
+
+	var raw []json.RawMessage
+	switch object.Type {
+	case "Feature":
+		raw = []json.RawMessage{data}
+	case "FeatureCollection":
+		raw = object.Features
+	default:
+		return nil, fmt.Errorf("%w: type must be Feature or FeatureCollection, got %q", ErrInvalidZone, object.Type)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: at least one feature is required", ErrInvalidZone)
+	}
+	if len(raw) > MaxFeatures {
+		return nil, fmt.Errorf("%w: at most %d features are allowed", ErrInvalidZone, MaxFeatures)
+	}
+
+	features := make([]Feature, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for i, data := range raw {
+		var feature Feature
+		if err := json.Unmarshal(data, &feature); err != nil {
+			return nil, fmt.Errorf("%w: features[%d]: %v", ErrInvalidZone, i, err)
+		}
+		if feature.Type != "Feature" {
+			return nil, fmt.Errorf("%w: features[%d].type must be Feature", ErrInvalidZone, i)
+		}
+		if feature.Geometry == nil {
+			return nil, fmt.Errorf("%w: features[%d].geometry is required", ErrInvalidZone, i)
+		}
+		feature.Properties.Name = strings.TrimSpace(feature.Properties.Name)
+		feature.Properties.Cidade = strings.TrimSpace(feature.Properties.Cidade)
+		switch name := feature.Properties.Name; {
+		case name == "":
+			return nil, fmt.Errorf("%w: features[%d].properties.name is required", ErrInvalidZone, i)
+		case len(name) > 100:
+			return nil, fmt.Errorf("%w: features[%d].properties.name must have at most 100 characters", ErrInvalidZone, i)
+		case seen[strings.ToLower(name)]:
+			return nil, fmt.Errorf("%w: zone %q appears more than once", ErrInvalidZone, name)
+		}
+		seen[strings.ToLower(feature.Properties.Name)] = true
+		features[i] = feature
+	}
+	return features, nil
+}
+
+// Index guarda as zonas em memória para localizar muitos pontos seguidos (importação e recálculo),
+// sem uma consulta por ponto.
+type Index struct {
+	zones []Zone
+}
+
+// LoadIndex lê todas as zonas, da menor para a maior área, para montar o índice.
+// Recebe a transação (ou conexão) em uso, como events.Record.
+func LoadIndex(tx *gorm.DB) (*Index, error) {
+	var zones []Zone
+	if err := tx.Order("area, id").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return &Index{zones: zones}, nil
+}
+
+// Locate retorna o ID da zona que contém o ponto, ou nil se nenhuma contiver. Coordenadas zeradas são tratadas como
+// ausentes. Se o ponto estiver em mais de uma zona (zonas sobrepostas ou sobre a divisa), vale a de menor área e,
+// no empate, a de menor ID.
+func (i *Index) Locate(lat, lon float64) *uint {
+	if lat == 0 && lon == 0 {
+		return nil
+	}
+	for _, zone := range i.zones {
+		if zone.Bounds().Contains(lat, lon) && zone.Geometry.Contains(lat, lon) {
+			id := zone.ID
+			return &id
+		}
+	}
+	return nil
+}
+
+// Locate localiza a zona de um único ponto (veja Index.Locate). Apenas as zonas cujo retângulo envolvente contém o
+// ponto são lidas do banco. Recebe a transação em uso, para que a entrega seja gravada junto com a zona encontrada.
+func Locate(tx *gorm.DB, lat, lon float64) (*uint, error) {
+	if lat == 0 && lon == 0 {
+		return nil, nil
+	}
+	var candidates []Zone
+	err := tx.Where("min_lat <= ? AND max_lat >= ? AND min_lon <= ? AND max_lon >= ?", lat, lat, lon, lon).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].Area != candidates[b].Area {
+			return candidates[a].Area < candidates[b].Area
+		}
+		return candidates[a].ID < candidates[b].ID
+	})
+	return (&Index{zones: candidates}).Locate(lat, lon), nil
+}
@@ -1,16 +1,27 @@
+// Comando delivery-api: servidor HTTP da API e tarefas de manutenção.
+//
+// Uso:
+//
+//	delivery-api [serve]                          Inicia o servidor HTTP (padrão, quando nenhum comando é informado)
+//	delivery-api migrate up|down|status|mark|baseline
+//	                                              Aplica, reverte, lista e gera as migrações do banco de dados
+//	delivery-api seed [-clients N] [-deliveries N] [-seed N]
+//	                                              Popula o banco com clientes e entregas fictícios
+//	delivery-api import [-file PATH] [-dry-run] [-mapping JSON]
+//	                                              Importa entregas de um arquivo CSV
+//	delivery-api export clients|deliveries [-format csv|ndjson] [-file PATH] [filtros]
+//	                                              Exporta clientes ou entregas em CSV ou NDJSON
+//	delivery-api user create -name NOME -email EMAIL [-role admin|operator]
+//	                                              Cria um usuário da API e mostra o token de acesso
+//
+// Todos os comandos leem a mesma configuração (variáveis de ambiente e arquivo .env) da API.
+// Use "delivery-api <comando> -h" para ver as opções de cada comando.
 package main
 
 import (
-	"log"
-	"time"
+	"fmt"
+	"os"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	"github.com/swaggo/gin-swagger"
-	"github.com/swaggo/files"
-	"delivery-api/config"
-	"delivery-api/internal/clients"
-	"delivery-api/internal/deliveries"
 	_ "delivery-api/docs" // Importa a documentação gerada pelo Swagger
 )
 
@@ -23,69 +34,60 @@ import (
 // @contact.url http://www.example.com
 // @license.name MIT
 // @license.url https://opensource.org/licenses/MIT
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Token de API no formato "Bearer <token>" (crie com "delivery-api user create")
 
-func main() {
-	// Inicializa a conexão com o banco de dados.
-	db := config.InitDB()
-
-	// Migra as tabelas no banco de dados.
-	// Isso garante que as tabelas necessárias para Client e Delivery estejam criadas.
-	if err := db.AutoMigrate(&clients.Client{}, &deliveries.Delivery{}); err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
-	}
-
-	// Cria as instâncias do repositório e serviço para clientes.
-	clientRepo := clients.NewRepository(db)
-	clientService := clients.NewService(clientRepo)
-
-	// Cria as instâncias do repositório e serviço para entregas.
-	deliveryRepo := deliveries.NewRepository(db)
-	deliveryService := deliveries.NewService(deliveryRepo)
-
-	// Cria os handlers para clientes e entregas.
-	// Os handlers são responsáveis por lidar com as requisições HTTP.
-	clientHandler := clients.Handler{Service: clientService}
-	deliveryHandler := deliveries.Handler{Service: deliveryService}
+// command é um subcomando da linha de comando.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	// Cria uma instância do servidor Gin.
-	r := gin.Default()
+// commands são os subcomandos disponíveis, na ordem em que aparecem na ajuda.
+var commands = []command{
+	{"serve", "inicia o servidor HTTP (padrão)", runServe},
+	{"migrate", "aplica, reverte e lista as migrações do banco de dados", runMigrate},
+	{"seed", "popula o banco com clientes e entregas fictícios", runSeed},
+	{"import", "importa entregas de um arquivo CSV", runImport},
+	{"export", "exporta clientes ou entregas em CSV ou NDJSON", runExport},
+	{"user", "gerencia os usuários da API", runUser},
+	{"recompute", "recalcula os pesos das entregas com o CUBIC_WEIGHT_DIVISOR atual", runRecompute},
+}
 
-	// Configura o middleware CORS para permitir requisições de diferentes origens.
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Permite todas as origens (altere para segurança em produção)
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Métodos HTTP permitidos
-		AllowHeaders:     []string{"Content-Type", "Authorization"}, // Cabeçalhos permitidos
-		ExposeHeaders:    []string{"Content-Length"}, // Cabeçalhos expostos
-		AllowCredentials: true, // Permite credenciais (cookies, autenticação)
-		MaxAge:           12 * time.Hour, // Tempo de cache para as configurações do CORS
-	}))
-
-	// Rotas para clientes:
-	r.POST("/api/v1/clients", clientHandler.CreateClient)          // Cria um novo cliente
-	r.GET("/api/v1/clients", clientHandler.GetClients)            // Retorna todos os clientes
-	r.GET("/api/v1/clients/cpf/:cpf", clientHandler.GetClientByCPF) // Busca um cliente pelo CPF
-	r.GET("/api/v1/clients/:id", clientHandler.GetClientByID)     // Retorna um cliente pelo ID
-	r.GET("/api/v1/clients/name/:name", clientHandler.GetClientsByName)     // Retorna um cliente pelo Nome
-	r.GET("/api/v1/clients/count", clientHandler.GetTotalClients) // Retorna o total de clientes
-	r.PUT("/api/v1/clients/:id", clientHandler.UpdateClient)      // Atualiza um cliente pelo ID
-	r.DELETE("/api/v1/clients/:id", clientHandler.DeleteClient)   // Deleta um cliente pelo ID
+func main() {
+	// Sem argumentos, sobe o servidor, como antes da linha de comando existir.
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
 
-	// Rotas para entregas:
-	r.POST("/api/v1/deliveries", deliveryHandler.CreateDelivery)          // Cria uma nova entrega
-	r.GET("/api/v1/deliveries", deliveryHandler.GetDeliveries)           // Retorna todas as entregas
-	r.GET("/api/v1/deliveries/:id", deliveryHandler.GetDeliveryByID)     // Retorna uma entrega pelo ID
-	r.GET("/api/v1/deliveries/client/cpf/:cpf", deliveryHandler.GetDeliveriesByCPF) // Busca entregas pelo CPF do cliente
-	r.GET("/api/v1/deliveries/client/name/:name", deliveryHandler.GetDeliveriesByClientName) // Busca entregas pelo Nome do cliente
-	r.GET("/api/v1/deliveries/city/:city", deliveryHandler.GetDeliveriesByCity) // Busca entregas pelo Nome do cliente
-	r.PUT("/api/v1/deliveries/:id", deliveryHandler.UpdateDelivery)      // Atualiza uma entrega pelo ID
-	r.DELETE("/api/v1/deliveries/:id", deliveryHandler.DeleteDelivery)   // Deleta uma entrega pelo ID
-	r.PATCH("/api/v1/deliveries/:id/:status", deliveryHandler.UpdateOrderStatus) // Atualiza o status de uma entrega
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
-	// Rota para o Swagger UI.
-	// Acesse http://localhost:8080/swagger/index.html para visualizar a documentação da API.
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
 
-	// Inicia o servidor na porta 8080.
-	log.Println("Servidor rodando na porta 8080")
-	r.Run(":8080")
-}
\ No newline at end of file
+// usage lista os comandos disponíveis.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: delivery-api <command> [flags]\n\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun \"delivery-api <command> -h\" for the flags of each command.")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"delivery-api/config"
+	"delivery-api/internal/migrations"
+)
+
+// migrateUsage resume os subcomandos de migrate.
+const migrateUsage = "migrate up | down [-steps N] | status | mark -version N | baseline [-name NAME] [-dir DIR]"
+
+// runMigrate aplica, reverte, lista e gera as migrações do banco de dados.
+//
+//	migrate up                 Aplica as migrações pendentes
+//	migrate down [-steps N]    Reverte as últimas N migrações (padrão: 1)
+//	migrate status             Lista as migrações e se já foram aplicadas
+//	migrate mark -version N    Registra as migrações até N como aplicadas, sem executá-las
+//	migrate baseline [-name baseline] [-dir internal/migrations/sql]
+//	                           Gera, para cada dialeto, uma migração que cria as tabelas dos models atuais
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: delivery-api %s", migrateUsage)
+	}
+
+	action, args := args[0], args[1:]
+	switch action {
+	case "up":
+		newFlagSet("migrate up", "migrate up").Parse(args)
+		migrator, err := openMigrator()
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up()
+		report("applied", applied)
+		return err
+	case "down":
+		flags := newFlagSet("migrate down", "migrate down [-steps N]")
+		steps := flags.Int("steps", 1, "quantidade de migrações a reverter")
+		flags.Parse(args)
+		migrator, err := openMigrator()
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(*steps)
+		report("reverted", reverted)
+		return err
+	case "status":
+		newFlagSet("migrate status", "migrate status").Parse(args)
+		migrator, err := openMigrator()
+		if err != nil {
+			return err
+		}
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	case "mark":
+		flags := newFlagSet("migrate mark", "migrate mark -version N")
+		version := flags.Uint("version", 0, "última versão a ser registrada como aplicada")
+		flags.Parse(args)
+		if *version == 0 {
+			return fmt.Errorf("mark: -version is required")
+		}
+		migrator, err := openMigrator()
+		if err != nil {
+			return err
+		}
+		marked, err := migrator.Mark(*version)
+		report("marked", marked)
+		return err
+	case "baseline":
+		flags := newFlagSet("migrate baseline", "migrate baseline [-name NAME] [-dir DIR]")
+		name := flags.String("name", "baseline", "nome da migração")
+		dir := flags.String("dir", filepath.Join("internal", "migrations", "sql"), "diretório das migrações")
+		flags.Parse(args)
+		return writeBaseline(*dir, *name)
+	default:
+		return fmt.Errorf("unknown action %q (usage: delivery-api %s)", action, migrateUsage)
+	}
+}
+
+// openMigrator carrega a configuração, conecta ao banco e cria o Migrator do dialeto configurado.
+// Diferente dos demais comandos, não exige que o schema esteja atualizado.
+func openMigrator() (*migrations.Migrator, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	db, err := config.OpenDatabase(cfg.Database, cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(db)
+}
+
+// report lista as migrações afetadas por um comando.
+func report(action string, affected []migrations.Migration) {
+	for _, migration := range affected {
+		fmt.Printf("%s %04d_%s\n", action, migration.Version, migration.Name)
+	}
+	if len(affected) == 0 {
+		fmt.Printf("nothing %s\n", action)
+	}
+}
+
+// writeBaseline gera os arquivos up/down da migração de base para todos os dialetos,
+// usando a próxima versão livre do diretório de migrações.
+func writeBaseline(dir, name string) error {
+	version := uint(1)
+	for _, dialect := range migrations.Dialects {
+		existing, err := filepath.Glob(filepath.Join(dir, dialect, "*.up.sql"))
+		if err != nil {
+			return err
+		}
+		if next := uint(len(existing)) + 1; next > version {
+			version = next
+		}
+	}
+
+	for _, dialect := range migrations.Dialects {
+		up, down, err := migrations.GenerateBaseline(dialect, migrations.Models()...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dialect, err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, dialect), 0o755); err != nil {
+			return err
+		}
+
+		prefix := filepath.Join(dir, dialect, fmt.Sprintf("%04d_%s", version, name))
+		header := fmt.Sprintf("-- Gerado por \"delivery-api migrate baseline\" a partir dos models (%s).\n", dialect)
+		if err := os.WriteFile(prefix+".up.sql", []byte(header+up), 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(prefix+".down.sql", []byte(header+down), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("created %s.up.sql and %s.down.sql\n", prefix, prefix)
+	}
+	return nil
+}
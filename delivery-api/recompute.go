@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"delivery-api/config"
+	"delivery-api/internal/deliveries"
+)
+
+// runRecompute recalcula dados derivados das entregas, com a mesma rotina da execução manual em /admin/jobs.
+//
+//	recompute weights    Recalcula os pesos cubado e tarifado com o CUBIC_WEIGHT_DIVISOR atual
+func runRecompute(args []string) error {
+	if len(args) == 0 || args[0] != "weights" {
+		return fmt.Errorf("usage: delivery-api recompute weights")
+	}
+	newFlagSet("recompute weights", "recompute weights").Parse(args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	changed, err := deliveries.NewService(deliveries.NewRepository(db, cfg.CubicWeightDivisor)).RecomputeWeights(ctx)
+	fmt.Printf("%d deliveries reweighed with CUBIC_WEIGHT_DIVISOR=%g\n", changed, cfg.CubicWeightDivisor)
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"delivery-api/config"
+	"delivery-api/internal/clients"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/seed"
+)
+
+// runSeed popula o banco com clientes e entregas fictícios, com CPFs e CNPJs válidos e coordenadas de cidades reais.
+// Os dados passam pelos mesmos serviços da API, então os eventos de domínio também são gravados na outbox.
+func runSeed(args []string) error {
+	flags := newFlagSet("seed", "seed [-clients N] [-deliveries N] [-seed N]")
+	clientCount := flags.Int("clients", 20, "quantidade de clientes a criar")
+	deliveryCount := flags.Int("deliveries", 100, "quantidade de entregas a criar, distribuídas entre os clientes")
+	randomSeed := flags.Int64("seed", time.Now().UnixNano(), "semente do gerador (a mesma semente gera os mesmos dados)")
+	flags.Parse(args)
+
+	if *clientCount <= 0 {
+		return fmt.Errorf("-clients must be greater than zero")
+	}
+	if *deliveryCount < 0 {
+		return fmt.Errorf("-deliveries must not be negative")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	clientService := clients.NewService(clients.NewRepository(db))
+	deliveryService := deliveries.NewService(deliveries.NewRepository(db, cfg.CubicWeightDivisor))
+	generator := seed.NewGenerator(*randomSeed)
+	ctx, stop := signalContext()
+	defer stop()
+
+	// Cria os clientes um a um, como a API faria.
+	created := make([]clients.Client, 0, *clientCount)
+	for i := 0; i < *clientCount; i++ {
+		client := generator.Client()
+		if _, err := clientService.CreateClient(ctx, &client); err != nil {
+			return fmt.Errorf("failed to create client %s: %w", client.CPF, err)
+		}
+		created = append(created, client)
+	}
+
+	// Cria as entregas em lote, pela mesma validação da importação de CSV.
+	rows := make([]deliveries.ImportRow, *deliveryCount)
+	for i := range rows {
+		rows[i] = deliveries.ImportRow{Row: i + 1, Delivery: generator.Delivery(&created[i%len(created)])}
+	}
+	report, err := deliveryService.ImportDeliveries(ctx, rows, false)
+	if err != nil {
+		return err
+	}
+	if len(report.Rejected) > 0 {
+		rejected := report.Rejected[0]
+		return fmt.Errorf("generated delivery %d was rejected: %s", rejected.Row, rejected.Reason)
+	}
+
+	fmt.Printf("created %d clients and %d deliveries (seed %d)\n", len(created), len(report.Accepted), *randomSeed)
+	return nil
+}
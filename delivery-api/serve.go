@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/gin-swagger"
+	"github.com/swaggo/files"
+	"delivery-api/config"
+	"delivery-api/internal/analytics"
+	"delivery-api/internal/clients"
+	"delivery-api/internal/couriers"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/events"
+	"delivery-api/internal/health"
+	"delivery-api/internal/idempotency"
+	"delivery-api/internal/jobs"
+	"delivery-api/internal/logging"
+	"delivery-api/internal/metrics"
+	"delivery-api/internal/migrations"
+	"delivery-api/internal/planning"
+	"delivery-api/internal/proofs"
+	"delivery-api/internal/scheduler"
+	"delivery-api/internal/timeout"
+	"delivery-api/internal/tracing"
+	"delivery-api/internal/users"
+	"delivery-api/internal/webhooks"
+	"delivery-api/internal/zones"
+)
+
+// runServe inicia o servidor HTTP da API, com os workers de webhooks, da outbox e das rotinas agendadas em segundo plano.
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "serve")
+	flags.Parse(args)
+
+	// Carrega e valida a configuração a partir das variáveis de ambiente e do arquivo .env.
+	// Se algum valor for inválido, a aplicação não sobe e todos os problemas são listados.
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	// Os logs saem em JSON (LOG_FORMAT), no nível de LOG_LEVEL e com os dados pessoais mascarados.
+	// As mensagens do pacote log e do GORM passam pelo mesmo logger.
+	logger := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+
+	// Em qualquer nível de log acima de debug, o Gin roda em modo de produção.
+	if cfg.LogLevel != config.LogLevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Configura o tracing com OpenTelemetry (TRACING_EXPORTER; padrão: none). No desligamento, os spans que
+	// ainda estão no buffer são enviados antes de o processo terminar.
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		File:         cfg.Tracing.File,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+		ServiceName:  cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
+	// Inicializa a conexão com o banco de dados e verifica se o schema está na versão esperada pelo código.
+	// As migrações são aplicadas com "delivery-api migrate up" (ou ao iniciar, se DB_AUTO_MIGRATE=true);
+	// a aplicação não sobe contra um schema desatualizado.
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	// Cria as métricas do Prometheus e passa a medir as consultas e o pool de conexões do banco.
+	appMetrics := metrics.New()
+	if err := appMetrics.InstrumentDB(db); err != nil {
+		return err
+	}
+
+	// Cria um span para cada consulta feita pelo GORM, abaixo do span da requisição ou do worker.
+	if err := tracing.InstrumentDB(db); err != nil {
+		return err
+	}
+
+	// O contexto é cancelado ao receber SIGINT ou SIGTERM, iniciando o desligamento.
+	ctx, stop := signalContext()
+	defer stop()
+
+	// Os workers em segundo plano rodam com um contexto próprio, cancelado só depois que o servidor HTTP
+	// parou de aceitar requisições, para que os eventos gravados pelas últimas requisições ainda sejam entregues.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	startWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workersCtx)
+		}()
+	}
+
+	// Cria as instâncias do repositório e serviço para clientes.
+	clientRepo := clients.NewRepository(db)
+	clientService := clients.NewService(clientRepo)
+
+	// Cria o dispatcher e o serviço de webhooks.
+	// O dispatcher roda em segundo plano, enviando as mensagens pendentes com novas tentativas em backoff exponencial.
+	webhookRepo := webhooks.NewRepository(db)
+	webhookConfig := webhooks.DefaultDispatcherConfig()
+	webhookConfig.MaxAttempts = cfg.Webhooks.MaxAttempts
+	webhookConfig.RetryBase = cfg.Webhooks.RetryBase
+	webhookConfig.Timeout = cfg.Webhooks.Timeout
+	webhookConfig.AllowPrivateTargets = cfg.Webhooks.AllowPrivateTargets
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepo, nil, webhookConfig)
+	webhookService := webhooks.NewService(webhookRepo, webhookDispatcher)
+	startWorker(webhookDispatcher.Run)
+
+	// Cria o barramento de eventos de domínio e o dispatcher da outbox.
+	// Os repositórios gravam os eventos na outbox junto com cada alteração; o dispatcher os entrega aos assinantes
+	// (pelo menos uma vez e em ordem por entrega/cliente), com o intervalo de leitura definido em OUTBOX_POLL_INTERVAL.
+	eventBus := events.NewBus()
+	eventBus.Subscribe("webhooks", webhooks.EventHandler(webhookService), webhooks.DomainEventTypes()...)
+	eventBus.Subscribe("metrics", appMetrics.HandleEvent, metrics.BusinessEventTypes()...)
+	eventConfig := events.DefaultDispatcherConfig()
+	eventConfig.PollInterval = cfg.OutboxPollInterval
+	eventConfig.MaxAttempts = cfg.OutboxMaxAttempts
+	eventDispatcher := events.NewDispatcher(events.NewRepository(db), eventBus, eventConfig)
+	startWorker(eventDispatcher.Run)
+
+	// Cria as instâncias do repositório e serviço para entregas.
+	deliveryRepo := deliveries.NewRepository(db, cfg.CubicWeightDivisor)
+	deliveryService := deliveries.NewService(deliveryRepo)
+
+	// O Broker repassa as mudanças de status aos clientes conectados ao stream de entregas.
+	// Ele não usa o barramento, em que cada evento é entregue por uma só instância: cada instância segue a outbox
+	// por conta própria, para que os clientes recebam todas as mudanças em qualquer instância.
+	deliveryBroker := deliveries.NewBroker()
+	startWorker(func(ctx context.Context) {
+		deliveryBroker.Follow(ctx, deliveryRepo, cfg.OutboxPollInterval)
+	})
+	if err := appMetrics.RegisterDeliveryStatusGauge(deliveryService.CountDeliveriesByStatus); err != nil {
+		return err
+	}
+
+	// Cria o serviço de usuários, usado para identificar quem faz cada requisição pelo token de API.
+	userService := users.NewService(users.NewRepository(db))
+
+	// Cria o serviço dos comprovantes de entrega. A assinatura e a foto ficam no armazenamento de arquivos
+	// (BLOB_STORE/BLOB_DIR); a tabela delivery_proofs guarda os demais dados e as chaves dos arquivos.
+	blobStore, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	proofService := proofs.NewService(proofs.NewRepository(db), blobStore)
+
+	// Cria o serviço dos entregadores, que controla a atribuição das entregas respeitando a capacidade de cada um.
+	courierService := couriers.NewService(couriers.NewRepository(db))
+
+	// Cria o serviço dos planos de carga, que distribui as entregas de uma cidade entre os veículos antes da saída.
+	planningService := planning.NewService(planning.NewRepository(db), courierService)
+
+	// Cria o serviço de indicadores, usado pelo dashboard e pelo resumo diário.
+	analyticsService := analytics.NewService(analytics.NewRepository(db))
+
+	// Cria o scheduler das rotinas em segundo plano (SCHEDULER_*): sinalização das entregas atrasadas,
+	// cancelamento das pendentes esquecidas, resumo diário e recálculo das zonas. Cada ocorrência é executada por uma
+	// única instância, que a reserva na tabela scheduler_jobs; com SCHEDULER_ENABLED=false, as rotinas só rodam manualmente.
+	schedulerConfig := scheduler.DefaultConfig()
+	schedulerConfig.Enabled = cfg.Scheduler.Enabled
+	schedulerConfig.LeaseTTL = cfg.Scheduler.LeaseTTL
+	jobScheduler := scheduler.New(scheduler.NewRepository(db), schedulerConfig)
+	err = jobScheduler.Register(ctx, jobs.New(deliveryService, analyticsService, jobs.Config{
+		OverdueSchedule: cfg.Scheduler.OverdueSchedule,
+		StaleSchedule:   cfg.Scheduler.StaleSchedule,
+		StalePendingAge: cfg.Scheduler.StalePendingAge,
+		SummarySchedule: cfg.Scheduler.SummarySchedule,
+		ZonesSchedule:   cfg.Scheduler.ZonesSchedule,
+	})...)
+	if err != nil {
+		return err
+	}
+	startWorker(jobScheduler.Run)
+
+	// Cria o serviço das zonas operacionais. Cada mudança nas zonas dispara o recálculo da zona das entregas em
+	// andamento; se ele já estiver em execução, a mudança é coberta pela próxima execução agendada.
+	zoneService := zones.NewService(zones.NewRepository(db), func(ctx context.Context) {
+		if err := jobScheduler.Trigger(ctx, jobs.RecomputeZones); err != nil && !errors.Is(err, scheduler.ErrJobRunning) {
+			slog.ErrorContext(ctx, "failed to trigger job", "job", jobs.RecomputeZones, "err", err)
+		}
+	})
+
+	// Cria os handlers para clientes e entregas.
+	// Os handlers são responsáveis por lidar com as requisições HTTP.
+	clientHandler := clients.Handler{Service: clientService}
+	deliveryHandler := deliveries.Handler{Service: deliveryService, Broker: deliveryBroker}
+	webhookHandler := webhooks.Handler{Service: webhookService}
+	analyticsHandler := analytics.Handler{Service: analyticsService}
+	proofHandler := proofs.Handler{Service: proofService}
+	courierHandler := couriers.Handler{Service: courierService}
+	planningHandler := planning.Handler{Service: planningService}
+	zoneHandler := zones.Handler{Service: zoneService}
+	schedulerHandler := scheduler.Handler{Scheduler: jobScheduler}
+
+	// Cria o middleware de idempotência usado nas rotas de criação.
+	// As respostas ficam guardadas pelo tempo definido em IDEMPOTENCY_TTL (padrão: 24h).
+	idempotent := idempotency.Middleware(idempotency.NewStore(db), cfg.IdempotencyTTL)
+
+	// Cria uma instância do servidor Gin.
+	// O log de acesso e a recuperação de pânicos do Gin são substituídos pelos do pacote logging,
+	// que escrevem no formato estruturado e mascaram os dados pessoais.
+	r := gin.New()
+
+	// Cria o span de cada requisição, continuando o trace do cabeçalho traceparent recebido.
+	// Vem antes dos demais middlewares para que o span cubra a requisição inteira.
+	r.Use(tracing.Middleware())
+
+	// Registra cada requisição com o request ID (X-Request-ID), o usuário, a rota, a latência e o status.
+	r.Use(logging.Middleware(logger))
+	r.Use(logging.Recovery(logger))
+
+	// Mede a duração de todas as requisições, por rota e status.
+	r.Use(appMetrics.Middleware())
+
+	// Configura o middleware CORS para permitir requisições de diferentes origens.
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.HTTP.CORSOrigins, // Origens permitidas (CORS_ALLOWED_ORIGINS; padrão: todas)
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, // Métodos HTTP permitidos
+		AllowHeaders:     []string{"Content-Type", "Authorization", "If-Match", "Last-Event-ID", idempotency.HeaderKey, logging.HeaderRequestID, "traceparent", "tracestate"}, // Cabeçalhos permitidos
+		ExposeHeaders:    []string{"Content-Length", "ETag", idempotency.HeaderReplayed, logging.HeaderRequestID}, // Cabeçalhos expostos
+		AllowCredentials: true, // Permite credenciais (cookies, autenticação)
+		MaxAge:           12 * time.Hour, // Tempo de cache para as configurações do CORS
+	}))
+
+	// Limita o tempo de cada requisição (QUERY_TIMEOUT, com tempos próprios por rota em QUERY_TIMEOUT_ROUTES).
+	// O prazo vai no contexto repassado aos serviços e repositórios, então as consultas ao banco são canceladas
+	// quando ele estoura ou quando o cliente desconecta.
+	r.Use(timeout.Middleware(cfg.HTTP.QueryTimeout, cfg.HTTP.RouteTimeouts))
+
+	// Identifica o usuário pelo cabeçalho "Authorization: Bearer <token>" (tokens criados com "delivery-api user create").
+	// Requisições sem o cabeçalho continuam sendo aceitas; um token inválido é rejeitado com 401.
+	r.Use(users.Identify(userService))
+
+	// Rotas para clientes:
+	r.POST("/api/v1/clients", idempotent, clientHandler.CreateClient) // Cria um novo cliente
+	r.GET("/api/v1/clients", clientHandler.GetClients)            // Retorna todos os clientes
+	r.GET("/api/v1/clients/export", clientHandler.ExportClients)   // Exporta os clientes em CSV ou NDJSON
+	r.GET("/api/v1/clients/cpf/:cpf", clientHandler.GetClientByCPF) // Busca um cliente pelo CPF
+	r.GET("/api/v1/clients/:id", clientHandler.GetClientByID)     // Retorna um cliente pelo ID
+	r.GET("/api/v1/clients/name/:name", clientHandler.GetClientsByName)     // Retorna um cliente pelo Nome
+	r.GET("/api/v1/clients/count", clientHandler.GetTotalClients) // Retorna o total de clientes
+	r.PUT("/api/v1/clients/:id", clientHandler.UpdateClient)      // Atualiza um cliente pelo ID
+	r.DELETE("/api/v1/clients/:id", clientHandler.DeleteClient)   // Deleta um cliente pelo ID
+
+	// Rotas para entregas:
+	r.POST("/api/v1/deliveries", idempotent, deliveryHandler.CreateDelivery) // Cria uma nova entrega
+	r.GET("/api/v1/deliveries", deliveryHandler.GetDeliveries)           // Retorna todas as entregas
+	r.POST("/api/v1/deliveries/import", deliveryHandler.ImportDeliveries) // Importa entregas a partir de um CSV
+	r.GET("/api/v1/deliveries/export", deliveryHandler.ExportDeliveries)  // Exporta as entregas em CSV ou NDJSON
+	r.GET("/api/v1/deliveries/stream", deliveryHandler.StreamDeliveries)  // Stream de mudanças de status (SSE)
+	r.GET("/api/v1/deliveries/late", deliveryHandler.GetLateDeliveries)  // Entregas com o prazo (SLA) vencido
+	r.GET("/api/v1/deliveries.geojson", deliveryHandler.GetDeliveriesGeoJSON)  // Entregas em GeoJSON, para o mapa
+	r.GET("/api/v1/deliveries/clusters", deliveryHandler.GetDeliveryClusters) // Entregas agrupadas por geohash, para o mapa
+	r.GET("/api/v1/deliveries/:id", deliveryHandler.GetDeliveryByID)     // Retorna uma entrega pelo ID
+	r.GET("/api/v1/deliveries/client/cpf/:cpf", deliveryHandler.GetDeliveriesByCPF) // Busca entregas pelo CPF do cliente
+	r.GET("/api/v1/deliveries/client/name/:name", deliveryHandler.GetDeliveriesByClientName) // Busca entregas pelo Nome do cliente
+	r.GET("/api/v1/deliveries/city/:city", deliveryHandler.GetDeliveriesByCity) // Busca entregas pelo Nome do cliente
+	r.PUT("/api/v1/deliveries/:id", deliveryHandler.UpdateDelivery)      // Atualiza uma entrega pelo ID
+	r.DELETE("/api/v1/deliveries/:id", deliveryHandler.DeleteDelivery)   // Deleta uma entrega pelo ID
+	r.PATCH("/api/v1/deliveries/:id/:status", deliveryHandler.UpdateOrderStatus) // Atualiza o status de uma entrega
+	r.POST("/api/v1/deliveries/:id/proof", proofHandler.SubmitProof)             // Registra o comprovante de entrega
+	r.GET("/api/v1/deliveries/:id/proof", proofHandler.GetProof)                 // Retorna o comprovante de entrega
+	r.GET("/api/v1/deliveries/:id/proof/:file", proofHandler.DownloadFile)       // Baixa a assinatura ou a foto do comprovante
+	r.GET("/api/v1/deliveries/:id/history", deliveryHandler.GetDeliveryHistory)  // Histórico de eventos de uma entrega
+	r.GET("/api/v1/deliveries/:id/packages", deliveryHandler.GetPackages)                 // Lista os volumes de uma entrega
+	r.POST("/api/v1/deliveries/:id/packages", deliveryHandler.AddPackage)                 // Inclui um volume na entrega
+	r.PUT("/api/v1/deliveries/:id/packages/:package_id", deliveryHandler.UpdatePackage)    // Altera um volume da entrega
+	r.DELETE("/api/v1/deliveries/:id/packages/:package_id", deliveryHandler.DeletePackage) // Remove um volume da entrega
+
+	// Rotas para entregadores:
+	r.POST("/api/v1/couriers", courierHandler.CreateCourier)         // Cadastra um entregador
+	r.GET("/api/v1/couriers", courierHandler.GetCouriers)            // Lista os entregadores
+	r.GET("/api/v1/couriers/:id", courierHandler.GetCourier)         // Retorna um entregador pelo ID
+	r.PUT("/api/v1/couriers/:id", courierHandler.UpdateCourier)      // Atualiza um entregador
+	r.DELETE("/api/v1/couriers/:id", courierHandler.DeleteCourier)   // Remove um entregador
+	r.GET("/api/v1/couriers/:id/deliveries", courierHandler.GetWorkload)     // Entregas do dia do entregador
+	r.POST("/api/v1/couriers/:id/deliveries", courierHandler.AssignDelivery) // Atribui uma entrega ao entregador
+	r.DELETE("/api/v1/couriers/:id/deliveries/:delivery_id", courierHandler.UnassignDelivery) // Retira uma entrega do entregador
+
+	// Rotas para planos de carga:
+	r.POST("/api/v1/load-plans", planningHandler.CreatePlan)          // Calcula (e grava) um plano de carga
+	r.GET("/api/v1/load-plans", planningHandler.GetPlans)             // Lista os planos gravados
+	r.GET("/api/v1/load-plans/:id", planningHandler.GetPlan)          // Retorna um plano pelo ID
+	r.POST("/api/v1/load-plans/:id/apply", planningHandler.ApplyPlan) // Atribui as entregas do plano aos entregadores
+
+	// Rotas para zonas operacionais:
+	r.POST("/api/v1/zones", zoneHandler.UploadZones)       // Cria ou substitui zonas a partir de GeoJSON
+	r.GET("/api/v1/zones", zoneHandler.GetZones)           // Lista as zonas
+	r.GET("/api/v1/zones/:id", zoneHandler.GetZone)        // Retorna uma zona pelo ID
+	r.DELETE("/api/v1/zones/:id", zoneHandler.DeleteZone)  // Remove uma zona
+
+	// Rotas para webhooks, restritas aos usuários com o papel admin:
+	webhookRoutes := r.Group("/api/v1/webhooks", users.RequireRole(users.RoleAdmin))
+	webhookRoutes.POST("", webhookHandler.CreateSubscription)          // Cria uma assinatura
+	webhookRoutes.GET("", webhookHandler.GetSubscriptions)             // Lista as assinaturas
+	webhookRoutes.GET("/dead-letters", webhookHandler.GetDeadLetters)  // Lista as mensagens mortas
+	webhookRoutes.GET("/:id", webhookHandler.GetSubscriptionByID)      // Retorna uma assinatura pelo ID
+	webhookRoutes.PUT("/:id", webhookHandler.UpdateSubscription)       // Atualiza uma assinatura
+	webhookRoutes.DELETE("/:id", webhookHandler.DeleteSubscription)    // Remove uma assinatura
+	webhookRoutes.POST("/messages/:id/redeliver", webhookHandler.RedeliverMessage) // Reenvia uma mensagem
+
+	// Rotas de indicadores operacionais (dashboard):
+	r.GET("/api/v1/analytics/deliveries", analyticsHandler.GetDeliveryAnalytics) // Indicadores das entregas do período
+	r.GET("/api/v1/analytics/daily/:day", analyticsHandler.GetDailySummary)      // Resumo gravado de um dia
+
+	// Rotas de administração, restritas aos usuários com o papel admin:
+	admin := r.Group("/api/v1/admin", users.RequireRole(users.RoleAdmin))
+	admin.GET("/jobs", schedulerHandler.ListJobs)            // Lista as rotinas agendadas
+	admin.POST("/jobs/:name/run", schedulerHandler.RunJob)   // Executa uma rotina imediatamente
+
+	// Rotas de saúde, usadas pelo orquestrador:
+	// /healthz indica que o processo está respondendo; /readyz verifica o banco e a versão do schema
+	// e passa a responder 503 assim que o desligamento começa.
+	readiness := health.NewChecker()
+	readiness.Register("database", sqlDB.PingContext)
+	readiness.Register("migrations", func(ctx context.Context) error { return migrator.Check() })
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", readiness.Readiness)
+
+	// Rota das métricas no formato do Prometheus.
+	r.GET("/metrics", appMetrics.Handler())
+
+	// Rota para o Swagger UI.
+	// Acesse http://localhost:8080/swagger/index.html para visualizar a documentação da API.
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Inicia o servidor no endereço configurado (HTTP_ADDR; padrão: :8080).
+	// No desligamento, as conexões do stream de status são encerradas para não segurar o servidor até o tempo limite.
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server.RegisterOnShutdown(deliveryBroker.Close)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Servidor rodando em %s", cfg.HTTP.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Desligamento: o /readyz passa a responder 503, o servidor para de aceitar conexões e espera as requisições
+	// em andamento por até HTTP_SHUTDOWN_TIMEOUT; depois os workers são parados e a conexão com o banco é fechada.
+	log.Printf("Shutting down (timeout %s)", cfg.HTTP.ShutdownTimeout)
+	stop()
+	readiness.SetDraining()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := server.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		shutdownErr = fmt.Errorf("failed to drain in-flight requests: %w", shutdownErr)
+	}
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		if shutdownErr == nil {
+			shutdownErr = fmt.Errorf("background workers did not stop within %s", cfg.HTTP.ShutdownTimeout)
+		}
+	}
+
+	if shutdownErr == nil {
+		log.Printf("Server stopped")
+	}
+	return shutdownErr
+}
\ No newline at end of file
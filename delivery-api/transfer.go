@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"delivery-api/config"
+	"delivery-api/internal/clients"
+	"delivery-api/internal/deliveries"
+	"delivery-api/internal/export"
+)
+
+// runImport importa entregas de um arquivo CSV, com as mesmas regras do endpoint POST /deliveries/import.
+// O relatório (linhas aceitas e rejeitadas) é impresso em JSON na saída padrão.
+func runImport(args []string) error {
+	flags := newFlagSet("import", "import [-file PATH] [-dry-run] [-mapping JSON]")
+	file := flags.String("file", "-", "arquivo CSV a importar (\"-\" para a entrada padrão)")
+	dryRun := flags.Bool("dry-run", false, "apenas valida o arquivo, sem gravar as entregas")
+	rawMapping := flags.String("mapping", "", "mapeamento de colunas em JSON, no formato {\"coluna\": \"campo\"}")
+	flags.Parse(args)
+
+	var mapping map[string]string
+	if *rawMapping != "" {
+		if err := json.Unmarshal([]byte(*rawMapping), &mapping); err != nil {
+			return fmt.Errorf("invalid -mapping, expected a JSON object: %w", err)
+		}
+	}
+
+	input, err := openInput(*file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	rows, err := deliveries.ParseCSV(input, mapping)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signalContext()
+	defer stop()
+	report, err := deliveries.NewService(deliveries.NewRepository(db, cfg.CubicWeightDivisor)).ImportDeliveries(ctx, rows, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if len(report.Rejected) > 0 {
+		return fmt.Errorf("%d of %d rows rejected", len(report.Rejected), report.Total)
+	}
+	return nil
+}
+
+// runExport exporta clientes ou entregas em CSV ou NDJSON, com os mesmos filtros e formato dos endpoints de exportação.
+func runExport(args []string) error {
+	if len(args) == 0 || (args[0] != "clients" && args[0] != "deliveries") {
+		return fmt.Errorf("usage: delivery-api export clients|deliveries [-format csv|ndjson] [-file PATH] [filters]")
+	}
+	resource, args := args[0], args[1:]
+
+	flags := newFlagSet("export "+resource, "export "+resource+" [-format csv|ndjson] [-file PATH] [filters]")
+	format := flags.String("format", export.FormatCSV, "formato do arquivo (csv ou ndjson)")
+	file := flags.String("file", "-", "arquivo de saída (\"-\" para a saída padrão)")
+	var clientFilter clients.Filter
+	var deliveryFilter deliveries.Filter
+	if resource == "clients" {
+		flags.StringVar(&clientFilter.Name, "name", "", "início do nome do cliente")
+		flags.StringVar(&clientFilter.CPF, "cpf", "", "CPF do cliente")
+		flags.StringVar(&clientFilter.CNPJ, "cnpj", "", "CNPJ do cliente")
+	} else {
+		flags.StringVar(&deliveryFilter.ClientCPF, "client-cpf", "", "CPF do cliente")
+		flags.StringVar(&deliveryFilter.ClientName, "client-name", "", "início do nome do cliente")
+		flags.StringVar(&deliveryFilter.Cidade, "cidade", "", "início do nome da cidade")
+		flags.StringVar(&deliveryFilter.Estado, "estado", "", "estado (UF)")
+		flags.StringVar(&deliveryFilter.OrderStatus, "order-status", "", "status do pedido")
+	}
+	flags.Parse(args)
+
+	parsedFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+
+	output, err := createOutput(*file)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	columns := deliveries.ExportColumns
+	if resource == "clients" {
+		columns = clients.ExportColumns
+	}
+	writer, err := export.NewWriter(output, parsedFormat, columns)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	if resource == "clients" {
+		err = clients.NewService(clients.NewRepository(db)).ExportClients(ctx, clientFilter, func(client *clients.Client) error {
+			return writer.Write(clients.ExportRecord(client), client)
+		})
+	} else {
+		err = deliveries.NewService(deliveries.NewRepository(db, cfg.CubicWeightDivisor)).ExportDeliveries(ctx, deliveryFilter, func(d *deliveries.Delivery) error {
+			return writer.Write(deliveries.ExportRecord(d), d)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return output.Close()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"delivery-api/config"
+	"delivery-api/internal/users"
+)
+
+// runUser gerencia os usuários da API.
+//
+//	user create -name NOME -email EMAIL [-role admin|operator]
+func runUser(args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return fmt.Errorf("usage: delivery-api user create -name NAME -email EMAIL [-role admin|operator]")
+	}
+
+	flags := newFlagSet("user create", "user create -name NAME -email EMAIL [-role admin|operator]")
+	name := flags.String("name", "", "nome do usuário")
+	email := flags.String("email", "", "e-mail do usuário (único)")
+	role := flags.String("role", users.RoleOperator, "papel do usuário ("+strings.Join(users.Roles, " ou ")+")")
+	flags.Parse(args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	user, token, err := users.NewService(users.NewRepository(db)).CreateUser(ctx, *name, *email, *role)
+	if errors.Is(err, users.ErrEmailTaken) {
+		return fmt.Errorf("%w: %s", err, *email)
+	}
+	if err != nil {
+		return err
+	}
+
+	// O token só é mostrado agora: no banco fica apenas o hash.
+	fmt.Printf("created user #%d %s <%s> (%s)\n", user.ID, user.Name, user.Email, user.Role)
+	fmt.Fprintln(os.Stderr, "API token (store it now, it cannot be shown again):")
+	fmt.Println(token)
+	return nil
+}